@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -12,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
@@ -27,6 +30,9 @@ type Type string
 const (
 	ReplicaSet       Type   = "ReplicaSet"
 	defaultDBForUser string = "admin"
+
+	// InitScriptsUsername is the name of the user the init scripts connect as.
+	InitScriptsUsername = "mms-init-scripts"
 )
 
 type Phase string
@@ -48,6 +54,23 @@ const (
 
 const (
 	defaultClusterDomain = "cluster.local"
+
+	// oidcKubernetesAuthMechanismProperties makes the drivers authenticate with the token of the Kubernetes
+	// service account of the Pod.
+	oidcKubernetesAuthMechanismProperties = "ENVIRONMENT:k8s"
+
+	defaultRevisionHistoryLimit = 10
+
+	defaultHealthStatusStaleSeconds    = 300
+	defaultMongodUnresponsiveSeconds   = 300
+	defaultLivenessProbePeriodSeconds  = 30
+	defaultLivenessProbeFailures       = 3
+	defaultStartupProbePeriodSeconds   = 10
+	defaultStartupProbeFailureDuration = 24 * 60 * 60
+
+	defaultGeneratedPasswordLength = 32
+
+	defaultPasswordRotationGracePeriodSeconds = 60 * 60
 )
 
 // Connection string options that should be ignored as they are set through other means.
@@ -114,6 +137,10 @@ type MongoDBCommunitySpec struct {
 	// +nullable
 	AdditionalMongodConfig MongodConfiguration `json:"additionalMongodConfig,omitempty"`
 
+	// AuditLog configures the auditing of the mongod processes. It is only available in MongoDB Enterprise.
+	// +optional
+	AuditLog *AuditLog `json:"auditLog,omitempty"`
+
 	// AutomationConfigOverride is merged on top of the operator created automation config. Processes are merged
 	// by name. Currently Only the process.disabled field is supported.
 	AutomationConfigOverride *AutomationConfigOverride `json:"automationConfig,omitempty"`
@@ -132,6 +159,39 @@ type MongoDBCommunitySpec struct {
 	// MemberConfig
 	// +optional
 	MemberConfig []automationconfig.MemberOptions `json:"memberConfig,omitempty"`
+
+	// RevisionHistoryLimit is the number of successfully applied specs the operator keeps
+	// in the spec history ConfigMap of this resource. Defaults to 10.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	RevisionHistoryLimit *int `json:"revisionHistoryLimit,omitempty"`
+
+	// LivenessProbe enables and configures the liveness probes of the agent and mongod containers.
+	// The agent container is restarted if the agent stops updating its health status and the mongod
+	// container is restarted if the agent reports mongod as unresponsive.
+	// +optional
+	LivenessProbe *LivenessProbeConfiguration `json:"livenessProbe,omitempty"`
+
+	// StartupProbe configures the startup probes of the agent and mongod containers. Liveness probes
+	// are only checked once the startup probes succeed, which gives mongod time for WiredTiger recovery.
+	// It requires LivenessProbe to be set.
+	// +optional
+	StartupProbe *StartupProbeConfiguration `json:"startupProbe,omitempty"`
+
+	// ReadinessProbe configures the policies the readiness probe uses to decide whether a member is ready.
+	// They allow trading rollout speed against safety.
+	// +optional
+	ReadinessProbe *ReadinessProbeConfiguration `json:"readinessProbe,omitempty"`
+
+	// Databases are the databases, collections and indexes the operator provisions once the deployment is running.
+	// Collections and indexes which are not listed are left untouched.
+	// +optional
+	Databases []Database `json:"databases,omitempty"`
+
+	// InitScripts are scripts the operator runs once, in order, through a Job after the deployment first reaches
+	// the Running phase. They are typically used to load reference data.
+	// +optional
+	InitScripts []InitScript `json:"initScripts,omitempty"`
 }
 
 // MapWrapper is a wrapper for a map to be used by other structs.
@@ -383,6 +443,171 @@ type AgentConfiguration struct {
 	SystemLog *automationconfig.SystemLog `json:"systemLog,omitempty"`
 }
 
+// AuditLogDestination is where the audit events are written to.
+// +kubebuilder:validation:Enum=file;syslog;console
+type AuditLogDestination string
+
+const (
+	AuditLogDestinationFile    AuditLogDestination = "file"
+	AuditLogDestinationSyslog  AuditLogDestination = "syslog"
+	AuditLogDestinationConsole AuditLogDestination = "console"
+)
+
+// AuditLogFormat is the format of the audit log file.
+// +kubebuilder:validation:Enum=JSON;BSON
+type AuditLogFormat string
+
+const (
+	AuditLogFormatJSON AuditLogFormat = "JSON"
+	AuditLogFormatBSON AuditLogFormat = "BSON"
+)
+
+// AuditLog configures the auditing of the mongod processes.
+type AuditLog struct {
+	// Destination is where the audit events are written to: a file of the logs volume, the syslog or the standard
+	// output of the mongod container. Defaults to file.
+	// +optional
+	Destination AuditLogDestination `json:"destination,omitempty"`
+
+	// Format is the format of the audit log file. Defaults to JSON. It only applies to the file destination.
+	// +optional
+	Format AuditLogFormat `json:"format,omitempty"`
+
+	// Filter is a JSON document selecting the audited events, such as {"atype": {"$in": ["authenticate", "createUser"]}}.
+	// All the events are audited if it isn't set.
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// RuntimeConfiguration lets the filter be changed at runtime with the auditConfig cluster parameter, instead of
+	// being part of the configuration of the processes. It requires MongoDB 5.0 or later and can't be used with
+	// Filter.
+	// +optional
+	RuntimeConfiguration *bool `json:"runtimeConfiguration,omitempty"`
+}
+
+// GetDestination returns where the audit events are written to, a file by default.
+func (a AuditLog) GetDestination() AuditLogDestination {
+	if a.Destination == "" {
+		return AuditLogDestinationFile
+	}
+	return a.Destination
+}
+
+// GetFormat returns the format of the audit log file, JSON by default.
+func (a AuditLog) GetFormat() AuditLogFormat {
+	if a.Format == "" {
+		return AuditLogFormatJSON
+	}
+	return a.Format
+}
+
+// IsRuntimeConfiguration returns true if the filter is configured at runtime.
+func (a AuditLog) IsRuntimeConfiguration() bool {
+	return a.RuntimeConfiguration != nil && *a.RuntimeConfiguration
+}
+
+type LivenessProbeConfiguration struct {
+	// HealthStatusStaleSeconds is the number of seconds after which the agent is considered hung
+	// if it has not updated its health status. Defaults to 300.
+	// +optional
+	// +kubebuilder:validation:Minimum=30
+	HealthStatusStaleSeconds *int `json:"healthStatusStaleSeconds,omitempty"`
+	// MongodUnresponsiveSeconds is the number of seconds after which mongod is considered deadlocked
+	// if the agent could not reach it. Defaults to 300.
+	// +optional
+	// +kubebuilder:validation:Minimum=30
+	MongodUnresponsiveSeconds *int `json:"mongodUnresponsiveSeconds,omitempty"`
+	// PeriodSeconds is how often the liveness probes are performed. Defaults to 30.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds *int `json:"periodSeconds,omitempty"`
+	// FailureThreshold is the number of consecutive failures after which a container is restarted. Defaults to 3.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold *int `json:"failureThreshold,omitempty"`
+}
+
+// GetHealthStatusStaleSeconds returns the number of seconds after which the agent is considered hung.
+func (l *LivenessProbeConfiguration) GetHealthStatusStaleSeconds() int {
+	return intOrDefault(l.HealthStatusStaleSeconds, defaultHealthStatusStaleSeconds)
+}
+
+// GetMongodUnresponsiveSeconds returns the number of seconds after which mongod is considered deadlocked.
+func (l *LivenessProbeConfiguration) GetMongodUnresponsiveSeconds() int {
+	return intOrDefault(l.MongodUnresponsiveSeconds, defaultMongodUnresponsiveSeconds)
+}
+
+// GetPeriodSeconds returns how often the liveness probes are performed.
+func (l *LivenessProbeConfiguration) GetPeriodSeconds() int {
+	return intOrDefault(l.PeriodSeconds, defaultLivenessProbePeriodSeconds)
+}
+
+// GetFailureThreshold returns the number of consecutive failures after which a container is restarted.
+func (l *LivenessProbeConfiguration) GetFailureThreshold() int {
+	return intOrDefault(l.FailureThreshold, defaultLivenessProbeFailures)
+}
+
+type StartupProbeConfiguration struct {
+	// PeriodSeconds is how often the startup probes are performed. Defaults to 10.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds *int `json:"periodSeconds,omitempty"`
+	// FailureThreshold is the number of consecutive failures after which a container which has
+	// not started is restarted. Defaults to 24 hours worth of probes.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold *int `json:"failureThreshold,omitempty"`
+}
+
+// GetPeriodSeconds returns how often the startup probes are performed.
+func (s *StartupProbeConfiguration) GetPeriodSeconds() int {
+	if s == nil {
+		return defaultStartupProbePeriodSeconds
+	}
+	return intOrDefault(s.PeriodSeconds, defaultStartupProbePeriodSeconds)
+}
+
+// GetFailureThreshold returns the number of consecutive failures after which a container which has not started is restarted.
+func (s *StartupProbeConfiguration) GetFailureThreshold() int {
+	defaultFailureThreshold := defaultStartupProbeFailureDuration / s.GetPeriodSeconds()
+	if s == nil {
+		return defaultFailureThreshold
+	}
+	return intOrDefault(s.FailureThreshold, defaultFailureThreshold)
+}
+
+type ReadinessProbeConfiguration struct {
+	// WaitStepGracePeriodSeconds is the number of seconds the agent must have spent on a wait step
+	// before the member is considered ready. Defaults to 15.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	WaitStepGracePeriodSeconds *int `json:"waitStepGracePeriodSeconds,omitempty"`
+	// WaitStepsReady configures whether a member whose agent is waiting, for instance for the
+	// other members to be up, is considered ready. Defaults to true.
+	// +optional
+	WaitStepsReady *bool `json:"waitStepsReady,omitempty"`
+	// WaitStepAllowList contains the agent steps which are considered wait steps,
+	// even if the agent does not report them as such.
+	// +optional
+	WaitStepAllowList []string `json:"waitStepAllowList,omitempty"`
+	// WaitStepDenyList contains the agent steps which are never considered wait steps,
+	// even if the agent reports them as such.
+	// +optional
+	WaitStepDenyList []string `json:"waitStepDenyList,omitempty"`
+	// MongodNotReadySeconds is the number of seconds after which a member is not ready
+	// if the agent could not reach mongod. Defaults to 60.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MongodNotReadySeconds *int `json:"mongodNotReadySeconds,omitempty"`
+}
+
+func intOrDefault(value *int, defaultValue int) int {
+	if value == nil {
+		return defaultValue
+	}
+	return *value
+}
+
 // StatefulSetSpecWrapper is a wrapper around StatefulSetSpec with a custom implementation
 // of MarshalJSON and UnmarshalJSON which delegate to the underlying Spec to avoid CRD pollution.
 
@@ -460,6 +685,187 @@ func (m MongodConfiguration) SetDBPort(port int) MongodConfiguration {
 	return m
 }
 
+// Database is a database provisioned by the operator.
+type Database struct {
+	// Name is the name of the database.
+	Name string `json:"name"`
+
+	// Collections are the collections of the database. MongoDB only creates a database with its first collection.
+	// +kubebuilder:validation:MinItems=1
+	Collections []Collection `json:"collections"`
+
+	// AllowDestructiveChanges allows the operator to drop and create again the collections and indexes of this
+	// database whose options differ from the spec. Dropping a collection deletes its documents. If false, these
+	// differences are only reported in status.databaseDrift.
+	// +optional
+	AllowDestructiveChanges bool `json:"allowDestructiveChanges,omitempty"`
+}
+
+// Collection is a collection provisioned by the operator.
+type Collection struct {
+	// Name is the name of the collection.
+	Name string `json:"name"`
+
+	// Capped makes the collection a capped collection.
+	// +optional
+	Capped *CappedCollectionOptions `json:"capped,omitempty"`
+
+	// TimeSeries makes the collection a time series collection.
+	// +optional
+	TimeSeries *TimeSeriesCollectionOptions `json:"timeSeries,omitempty"`
+
+	// Validator is the query or $jsonSchema document validating the documents of the collection.
+	// It is written in MongoDB Extended JSON.
+	// +kubebuilder:validation:Type=object
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Validator *runtime.RawExtension `json:"validator,omitempty"`
+
+	// ValidationLevel is how strictly the validator is applied to existing documents. Defaults to "strict".
+	// +kubebuilder:validation:Enum=off;strict;moderate
+	// +optional
+	ValidationLevel string `json:"validationLevel,omitempty"`
+
+	// ValidationAction is whether invalid documents are rejected or only logged. Defaults to "error".
+	// +kubebuilder:validation:Enum=error;warn
+	// +optional
+	ValidationAction string `json:"validationAction,omitempty"`
+
+	// Indexes are the indexes of the collection.
+	// +optional
+	Indexes []Index `json:"indexes,omitempty"`
+}
+
+// CappedCollectionOptions are the options of a capped collection.
+type CappedCollectionOptions struct {
+	// Size is the maximum size of the collection in bytes. MongoDB rounds it up to a multiple of 256.
+	// +kubebuilder:validation:Minimum=1
+	Size int64 `json:"size"`
+
+	// Max is the maximum number of documents of the collection.
+	// +optional
+	Max int64 `json:"max,omitempty"`
+}
+
+// TimeSeriesCollectionOptions are the options of a time series collection.
+type TimeSeriesCollectionOptions struct {
+	// TimeField is the field containing the date of each document.
+	TimeField string `json:"timeField"`
+
+	// MetaField is the field containing the metadata identifying the series of each document.
+	// +optional
+	MetaField string `json:"metaField,omitempty"`
+
+	// Granularity is the expected interval between the documents of a series. Defaults to "seconds".
+	// +kubebuilder:validation:Enum=seconds;minutes;hours
+	// +optional
+	Granularity string `json:"granularity,omitempty"`
+
+	// ExpireAfterSeconds is the number of seconds after which the documents are deleted.
+	// +optional
+	ExpireAfterSeconds *int64 `json:"expireAfterSeconds,omitempty"`
+}
+
+// Index is an index provisioned by the operator. Indexes are identified by their name.
+type Index struct {
+	// Name is the name of the index.
+	Name string `json:"name"`
+
+	// Keys are the indexed fields, in order.
+	// +kubebuilder:validation:MinItems=1
+	Keys []IndexKey `json:"keys"`
+
+	// Unique rejects the documents with the same indexed values.
+	// +optional
+	Unique bool `json:"unique,omitempty"`
+
+	// Sparse only indexes the documents containing the indexed fields.
+	// +optional
+	Sparse bool `json:"sparse,omitempty"`
+
+	// ExpireAfterSeconds makes the index a TTL index, deleting the documents this number of seconds after the
+	// date of the indexed field.
+	// +optional
+	ExpireAfterSeconds *int32 `json:"expireAfterSeconds,omitempty"`
+
+	// PartialFilterExpression only indexes the documents matching this query, written in MongoDB Extended JSON.
+	// +kubebuilder:validation:Type=object
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PartialFilterExpression *runtime.RawExtension `json:"partialFilterExpression,omitempty"`
+}
+
+// IndexKey is a field of an index.
+type IndexKey struct {
+	// Field is the name of the indexed field.
+	Field string `json:"field"`
+
+	// Type is 1 or -1 for an ascending or descending index, or one of "text", "2d", "2dsphere" and "hashed".
+	// +kubebuilder:validation:XIntOrString
+	Type intstr.IntOrString `json:"type"`
+}
+
+// InitScriptType is the type of the content of an init script.
+type InitScriptType string
+
+const (
+	// InitScriptJS is a JavaScript file run with mongosh.
+	InitScriptJS InitScriptType = "js"
+	// InitScriptJSON is a JSON array of documents, in Extended JSON, imported with mongoimport.
+	InitScriptJSON InitScriptType = "json"
+	// InitScriptBSON is a BSON dump of a collection restored with mongorestore.
+	InitScriptBSON InitScriptType = "bson"
+)
+
+// InitScript is a script run once to seed the deployment. Its content is read from exactly one of ConfigMapRef
+// and SecretRef.
+type InitScript struct {
+	// Name identifies the script. A script is never run again once a script with this name has completed,
+	// unless a re-run is requested.
+	Name string `json:"name"`
+
+	// Type is the type of the content of the script.
+	// +kubebuilder:validation:Enum=js;json;bson
+	// +kubebuilder:default:=js
+	// +optional
+	Type InitScriptType `json:"type,omitempty"`
+
+	// ConfigMapRef is the key of a ConfigMap holding the script. BSON data must be stored in binaryData.
+	// +optional
+	ConfigMapRef *InitScriptSourceReference `json:"configMapRef,omitempty"`
+
+	// SecretRef is the key of a Secret holding the script.
+	// +optional
+	SecretRef *InitScriptSourceReference `json:"secretRef,omitempty"`
+
+	// Database is the database the JSON or BSON documents are loaded into.
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// Collection is the collection the JSON or BSON documents are loaded into.
+	// +optional
+	Collection string `json:"collection,omitempty"`
+
+	// Image is the image the script runs with. It must contain mongosh for JavaScript scripts, mongoimport for JSON
+	// scripts and mongorestore for BSON scripts. Defaults to the MongoDB image of the deployment.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// GetType returns the type of the script, which defaults to JavaScript.
+func (s InitScript) GetType() InitScriptType {
+	if s.Type == "" {
+		return InitScriptJS
+	}
+	return s.Type
+}
+
+// InitScriptSourceReference is a key of a ConfigMap or Secret in the namespace of the resource.
+type InitScriptSourceReference struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
 type MongoDBUser struct {
 	// Name is the username of the user
 	Name string `json:"name"`
@@ -474,6 +880,20 @@ type MongoDBUser struct {
 	// +optional
 	PasswordSecretRef SecretKeyReference `json:"passwordSecretRef,omitempty"`
 
+	// GeneratePassword makes the operator generate this user's password and store it in the secret
+	// referenced by PasswordSecretRef, if this secret does not exist. The generated secret is owned by the resource.
+	// +optional
+	GeneratePassword bool `json:"generatePassword,omitempty"`
+
+	// PasswordGeneration configures the password generated when GeneratePassword is set.
+	// +optional
+	PasswordGeneration *PasswordGenerationOptions `json:"passwordGeneration,omitempty"`
+
+	// PasswordRotation configures how changes of this user's password are rolled out. If set, the previous password
+	// stays valid for a grace period after the password has been changed.
+	// +optional
+	PasswordRotation *PasswordRotationConfiguration `json:"passwordRotation,omitempty"`
+
 	// Roles is an array of roles assigned to this user
 	Roles []Role `json:"roles"`
 
@@ -492,6 +912,11 @@ type MongoDBUser struct {
 	// +optional
 	ConnectionStringSecretNamespace string `json:"connectionStringSecretNamespace,omitempty"`
 
+	// CertificateSecretName is the name of the secret the operator stores the certificate of this X.509 user in,
+	// when spec.security.tls.autoGenerate or spec.security.tls.certManager is configured. Defaults to "<resource name>-<user name>-certificate".
+	// +optional
+	CertificateSecretName string `json:"certificateSecretName,omitempty"`
+
 	// Additional options to be appended to the connection string.
 	// These options apply only to this user and will override any existing options in the resource.
 	// +kubebuilder:validation:Type=object
@@ -514,6 +939,17 @@ func (m MongoDBUser) GetScramCredentialsSecretName() string {
 	return fmt.Sprintf("%s-%s", m.ScramCredentialsSecretName, "scram-credentials")
 }
 
+// GetShadowScramCredentialsSecretName returns the name of the secret storing the SCRAM credentials of the
+// shadow user created while the password of this user is rotated.
+func (m MongoDBUser) GetShadowScramCredentialsSecretName() string {
+	return fmt.Sprintf("%s-%s", m.GetScramCredentialsSecretName(), "rotating")
+}
+
+// GetShadowUsername returns the name of the shadow user created while the password of this user is rotated.
+func (m MongoDBUser) GetShadowUsername() string {
+	return fmt.Sprintf("%s-%s", m.Name, "rotating")
+}
+
 // GetConnectionStringSecretName gets the connection string secret name provided by the user or generated
 // from the SCRAM user configuration.
 func (m MongoDBUser) GetConnectionStringSecretName(resourceName string) string {
@@ -524,6 +960,16 @@ func (m MongoDBUser) GetConnectionStringSecretName(resourceName string) string {
 	return normalizeName(fmt.Sprintf("%s-%s-%s", resourceName, m.DB, m.Name))
 }
 
+// GetCertificateSecretName returns the name of the secret storing the certificate the operator issues for this
+// X.509 user.
+func (m MongoDBUser) GetCertificateSecretName(resourceName string) string {
+	if m.CertificateSecretName != "" {
+		return m.CertificateSecretName
+	}
+
+	return normalizeName(fmt.Sprintf("%s-%s-certificate", resourceName, m.Name))
+}
+
 // GetConnectionStringSecretNamespace gets the connection string secret namespace provided by the user or generated
 // from the SCRAM user configuration.
 func (m MongoDBUser) GetConnectionStringSecretNamespace(resourceNamespace string) string {
@@ -558,6 +1004,64 @@ func normalizeName(name string) string {
 	return name
 }
 
+// PasswordCharacterClass is a class of characters a generated password can be made of.
+// +kubebuilder:validation:Enum=lowercase;uppercase;digits;symbols
+type PasswordCharacterClass string
+
+const (
+	PasswordCharacterClassLowercase PasswordCharacterClass = "lowercase"
+	PasswordCharacterClassUppercase PasswordCharacterClass = "uppercase"
+	PasswordCharacterClassDigits    PasswordCharacterClass = "digits"
+	PasswordCharacterClassSymbols   PasswordCharacterClass = "symbols"
+)
+
+// PasswordGenerationOptions configures the passwords generated by the operator.
+type PasswordGenerationOptions struct {
+	// Length is the number of characters of the generated password. Defaults to 32.
+	// +kubebuilder:validation:Minimum=8
+	// +kubebuilder:validation:Maximum=256
+	// +optional
+	Length *int `json:"length,omitempty"`
+
+	// CharacterClasses are the classes of characters the generated password is made of. The password contains
+	// at least one character of each class. If not set, the password is made of URL safe base64 characters.
+	// +optional
+	CharacterClasses []PasswordCharacterClass `json:"characterClasses,omitempty"`
+}
+
+// GetLength returns the length of the generated password.
+func (p *PasswordGenerationOptions) GetLength() int {
+	if p == nil || p.Length == nil {
+		return defaultGeneratedPasswordLength
+	}
+	return *p.Length
+}
+
+// GetCharacterClasses returns the classes of characters of the generated password.
+func (p *PasswordGenerationOptions) GetCharacterClasses() []PasswordCharacterClass {
+	if p == nil {
+		return nil
+	}
+	return p.CharacterClasses
+}
+
+// PasswordRotationConfiguration configures the rotation of a user's password.
+type PasswordRotationConfiguration struct {
+	// GracePeriodSeconds is the number of seconds during which both the previous and the new password are valid.
+	// Defaults to 3600.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	GracePeriodSeconds *int `json:"gracePeriodSeconds,omitempty"`
+}
+
+// GetGracePeriod returns the time during which both the previous and the new password are valid.
+func (p *PasswordRotationConfiguration) GetGracePeriod() time.Duration {
+	if p == nil || p.GracePeriodSeconds == nil {
+		return defaultPasswordRotationGracePeriodSeconds * time.Second
+	}
+	return time.Duration(*p.GracePeriodSeconds) * time.Second
+}
+
 // SecretKeyReference is a reference to the secret containing the user's password
 type SecretKeyReference struct {
 	// Name is the name of the secret storing this user's password
@@ -566,6 +1070,11 @@ type SecretKeyReference struct {
 	// Key is the key in the secret storing this password. Defaults to "password"
 	// +optional
 	Key string `json:"key"`
+
+	// Provider is the name of the secret provider of spec.security.secretProviders storing the secret.
+	// Defaults to Kubernetes Secrets.
+	// +optional
+	Provider string `json:"provider,omitempty"`
 }
 
 // Role is the database role this user should have
@@ -585,69 +1094,686 @@ type Security struct {
 	// User-specified custom MongoDB roles that should be configured in the deployment.
 	// +optional
 	Roles []CustomRole `json:"roles,omitempty"`
+	// SecretProviders are the stores, other than Kubernetes Secrets, the referenced secrets can be read from.
+	// They are selected by name in the references to the secrets.
+	// +optional
+	SecretProviders []SecretProvider `json:"secretProviders,omitempty"`
+	// CertificateExpiry configures the warnings about the certificates which are about to expire.
+	// +optional
+	CertificateExpiry *CertificateExpiry `json:"certificateExpiry,omitempty"`
+	// EncryptionAtRest configures the encryption of the data files by the WiredTiger storage engine. It is only
+	// available in MongoDB Enterprise.
+	// +optional
+	EncryptionAtRest *EncryptionAtRest `json:"encryptionAtRest,omitempty"`
 }
 
-// TLS is the configuration used to set up TLS encryption
-type TLS struct {
-	Enabled bool `json:"enabled"`
+// defaultCertificateExpiryWarningThresholdDays are the numbers of days before their expiry at which warnings are
+// emitted for the certificates, when none are configured.
+var defaultCertificateExpiryWarningThresholdDays = []int{30, 7}
 
-	// Optional configures if TLS should be required or optional for connections
+// CertificateExpiry configures the warnings about the certificates which are about to expire.
+type CertificateExpiry struct {
+	// WarningThresholdDays are the numbers of days before its expiry at which a warning event is emitted for a
+	// certificate. Defaults to 30 and 7 days.
+	// +kubebuilder:validation:items:Minimum=1
 	// +optional
-	Optional bool `json:"optional"`
+	WarningThresholdDays []int `json:"warningThresholdDays,omitempty"`
+}
 
-	// CertificateKeySecret is a reference to a Secret containing a private key and certificate to use for TLS.
-	// The key and cert are expected to be PEM encoded and available at "tls.key" and "tls.crt".
-	// This is the same format used for the standard "kubernetes.io/tls" Secret type, but no specific type is required.
-	// Alternatively, an entry tls.pem, containing the concatenation of cert and key, can be provided.
-	// If all of tls.pem, tls.crt and tls.key are present, the tls.pem one needs to be equal to the concatenation of tls.crt and tls.key
+// EncryptionCipherMode is the cipher mode the data files are encrypted with.
+// +kubebuilder:validation:Enum=AES256-CBC;AES256-GCM
+type EncryptionCipherMode string
+
+const (
+	EncryptionCipherModeAES256CBC EncryptionCipherMode = "AES256-CBC"
+	EncryptionCipherModeAES256GCM EncryptionCipherMode = "AES256-GCM"
+)
+
+const (
+	defaultEncryptionKeyFileKey = "encryption-key"
+	defaultKmipPort             = 5696
+)
+
+// EncryptionAtRest configures the encryption of the data files. The master key, which encrypts the keys of the
+// databases, is either a local key read from a secret or managed by a KMIP server.
+type EncryptionAtRest struct {
+	// Enabled encrypts the data files. Encryption at rest can only be enabled or disabled for new deployments, as
+	// the existing data files are not encrypted or decrypted.
+	Enabled bool `json:"enabled"`
+
+	// CipherMode is the cipher mode the data files are encrypted with. Defaults to AES256-CBC.
 	// +optional
-	CertificateKeySecret corev1.LocalObjectReference `json:"certificateKeySecretRef"`
+	CipherMode EncryptionCipherMode `json:"cipherMode,omitempty"`
 
-	// CaCertificateSecret is a reference to a Secret containing the certificate for the CA which signed the server certificates
-	// The certificate is expected to be available under the key "ca.crt"
+	// KeyFileSecretRef is a reference to the secret storing the local master key, a base64 encoded 16 or 32 byte
+	// key. The key defaults to "encryption-key". The local master key can't be changed once the data files are
+	// encrypted with it.
 	// +optional
-	CaCertificateSecret *corev1.LocalObjectReference `json:"caCertificateSecretRef,omitempty"`
+	KeyFileSecretRef *SecretKeyReference `json:"keyFileSecretRef,omitempty"`
 
-	// CaConfigMap is a reference to a ConfigMap containing the certificate for the CA which signed the server certificates
-	// The certificate is expected to be available under the key "ca.crt"
-	// This field is ignored when CaCertificateSecretRef is configured
+	// Kmip configures the KMIP server managing the master key.
 	// +optional
-	CaConfigMap *corev1.LocalObjectReference `json:"caConfigMapRef,omitempty"`
+	Kmip *Kmip `json:"kmip,omitempty"`
 }
 
-type Authentication struct {
-	// Modes is an array specifying which authentication methods should be enabled.
-	Modes []AuthMode `json:"modes"`
+// GetCipherMode returns the cipher mode the data files are encrypted with, AES256-CBC by default.
+func (e EncryptionAtRest) GetCipherMode() EncryptionCipherMode {
+	if e.CipherMode == "" {
+		return EncryptionCipherModeAES256CBC
+	}
+	return e.CipherMode
+}
 
-	// AgentMode contains the authentication mode used by the automation agent.
-	// +optional
-	AgentMode AuthMode `json:"agentMode,omitempty"`
+// GetKeyFileKey returns the key of the local master key in its secret, "encryption-key" by default.
+func (e EncryptionAtRest) GetKeyFileKey() string {
+	if e.KeyFileSecretRef == nil || e.KeyFileSecretRef.Key == "" {
+		return defaultEncryptionKeyFileKey
+	}
+	return e.KeyFileSecretRef.Key
+}
 
-	// AgentCertificateSecret is a reference to a Secret containing the certificate and the key for the automation agent
-	// The secret needs to have available:
-	// - certificate under key: "tls.crt"
-	// - private key under key: "tls.key"
-	// If additionally, tls.pem is present, then it needs to be equal to the concatenation of tls.crt and tls.key
+// Kmip configures the KMIP server managing the master key of the encryption at rest.
+type Kmip struct {
+	// ServerNames are the host names or IP addresses of the KMIP servers. They are tried in order.
+	// +kubebuilder:validation:MinItems=1
+	ServerNames []string `json:"serverNames"`
+
+	// Port is the port of the KMIP servers. Defaults to 5696.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	// +optional
-	AgentCertificateSecret *corev1.LocalObjectReference `json:"agentCertificateSecretRef,omitempty"`
+	Port *int `json:"port,omitempty"`
 
-	// IgnoreUnknownUsers set to true will ensure any users added manually (not through the CRD)
-	// will not be removed.
+	// ClientCertificateSecretRef is a reference to the secret storing the certificate and key the members present
+	// to the KMIP server, in the "tls.crt" and "tls.key" entries or in the "tls.pem" entry.
+	ClientCertificateSecretRef corev1.LocalObjectReference `json:"clientCertificateSecretRef"`
 
-	// TODO: defaults will work once we update to v1 CRD.
+	// CaConfigMapRef is a reference to the ConfigMap storing the CA certificate of the KMIP server in its "ca.crt"
+	// entry. The system CA certificates are used if it isn't set.
+	// +optional
+	CaConfigMapRef *corev1.LocalObjectReference `json:"caConfigMapRef,omitempty"`
 
+	// KeyIdentifier is the identifier of the master key on the KMIP server. The KMIP server creates a master key for
+	// each member if it isn't set. Changing it rotates the master key of the members one at a time.
 	// +optional
-	// +kubebuilder:default:=true
-	// +nullable
-	IgnoreUnknownUsers *bool `json:"ignoreUnknownUsers,omitempty"`
+	KeyIdentifier string `json:"keyIdentifier,omitempty"`
 }
 
-// +kubebuilder:validation:Enum=SCRAM;SCRAM-SHA-256;SCRAM-SHA-1;X509
-type AuthMode string
+// GetPort returns the port of the KMIP servers, 5696 by default.
+func (k Kmip) GetPort() int {
+	if k.Port == nil {
+		return defaultKmipPort
+	}
+	return *k.Port
+}
 
-func IsAuthPresent(authModes []AuthMode, auth string) bool {
-	for _, authMode := range authModes {
-		if string(authMode) == auth {
+// SecretProviderType is the kind of store a secret provider reads secrets from.
+type SecretProviderType string
+
+const (
+	// SecretProviderFile reads the secrets mounted in the operator container, for example by the Secrets Store CSI
+	// Driver, as <directory>/<namespace>/<secret name>/<key>.
+	SecretProviderFile SecretProviderType = "File"
+	// SecretProviderVault reads the secrets of a HashiCorp Vault KV version 2 secrets engine.
+	SecretProviderVault SecretProviderType = "Vault"
+)
+
+// SecretProvider is a store the referenced secrets can be read from. The secrets of a provider are read only, the
+// operator never creates them.
+type SecretProvider struct {
+	// Name identifies the provider in the references to secrets.
+	Name string `json:"name"`
+
+	// Type is the kind of store the secrets are read from.
+	// +kubebuilder:validation:Enum=File;Vault
+	Type SecretProviderType `json:"type"`
+
+	// Vault configures the Vault provider.
+	// +optional
+	Vault *VaultSecretProvider `json:"vault,omitempty"`
+}
+
+// VaultSecretProvider configures how secrets are read from HashiCorp Vault. A secret is read from
+// <mountPath>/data/<pathPrefix>/<secret name>.
+type VaultSecretProvider struct {
+	// Address is the URL of the Vault server.
+	Address string `json:"address"`
+
+	// MountPath is the path of the KV version 2 secrets engine. Defaults to "secret".
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+
+	// PathPrefix is the path of the secrets in the secrets engine.
+	// +optional
+	PathPrefix string `json:"pathPrefix,omitempty"`
+
+	// TokenSecretRef is a reference to the Kubernetes Secret containing the Vault token, under the key "token"
+	// by default.
+	TokenSecretRef SecretKeyReference `json:"tokenSecretRef"`
+
+	// CaConfigMap is a reference to a ConfigMap containing the certificate of the CA which signed the certificate of
+	// the Vault server, under the key "ca.crt". Defaults to the CAs trusted by the operator.
+	// +optional
+	CaConfigMap *corev1.LocalObjectReference `json:"caConfigMapRef,omitempty"`
+}
+
+// GetMountPath returns the path of the KV version 2 secrets engine, "secret" by default.
+func (v VaultSecretProvider) GetMountPath() string {
+	if v.MountPath == "" {
+		return "secret"
+	}
+	return v.MountPath
+}
+
+// GetTokenKey returns the key of the token in its secret, "token" by default.
+func (v VaultSecretProvider) GetTokenKey() string {
+	if v.TokenSecretRef.Key == "" {
+		return "token"
+	}
+	return v.TokenSecretRef.Key
+}
+
+// GetSecretProvider returns the secret provider with the given name.
+func (s Security) GetSecretProvider(name string) (SecretProvider, bool) {
+	for _, provider := range s.SecretProviders {
+		if provider.Name == name {
+			return provider, true
+		}
+	}
+	return SecretProvider{}, false
+}
+
+// TLS is the configuration used to set up TLS encryption
+type TLS struct {
+	Enabled bool `json:"enabled"`
+
+	// Optional configures if TLS should be required or optional for connections
+	// +optional
+	Optional bool `json:"optional"`
+
+	// CertificateKeySecret is a reference to a Secret containing a private key and certificate to use for TLS.
+	// The key and cert are expected to be PEM encoded and available at "tls.key" and "tls.crt".
+	// This is the same format used for the standard "kubernetes.io/tls" Secret type, but no specific type is required.
+	// Alternatively, an entry tls.pem, containing the concatenation of cert and key, can be provided.
+	// If all of tls.pem, tls.crt and tls.key are present, the tls.pem one needs to be equal to the concatenation of tls.crt and tls.key
+	// +optional
+	CertificateKeySecret corev1.LocalObjectReference `json:"certificateKeySecretRef"`
+
+	// CertificateKeySecretProvider is the name of the secret provider of spec.security.secretProviders storing the
+	// secret of CertificateKeySecret. Defaults to Kubernetes Secrets.
+	// +optional
+	CertificateKeySecretProvider string `json:"certificateKeySecretProvider,omitempty"`
+
+	// MemberCertificates references a certificate for each member and arbiter, used instead of the certificate of
+	// CertificateKeySecret shared by all of them.
+	// +optional
+	MemberCertificates *MemberCertificates `json:"memberCertificates,omitempty"`
+
+	// CaCertificateSecret is a reference to a Secret containing the certificate for the CA which signed the server certificates
+	// The certificate is expected to be available under the key "ca.crt"
+	// +optional
+	CaCertificateSecret *corev1.LocalObjectReference `json:"caCertificateSecretRef,omitempty"`
+
+	// CaConfigMap is a reference to a ConfigMap containing the certificate for the CA which signed the server certificates
+	// The certificate is expected to be available under the key "ca.crt"
+	// This field is ignored when CaCertificateSecretRef is configured
+	// +optional
+	CaConfigMap *corev1.LocalObjectReference `json:"caConfigMapRef,omitempty"`
+
+	// AutoGenerate makes the operator create a CA for this resource and issue the server certificate, the agent
+	// certificate when the agent authenticates with X.509, and the certificates of the X.509 users of spec.users.
+	// The certificates are renewed before they expire.
+	// The server certificate is stored in the secret of CertificateKeySecret, "<resource name>-server-tls" by default.
+	// +optional
+	AutoGenerate bool `json:"autoGenerate,omitempty"`
+
+	// CertManager makes the operator request the server certificate, the agent certificate when the agent
+	// authenticates with X.509, and the certificates of the X.509 users of spec.users from cert-manager, by creating
+	// cert-manager Certificate resources. The CA is read from the "ca.crt" entry of the secret of the server certificate.
+	// The server certificate is stored in the secret of CertificateKeySecret, "<resource name>-server-tls" by default.
+	// +optional
+	CertManager *CertManager `json:"certManager,omitempty"`
+
+	// RequireClientCertificates makes the processes reject the TLS connections of clients which don't present a
+	// certificate signed by the CA. The agent presents the certificate of
+	// spec.security.authentication.agentCertificateSecretRef.
+	// +optional
+	RequireClientCertificates bool `json:"requireClientCertificates,omitempty"`
+
+	// CrlSecret is a reference to a Secret containing the certificate revocation list of the CA under the key "crl.pem".
+	// +optional
+	CrlSecret *corev1.LocalObjectReference `json:"crlSecretRef,omitempty"`
+
+	// CrlConfigMap is a reference to a ConfigMap containing the certificate revocation list of the CA under the key
+	// "crl.pem". This field is ignored when CrlSecretRef is configured.
+	// +optional
+	CrlConfigMap *corev1.LocalObjectReference `json:"crlConfigMapRef,omitempty"`
+
+	// DisabledProtocols are the TLS protocols the processes refuse.
+	// +optional
+	DisabledProtocols []TLSProtocol `json:"disabledProtocols,omitempty"`
+
+	// CipherConfig is the OpenSSL cipher string used with TLS 1.2 and earlier, such as "HIGH:!EXPORT:!aNULL@STRENGTH".
+	// +optional
+	CipherConfig string `json:"cipherConfig,omitempty"`
+
+	// CipherSuiteConfig is the OpenSSL cipher suite string used with TLS 1.3, such as "TLS_AES_256_GCM_SHA384".
+	// +optional
+	CipherSuiteConfig string `json:"cipherSuiteConfig,omitempty"`
+
+	// Ocsp configures the Online Certificate Status Protocol checks of the certificates.
+	// +optional
+	Ocsp *TLSOcsp `json:"ocsp,omitempty"`
+
+	// DistributeCA makes the operator publish the CA certificate in the namespaces of the applications connecting to
+	// the resource, and keep it in sync.
+	// +optional
+	DistributeCA *DistributeCA `json:"distributeCA,omitempty"`
+}
+
+// DistributeCAKind is the kind of the resources the CA certificate is distributed in.
+// +kubebuilder:validation:Enum=ConfigMap;Secret
+type DistributeCAKind string
+
+const (
+	DistributeCAKindConfigMap DistributeCAKind = "ConfigMap"
+	DistributeCAKindSecret    DistributeCAKind = "Secret"
+)
+
+// DistributeCA configures the ConfigMaps or Secrets the CA certificate is distributed in. Each of them stores the CA
+// certificate under the key "ca.crt".
+type DistributeCA struct {
+	// NamespaceSelector selects the namespaces the CA certificate is distributed to. An empty selector selects all
+	// the namespaces.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// Kind is the kind of the resources storing the CA certificate. Defaults to "ConfigMap".
+	// +optional
+	Kind DistributeCAKind `json:"kind,omitempty"`
+
+	// Name is the name of the resources storing the CA certificate. Defaults to "<resource name>-ca".
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// GetKind returns the kind of the resources storing the CA certificate.
+func (d DistributeCA) GetKind() DistributeCAKind {
+	if d.Kind == "" {
+		return DistributeCAKindConfigMap
+	}
+	return d.Kind
+}
+
+// DistributedCAStatus is the kind, name and namespaces of the resources the CA certificate is distributed in.
+type DistributedCAStatus struct {
+	Kind DistributeCAKind `json:"kind"`
+	Name string           `json:"name"`
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// IsDistributedIn returns true if the CA certificate is distributed in all the resources of the status.
+func (d DistributedCAStatus) IsDistributedIn(kind DistributeCAKind, name string, namespaces []string) bool {
+	if d.Kind != kind || d.Name != name {
+		return false
+	}
+	for _, namespace := range d.Namespaces {
+		if !slices.Contains(namespaces, namespace) {
+			return false
+		}
+	}
+	return true
+}
+
+// CertManager configures the cert-manager Certificate resources the operator creates.
+type CertManager struct {
+	// IssuerRef references the cert-manager issuer signing the certificates. The issuer must store the CA certificate
+	// in the "ca.crt" entry of the secrets, as the CA and Vault issuers do.
+	IssuerRef CertManagerIssuerReference `json:"issuerRef"`
+
+	// Duration is the validity of the certificates, such as "2160h". Defaults to the one of cert-manager.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// RenewBefore is how long before their expiry the certificates are renewed. Defaults to the one of cert-manager.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// CertManagerIssuerReference references a cert-manager Issuer or ClusterIssuer, or an issuer of an external group.
+type CertManagerIssuerReference struct {
+	// Name is the name of the issuer.
+	Name string `json:"name"`
+
+	// Kind is the kind of the issuer. Defaults to "Issuer".
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Group is the API group of the issuer. Defaults to "cert-manager.io".
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// TLSProtocol is a version of the TLS protocol.
+// +kubebuilder:validation:Enum=TLS1_0;TLS1_1;TLS1_2;TLS1_3
+type TLSProtocol string
+
+const (
+	TLSProtocol10 TLSProtocol = "TLS1_0"
+	TLSProtocol11 TLSProtocol = "TLS1_1"
+	TLSProtocol12 TLSProtocol = "TLS1_2"
+	TLSProtocol13 TLSProtocol = "TLS1_3"
+)
+
+// TLSOcsp configures OCSP stapling and the OCSP validation of the certificates of the peers.
+type TLSOcsp struct {
+	// Enabled turns OCSP stapling and validation on or off. Defaults to true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// StaplingRefreshPeriodSecs is how often the processes refresh the stapled OCSP response of their certificate.
+	// Defaults to the validity of the response.
+	// +optional
+	StaplingRefreshPeriodSecs *int `json:"staplingRefreshPeriodSecs,omitempty"`
+
+	// StaplingTimeoutSecs is how long the processes wait for the OCSP response to staple on startup.
+	// +optional
+	StaplingTimeoutSecs *int `json:"staplingTimeoutSecs,omitempty"`
+
+	// VerifyTimeoutSecs is how long the processes wait for the OCSP response when validating the certificate of a peer.
+	// +optional
+	VerifyTimeoutSecs *int `json:"verifyTimeoutSecs,omitempty"`
+}
+
+// MemberCertificates references the secrets of the certificates of the members and arbiters, in the format of
+// CertificateKeySecret. Each certificate only needs to cover the hostnames of its own member.
+type MemberCertificates struct {
+	// SecretNameTemplate is the name of the secret of each member, in which "{pod}" is replaced by the name of the pod
+	// of the member. For example, "{pod}-cert" references the secrets "<resource name>-0-cert", "<resource name>-1-cert"
+	// and, for arbiters, "<resource name>-arb-0-cert".
+	// +optional
+	SecretNameTemplate string `json:"secretNameTemplate,omitempty"`
+
+	// SecretRefs maps the names of the pods to the secrets of their certificates. They take precedence over
+	// SecretNameTemplate.
+	// +optional
+	SecretRefs map[string]corev1.LocalObjectReference `json:"secretRefs,omitempty"`
+}
+
+type Authentication struct {
+	// Modes is an array specifying which authentication methods should be enabled.
+	Modes []AuthMode `json:"modes"`
+
+	// AgentMode contains the authentication mode used by the automation agent.
+	// +optional
+	AgentMode AuthMode `json:"agentMode,omitempty"`
+
+	// AgentCertificateSecret is a reference to a Secret containing the certificate and the key for the automation agent
+	// The secret needs to have available:
+	// - certificate under key: "tls.crt"
+	// - private key under key: "tls.key"
+	// If additionally, tls.pem is present, then it needs to be equal to the concatenation of tls.crt and tls.key
+	// +optional
+	AgentCertificateSecret *corev1.LocalObjectReference `json:"agentCertificateSecretRef,omitempty"`
+
+	// AgentKeyfileSecretRef is a reference to the keyfile the agent and the processes authenticate with, read from a
+	// secret provider of spec.security.secretProviders. The operator generates the keyfile if it is not set.
+	// +optional
+	AgentKeyfileSecretRef *SecretKeyReference `json:"agentKeyfileSecretRef,omitempty"`
+
+	// ClusterAuthMode is how the members authenticate to each other: with the keyfile, "keyFile", or with the
+	// certificate of ClusterCertificateSecret, "x509". The operator moves the members from one mode to the other
+	// through the transitional modes "sendKeyFile" and "sendX509", one at a time. Modes other than "keyFile" require
+	// TLS. Defaults to "keyFile".
+	// +kubebuilder:validation:Enum=keyFile;sendKeyFile;sendX509;x509
+	// +optional
+	ClusterAuthMode ClusterAuthMode `json:"clusterAuthMode,omitempty"`
+
+	// ClusterCertificateSecret is a reference to a Secret containing the certificate and the key the members present to
+	// each other when ClusterAuthMode isn't "keyFile", in the format of AgentCertificateSecret. Its organization,
+	// organizational unit and domain components must match the ones of the server certificates, and differ from the
+	// ones of the certificates of the agent and the X.509 users.
+	// Defaults to "<resource name>-cluster-tls".
+	// +optional
+	ClusterCertificateSecret *corev1.LocalObjectReference `json:"clusterCertificateSecretRef,omitempty"`
+
+	// IgnoreUnknownUsers set to true will ensure any users added manually (not through the CRD)
+	// will not be removed.
+
+	// TODO: defaults will work once we update to v1 CRD.
+
+	// +optional
+	// +kubebuilder:default:=true
+	// +nullable
+	IgnoreUnknownUsers *bool `json:"ignoreUnknownUsers,omitempty"`
+
+	// AllowedUserNamespaces is the list of namespaces, besides the namespace of this resource, whose
+	// MongoDBCommunityUser and MongoDBCommunityRole resources can create users and roles in this resource.
+	// "*" allows every namespace.
+	// +optional
+	AllowedUserNamespaces []string `json:"allowedUserNamespaces,omitempty"`
+
+	// Ldap configures the LDAP servers used by the LDAP authentication mode, which is only supported by
+	// MongoDB Enterprise.
+	// +optional
+	Ldap *LdapConfiguration `json:"ldap,omitempty"`
+
+	// OidcProviderConfigs are the OpenID Connect identity providers used by the OIDC authentication mode, which is
+	// only supported by MongoDB Enterprise 7.0.11 or later.
+	// +optional
+	OidcProviderConfigs []OidcProviderConfig `json:"oidcProviderConfigs,omitempty"`
+}
+
+// ClusterAuthMode is how the members authenticate to each other.
+type ClusterAuthMode string
+
+const (
+	ClusterAuthModeKeyFile     ClusterAuthMode = "keyFile"
+	ClusterAuthModeSendKeyFile ClusterAuthMode = "sendKeyFile"
+	ClusterAuthModeSendX509    ClusterAuthMode = "sendX509"
+	ClusterAuthModeX509        ClusterAuthMode = "x509"
+)
+
+// LdapTransportSecurity is how mongod connects to the LDAP servers.
+type LdapTransportSecurity string
+
+const (
+	LdapTransportSecurityTLS  LdapTransportSecurity = "tls"
+	LdapTransportSecurityNone LdapTransportSecurity = "none"
+)
+
+// LdapConfiguration configures LDAP authentication and, if AuthzQueryTemplate is set, LDAP authorization.
+type LdapConfiguration struct {
+	// Servers are the LDAP servers, as host or host:port. mongod connects to the first available server.
+	// +kubebuilder:validation:MinItems=1
+	Servers []string `json:"servers"`
+
+	// TransportSecurity is "tls" to connect to the LDAP servers with TLS, or "none".
+	// +kubebuilder:validation:Enum=tls;none
+	// +kubebuilder:default:=tls
+	// +optional
+	TransportSecurity LdapTransportSecurity `json:"transportSecurity,omitempty"`
+
+	// CaConfigMap is a reference to a ConfigMap containing the certificate of the CA which signed the certificates
+	// of the LDAP servers, under the key "ca.crt". Defaults to the CAs trusted by the mongod image.
+	// +optional
+	CaConfigMap *corev1.LocalObjectReference `json:"caConfigMapRef,omitempty"`
+
+	// ValidateLDAPServerConfig makes mongod check that the LDAP servers are reachable on startup. Defaults to true.
+	// +optional
+	ValidateLDAPServerConfig *bool `json:"validateLDAPServerConfig,omitempty"`
+
+	// BindQueryUser is the DN mongod binds as to run queries. mongod binds anonymously if empty.
+	// +optional
+	BindQueryUser string `json:"bindQueryUser,omitempty"`
+
+	// BindQueryPasswordSecretRef is a reference to the secret containing the password of BindQueryUser.
+	// +optional
+	BindQueryPasswordSecretRef *SecretKeyReference `json:"bindQueryPasswordSecretRef,omitempty"`
+
+	// UserToDNMapping maps the usernames clients authenticate with to LDAP DNs. The first matching rule applies,
+	// usernames which match no rule are used as DNs.
+	// +optional
+	UserToDNMapping []LdapUserToDNMapping `json:"userToDNMapping,omitempty"`
+
+	// AuthzQueryTemplate is an RFC 4516 LDAP URL returning the groups of a user, in which {USER} is replaced by the
+	// DN of the user. If set, LDAP authorization is enabled: users are granted the roles of the admin database named
+	// after their groups.
+	// +optional
+	AuthzQueryTemplate string `json:"authzQueryTemplate,omitempty"`
+
+	// TimeoutMS is how long mongod waits for a response of the LDAP servers.
+	// +optional
+	TimeoutMS *int `json:"timeoutMS,omitempty"`
+
+	// UserCacheInvalidationIntervalSeconds is how long mongod caches the users returned by the LDAP servers.
+	// +optional
+	UserCacheInvalidationIntervalSeconds *int `json:"userCacheInvalidationIntervalSeconds,omitempty"`
+}
+
+// LdapUserToDNMapping is a rule mapping usernames to LDAP DNs. Exactly one of Substitution and LdapQuery must be set.
+type LdapUserToDNMapping struct {
+	// Match is a regular expression matched against the username, whose capture groups can be used as {0}, {1}, ...
+	Match string `json:"match"`
+
+	// Substitution is the DN of the user.
+	// +optional
+	Substitution string `json:"substitution,omitempty"`
+
+	// LdapQuery is an RFC 4516 LDAP URL returning the DN of the user.
+	// +optional
+	LdapQuery string `json:"ldapQuery,omitempty"`
+}
+
+// GetTransportSecurity returns how mongod connects to the LDAP servers, TLS by default.
+func (l LdapConfiguration) GetTransportSecurity() LdapTransportSecurity {
+	if l.TransportSecurity == "" {
+		return LdapTransportSecurityTLS
+	}
+	return l.TransportSecurity
+}
+
+// GetBindQueryPasswordKey returns the key of the bind query password in its secret, "password" by default.
+func (l LdapConfiguration) GetBindQueryPasswordKey() string {
+	if l.BindQueryPasswordSecretRef == nil || l.BindQueryPasswordSecretRef.Key == "" {
+		return defaultPasswordKey
+	}
+	return l.BindQueryPasswordSecretRef.Key
+}
+
+// OidcAuthorizationType is how the users authenticated by an OIDC identity provider are granted roles.
+type OidcAuthorizationType string
+
+const (
+	// OidcAuthorizationTypeGroupMembership grants the users the roles of the admin database named
+	// "<configurationName>/<group>", for each group of the groups claim of their token.
+	OidcAuthorizationTypeGroupMembership OidcAuthorizationType = "GroupMembership"
+	// OidcAuthorizationTypeUserID grants the users the roles of the $external user named
+	// "<configurationName>/<user claim>".
+	OidcAuthorizationTypeUserID OidcAuthorizationType = "UserID"
+)
+
+// OidcAuthorizationMethod is who the tokens issued by an OIDC identity provider identify.
+type OidcAuthorizationMethod string
+
+const (
+	// OidcAuthorizationMethodWorkloadIdentityFederation authenticates workloads, such as Pods with a service
+	// account token, which get their tokens themselves.
+	OidcAuthorizationMethodWorkloadIdentityFederation OidcAuthorizationMethod = "WorkloadIdentityFederation"
+	// OidcAuthorizationMethodWorkforceIdentityFederation authenticates people, whose driver gets their tokens
+	// from the identity provider.
+	OidcAuthorizationMethodWorkforceIdentityFederation OidcAuthorizationMethod = "WorkforceIdentityFederation"
+)
+
+// OidcProviderConfig configures an OpenID Connect identity provider.
+type OidcProviderConfig struct {
+	// ConfigurationName identifies the identity provider. It prefixes the names of the users and roles it
+	// authenticates.
+	// +kubebuilder:validation:Pattern=^[A-Za-z0-9_-]+$
+	ConfigurationName string `json:"configurationName"`
+
+	// IssuerURI is the issuer of the tokens, whose OpenID Connect discovery document mongod reads.
+	IssuerURI string `json:"issuerURI"`
+
+	// Audience is the audience the tokens must be issued for.
+	Audience string `json:"audience"`
+
+	// AuthorizationType is how the users are granted roles.
+	// +kubebuilder:validation:Enum=GroupMembership;UserID
+	// +kubebuilder:default:=UserID
+	// +optional
+	AuthorizationType OidcAuthorizationType `json:"authorizationType,omitempty"`
+
+	// AuthorizationMethod is whether the tokens identify workloads or people.
+	// +kubebuilder:validation:Enum=WorkloadIdentityFederation;WorkforceIdentityFederation
+	// +kubebuilder:default:=WorkloadIdentityFederation
+	// +optional
+	AuthorizationMethod OidcAuthorizationMethod `json:"authorizationMethod,omitempty"`
+
+	// UserClaim is the claim of the tokens identifying the user. Defaults to "sub".
+	// +optional
+	UserClaim string `json:"userClaim,omitempty"`
+
+	// GroupsClaim is the claim of the tokens listing the groups of the user. Required by the GroupMembership
+	// authorization type.
+	// +optional
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+
+	// ClientId is the client the drivers request tokens as. Required by the WorkforceIdentityFederation
+	// authorization method.
+	// +optional
+	ClientId string `json:"clientId,omitempty"`
+
+	// RequestedScopes are the scopes the drivers request tokens with, for the WorkforceIdentityFederation
+	// authorization method.
+	// +optional
+	RequestedScopes []string `json:"requestedScopes,omitempty"`
+}
+
+// GetAuthorizationType returns how the users are granted roles, by UserID by default.
+func (o OidcProviderConfig) GetAuthorizationType() OidcAuthorizationType {
+	if o.AuthorizationType == "" {
+		return OidcAuthorizationTypeUserID
+	}
+	return o.AuthorizationType
+}
+
+// GetAuthorizationMethod returns whether the tokens identify workloads or people, workloads by default.
+func (o OidcProviderConfig) GetAuthorizationMethod() OidcAuthorizationMethod {
+	if o.AuthorizationMethod == "" {
+		return OidcAuthorizationMethodWorkloadIdentityFederation
+	}
+	return o.AuthorizationMethod
+}
+
+// GetUserClaim returns the claim of the tokens identifying the user, "sub" by default.
+func (o OidcProviderConfig) GetUserClaim() string {
+	if o.UserClaim == "" {
+		return "sub"
+	}
+	return o.UserClaim
+}
+
+// IsUserNamespaceAllowed returns true if the MongoDBCommunityUser and MongoDBCommunityRole resources of the given
+// namespace can create users and roles in a resource in resourceNamespace.
+func (a Authentication) IsUserNamespaceAllowed(namespace, resourceNamespace string) bool {
+	if namespace == resourceNamespace {
+		return true
+	}
+	for _, allowed := range a.AllowedUserNamespaces {
+		if allowed == "*" || allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// +kubebuilder:validation:Enum=SCRAM;SCRAM-SHA-256;SCRAM-SHA-1;X509;LDAP;OIDC
+type AuthMode string
+
+func IsAuthPresent(authModes []AuthMode, auth string) bool {
+	for _, authMode := range authModes {
+		if string(authMode) == auth {
 			return true
 		}
 	}
@@ -664,6 +1790,10 @@ func ConvertAuthModeToAuthMechanism(authModeLabel AuthMode) string {
 		return constants.Sha1
 	case "X509":
 		return constants.X509
+	case "LDAP":
+		return constants.Ldap
+	case "OIDC":
+		return constants.Oidc
 	default:
 		return ""
 	}
@@ -682,6 +1812,210 @@ type MongoDBCommunityStatus struct {
 	CurrentMongoDBArbiters             int `json:"currentMongoDBArbiters,omitempty"`
 
 	Message string `json:"message,omitempty"`
+
+	// UserPasswordRotations tracks the password rotations of the users configured with passwordRotation.
+	// +optional
+	UserPasswordRotations []UserPasswordRotation `json:"userPasswordRotations,omitempty"`
+
+	// AgentCredentialsRotation tracks the last rotation of the agent password and keyfile.
+	// +optional
+	AgentCredentialsRotation *AgentCredentialsRotation `json:"agentCredentialsRotation,omitempty"`
+
+	// UserResources are the MongoDBCommunityUser resources whose users have been created in this resource.
+	// +optional
+	UserResources []UserResourceReference `json:"userResources,omitempty"`
+
+	// DatabaseDrift lists the differences between spec.databases and the deployment which the operator did not
+	// change, because they require destructive changes.
+	// +optional
+	DatabaseDrift []DatabaseDrift `json:"databaseDrift,omitempty"`
+
+	// InitScripts tracks the runs of spec.initScripts.
+	// +optional
+	InitScripts *InitScriptsStatus `json:"initScripts,omitempty"`
+
+	// TLSMode is the TLS mode all the processes have reached. TLS is enabled and disabled one mode at a time,
+	// through allowTLS and preferTLS, so that the clients can move to or away from TLS in between.
+	// +kubebuilder:validation:Enum=disabled;allowTLS;preferTLS;requireTLS
+	// +optional
+	TLSMode automationconfig.TLSMode `json:"tlsMode,omitempty"`
+
+	// ClusterAuthMode is the cluster authentication mode all the processes have reached. The mode changes one step at
+	// a time, through sendKeyFile and sendX509, so that the members can always authenticate to each other.
+	// +kubebuilder:validation:Enum=keyFile;sendKeyFile;sendX509;x509
+	// +optional
+	ClusterAuthMode ClusterAuthMode `json:"clusterAuthMode,omitempty"`
+
+	// Certificates are the certificates the deployment is configured with, and their expiry.
+	// +optional
+	Certificates []CertificateStatus `json:"certificates,omitempty"`
+
+	// DistributedCA records the ConfigMaps or Secrets the CA certificate is distributed in, so that they are only
+	// looked up in all the namespaces when some of them must be deleted.
+	// +optional
+	DistributedCA *DistributedCAStatus `json:"distributedCA,omitempty"`
+
+	// Conditions are the latest observations of the state of the resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// CertificateType is the use of a certificate in the deployment.
+type CertificateType string
+
+const (
+	CertificateTypeCA         CertificateType = "CA"
+	CertificateTypeServer     CertificateType = "Server"
+	CertificateTypeAgent      CertificateType = "Agent"
+	CertificateTypeCluster    CertificateType = "Cluster"
+	CertificateTypePrometheus CertificateType = "Prometheus"
+)
+
+// ConditionCertificatesExpiring is the type of the condition which is true when a certificate expires within the
+// largest warning threshold, or has expired.
+const ConditionCertificatesExpiring = "CertificatesExpiring"
+
+// CertificateStatus is the expiry of a certificate the deployment is configured with.
+type CertificateStatus struct {
+	// Type is the use of the certificate.
+	Type CertificateType `json:"type"`
+	// Source is the name of the Secret or ConfigMap the certificate is read from.
+	Source string `json:"source"`
+	// Subject is the distinguished name of the certificate.
+	Subject string `json:"subject"`
+	// NotAfter is the time at which the certificate expires.
+	NotAfter metav1.Time `json:"notAfter"`
+	// WarningThresholdDays is the smallest warning threshold the certificate expires within, or 0 once it has
+	// expired.
+	// +optional
+	WarningThresholdDays *int `json:"warningThresholdDays,omitempty"`
+}
+
+// InitScriptsStatus is the state of the init scripts.
+type InitScriptsStatus struct {
+	// Completed are the names of the scripts which ran successfully.
+	// +optional
+	Completed []string `json:"completed,omitempty"`
+	// Running are the names of the scripts run by the current Job.
+	// +optional
+	Running []string `json:"running,omitempty"`
+	// Failed are the names of the scripts run by a Job which failed. They are not run again until the Job is
+	// deleted or a re-run is requested.
+	// +optional
+	Failed []string `json:"failed,omitempty"`
+	// Message explains why the Job failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// RerunTrigger is the value of the annotation which requested the last re-run of the scripts.
+	// +optional
+	RerunTrigger string `json:"rerunTrigger,omitempty"`
+}
+
+// IsCompleted returns true if the script with the given name ran successfully.
+func (s *InitScriptsStatus) IsCompleted(name string) bool {
+	if s == nil {
+		return false
+	}
+	for _, completed := range s.Completed {
+		if completed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DatabaseDrift is a difference between a collection or index of spec.databases and the deployment.
+type DatabaseDrift struct {
+	Database   string `json:"database"`
+	Collection string `json:"collection"`
+	// +optional
+	Index   string `json:"index,omitempty"`
+	Message string `json:"message"`
+}
+
+// UserResourceReference is a reference to a MongoDBCommunityUser resource and the user it created.
+type UserResourceReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Username  string `json:"username"`
+	DB        string `json:"db"`
+}
+
+// GetScramCredentialsSecretName returns the name of the secret storing the SCRAM credentials of the referenced user.
+func (u UserResourceReference) GetScramCredentialsSecretName() string {
+	return userResourceScramCredentialsSecretName(u.Namespace, u.Name)
+}
+
+// AgentCredentialsRotationPhase is the phase of a rotation of the agent password and keyfile.
+type AgentCredentialsRotationPhase string
+
+const (
+	// AgentCredentialsRotationAddingKey is the phase during which the processes are restarted with a keyfile
+	// containing both the previous and the new key, and the agent is granted its new password.
+	AgentCredentialsRotationAddingKey AgentCredentialsRotationPhase = "AddingKey"
+	// AgentCredentialsRotationRemovingKey is the phase during which the processes are restarted with a keyfile
+	// containing only the new key.
+	AgentCredentialsRotationRemovingKey AgentCredentialsRotationPhase = "RemovingKey"
+	// AgentCredentialsRotationCompleted is the phase of a rotation whose previous key has been removed.
+	AgentCredentialsRotationCompleted AgentCredentialsRotationPhase = "Completed"
+)
+
+// AgentCredentialsRotation is the state of a rotation of the agent password and keyfile.
+type AgentCredentialsRotation struct {
+	// Trigger is the value of the annotation which requested the rotation.
+	Trigger string `json:"trigger"`
+	// Phase is the phase of the rotation.
+	Phase AgentCredentialsRotationPhase `json:"phase"`
+	// LastTransitionTime is the time at which the rotation entered its current phase.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// UserPasswordRotationPhase is the phase of a user password rotation.
+type UserPasswordRotationPhase string
+
+const (
+	// PasswordRotationOverlapping is the phase during which the user keeps its previous password and
+	// the new password is granted to a shadow user.
+	PasswordRotationOverlapping UserPasswordRotationPhase = "Overlapping"
+	// PasswordRotationRetiring is the phase during which the user has the new password and
+	// the shadow user is kept for the clients which still use it.
+	PasswordRotationRetiring UserPasswordRotationPhase = "Retiring"
+	// PasswordRotationCompleted is the phase of a rotation whose shadow user has been deleted.
+	PasswordRotationCompleted UserPasswordRotationPhase = "Completed"
+)
+
+// UserPasswordRotation is the state of the password rotation of a user.
+type UserPasswordRotation struct {
+	// Username is the name of the user whose password is rotated.
+	Username string `json:"username"`
+	// DB is the database the user is stored in.
+	DB string `json:"db"`
+	// ShadowUsername is the name of the temporary user which is granted the new password while the
+	// previous password is still valid.
+	ShadowUsername string `json:"shadowUsername"`
+	// Phase is the phase of the rotation.
+	Phase UserPasswordRotationPhase `json:"phase"`
+	// StartedAt is the time at which the password change was detected.
+	StartedAt metav1.Time `json:"startedAt"`
+	// LastTransitionTime is the time at which the rotation entered its current phase.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// IsInProgress returns true if the shadow user of this rotation exists.
+func (r UserPasswordRotation) IsInProgress() bool {
+	return r.Phase == PasswordRotationOverlapping || r.Phase == PasswordRotationRetiring
+}
+
+// GetUserPasswordRotation returns the password rotation of the given user, if there is one.
+func (s MongoDBCommunityStatus) GetUserPasswordRotation(username, db string) (UserPasswordRotation, bool) {
+	for _, rotation := range s.UserPasswordRotations {
+		if rotation.Username == username && rotation.DB == db {
+			return rotation, true
+		}
+	}
+	return UserPasswordRotation{}, false
 }
 
 // +kubebuilder:object:root=true
@@ -722,6 +2056,33 @@ func (m *MongoDBCommunity) GetAgentKeyfileSecretNamespacedName() types.Namespace
 	return types.NamespacedName{Name: m.Name + "-keyfile", Namespace: m.Namespace}
 }
 
+// InitScriptsPasswordSecretNamespacedName is the secret holding the generated password of the user the init
+// scripts connect as.
+func (m *MongoDBCommunity) InitScriptsPasswordSecretNamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: m.Name + "-init-scripts-password", Namespace: m.Namespace}
+}
+
+// InitScriptsJobNamespacedName is the Job running the init scripts.
+func (m *MongoDBCommunity) InitScriptsJobNamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: m.Name + "-init-scripts", Namespace: m.Namespace}
+}
+
+// InitScriptsUser returns the user the init scripts connect as. It is created by the operator while
+// spec.initScripts is set.
+func (m *MongoDBCommunity) InitScriptsUser() authtypes.User {
+	return authtypes.User{
+		Username: InitScriptsUsername,
+		Database: defaultDBForUser,
+		Roles: []authtypes.Role{
+			{Name: "readWriteAnyDatabase", Database: defaultDBForUser},
+			{Name: "dbAdminAnyDatabase", Database: defaultDBForUser},
+		},
+		PasswordSecretKey:          defaultPasswordKey,
+		PasswordSecretName:         m.InitScriptsPasswordSecretNamespacedName().Name,
+		ScramCredentialsSecretName: m.Name + "-init-scripts-scram-credentials",
+	}
+}
+
 func (m *MongoDBCommunity) GetOwnerReferences() []metav1.OwnerReference {
 	ownerReference := *metav1.NewControllerRef(m, schema.GroupVersionKind{
 		Group:   GroupVersion.Group,
@@ -804,6 +2165,14 @@ func (m *MongoDBCommunity) GetAuthUsers() []authtypes.User {
 			users[i].ScramCredentialsSecretName = u.GetScramCredentialsSecretName()
 			users[i].PasswordSecretKey = u.GetPasswordSecretKey()
 			users[i].PasswordSecretName = u.PasswordSecretRef.Name
+
+			if rotation, ok := m.Status.GetUserPasswordRotation(u.Name, u.DB); ok && rotation.IsInProgress() {
+				users[i].PasswordRotation = &authtypes.PasswordRotation{
+					RetainCredentials:                rotation.Phase == PasswordRotationOverlapping,
+					ShadowUsername:                   rotation.ShadowUsername,
+					ShadowScramCredentialsSecretName: u.GetShadowScramCredentialsSecretName(),
+				}
+			}
 		}
 	}
 	return users
@@ -840,28 +2209,78 @@ func (m *MongoDBCommunitySpec) GetAgentCertificateRef() string {
 // If spec.security.authentication.modes has one element, the agent auth mode will default to that.
 // If spec.security.authentication.modes has more than one element, then agent auth will need to be specified,
 // with one exception: if spec.security.authentication.modes contains only SCRAM-SHA-256 and SCRAM-SHA-1, then it defaults to SCRAM-SHA-256 (for backwards compatibility).
+// LDAP is ignored, as the agent can't authenticate with it.
 func (m *MongoDBCommunitySpec) GetAgentAuthMode() AuthMode {
 	if m.Security.Authentication.AgentMode != "" {
 		return m.Security.Authentication.AgentMode
 	}
 
+	var modes []AuthMode
+	for _, mode := range m.Security.Authentication.Modes {
+		if mode != "LDAP" && mode != "OIDC" {
+			modes = append(modes, mode)
+		}
+	}
+
 	if len(m.Security.Authentication.Modes) == 0 {
 		return "SCRAM-SHA-256"
-	} else if len(m.Security.Authentication.Modes) == 1 {
-		return m.Security.Authentication.Modes[0]
-	} else if len(m.Security.Authentication.Modes) == 2 {
-		if (IsAuthPresent(m.Security.Authentication.Modes, "SCRAM") || IsAuthPresent(m.Security.Authentication.Modes, "SCRAM-SHA-256")) &&
-			IsAuthPresent(m.Security.Authentication.Modes, "SCRAM-SHA-1") {
+	} else if len(modes) == 1 {
+		return modes[0]
+	} else if len(modes) == 2 {
+		if (IsAuthPresent(modes, "SCRAM") || IsAuthPresent(modes, "SCRAM-SHA-256")) &&
+			IsAuthPresent(modes, "SCRAM-SHA-1") {
 			return "SCRAM-SHA-256"
 		}
 	}
 	return ""
 }
 
+// IsLdapEnabled returns true if clients can authenticate with LDAP.
+func (m *MongoDBCommunitySpec) IsLdapEnabled() bool {
+	return IsAuthPresent(m.Security.Authentication.Modes, "LDAP")
+}
+
+// IsOidcEnabled returns true if clients can authenticate with OpenID Connect.
+func (m *MongoDBCommunitySpec) IsOidcEnabled() bool {
+	return IsAuthPresent(m.Security.Authentication.Modes, "OIDC")
+}
+
+// IsEncryptionAtRestEnabled returns true if the data files are encrypted.
+func (m *MongoDBCommunitySpec) IsEncryptionAtRestEnabled() bool {
+	return m.Security.EncryptionAtRest != nil && m.Security.EncryptionAtRest.Enabled
+}
+
 func (m *MongoDBCommunitySpec) IsAgentX509() bool {
 	return m.GetAgentAuthMode() == "X509"
 }
 
+// IsAgentCertificateRequired returns true if the agent presents the certificate of AgentCertificateSecret to the
+// processes, either to authenticate with X.509 or because they require client certificates.
+func (m *MongoDBCommunitySpec) IsAgentCertificateRequired() bool {
+	return m.IsAgentX509() || (m.Security.TLS.Enabled && m.Security.TLS.RequireClientCertificates)
+}
+
+// GetCertificateExpiryWarningThresholdDays returns the numbers of days before its expiry at which a warning is
+// emitted for a certificate, from the largest to the smallest.
+func (m *MongoDBCommunitySpec) GetCertificateExpiryWarningThresholdDays() []int {
+	thresholds := defaultCertificateExpiryWarningThresholdDays
+	if m.Security.CertificateExpiry != nil && len(m.Security.CertificateExpiry.WarningThresholdDays) > 0 {
+		thresholds = m.Security.CertificateExpiry.WarningThresholdDays
+	}
+	thresholds = slices.Clone(thresholds)
+	slices.Sort(thresholds)
+	slices.Reverse(thresholds)
+	return slices.Compact(thresholds)
+}
+
+// GetRevisionHistoryLimit returns the number of applied specs which should be kept in the spec history.
+func (m *MongoDBCommunitySpec) GetRevisionHistoryLimit() int {
+	if m.RevisionHistoryLimit != nil && *m.RevisionHistoryLimit >= 0 {
+		return *m.RevisionHistoryLimit
+	}
+	return defaultRevisionHistoryLimit
+}
+
 // IsStillScaling returns true if this resource is currently scaling,
 // considering both arbiters and regular members.
 func (m *MongoDBCommunity) IsStillScaling() bool {
@@ -901,6 +2320,145 @@ func (m *MongoDBCommunity) AutomationConfigArbitersThisReconciliation() int {
 	})
 }
 
+// clusterAuthModes are the cluster authentication modes, in the order the processes go through when moving to X.509.
+var clusterAuthModes = []ClusterAuthMode{
+	ClusterAuthModeKeyFile,
+	ClusterAuthModeSendKeyFile,
+	ClusterAuthModeSendX509,
+	ClusterAuthModeX509,
+}
+
+// DesiredClusterAuthMode returns the cluster authentication mode the processes must eventually use.
+func (m *MongoDBCommunity) DesiredClusterAuthMode() ClusterAuthMode {
+	if m.Spec.Security.Authentication.ClusterAuthMode == "" {
+		return ClusterAuthModeKeyFile
+	}
+	return m.Spec.Security.Authentication.ClusterAuthMode
+}
+
+// CurrentClusterAuthMode returns the cluster authentication mode all the processes have reached. Resources without a
+// recorded mode are considered to be in their desired mode.
+func (m *MongoDBCommunity) CurrentClusterAuthMode() ClusterAuthMode {
+	if m.Status.ClusterAuthMode == "" {
+		return m.DesiredClusterAuthMode()
+	}
+	return m.Status.ClusterAuthMode
+}
+
+// ClusterAuthModeThisReconciliation returns the cluster authentication mode the processes must use in this
+// reconciliation: the mode next to the current one, in the direction of the desired one. The processes only move
+// away from the keyfile once they connect to each other with TLS.
+func (m *MongoDBCommunity) ClusterAuthModeThisReconciliation() ClusterAuthMode {
+	current, desired := clusterAuthModeIndex(m.CurrentClusterAuthMode()), clusterAuthModeIndex(m.DesiredClusterAuthMode())
+	switch {
+	case current < desired:
+		if current == 0 && !m.isTLSUsedBetweenMembers() {
+			return clusterAuthModes[current]
+		}
+		return clusterAuthModes[current+1]
+	case current > desired:
+		return clusterAuthModes[current-1]
+	default:
+		return clusterAuthModes[current]
+	}
+}
+
+// IsClusterCertificateRequired returns true if the members present the certificate of ClusterCertificateSecret to
+// each other, before, during or after this reconciliation.
+func (m *MongoDBCommunity) IsClusterCertificateRequired() bool {
+	return m.CurrentClusterAuthMode() != ClusterAuthModeKeyFile || m.DesiredClusterAuthMode() != ClusterAuthModeKeyFile
+}
+
+// isTLSUsedBetweenMembers returns true if all the processes connect to each other with TLS.
+func (m *MongoDBCommunity) isTLSUsedBetweenMembers() bool {
+	return tlsModeIndex(m.CurrentTLSMode()) >= tlsModeIndex(automationconfig.TLSModePreferred)
+}
+
+func clusterAuthModeIndex(mode ClusterAuthMode) int {
+	for i, m := range clusterAuthModes {
+		if m == mode {
+			return i
+		}
+	}
+	return 0
+}
+
+// tlsModes are the TLS modes, in the order the processes go through when TLS is enabled.
+var tlsModes = []automationconfig.TLSMode{
+	automationconfig.TLSModeDisabled,
+	automationconfig.TLSModeAllowed,
+	automationconfig.TLSModePreferred,
+	automationconfig.TLSModeRequired,
+}
+
+// DesiredTLSMode returns the TLS mode the processes must eventually use.
+func (m *MongoDBCommunity) DesiredTLSMode() automationconfig.TLSMode {
+	if !m.Spec.Security.TLS.Enabled {
+		return automationconfig.TLSModeDisabled
+	}
+	if m.Spec.Security.TLS.Optional {
+		// preferTLS requires server-server connections to use TLS but makes it optional for clients.
+		return automationconfig.TLSModePreferred
+	}
+	return automationconfig.TLSModeRequired
+}
+
+// CurrentTLSMode returns the TLS mode all the processes have reached. Resources without a recorded mode are new ones,
+// which are considered to be in their desired mode: the operator records the mode of the deployed processes of the
+// resources created by a previous version of the operator before reconciling them.
+func (m *MongoDBCommunity) CurrentTLSMode() automationconfig.TLSMode {
+	if m.Status.TLSMode == "" {
+		return m.DesiredTLSMode()
+	}
+	return m.Status.TLSMode
+}
+
+// TLSModeThisReconciliation returns the TLS mode the processes must use in this reconciliation: the mode next to
+// the current one, in the direction of the desired one. The processes only stop connecting to each other with TLS
+// once they authenticate to each other with the keyfile.
+func (m *MongoDBCommunity) TLSModeThisReconciliation() automationconfig.TLSMode {
+	current, desired := tlsModeIndex(m.CurrentTLSMode()), tlsModeIndex(m.DesiredTLSMode())
+	switch {
+	case current < desired:
+		return tlsModes[current+1]
+	case current > desired:
+		if tlsModes[current] == automationconfig.TLSModePreferred && m.CurrentClusterAuthMode() != ClusterAuthModeKeyFile {
+			return tlsModes[current]
+		}
+		return tlsModes[current-1]
+	default:
+		return tlsModes[current]
+	}
+}
+
+// IsTLSConfiguredThisReconciliation returns true if the processes must have their certificates in this
+// reconciliation, because they use TLS either before or after it.
+func (m *MongoDBCommunity) IsTLSConfiguredThisReconciliation() bool {
+	return m.CurrentTLSMode() != automationconfig.TLSModeDisabled || m.TLSModeThisReconciliation() != automationconfig.TLSModeDisabled
+}
+
+// IsTLSEnabledForClients returns true if the clients must connect with TLS. While the processes accept both TLS
+// and non-TLS connections, the clients already use the desired one.
+func (m *MongoDBCommunity) IsTLSEnabledForClients() bool {
+	switch m.CurrentTLSMode() {
+	case automationconfig.TLSModeDisabled:
+		return false
+	case automationconfig.TLSModeRequired:
+		return true
+	default:
+		return m.Spec.Security.TLS.Enabled
+	}
+}
+
+func tlsModeIndex(mode automationconfig.TLSMode) int {
+	for i, m := range tlsModes {
+		if m == mode {
+			return i
+		}
+	}
+	return 0
+}
+
 // GetOptionsString return a string format of the connection string
 // options that can be appended directly to the connection string.
 //
@@ -987,7 +2545,7 @@ func (m *MongoDBCommunity) MongoAuthUserURI(user authtypes.User, password string
 		strings.Join(m.Hosts(clusterDomain), ","),
 		user.Database,
 		m.Name,
-		m.Spec.Security.TLS.Enabled,
+		m.IsTLSEnabledForClients(),
 		optionsString)
 }
 
@@ -1006,10 +2564,48 @@ func (m *MongoDBCommunity) MongoAuthUserSRVURI(user authtypes.User, password str
 		clusterDomain,
 		user.Database,
 		m.Name,
-		m.Spec.Security.TLS.Enabled,
+		m.IsTLSEnabledForClients(),
 		optionsString)
 }
 
+// MongoOIDCURI returns a mongo uri which workloads can use to connect to this deployment with the OIDC
+// authentication mode, authenticating with the token of their Kubernetes service account.
+func (m *MongoDBCommunity) MongoOIDCURI(clusterDomain string) string {
+	return fmt.Sprintf("mongodb://%s/%s?replicaSet=%s&ssl=%t&authMechanism=%s&authMechanismProperties=%s%s",
+		strings.Join(m.Hosts(clusterDomain), ","),
+		constants.ExternalDB,
+		m.Name,
+		m.IsTLSEnabledForClients(),
+		constants.Oidc,
+		oidcKubernetesAuthMechanismProperties,
+		m.GetOptionsString())
+}
+
+// MongoOIDCSRVURI returns a mongo srv uri which workloads can use to connect to this deployment with the OIDC
+// authentication mode, authenticating with the token of their Kubernetes service account.
+func (m *MongoDBCommunity) MongoOIDCSRVURI(clusterDomain string) string {
+	if clusterDomain == "" {
+		clusterDomain = defaultClusterDomain
+	}
+
+	return fmt.Sprintf("mongodb+srv://%s.%s.svc.%s/%s?replicaSet=%s&ssl=%t&authMechanism=%s&authMechanismProperties=%s%s",
+		m.ServiceName(),
+		m.Namespace,
+		clusterDomain,
+		constants.ExternalDB,
+		m.Name,
+		m.IsTLSEnabledForClients(),
+		constants.Oidc,
+		oidcKubernetesAuthMechanismProperties,
+		m.GetOptionsString())
+}
+
+// OidcConnectionStringSecretNamespacedName returns the NamespacedName of the secret storing the connection strings
+// workloads use to authenticate with OIDC.
+func (m *MongoDBCommunity) OidcConnectionStringSecretNamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: m.Name + "-oidc-connection-string", Namespace: m.Namespace}
+}
+
 func (m *MongoDBCommunity) Hosts(clusterDomain string) []string {
 	hosts := make([]string, m.Spec.Members)
 
@@ -1045,12 +2641,55 @@ func (m *MongoDBCommunity) AutomationConfigSecretName() string {
 	return m.Name + "-config"
 }
 
+// SpecHistoryConfigMapNamespacedName returns the namespaced name of the ConfigMap holding the
+// history of the specs which were successfully applied to this resource.
+func (m *MongoDBCommunity) SpecHistoryConfigMapNamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: m.Name + "-spec-history", Namespace: m.Namespace}
+}
+
 // TLSCaCertificateSecretNamespacedName will get the namespaced name of the Secret containing the CA certificate
 // As the Secret will be mounted to our pods, it has to be in the same namespace as the MongoDB resource
 func (m *MongoDBCommunity) TLSCaCertificateSecretNamespacedName() types.NamespacedName {
+	if m.Spec.Security.TLS.AutoGenerate {
+		return m.TLSAutoGeneratedCANamespacedName()
+	}
+	if m.Spec.Security.TLS.CertManager != nil {
+		// cert-manager stores the CA in the secret of every certificate it issues.
+		if m.HasTLSMemberCertificates() {
+			return m.TLSMemberSecretNamespacedName(m.Name + "-0")
+		}
+		return m.TLSSecretNamespacedName()
+	}
 	return types.NamespacedName{Name: m.Spec.Security.TLS.CaCertificateSecret.Name, Namespace: m.Namespace}
 }
 
+// HasTLSCaCertificateSecret returns true if the CA certificate is stored in a Secret rather than a ConfigMap.
+func (m *MongoDBCommunity) HasTLSCaCertificateSecret() bool {
+	return m.Spec.Security.TLS.CaCertificateSecret != nil || m.Spec.Security.TLS.AutoGenerate || m.Spec.Security.TLS.CertManager != nil
+}
+
+// HasOperatorIssuedTLSCertificates returns true if the operator issues the certificates of the resource, itself or
+// through cert-manager, rather than reading the user provided ones.
+func (m *MongoDBCommunity) HasOperatorIssuedTLSCertificates() bool {
+	return m.Spec.Security.TLS.AutoGenerate || m.Spec.Security.TLS.CertManager != nil
+}
+
+// TLSAutoGeneratedCANamespacedName returns the namespaced name of the Secret storing the certificate and the key of
+// the CA the operator creates when spec.security.tls.autoGenerate is enabled.
+func (m *MongoDBCommunity) TLSAutoGeneratedCANamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: m.Name + "-ca-keypair", Namespace: m.Namespace}
+}
+
+// TLSCrlSecretNamespacedName returns the namespaced name of the Secret containing the certificate revocation list.
+func (m *MongoDBCommunity) TLSCrlSecretNamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: m.Spec.Security.TLS.CrlSecret.Name, Namespace: m.Namespace}
+}
+
+// TLSCrlConfigMapNamespacedName returns the namespaced name of the ConfigMap containing the certificate revocation list.
+func (m *MongoDBCommunity) TLSCrlConfigMapNamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: m.Spec.Security.TLS.CrlConfigMap.Name, Namespace: m.Namespace}
+}
+
 // TLSConfigMapNamespacedName will get the namespaced name of the ConfigMap containing the CA certificate
 // As the ConfigMap will be mounted to our pods, it has to be in the same namespace as the MongoDB resource
 func (m *MongoDBCommunity) TLSConfigMapNamespacedName() types.NamespacedName {
@@ -1059,14 +2698,49 @@ func (m *MongoDBCommunity) TLSConfigMapNamespacedName() types.NamespacedName {
 
 // TLSSecretNamespacedName will get the namespaced name of the Secret containing the server certificate and key
 func (m *MongoDBCommunity) TLSSecretNamespacedName() types.NamespacedName {
+	if m.HasOperatorIssuedTLSCertificates() && m.Spec.Security.TLS.CertificateKeySecret.Name == "" {
+		return types.NamespacedName{Name: m.Name + "-server-tls", Namespace: m.Namespace}
+	}
 	return types.NamespacedName{Name: m.Spec.Security.TLS.CertificateKeySecret.Name, Namespace: m.Namespace}
 }
 
+// HasTLSMemberCertificates returns true if each member has its own certificate.
+func (m *MongoDBCommunity) HasTLSMemberCertificates() bool {
+	return m.Spec.Security.TLS.MemberCertificates != nil
+}
+
+// TLSMemberSecretNamespacedName returns the namespaced name of the Secret containing the certificate and key of the
+// member running in the given pod.
+func (m *MongoDBCommunity) TLSMemberSecretNamespacedName(podName string) types.NamespacedName {
+	memberCertificates := m.Spec.Security.TLS.MemberCertificates
+	if ref, ok := memberCertificates.SecretRefs[podName]; ok {
+		return types.NamespacedName{Name: ref.Name, Namespace: m.Namespace}
+	}
+	return types.NamespacedName{Name: strings.ReplaceAll(memberCertificates.SecretNameTemplate, "{pod}", podName), Namespace: m.Namespace}
+}
+
+// ClusterCertificateSecretNamespacedName returns the namespaced name of the Secret containing the certificate and
+// key the members present to each other.
+func (m *MongoDBCommunity) ClusterCertificateSecretNamespacedName() types.NamespacedName {
+	if ref := m.Spec.Security.Authentication.ClusterCertificateSecret; ref != nil && ref.Name != "" {
+		return types.NamespacedName{Name: ref.Name, Namespace: m.Namespace}
+	}
+	return types.NamespacedName{Name: m.Name + "-cluster-tls", Namespace: m.Namespace}
+}
+
 // PrometheusTLSSecretNamespacedName will get the namespaced name of the Secret containing the server certificate and key
 func (m *MongoDBCommunity) PrometheusTLSSecretNamespacedName() types.NamespacedName {
 	return types.NamespacedName{Name: m.Spec.Prometheus.TLSSecretRef.Name, Namespace: m.Namespace}
 }
 
+// DistributedCAName returns the name of the ConfigMaps or Secrets the CA certificate is distributed in.
+func (m *MongoDBCommunity) DistributedCAName() string {
+	if distributeCA := m.Spec.Security.TLS.DistributeCA; distributeCA != nil && distributeCA.Name != "" {
+		return distributeCA.Name
+	}
+	return m.Name + "-ca"
+}
+
 func (m *MongoDBCommunity) TLSOperatorCASecretNamespacedName() types.NamespacedName {
 	return types.NamespacedName{Name: m.Name + "-ca-certificate", Namespace: m.Namespace}
 }
@@ -1077,6 +2751,18 @@ func (m *MongoDBCommunity) TLSOperatorSecretNamespacedName() types.NamespacedNam
 	return types.NamespacedName{Name: m.Name + "-server-certificate-key", Namespace: m.Namespace}
 }
 
+// TLSOperatorMemberSecretNamespacedName returns the namespaced name of the Secret created by the operator containing
+// the combined certificate and key of the member of the given pod, when each member has its own certificate.
+func (m *MongoDBCommunity) TLSOperatorMemberSecretNamespacedName(podName string) types.NamespacedName {
+	return types.NamespacedName{Name: podName + "-server-certificate-key", Namespace: m.Namespace}
+}
+
+// EncryptionAtRestSecretNamespacedName will get the namespaced name of the Secret created by the operator containing
+// the key material of the encryption at rest mounted in the pods.
+func (m *MongoDBCommunity) EncryptionAtRestSecretNamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: m.Name + "-encryption-at-rest", Namespace: m.Namespace}
+}
+
 // PrometheusTLSOperatorSecretNamespacedName will get the namespaced name of the Secret created by the operator
 // containing the combined certificate and key.
 func (m *MongoDBCommunity) PrometheusTLSOperatorSecretNamespacedName() types.NamespacedName {
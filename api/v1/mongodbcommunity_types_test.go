@@ -9,6 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -463,12 +464,21 @@ func TestMongoDBCommunity_MongoAuthUserSRVURI(t *testing.T) {
 	assert.Equal(t, mdb.MongoAuthUserSRVURI(testuser, "", ""), "mongodb+srv://my-rs-svc.my-namespace.svc.cluster.local/$external?replicaSet=my-rs&ssl=false")
 }
 
+func TestMongoDBCommunity_MongoOIDCURI(t *testing.T) {
+	mdb := newReplicaSet(2, "my-rs", "my-namespace")
+
+	assert.Equal(t, "mongodb://my-rs-0.my-rs-svc.my-namespace.svc.cluster.local:27017,my-rs-1.my-rs-svc.my-namespace.svc.cluster.local:27017/$external?replicaSet=my-rs&ssl=false&authMechanism=MONGODB-OIDC&authMechanismProperties=ENVIRONMENT:k8s", mdb.MongoOIDCURI(""))
+	assert.Equal(t, "mongodb+srv://my-rs-svc.my-namespace.svc.cluster.local/$external?replicaSet=my-rs&ssl=false&authMechanism=MONGODB-OIDC&authMechanismProperties=ENVIRONMENT:k8s", mdb.MongoOIDCSRVURI(""))
+}
+
 func TestConvertAuthModeToAuthMechanism(t *testing.T) {
 	assert.Equal(t, constants.X509, ConvertAuthModeToAuthMechanism("X509"))
 	assert.Equal(t, constants.Sha256, ConvertAuthModeToAuthMechanism("SCRAM"))
 	assert.Equal(t, constants.Sha256, ConvertAuthModeToAuthMechanism("SCRAM-SHA-256"))
 	assert.Equal(t, constants.Sha1, ConvertAuthModeToAuthMechanism("SCRAM-SHA-1"))
-	assert.Equal(t, "", ConvertAuthModeToAuthMechanism("LDAP"))
+	assert.Equal(t, constants.Ldap, ConvertAuthModeToAuthMechanism("LDAP"))
+	assert.Equal(t, constants.Oidc, ConvertAuthModeToAuthMechanism("OIDC"))
+	assert.Equal(t, "", ConvertAuthModeToAuthMechanism("GSSAPI"))
 }
 
 func TestMongoDBCommunity_GetAuthOptions(t *testing.T) {
@@ -674,6 +684,22 @@ func TestMongoDBCommunitySpec_GetAgentAuthMode(t *testing.T) {
 			},
 			want: AuthMode(""),
 		},
+		{
+			name: "LDAP is not used by the agent",
+			fields: fields{
+				agentAuth: "",
+				modes:     []AuthMode{"SCRAM", "LDAP"},
+			},
+			want: AuthMode("SCRAM"),
+		},
+		{
+			name: "OIDC is not used by the agent",
+			fields: fields{
+				agentAuth: "",
+				modes:     []AuthMode{"X509", "OIDC", "LDAP"},
+			},
+			want: AuthMode("X509"),
+		},
 		{
 			name: "Modes array has 3 auth modes",
 			fields: fields{
@@ -692,3 +718,91 @@ func TestMongoDBCommunitySpec_GetAgentAuthMode(t *testing.T) {
 		})
 	}
 }
+
+func TestMongoDBCommunity_TLSModeThisReconciliation(t *testing.T) {
+	tests := []struct {
+		name             string
+		enabled          bool
+		optional         bool
+		current          automationconfig.TLSMode
+		want             automationconfig.TLSMode
+		wantConfigured   bool
+		wantClientsUsing bool
+	}{
+		{name: "New resource with TLS", enabled: true, want: automationconfig.TLSModeRequired, wantConfigured: true, wantClientsUsing: true},
+		{name: "New resource without TLS", want: automationconfig.TLSModeDisabled},
+		{name: "Enabling TLS", enabled: true, current: automationconfig.TLSModeDisabled, want: automationconfig.TLSModeAllowed, wantConfigured: true},
+		{name: "Enabling TLS, TLS allowed", enabled: true, current: automationconfig.TLSModeAllowed, want: automationconfig.TLSModePreferred, wantConfigured: true, wantClientsUsing: true},
+		{name: "Enabling optional TLS, TLS preferred", enabled: true, optional: true, current: automationconfig.TLSModePreferred, want: automationconfig.TLSModePreferred, wantConfigured: true, wantClientsUsing: true},
+		{name: "Disabling TLS", current: automationconfig.TLSModeRequired, want: automationconfig.TLSModePreferred, wantConfigured: true, wantClientsUsing: true},
+		{name: "Disabling TLS, TLS allowed", current: automationconfig.TLSModeAllowed, want: automationconfig.TLSModeDisabled, wantConfigured: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newReplicaSet(3, "mdb", "mdb")
+			m.Spec.Security.TLS.Enabled = tt.enabled
+			m.Spec.Security.TLS.Optional = tt.optional
+			m.Status.TLSMode = tt.current
+			assert.Equal(t, tt.want, m.TLSModeThisReconciliation())
+			assert.Equal(t, tt.wantConfigured, m.IsTLSConfiguredThisReconciliation())
+			assert.Equal(t, tt.wantClientsUsing, m.IsTLSEnabledForClients())
+		})
+	}
+}
+
+func TestMongoDBCommunity_ClusterAuthModeThisReconciliation(t *testing.T) {
+	tests := []struct {
+		name             string
+		desired          ClusterAuthMode
+		current          ClusterAuthMode
+		tlsMode          automationconfig.TLSMode
+		want             ClusterAuthMode
+		wantCertRequired bool
+	}{
+		{name: "New resource", tlsMode: automationconfig.TLSModeRequired, want: ClusterAuthModeKeyFile},
+		{name: "New resource with X.509", desired: ClusterAuthModeX509, tlsMode: automationconfig.TLSModeRequired, want: ClusterAuthModeX509, wantCertRequired: true},
+		{name: "Moving to X.509", desired: ClusterAuthModeX509, current: ClusterAuthModeKeyFile, tlsMode: automationconfig.TLSModeRequired, want: ClusterAuthModeSendKeyFile, wantCertRequired: true},
+		{name: "Moving to X.509, sending the keyfile", desired: ClusterAuthModeX509, current: ClusterAuthModeSendKeyFile, tlsMode: automationconfig.TLSModeRequired, want: ClusterAuthModeSendX509, wantCertRequired: true},
+		{name: "Moving to X.509 while TLS is being enabled", desired: ClusterAuthModeX509, current: ClusterAuthModeKeyFile, tlsMode: automationconfig.TLSModeAllowed, want: ClusterAuthModeKeyFile, wantCertRequired: true},
+		{name: "Moving to the keyfile", current: ClusterAuthModeX509, tlsMode: automationconfig.TLSModeRequired, want: ClusterAuthModeSendX509, wantCertRequired: true},
+		{name: "Moving to the keyfile, sending the keyfile", current: ClusterAuthModeSendKeyFile, tlsMode: automationconfig.TLSModeRequired, want: ClusterAuthModeKeyFile, wantCertRequired: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newReplicaSet(3, "mdb", "mdb")
+			m.Spec.Security.TLS.Enabled = true
+			m.Spec.Security.Authentication.ClusterAuthMode = tt.desired
+			m.Status.ClusterAuthMode = tt.current
+			m.Status.TLSMode = tt.tlsMode
+			assert.Equal(t, tt.want, m.ClusterAuthModeThisReconciliation())
+			assert.Equal(t, tt.wantCertRequired, m.IsClusterCertificateRequired())
+		})
+	}
+}
+
+func TestMongoDBCommunity_TLSIsDisabledOnceMembersUseTheKeyfile(t *testing.T) {
+	m := newReplicaSet(3, "mdb", "mdb")
+	m.Status.TLSMode = automationconfig.TLSModePreferred
+	m.Status.ClusterAuthMode = ClusterAuthModeSendKeyFile
+	assert.Equal(t, automationconfig.TLSModePreferred, m.TLSModeThisReconciliation())
+
+	m.Status.ClusterAuthMode = ClusterAuthModeKeyFile
+	assert.Equal(t, automationconfig.TLSModeAllowed, m.TLSModeThisReconciliation())
+}
+
+func TestLivenessProbeConfiguration_Defaults(t *testing.T) {
+	liveness := LivenessProbeConfiguration{}
+	assert.Equal(t, 300, liveness.GetHealthStatusStaleSeconds())
+	assert.Equal(t, 300, liveness.GetMongodUnresponsiveSeconds())
+	assert.Equal(t, 30, liveness.GetPeriodSeconds())
+	assert.Equal(t, 3, liveness.GetFailureThreshold())
+
+	var startup *StartupProbeConfiguration
+	assert.Equal(t, 10, startup.GetPeriodSeconds())
+	assert.Equal(t, 8640, startup.GetFailureThreshold())
+
+	periodSeconds := 60
+	startup = &StartupProbeConfiguration{PeriodSeconds: &periodSeconds}
+	assert.Equal(t, 60, startup.GetPeriodSeconds())
+	assert.Equal(t, 1440, startup.GetFailureThreshold(), "the default failure threshold covers 24 hours")
+}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// MongoDBCommunityRoleSpec defines the desired state of a MongoDBCommunityRole
+type MongoDBCommunityRoleSpec struct {
+	// MongoDBResourceRef is a reference to the MongoDBCommunity resource the role is created in.
+	MongoDBResourceRef MongoDBCommunityReference `json:"mongodbResourceRef"`
+
+	CustomRole `json:",inline"`
+}
+
+// MongoDBCommunityRoleStatus defines the observed state of a MongoDBCommunityRole
+type MongoDBCommunityRoleStatus struct {
+	Phase   Phase  `json:"phase"`
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// MongoDBCommunityRole is the Schema for a custom role of a MongoDBCommunity resource
+// +kubebuilder:resource:path=mongodbcommunityroles,scope=Namespaced,shortName=mdbcr,singular=mongodbcommunityrole
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Current state of the role"
+// +kubebuilder:printcolumn:name="MongoDB",type="string",JSONPath=".spec.mongodbResourceRef.name",description="MongoDBCommunity resource the role is created in"
+type MongoDBCommunityRole struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MongoDBCommunityRoleSpec   `json:"spec,omitempty"`
+	Status MongoDBCommunityRoleStatus `json:"status,omitempty"`
+}
+
+// MongoDBResourceNamespacedName returns the namespaced name of the MongoDBCommunity resource the role is created in.
+func (r MongoDBCommunityRole) MongoDBResourceNamespacedName() types.NamespacedName {
+	return r.Spec.MongoDBResourceRef.NamespacedName(r.Namespace)
+}
+
+// +kubebuilder:object:root=true
+
+// MongoDBCommunityRoleList contains a list of MongoDBCommunityRole
+type MongoDBCommunityRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MongoDBCommunityRole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MongoDBCommunityRole{}, &MongoDBCommunityRoleList{})
+}
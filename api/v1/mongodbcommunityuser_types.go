@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+// MongoDBCommunityUserSpec defines the desired state of a MongoDBCommunityUser
+type MongoDBCommunityUserSpec struct {
+	// MongoDBResourceRef is a reference to the MongoDBCommunity resource the user is created in.
+	MongoDBResourceRef MongoDBCommunityReference `json:"mongodbResourceRef"`
+
+	// Username is the username of the user. Defaults to the name of the MongoDBCommunityUser resource.
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// DB is the database the user is stored in. Defaults to "admin"
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=admin
+	DB string `json:"db,omitempty"`
+
+	// PasswordSecretRef is a reference to the secret containing this user's password. The secret must be
+	// in the namespace of the MongoDBCommunityUser resource.
+	// +optional
+	PasswordSecretRef SecretKeyReference `json:"passwordSecretRef,omitempty"`
+
+	// Roles is an array of roles assigned to this user
+	Roles []Role `json:"roles"`
+
+	// ConnectionStringSecretName is the name of the secret object created by the operator in the namespace of the
+	// MongoDBCommunityUser resource, which exposes the connection strings for the user.
+	// +optional
+	ConnectionStringSecretName string `json:"connectionStringSecretName,omitempty"`
+
+	// Additional options to be appended to the connection string.
+	// These options apply only to this user and will override any existing options in the resource.
+	// +kubebuilder:validation:Type=object
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +nullable
+	AdditionalConnectionStringConfig MapWrapper `json:"additionalConnectionStringConfig,omitempty"`
+}
+
+// MongoDBCommunityReference is a reference to a MongoDBCommunity resource.
+type MongoDBCommunityReference struct {
+	// Name is the name of the MongoDBCommunity resource.
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the MongoDBCommunity resource. Defaults to the namespace of the referencing resource.
+	// The MongoDBCommunity resource must allow this namespace in spec.security.authentication.allowedUserNamespaces.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// NamespacedName returns the namespaced name of the referenced MongoDBCommunity resource, which is in the given
+// namespace unless the reference sets its own.
+func (r MongoDBCommunityReference) NamespacedName(namespace string) types.NamespacedName {
+	if r.Namespace != "" {
+		namespace = r.Namespace
+	}
+	return types.NamespacedName{Name: r.Name, Namespace: namespace}
+}
+
+// MongoDBCommunityUserStatus defines the observed state of a MongoDBCommunityUser
+type MongoDBCommunityUserStatus struct {
+	Phase   Phase  `json:"phase"`
+	Message string `json:"message,omitempty"`
+
+	// ConnectionStringSecretName is the name of the secret exposing the connection strings for the user.
+	// +optional
+	ConnectionStringSecretName string `json:"connectionStringSecretName,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// MongoDBCommunityUser is the Schema for a user of a MongoDBCommunity resource
+// +kubebuilder:resource:path=mongodbcommunityusers,scope=Namespaced,shortName=mdbcu,singular=mongodbcommunityuser
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Current state of the user"
+// +kubebuilder:printcolumn:name="MongoDB",type="string",JSONPath=".spec.mongodbResourceRef.name",description="MongoDBCommunity resource the user is created in"
+type MongoDBCommunityUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MongoDBCommunityUserSpec   `json:"spec,omitempty"`
+	Status MongoDBCommunityUserStatus `json:"status,omitempty"`
+}
+
+// MongoDBResourceNamespacedName returns the namespaced name of the MongoDBCommunity resource the user is created in.
+func (u MongoDBCommunityUser) MongoDBResourceNamespacedName() types.NamespacedName {
+	return u.Spec.MongoDBResourceRef.NamespacedName(u.Namespace)
+}
+
+// GetUsername returns the username of the user.
+func (u MongoDBCommunityUser) GetUsername() string {
+	if u.Spec.Username == "" {
+		return u.Name
+	}
+	return u.Spec.Username
+}
+
+// GetDB returns the database the user is stored in.
+func (u MongoDBCommunityUser) GetDB() string {
+	if u.Spec.DB == "" {
+		return defaultDBForUser
+	}
+	return u.Spec.DB
+}
+
+// GetOwnerReferences returns the owner references of the resources created for this user.
+func (u MongoDBCommunityUser) GetOwnerReferences() []metav1.OwnerReference {
+	ownerReference := *metav1.NewControllerRef(&u, GroupVersion.WithKind("MongoDBCommunityUser"))
+	return []metav1.OwnerReference{ownerReference}
+}
+
+// GetAuthUser returns the user to create in the given MongoDBCommunity resource.
+func (u MongoDBCommunityUser) GetAuthUser(mdb MongoDBCommunity) authtypes.User {
+	roles := make([]authtypes.Role, len(u.Spec.Roles))
+	for i, r := range u.Spec.Roles {
+		roles[i] = authtypes.Role{
+			Name:     r.Name,
+			Database: r.DB,
+		}
+	}
+
+	connectionStringSecretName := u.Spec.ConnectionStringSecretName
+	if connectionStringSecretName == "" {
+		connectionStringSecretName = normalizeName(fmt.Sprintf("%s-%s-%s", mdb.Name, u.GetDB(), u.GetUsername()))
+	}
+
+	user := authtypes.User{
+		Username:                        u.GetUsername(),
+		Database:                        u.GetDB(),
+		Roles:                           roles,
+		ConnectionStringSecretName:      connectionStringSecretName,
+		ConnectionStringSecretNamespace: u.Namespace,
+		ConnectionStringOptions:         u.Spec.AdditionalConnectionStringConfig.Object,
+	}
+
+	if user.Database != constants.ExternalDB {
+		passwordSecretKey := u.Spec.PasswordSecretRef.Key
+		if passwordSecretKey == "" {
+			passwordSecretKey = defaultPasswordKey
+		}
+		user.PasswordSecretKey = passwordSecretKey
+		user.PasswordSecretName = u.Spec.PasswordSecretRef.Name
+		user.PasswordSecretNamespace = u.Namespace
+		user.ScramCredentialsSecretName = userResourceScramCredentialsSecretName(u.Namespace, u.Name)
+	}
+	return user
+}
+
+// GetReference returns the reference to this resource stored in the status of the MongoDBCommunity resource.
+func (u MongoDBCommunityUser) GetReference() UserResourceReference {
+	return UserResourceReference{
+		Name:      u.Name,
+		Namespace: u.Namespace,
+		Username:  u.GetUsername(),
+		DB:        u.GetDB(),
+	}
+}
+
+// userResourceScramCredentialsSecretName returns the name of the secret storing the SCRAM credentials of the user
+// created by a MongoDBCommunityUser resource. The secret is stored next to the MongoDBCommunity resource, the
+// namespace is part of the name so that the users of different namespaces do not collide.
+func userResourceScramCredentialsSecretName(namespace, name string) string {
+	return normalizeName(fmt.Sprintf("%s-%s-scram-credentials", namespace, name))
+}
+
+// +kubebuilder:object:root=true
+
+// MongoDBCommunityUserList contains a list of MongoDBCommunityUser
+type MongoDBCommunityUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MongoDBCommunityUser `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MongoDBCommunityUser{}, &MongoDBCommunityUserList{})
+}
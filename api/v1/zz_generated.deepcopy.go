@@ -23,6 +23,7 @@ package v1
 import (
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -56,6 +57,42 @@ func (in *AgentConfiguration) DeepCopy() *AgentConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentCredentialsRotation) DeepCopyInto(out *AgentCredentialsRotation) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentCredentialsRotation.
+func (in *AgentCredentialsRotation) DeepCopy() *AgentCredentialsRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentCredentialsRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLog) DeepCopyInto(out *AuditLog) {
+	*out = *in
+	if in.RuntimeConfiguration != nil {
+		in, out := &in.RuntimeConfiguration, &out.RuntimeConfiguration
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLog.
+func (in *AuditLog) DeepCopy() *AuditLog {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLog)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Authentication) DeepCopyInto(out *Authentication) {
 	*out = *in
@@ -69,11 +106,38 @@ func (in *Authentication) DeepCopyInto(out *Authentication) {
 		*out = new(corev1.LocalObjectReference)
 		**out = **in
 	}
+	if in.AgentKeyfileSecretRef != nil {
+		in, out := &in.AgentKeyfileSecretRef, &out.AgentKeyfileSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.ClusterCertificateSecret != nil {
+		in, out := &in.ClusterCertificateSecret, &out.ClusterCertificateSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
 	if in.IgnoreUnknownUsers != nil {
 		in, out := &in.IgnoreUnknownUsers, &out.IgnoreUnknownUsers
 		*out = new(bool)
 		**out = **in
 	}
+	if in.AllowedUserNamespaces != nil {
+		in, out := &in.AllowedUserNamespaces, &out.AllowedUserNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ldap != nil {
+		in, out := &in.Ldap, &out.Ldap
+		*out = new(LdapConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OidcProviderConfigs != nil {
+		in, out := &in.OidcProviderConfigs, &out.OidcProviderConfigs
+		*out = make([]OidcProviderConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Authentication.
@@ -134,6 +198,140 @@ func (in *AutomationConfigOverride) DeepCopy() *AutomationConfigOverride {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CappedCollectionOptions) DeepCopyInto(out *CappedCollectionOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CappedCollectionOptions.
+func (in *CappedCollectionOptions) DeepCopy() *CappedCollectionOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(CappedCollectionOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManager) DeepCopyInto(out *CertManager) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManager.
+func (in *CertManager) DeepCopy() *CertManager {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManager)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerReference) DeepCopyInto(out *CertManagerIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerReference.
+func (in *CertManagerIssuerReference) DeepCopy() *CertManagerIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateExpiry) DeepCopyInto(out *CertificateExpiry) {
+	*out = *in
+	if in.WarningThresholdDays != nil {
+		in, out := &in.WarningThresholdDays, &out.WarningThresholdDays
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateExpiry.
+func (in *CertificateExpiry) DeepCopy() *CertificateExpiry {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateExpiry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateStatus) DeepCopyInto(out *CertificateStatus) {
+	*out = *in
+	in.NotAfter.DeepCopyInto(&out.NotAfter)
+	if in.WarningThresholdDays != nil {
+		in, out := &in.WarningThresholdDays, &out.WarningThresholdDays
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateStatus.
+func (in *CertificateStatus) DeepCopy() *CertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Collection) DeepCopyInto(out *Collection) {
+	*out = *in
+	if in.Capped != nil {
+		in, out := &in.Capped, &out.Capped
+		*out = new(CappedCollectionOptions)
+		**out = **in
+	}
+	if in.TimeSeries != nil {
+		in, out := &in.TimeSeries, &out.TimeSeries
+		*out = new(TimeSeriesCollectionOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Validator != nil {
+		in, out := &in.Validator, &out.Validator
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Indexes != nil {
+		in, out := &in.Indexes, &out.Indexes
+		*out = make([]Index, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Collection.
+func (in *Collection) DeepCopy() *Collection {
+	if in == nil {
+		return nil
+	}
+	out := new(Collection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomRole) DeepCopyInto(out *CustomRole) {
 	*out = *in
@@ -169,13 +367,702 @@ func (in *CustomRole) DeepCopy() *CustomRole {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MapWrapper) DeepCopyInto(out *MapWrapper) {
-	clone := in.DeepCopy()
-	*out = *clone
+func (in *Database) DeepCopyInto(out *Database) {
+	*out = *in
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]Collection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Database.
+func (in *Database) DeepCopy() *Database {
+	if in == nil {
+		return nil
+	}
+	out := new(Database)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MongoDBCommunity) DeepCopyInto(out *MongoDBCommunity) {
+func (in *DatabaseDrift) DeepCopyInto(out *DatabaseDrift) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseDrift.
+func (in *DatabaseDrift) DeepCopy() *DatabaseDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DistributeCA) DeepCopyInto(out *DistributeCA) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DistributeCA.
+func (in *DistributeCA) DeepCopy() *DistributeCA {
+	if in == nil {
+		return nil
+	}
+	out := new(DistributeCA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DistributedCAStatus) DeepCopyInto(out *DistributedCAStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DistributedCAStatus.
+func (in *DistributedCAStatus) DeepCopy() *DistributedCAStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DistributedCAStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionAtRest) DeepCopyInto(out *EncryptionAtRest) {
+	*out = *in
+	if in.KeyFileSecretRef != nil {
+		in, out := &in.KeyFileSecretRef, &out.KeyFileSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.Kmip != nil {
+		in, out := &in.Kmip, &out.Kmip
+		*out = new(Kmip)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionAtRest.
+func (in *EncryptionAtRest) DeepCopy() *EncryptionAtRest {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionAtRest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Index) DeepCopyInto(out *Index) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]IndexKey, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpireAfterSeconds != nil {
+		in, out := &in.ExpireAfterSeconds, &out.ExpireAfterSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PartialFilterExpression != nil {
+		in, out := &in.PartialFilterExpression, &out.PartialFilterExpression
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Index.
+func (in *Index) DeepCopy() *Index {
+	if in == nil {
+		return nil
+	}
+	out := new(Index)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexKey) DeepCopyInto(out *IndexKey) {
+	*out = *in
+	out.Type = in.Type
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexKey.
+func (in *IndexKey) DeepCopy() *IndexKey {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitScript) DeepCopyInto(out *InitScript) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(InitScriptSourceReference)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(InitScriptSourceReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitScript.
+func (in *InitScript) DeepCopy() *InitScript {
+	if in == nil {
+		return nil
+	}
+	out := new(InitScript)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitScriptSourceReference) DeepCopyInto(out *InitScriptSourceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitScriptSourceReference.
+func (in *InitScriptSourceReference) DeepCopy() *InitScriptSourceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(InitScriptSourceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitScriptsStatus) DeepCopyInto(out *InitScriptsStatus) {
+	*out = *in
+	if in.Completed != nil {
+		in, out := &in.Completed, &out.Completed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Running != nil {
+		in, out := &in.Running, &out.Running
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Failed != nil {
+		in, out := &in.Failed, &out.Failed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitScriptsStatus.
+func (in *InitScriptsStatus) DeepCopy() *InitScriptsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InitScriptsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Kmip) DeepCopyInto(out *Kmip) {
+	*out = *in
+	if in.ServerNames != nil {
+		in, out := &in.ServerNames, &out.ServerNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int)
+		**out = **in
+	}
+	out.ClientCertificateSecretRef = in.ClientCertificateSecretRef
+	if in.CaConfigMapRef != nil {
+		in, out := &in.CaConfigMapRef, &out.CaConfigMapRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kmip.
+func (in *Kmip) DeepCopy() *Kmip {
+	if in == nil {
+		return nil
+	}
+	out := new(Kmip)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LdapConfiguration) DeepCopyInto(out *LdapConfiguration) {
+	*out = *in
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CaConfigMap != nil {
+		in, out := &in.CaConfigMap, &out.CaConfigMap
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ValidateLDAPServerConfig != nil {
+		in, out := &in.ValidateLDAPServerConfig, &out.ValidateLDAPServerConfig
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BindQueryPasswordSecretRef != nil {
+		in, out := &in.BindQueryPasswordSecretRef, &out.BindQueryPasswordSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.UserToDNMapping != nil {
+		in, out := &in.UserToDNMapping, &out.UserToDNMapping
+		*out = make([]LdapUserToDNMapping, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeoutMS != nil {
+		in, out := &in.TimeoutMS, &out.TimeoutMS
+		*out = new(int)
+		**out = **in
+	}
+	if in.UserCacheInvalidationIntervalSeconds != nil {
+		in, out := &in.UserCacheInvalidationIntervalSeconds, &out.UserCacheInvalidationIntervalSeconds
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LdapConfiguration.
+func (in *LdapConfiguration) DeepCopy() *LdapConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(LdapConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LdapUserToDNMapping) DeepCopyInto(out *LdapUserToDNMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LdapUserToDNMapping.
+func (in *LdapUserToDNMapping) DeepCopy() *LdapUserToDNMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(LdapUserToDNMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LivenessProbeConfiguration) DeepCopyInto(out *LivenessProbeConfiguration) {
+	*out = *in
+	if in.HealthStatusStaleSeconds != nil {
+		in, out := &in.HealthStatusStaleSeconds, &out.HealthStatusStaleSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.MongodUnresponsiveSeconds != nil {
+		in, out := &in.MongodUnresponsiveSeconds, &out.MongodUnresponsiveSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LivenessProbeConfiguration.
+func (in *LivenessProbeConfiguration) DeepCopy() *LivenessProbeConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(LivenessProbeConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MapWrapper) DeepCopyInto(out *MapWrapper) {
+	clone := in.DeepCopy()
+	*out = *clone
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemberCertificates) DeepCopyInto(out *MemberCertificates) {
+	*out = *in
+	if in.SecretRefs != nil {
+		in, out := &in.SecretRefs, &out.SecretRefs
+		*out = make(map[string]corev1.LocalObjectReference, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemberCertificates.
+func (in *MemberCertificates) DeepCopy() *MemberCertificates {
+	if in == nil {
+		return nil
+	}
+	out := new(MemberCertificates)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBCommunity) DeepCopyInto(out *MongoDBCommunity) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunity.
+func (in *MongoDBCommunity) DeepCopy() *MongoDBCommunity {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBCommunity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MongoDBCommunity) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBCommunityList) DeepCopyInto(out *MongoDBCommunityList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MongoDBCommunity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityList.
+func (in *MongoDBCommunityList) DeepCopy() *MongoDBCommunityList {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBCommunityList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MongoDBCommunityList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBCommunityReference) DeepCopyInto(out *MongoDBCommunityReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityReference.
+func (in *MongoDBCommunityReference) DeepCopy() *MongoDBCommunityReference {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBCommunityReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBCommunityRole) DeepCopyInto(out *MongoDBCommunityRole) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityRole.
+func (in *MongoDBCommunityRole) DeepCopy() *MongoDBCommunityRole {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBCommunityRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MongoDBCommunityRole) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBCommunityRoleList) DeepCopyInto(out *MongoDBCommunityRoleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MongoDBCommunityRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityRoleList.
+func (in *MongoDBCommunityRoleList) DeepCopy() *MongoDBCommunityRoleList {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBCommunityRoleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MongoDBCommunityRoleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBCommunityRoleSpec) DeepCopyInto(out *MongoDBCommunityRoleSpec) {
+	*out = *in
+	out.MongoDBResourceRef = in.MongoDBResourceRef
+	in.CustomRole.DeepCopyInto(&out.CustomRole)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityRoleSpec.
+func (in *MongoDBCommunityRoleSpec) DeepCopy() *MongoDBCommunityRoleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBCommunityRoleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBCommunityRoleStatus) DeepCopyInto(out *MongoDBCommunityRoleStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityRoleStatus.
+func (in *MongoDBCommunityRoleStatus) DeepCopy() *MongoDBCommunityRoleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBCommunityRoleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBCommunitySpec) DeepCopyInto(out *MongoDBCommunitySpec) {
+	*out = *in
+	if in.ReplicaSetHorizons != nil {
+		in, out := &in.ReplicaSetHorizons, &out.ReplicaSetHorizons
+		*out = make(ReplicaSetHorizonConfiguration, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(automationconfig.ReplicaSetHorizons, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+		}
+	}
+	in.Security.DeepCopyInto(&out.Security)
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]MongoDBUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.StatefulSetConfiguration.DeepCopyInto(&out.StatefulSetConfiguration)
+	in.AgentConfiguration.DeepCopyInto(&out.AgentConfiguration)
+	in.AdditionalMongodConfig.DeepCopyInto(&out.AdditionalMongodConfig)
+	if in.AuditLog != nil {
+		in, out := &in.AuditLog, &out.AuditLog
+		*out = new(AuditLog)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutomationConfigOverride != nil {
+		in, out := &in.AutomationConfigOverride, &out.AutomationConfigOverride
+		*out = new(AutomationConfigOverride)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Prometheus != nil {
+		in, out := &in.Prometheus, &out.Prometheus
+		*out = new(Prometheus)
+		**out = **in
+	}
+	in.AdditionalConnectionStringConfig.DeepCopyInto(&out.AdditionalConnectionStringConfig)
+	if in.MemberConfig != nil {
+		in, out := &in.MemberConfig, &out.MemberConfig
+		*out = make([]automationconfig.MemberOptions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(LivenessProbeConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(StartupProbeConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(ReadinessProbeConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]Database, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitScripts != nil {
+		in, out := &in.InitScripts, &out.InitScripts
+		*out = make([]InitScript, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunitySpec.
+func (in *MongoDBCommunitySpec) DeepCopy() *MongoDBCommunitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBCommunitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBCommunityStatus) DeepCopyInto(out *MongoDBCommunityStatus) {
+	*out = *in
+	if in.UserPasswordRotations != nil {
+		in, out := &in.UserPasswordRotations, &out.UserPasswordRotations
+		*out = make([]UserPasswordRotation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AgentCredentialsRotation != nil {
+		in, out := &in.AgentCredentialsRotation, &out.AgentCredentialsRotation
+		*out = new(AgentCredentialsRotation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UserResources != nil {
+		in, out := &in.UserResources, &out.UserResources
+		*out = make([]UserResourceReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.DatabaseDrift != nil {
+		in, out := &in.DatabaseDrift, &out.DatabaseDrift
+		*out = make([]DatabaseDrift, len(*in))
+		copy(*out, *in)
+	}
+	if in.InitScripts != nil {
+		in, out := &in.InitScripts, &out.InitScripts
+		*out = new(InitScriptsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]CertificateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DistributedCA != nil {
+		in, out := &in.DistributedCA, &out.DistributedCA
+		*out = new(DistributedCAStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityStatus.
+func (in *MongoDBCommunityStatus) DeepCopy() *MongoDBCommunityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBCommunityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBCommunityUser) DeepCopyInto(out *MongoDBCommunityUser) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -183,18 +1070,18 @@ func (in *MongoDBCommunity) DeepCopyInto(out *MongoDBCommunity) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunity.
-func (in *MongoDBCommunity) DeepCopy() *MongoDBCommunity {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityUser.
+func (in *MongoDBCommunityUser) DeepCopy() *MongoDBCommunityUser {
 	if in == nil {
 		return nil
 	}
-	out := new(MongoDBCommunity)
+	out := new(MongoDBCommunityUser)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MongoDBCommunity) DeepCopyObject() runtime.Object {
+func (in *MongoDBCommunityUser) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -202,31 +1089,31 @@ func (in *MongoDBCommunity) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MongoDBCommunityList) DeepCopyInto(out *MongoDBCommunityList) {
+func (in *MongoDBCommunityUserList) DeepCopyInto(out *MongoDBCommunityUserList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]MongoDBCommunity, len(*in))
+		*out = make([]MongoDBCommunityUser, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityList.
-func (in *MongoDBCommunityList) DeepCopy() *MongoDBCommunityList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityUserList.
+func (in *MongoDBCommunityUserList) DeepCopy() *MongoDBCommunityUserList {
 	if in == nil {
 		return nil
 	}
-	out := new(MongoDBCommunityList)
+	out := new(MongoDBCommunityUserList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MongoDBCommunityList) DeepCopyObject() runtime.Object {
+func (in *MongoDBCommunityUserList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -234,73 +1121,39 @@ func (in *MongoDBCommunityList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MongoDBCommunitySpec) DeepCopyInto(out *MongoDBCommunitySpec) {
+func (in *MongoDBCommunityUserSpec) DeepCopyInto(out *MongoDBCommunityUserSpec) {
 	*out = *in
-	if in.ReplicaSetHorizons != nil {
-		in, out := &in.ReplicaSetHorizons, &out.ReplicaSetHorizons
-		*out = make(ReplicaSetHorizonConfiguration, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = make(automationconfig.ReplicaSetHorizons, len(*in))
-				for key, val := range *in {
-					(*out)[key] = val
-				}
-			}
-		}
-	}
-	in.Security.DeepCopyInto(&out.Security)
-	if in.Users != nil {
-		in, out := &in.Users, &out.Users
-		*out = make([]MongoDBUser, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	in.StatefulSetConfiguration.DeepCopyInto(&out.StatefulSetConfiguration)
-	in.AgentConfiguration.DeepCopyInto(&out.AgentConfiguration)
-	in.AdditionalMongodConfig.DeepCopyInto(&out.AdditionalMongodConfig)
-	if in.AutomationConfigOverride != nil {
-		in, out := &in.AutomationConfigOverride, &out.AutomationConfigOverride
-		*out = new(AutomationConfigOverride)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Prometheus != nil {
-		in, out := &in.Prometheus, &out.Prometheus
-		*out = new(Prometheus)
-		**out = **in
+	out.MongoDBResourceRef = in.MongoDBResourceRef
+	out.PasswordSecretRef = in.PasswordSecretRef
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]Role, len(*in))
+		copy(*out, *in)
 	}
 	in.AdditionalConnectionStringConfig.DeepCopyInto(&out.AdditionalConnectionStringConfig)
-	if in.MemberConfig != nil {
-		in, out := &in.MemberConfig, &out.MemberConfig
-		*out = make([]automationconfig.MemberOptions, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunitySpec.
-func (in *MongoDBCommunitySpec) DeepCopy() *MongoDBCommunitySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityUserSpec.
+func (in *MongoDBCommunityUserSpec) DeepCopy() *MongoDBCommunityUserSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MongoDBCommunitySpec)
+	out := new(MongoDBCommunityUserSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MongoDBCommunityStatus) DeepCopyInto(out *MongoDBCommunityStatus) {
+func (in *MongoDBCommunityUserStatus) DeepCopyInto(out *MongoDBCommunityUserStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityStatus.
-func (in *MongoDBCommunityStatus) DeepCopy() *MongoDBCommunityStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCommunityUserStatus.
+func (in *MongoDBCommunityUserStatus) DeepCopy() *MongoDBCommunityUserStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MongoDBCommunityStatus)
+	out := new(MongoDBCommunityUserStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -309,6 +1162,16 @@ func (in *MongoDBCommunityStatus) DeepCopy() *MongoDBCommunityStatus {
 func (in *MongoDBUser) DeepCopyInto(out *MongoDBUser) {
 	*out = *in
 	out.PasswordSecretRef = in.PasswordSecretRef
+	if in.PasswordGeneration != nil {
+		in, out := &in.PasswordGeneration, &out.PasswordGeneration
+		*out = new(PasswordGenerationOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordRotation != nil {
+		in, out := &in.PasswordRotation, &out.PasswordRotation
+		*out = new(PasswordRotationConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Roles != nil {
 		in, out := &in.Roles, &out.Roles
 		*out = make([]Role, len(*in))
@@ -343,6 +1206,26 @@ func (in *MongodConfiguration) DeepCopy() *MongodConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OidcProviderConfig) DeepCopyInto(out *OidcProviderConfig) {
+	*out = *in
+	if in.RequestedScopes != nil {
+		in, out := &in.RequestedScopes, &out.RequestedScopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OidcProviderConfig.
+func (in *OidcProviderConfig) DeepCopy() *OidcProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OidcProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OverrideProcess) DeepCopyInto(out *OverrideProcess) {
 	*out = *in
@@ -384,6 +1267,51 @@ func (in *OverrideReplicaSet) DeepCopy() *OverrideReplicaSet {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordGenerationOptions) DeepCopyInto(out *PasswordGenerationOptions) {
+	*out = *in
+	if in.Length != nil {
+		in, out := &in.Length, &out.Length
+		*out = new(int)
+		**out = **in
+	}
+	if in.CharacterClasses != nil {
+		in, out := &in.CharacterClasses, &out.CharacterClasses
+		*out = make([]PasswordCharacterClass, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordGenerationOptions.
+func (in *PasswordGenerationOptions) DeepCopy() *PasswordGenerationOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordGenerationOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordRotationConfiguration) DeepCopyInto(out *PasswordRotationConfiguration) {
+	*out = *in
+	if in.GracePeriodSeconds != nil {
+		in, out := &in.GracePeriodSeconds, &out.GracePeriodSeconds
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordRotationConfiguration.
+func (in *PasswordRotationConfiguration) DeepCopy() *PasswordRotationConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordRotationConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Privilege) DeepCopyInto(out *Privilege) {
 	*out = *in
@@ -449,6 +1377,46 @@ func (in ReplicaSetHorizonConfiguration) DeepCopy() ReplicaSetHorizonConfigurati
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessProbeConfiguration) DeepCopyInto(out *ReadinessProbeConfiguration) {
+	*out = *in
+	if in.WaitStepGracePeriodSeconds != nil {
+		in, out := &in.WaitStepGracePeriodSeconds, &out.WaitStepGracePeriodSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.WaitStepsReady != nil {
+		in, out := &in.WaitStepsReady, &out.WaitStepsReady
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WaitStepAllowList != nil {
+		in, out := &in.WaitStepAllowList, &out.WaitStepAllowList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WaitStepDenyList != nil {
+		in, out := &in.WaitStepDenyList, &out.WaitStepDenyList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MongodNotReadySeconds != nil {
+		in, out := &in.MongodNotReadySeconds, &out.MongodNotReadySeconds
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessProbeConfiguration.
+func (in *ReadinessProbeConfiguration) DeepCopy() *ReadinessProbeConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessProbeConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Resource) DeepCopyInto(out *Resource) {
 	*out = *in
@@ -504,6 +1472,26 @@ func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretProvider) DeepCopyInto(out *SecretProvider) {
+	*out = *in
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultSecretProvider)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretProvider.
+func (in *SecretProvider) DeepCopy() *SecretProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Security) DeepCopyInto(out *Security) {
 	*out = *in
@@ -516,6 +1504,23 @@ func (in *Security) DeepCopyInto(out *Security) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SecretProviders != nil {
+		in, out := &in.SecretProviders, &out.SecretProviders
+		*out = make([]SecretProvider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CertificateExpiry != nil {
+		in, out := &in.CertificateExpiry, &out.CertificateExpiry
+		*out = new(CertificateExpiry)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EncryptionAtRest != nil {
+		in, out := &in.EncryptionAtRest, &out.EncryptionAtRest
+		*out = new(EncryptionAtRest)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Security.
@@ -528,6 +1533,31 @@ func (in *Security) DeepCopy() *Security {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupProbeConfiguration) DeepCopyInto(out *StartupProbeConfiguration) {
+	*out = *in
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupProbeConfiguration.
+func (in *StartupProbeConfiguration) DeepCopy() *StartupProbeConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupProbeConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatefulSetConfiguration) DeepCopyInto(out *StatefulSetConfiguration) {
 	*out = *in
@@ -561,6 +1591,11 @@ func (in *StatefulSetSpecWrapper) DeepCopyInto(out *StatefulSetSpecWrapper) {
 func (in *TLS) DeepCopyInto(out *TLS) {
 	*out = *in
 	out.CertificateKeySecret = in.CertificateKeySecret
+	if in.MemberCertificates != nil {
+		in, out := &in.MemberCertificates, &out.MemberCertificates
+		*out = new(MemberCertificates)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CaCertificateSecret != nil {
 		in, out := &in.CaCertificateSecret, &out.CaCertificateSecret
 		*out = new(corev1.LocalObjectReference)
@@ -571,6 +1606,36 @@ func (in *TLS) DeepCopyInto(out *TLS) {
 		*out = new(corev1.LocalObjectReference)
 		**out = **in
 	}
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManager)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CrlSecret != nil {
+		in, out := &in.CrlSecret, &out.CrlSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CrlConfigMap != nil {
+		in, out := &in.CrlConfigMap, &out.CrlConfigMap
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.DisabledProtocols != nil {
+		in, out := &in.DisabledProtocols, &out.DisabledProtocols
+		*out = make([]TLSProtocol, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ocsp != nil {
+		in, out := &in.Ocsp, &out.Ocsp
+		*out = new(TLSOcsp)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DistributeCA != nil {
+		in, out := &in.DistributeCA, &out.DistributeCA
+		*out = new(DistributeCA)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLS.
@@ -582,3 +1647,111 @@ func (in *TLS) DeepCopy() *TLS {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSOcsp) DeepCopyInto(out *TLSOcsp) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.StaplingRefreshPeriodSecs != nil {
+		in, out := &in.StaplingRefreshPeriodSecs, &out.StaplingRefreshPeriodSecs
+		*out = new(int)
+		**out = **in
+	}
+	if in.StaplingTimeoutSecs != nil {
+		in, out := &in.StaplingTimeoutSecs, &out.StaplingTimeoutSecs
+		*out = new(int)
+		**out = **in
+	}
+	if in.VerifyTimeoutSecs != nil {
+		in, out := &in.VerifyTimeoutSecs, &out.VerifyTimeoutSecs
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSOcsp.
+func (in *TLSOcsp) DeepCopy() *TLSOcsp {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSOcsp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeSeriesCollectionOptions) DeepCopyInto(out *TimeSeriesCollectionOptions) {
+	*out = *in
+	if in.ExpireAfterSeconds != nil {
+		in, out := &in.ExpireAfterSeconds, &out.ExpireAfterSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeSeriesCollectionOptions.
+func (in *TimeSeriesCollectionOptions) DeepCopy() *TimeSeriesCollectionOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeSeriesCollectionOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserPasswordRotation) DeepCopyInto(out *UserPasswordRotation) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserPasswordRotation.
+func (in *UserPasswordRotation) DeepCopy() *UserPasswordRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(UserPasswordRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserResourceReference) DeepCopyInto(out *UserResourceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserResourceReference.
+func (in *UserResourceReference) DeepCopy() *UserResourceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(UserResourceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretProvider) DeepCopyInto(out *VaultSecretProvider) {
+	*out = *in
+	out.TokenSecretRef = in.TokenSecretRef
+	if in.CaConfigMap != nil {
+		in, out := &in.CaConfigMap, &out.CaConfigMap
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretProvider.
+func (in *VaultSecretProvider) DeepCopy() *VaultSecretProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSecretProvider)
+	in.DeepCopyInto(out)
+	return out
+}
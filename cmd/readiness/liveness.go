@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/config"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/health"
+)
+
+// performLivenessCheck runs the given liveness or startup check against the agent health status file.
+func performLivenessCheck(check string, conf config.LivenessConfig, now time.Time) (bool, error) {
+	info, err := os.Stat(conf.HealthStatusFilePath)
+	if err != nil {
+		logger.Infof("The health status file is not available: %s", err)
+		return false, nil
+	}
+
+	switch check {
+	case config.AgentLivenessCheck:
+		return isAgentAlive(info.ModTime(), conf, now), nil
+	case config.MongodLivenessCheck, config.MongodStartupCheck:
+		file, err := os.Open(conf.HealthStatusFilePath)
+		if err != nil {
+			return false, err
+		}
+		defer file.Close()
+
+		healthStatus, err := parseHealthStatus(file)
+		if err != nil {
+			logger.Errorf("There was problem parsing health status file: %s", err)
+			return false, nil
+		}
+		if check == config.MongodStartupCheck {
+			return hasMongodStarted(healthStatus, conf, now), nil
+		}
+		return isMongodAlive(healthStatus, info.ModTime(), conf, now), nil
+	default:
+		return false, fmt.Errorf("unknown check %s", check)
+	}
+}
+
+// isAgentAlive returns false if the agent has not written its health status for longer than the configured threshold.
+// The agent writes the file every 10 seconds, so a stale file means the agent is hung.
+func isAgentAlive(healthStatusModTime time.Time, conf config.LivenessConfig, now time.Time) bool {
+	if now.Sub(healthStatusModTime) > conf.HealthStatusStaleThreshold {
+		logger.Infof("The agent has not updated its health status since %s", healthStatusModTime.Format(time.RFC3339))
+		return false
+	}
+	return true
+}
+
+// isMongodAlive returns false if mongod is expected to be up but the agent has not been able to reach it for longer
+// than the configured threshold. If the health status is stale, the state of mongod is unknown and it is considered
+// alive, as restarting the agent is handled by the agent liveness check.
+func isMongodAlive(healthStatus health.Status, healthStatusModTime time.Time, conf config.LivenessConfig, now time.Time) bool {
+	if now.Sub(healthStatusModTime) > conf.HealthStatusStaleThreshold {
+		logger.Info("The health status is stale, the state of mongod is unknown")
+		return true
+	}
+
+	for _, processHealth := range healthStatus.Statuses {
+		if !processHealth.ExpectedToBeUp {
+			return true
+		}
+		lastMongoUpTime := time.Unix(processHealth.LastMongoUpTime, 0)
+		if now.Sub(lastMongoUpTime) > conf.MongodUnresponsiveThreshold {
+			logger.Infof("Mongod has been unresponsive since %s", lastMongoUpTime.Format(time.RFC3339))
+			return false
+		}
+	}
+	return true
+}
+
+// hasMongodStarted returns true once the agent has reported mongod as up, or if mongod is not expected to be up.
+// It is used as a startup probe, so that the liveness probe is not checked during WiredTiger recovery.
+func hasMongodStarted(healthStatus health.Status, conf config.LivenessConfig, now time.Time) bool {
+	if len(healthStatus.Statuses) == 0 {
+		logger.Info("The agent has not reported the state of mongod yet")
+		return false
+	}
+	for _, processHealth := range healthStatus.Statuses {
+		if !processHealth.ExpectedToBeUp {
+			return true
+		}
+		if now.Sub(time.Unix(processHealth.LastMongoUpTime, 0)) < conf.MongodNotReadyInterval {
+			return true
+		}
+	}
+	logger.Info("Mongod has not started yet")
+	return false
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLivenessConfig(healthFilePath string) config.LivenessConfig {
+	return config.LivenessConfig{
+		HealthStatusFilePath:        healthFilePath,
+		HealthStatusStaleThreshold:  5 * time.Minute,
+		MongodUnresponsiveThreshold: 5 * time.Minute,
+		MongodNotReadyInterval:      2 * time.Minute,
+	}
+}
+
+func TestLivenessChecks(t *testing.T) {
+	now := time.Now()
+	type TestConfig struct {
+		check                string
+		testdataFile         string
+		timeSinceMongoLastUp time.Duration
+		healthStatusAge      time.Duration
+		isAliveExpected      bool
+	}
+	tests := map[string]TestConfig{
+		"Agent is alive when the health status is recent": {
+			check:           config.AgentLivenessCheck,
+			testdataFile:    "testdata/health-status-ok.json",
+			healthStatusAge: time.Minute,
+			isAliveExpected: true,
+		},
+		"Agent is hung when the health status is stale": {
+			check:           config.AgentLivenessCheck,
+			testdataFile:    "testdata/health-status-ok.json",
+			healthStatusAge: time.Hour,
+			isAliveExpected: false,
+		},
+		"Mongod is alive when it was recently up": {
+			check:                config.MongodLivenessCheck,
+			testdataFile:         "testdata/health-status-ok.json",
+			timeSinceMongoLastUp: time.Minute,
+			isAliveExpected:      true,
+		},
+		"Mongod is deadlocked when it has been unresponsive for too long": {
+			check:                config.MongodLivenessCheck,
+			testdataFile:         "testdata/health-status-ok.json",
+			timeSinceMongoLastUp: time.Hour,
+			isAliveExpected:      false,
+		},
+		"Mongod is considered alive when the health status is stale": {
+			check:                config.MongodLivenessCheck,
+			testdataFile:         "testdata/health-status-ok.json",
+			timeSinceMongoLastUp: time.Hour,
+			healthStatusAge:      time.Hour,
+			isAliveExpected:      true,
+		},
+		"Mongod has started once it is up": {
+			check:                config.MongodStartupCheck,
+			testdataFile:         "testdata/health-status-ok.json",
+			timeSinceMongoLastUp: 15 * time.Second,
+			isAliveExpected:      true,
+		},
+		"Mongod has started within the configured not ready interval": {
+			check:                config.MongodStartupCheck,
+			testdataFile:         "testdata/health-status-ok.json",
+			timeSinceMongoLastUp: 90 * time.Second,
+			isAliveExpected:      true,
+		},
+		"Mongod has not started during WiredTiger recovery": {
+			check:                config.MongodStartupCheck,
+			testdataFile:         "testdata/health-status-ok.json",
+			timeSinceMongoLastUp: time.Hour,
+			isAliveExpected:      false,
+		},
+	}
+	for testName := range tests {
+		tc := tests[testName]
+		t.Run(testName, func(t *testing.T) {
+			healthFilePath := filepath.Join(t.TempDir(), "agent-health-status.json")
+			writeHealthStatus(t, healthFilePath, tc.testdataFile, tc.timeSinceMongoLastUp, now.Add(-tc.healthStatusAge))
+
+			alive, err := performLivenessCheck(tc.check, testLivenessConfig(healthFilePath), now)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.isAliveExpected, alive)
+		})
+	}
+}
+
+func TestLivenessCheckWithoutHealthStatusFile(t *testing.T) {
+	alive, err := performLivenessCheck(config.AgentLivenessCheck, testLivenessConfig(filepath.Join(t.TempDir(), "missing.json")), time.Now())
+	assert.NoError(t, err)
+	assert.False(t, alive)
+}
+
+func TestMongodHasNotStartedWithoutStatuses(t *testing.T) {
+	healthFilePath := filepath.Join(t.TempDir(), "agent-health-status.json")
+	assert.NoError(t, os.WriteFile(healthFilePath, []byte(`{"statuses": {}, "mmsStatus": {}}`), 0o600))
+	alive, err := performLivenessCheck(config.MongodStartupCheck, testLivenessConfig(healthFilePath), time.Now())
+	assert.NoError(t, err)
+	assert.False(t, alive)
+}
+
+func TestUnknownLivenessCheck(t *testing.T) {
+	healthFilePath := filepath.Join(t.TempDir(), "agent-health-status.json")
+	assert.NoError(t, os.WriteFile(healthFilePath, []byte("{}"), 0o600))
+	_, err := performLivenessCheck("unknown", testLivenessConfig(healthFilePath), time.Now())
+	assert.Error(t, err)
+}
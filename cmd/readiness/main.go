@@ -3,14 +3,20 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"time"
 
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/config"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/headless"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/health"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/pod"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/replication"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/secret"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
 
@@ -21,8 +27,9 @@ import (
 )
 
 const (
-	headlessAgent                 = "HEADLESS_AGENT"
-	mongodNotReadyIntervalMinutes = time.Minute * 1
+	headlessAgent = "HEADLESS_AGENT"
+	// annotationPatchInterval is how often the server mode patches the Pod with an unchanged agent version.
+	annotationPatchInterval = time.Minute * 10
 )
 
 var logger *zap.SugaredLogger
@@ -63,18 +70,22 @@ func isPodReady(ctx context.Context, conf config.Config) (bool, error) {
 		return false, err
 	}
 
-	inReadyState := isInReadyState(healthStatus)
+	inReadyState := isInReadyState(healthStatus, conf.Heuristics)
 	if !inReadyState {
 		logger.Info("Mongod is not ready")
 	}
 
 	if inGoalState && inReadyState {
+		if !isReplicationLagAcceptable(ctx, conf) {
+			logger.Info("The Agent has reached goal state but the replication lag is too high. Returning not ready.")
+			return false, nil
+		}
 		logger.Info("The Agent has reached goal state. Returning ready.")
 		return true, nil
 	}
 
 	// Fallback logic: the agent is not in goal state and got stuck in some steps
-	if !inGoalState && isOnWaitingStep(healthStatus) {
+	if !inGoalState && isOnWaitingStep(healthStatus, conf.Heuristics) {
 		logger.Info("The Agent is on wait Step. Returning ready.")
 		return true, nil
 	}
@@ -83,11 +94,67 @@ func isPodReady(ctx context.Context, conf config.Config) (bool, error) {
 	return false, nil
 }
 
+// isReplicationLagAcceptable returns false if the mongod process is a secondary lagging behind the primary by more
+// than the configured threshold. If the replication lag cannot be determined, it is considered acceptable, as
+// the rest of the probe already covers the health of the process.
+func isReplicationLagAcceptable(ctx context.Context, conf config.Config) bool {
+	if conf.MaxReplicationLag <= 0 || conf.ReplicationLagReader == nil {
+		return true
+	}
+
+	lag, err := conf.ReplicationLagReader.ReplicationLag(ctx)
+	if err != nil {
+		logger.Warnf("Could not determine the replication lag, ignoring it: %s", err)
+		return true
+	}
+
+	if lag > conf.MaxReplicationLag {
+		logger.Infof("The replication lag is %s, more than the maximum of %s", lag, conf.MaxReplicationLag)
+		return false
+	}
+	logger.Debugf("The replication lag is %s", lag)
+	return true
+}
+
+// replicationLagReader returns a reader for the replication lag of the local mongod, connecting with the
+// automation agent settings from the automation config. In headless mode the automation config is read from its
+// secret, otherwise from the copy the agent keeps of the one it received from Ops Manager.
+func replicationLagReader(ctx context.Context, conf config.Config) (replication.LagReader, error) {
+	ac, err := readAutomationConfig(ctx, conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the automation config: %s", err)
+	}
+	clientOptions, err := replication.ClientOptionsFromAutomationConfig(ac, conf.Hostname)
+	if err != nil {
+		return nil, err
+	}
+	return replication.NewLagReader(clientOptions), nil
+}
+
+func readAutomationConfig(ctx context.Context, conf config.Config) (automationconfig.AutomationConfig, error) {
+	if isHeadlessMode() {
+		return secret.ReadAutomationConfigFromSecret(ctx, conf.Namespace, conf.ClientSet, conf.AutomationConfigSecretName)
+	}
+	contents, err := os.ReadFile(conf.AutomationConfigBackupFilePath)
+	if err != nil {
+		return automationconfig.AutomationConfig{}, err
+	}
+	var ac automationconfig.AutomationConfig
+	if err := json.Unmarshal(contents, &ac); err != nil {
+		return automationconfig.AutomationConfig{}, err
+	}
+	return ac, nil
+}
+
 // isOnWaitingStep returns true if the agent is stuck on waiting for the other Agents or something else to happen.
-func isOnWaitingStep(health health.Status) bool {
+// Wait steps are only taken into account if the heuristics allow it.
+func isOnWaitingStep(health health.Status, heuristics config.Heuristics) bool {
+	if !heuristics.WaitStepsReady {
+		return false
+	}
 	currentStep := findCurrentStep(health.MmsStatus)
 	if currentStep != nil {
-		return isWaitStep(currentStep)
+		return isWaitStep(currentStep, heuristics)
 	}
 	return false
 }
@@ -143,16 +210,18 @@ func findCurrentStep(processStatuses map[string]health.MmsDirectorStatus) *healt
 // holding the rollout does not improve the overall system state. Even if the probe returns true too quickly
 // the worst thing that can happen is a short service interruption, which is still better than full service outage.
 //
-// The 15 seconds explanation:
+// The grace period explanation (15 seconds by default):
 //   - The status file is written every 10s but the Agent processes steps independently of it
 //   - In order to avoid reacting on a newly added wait Step (as they can naturally go away), we're giving the Agent
-//     at least 15 sends to spend on that Step.
+//     at least the grace period to spend on that Step.
 //   - This hopefully prevents the Probe from flipping False to True too quickly.
-func isWaitStep(status *health.StepStatus) bool {
-	// Some logic behind 15 seconds: the health status file is dumped each 10 seconds, so we are sure that if the agent
-	// has been in the step for 10 seconds - this means it is waiting for the other hosts, and they are not available
-	fifteenSecondsAgo := time.Now().Add(time.Duration(-15) * time.Second)
-	if status.IsWaitStep && status.Completed == nil && status.Started.Before(fifteenSecondsAgo) {
+//
+// Whether a step is a wait step is reported by the Agent, unless the step is in the allow or deny list of the heuristics.
+func isWaitStep(status *health.StepStatus, heuristics config.Heuristics) bool {
+	// The grace period should be longer than the 10 seconds between two writes of the health status file, so that a
+	// step still reported after it is one the agent is waiting on, and not one it went through between two writes
+	gracePeriodStart := time.Now().Add(-heuristics.WaitStepGracePeriod)
+	if isConsideredWaitStep(status, heuristics) && status.Completed == nil && status.Started.Before(gracePeriodStart) {
 		logger.Debugf("Indicated a wait Step, status: %s, started at %s but hasn't finished "+
 			"yet. Marking the probe as ready", status.Step, status.Started.Format(time.RFC3339))
 		return true
@@ -160,6 +229,14 @@ func isWaitStep(status *health.StepStatus) bool {
 	return false
 }
 
+// isConsideredWaitStep returns true if the step is a wait step according to the Agent and the allow and deny lists.
+func isConsideredWaitStep(status *health.StepStatus, heuristics config.Heuristics) bool {
+	if slices.Contains(heuristics.WaitStepDenyList, status.Step) {
+		return false
+	}
+	return status.IsWaitStep || slices.Contains(heuristics.WaitStepAllowList, status.Step)
+}
+
 func isInGoalState(ctx context.Context, health health.Status, conf config.Config) (bool, error) {
 	if isHeadlessMode() {
 		return headless.PerformCheckHeadlessMode(ctx, health, conf)
@@ -233,6 +310,23 @@ func initLogger(l *lumberjack.Logger) {
 }
 
 func main() {
+	serverMode := flag.Bool("serve", false, "Serve the readiness of the Pod over HTTP instead of performing a single check")
+	port := flag.Int("port", config.DefaultServerPort, "The port to serve the readiness probe on, when running with -serve")
+	check := flag.String("check", config.ReadinessCheck, fmt.Sprintf("The check to perform: %s, %s, %s or %s", config.ReadinessCheck, config.AgentLivenessCheck, config.MongodLivenessCheck, config.MongodStartupCheck))
+	flag.Parse()
+
+	if *check != config.ReadinessCheck {
+		initLogger(config.GetLogger())
+		alive, err := performLivenessCheck(*check, config.BuildLivenessFromEnvVariables(), time.Now())
+		if err != nil {
+			panic(err)
+		}
+		if !alive {
+			os.Exit(1)
+		}
+		return
+	}
+
 	ctx := context.Background()
 	clientSet, err := kubernetesClientset()
 	if err != nil {
@@ -242,6 +336,19 @@ func main() {
 	initLogger(config.GetLogger())
 
 	healthStatusFilePath := config.GetEnvOrDefault(config.AgentHealthStatusFilePathEnv, config.DefaultAgentHealthStatusFilePath)
+
+	if *serverMode {
+		cfg, err := config.BuildFromEnvVariables(clientSet, isHeadlessMode(), nil)
+		if err != nil {
+			panic(err)
+		}
+		cfg.AnnotationThrottle = pod.NewAnnotationThrottle(annotationPatchInterval)
+		if err := serve(ctx, cfg, config.BuildLivenessFromEnvVariables(), *port); err != nil {
+			logger.Fatalf("The readiness probe server stopped: %s", err)
+		}
+		return
+	}
+
 	file, err := os.Open(healthStatusFilePath)
 	// The agent might be slow in creating the health status file.
 	// In that case, we don't want to panic to show the message
@@ -256,6 +363,13 @@ func main() {
 		panic(err)
 	}
 
+	if cfg.MaxReplicationLag > 0 {
+		cfg.ReplicationLagReader, err = replicationLagReader(ctx, cfg)
+		if err != nil {
+			logger.Warnf("The replication lag will not be checked: %s", err)
+		}
+	}
+
 	ready, err := isPodReady(ctx, cfg)
 	if err != nil {
 		panic(err)
@@ -267,7 +381,7 @@ func main() {
 
 // isInReadyState checks the MongoDB Server state. It returns true if the mongod process is up and its state
 // is PRIMARY or SECONDARY.
-func isInReadyState(health health.Status) bool {
+func isInReadyState(health health.Status, heuristics config.Heuristics) bool {
 	if len(health.Statuses) == 0 {
 		return true
 	}
@@ -280,7 +394,7 @@ func isInReadyState(health health.Status) bool {
 		}
 
 		timeMongoUp := time.Unix(processHealth.LastMongoUpTime, 0)
-		mongoUpThreshold := time.Now().Add(-mongodNotReadyIntervalMinutes)
+		mongoUpThreshold := time.Now().Add(-heuristics.MongodNotReadyInterval)
 		mongoIsHealthy := timeMongoUp.After(mongoUpThreshold)
 		// The case in which the agent is too old to publish replication status is handled inside "IsReadyState"
 		return mongoIsHealthy && processHealth.IsReadyState()
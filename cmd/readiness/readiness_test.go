@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/mongodb/mongodb-kubernetes-operator/cmd/readiness/testdata"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/config"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/health"
 
@@ -308,6 +311,7 @@ func testConfigWithMongoUp(healthFilePath string, timeSinceMongoLastUp time.Dura
 		Namespace:                  "test-ns",
 		AutomationConfigSecretName: "test-mongodb-automation-config",
 		Hostname:                   "test-mongodb-0",
+		Heuristics:                 config.DefaultHeuristics(),
 	}
 }
 
@@ -318,3 +322,120 @@ func NewTestHealthStatusReader(status health.Status) io.Reader {
 	}
 	return bytes.NewReader(data)
 }
+
+type mockLagReader struct {
+	lag time.Duration
+	err error
+}
+
+func (m mockLagReader) ReplicationLag(context.Context) (time.Duration, error) {
+	return m.lag, m.err
+}
+
+// TestReplicationLag verifies that a member in goal state is not ready when it lags behind the primary by more
+// than the configured threshold.
+func TestReplicationLag(t *testing.T) {
+	ctx := context.Background()
+	tests := map[string]struct {
+		maxLag          time.Duration
+		reader          mockLagReader
+		isReadyExpected bool
+	}{
+		"Ready when the lag is below the threshold": {
+			maxLag:          time.Minute,
+			reader:          mockLagReader{lag: time.Second},
+			isReadyExpected: true,
+		},
+		"Not Ready when the lag is above the threshold": {
+			maxLag:          time.Minute,
+			reader:          mockLagReader{lag: time.Hour},
+			isReadyExpected: false,
+		},
+		"Ready when the lag check is disabled": {
+			reader:          mockLagReader{lag: time.Hour},
+			isReadyExpected: true,
+		},
+		"Ready when the lag cannot be determined": {
+			maxLag:          time.Minute,
+			reader:          mockLagReader{err: errors.New("connection refused")},
+			isReadyExpected: true,
+		},
+	}
+	for testName, tc := range tests {
+		t.Run(testName, func(t *testing.T) {
+			c := testConfig("testdata/health-status-ok.json")
+			c.MaxReplicationLag = tc.maxLag
+			c.ReplicationLagReader = tc.reader
+			ready, err := isPodReady(ctx, c)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.isReadyExpected, ready)
+		})
+	}
+}
+
+// TestReplicationLagReaderOMMode verifies that outside headless mode the replication lag is read with the settings
+// of the automation config copy kept by the agent.
+func TestReplicationLagReaderOMMode(t *testing.T) {
+	ctx := context.Background()
+	backup := filepath.Join(t.TempDir(), "mms-cluster-config-backup.json")
+	c := testConfig("testdata/health-status-ok.json")
+	c.Hostname = "mdb-0"
+	c.AutomationConfigBackupFilePath = backup
+
+	_, err := replicationLagReader(ctx, c)
+	assert.Error(t, err)
+
+	ac := automationconfig.AutomationConfig{Processes: []automationconfig.Process{{Name: "mdb-0", HostName: "mdb-0.mdb-svc.ns.svc.cluster.local"}}}
+	contents, err := json.Marshal(ac)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(backup, contents, 0600))
+	reader, err := replicationLagReader(ctx, c)
+	assert.NoError(t, err)
+	assert.NotNil(t, reader)
+}
+
+// TestReadinessHeuristics verifies that the policies deciding whether wait steps count as ready can be configured.
+func TestReadinessHeuristics(t *testing.T) {
+	ctx := context.Background()
+	tests := map[string]struct {
+		healthFile      string
+		heuristics      func(h *config.Heuristics)
+		isReadyExpected bool
+	}{
+		"Not Ready when wait steps do not count as ready": {
+			healthFile:      "testdata/health-status-deadlocked.json",
+			heuristics:      func(h *config.Heuristics) { h.WaitStepsReady = false },
+			isReadyExpected: false,
+		},
+		"Not Ready when the wait step is in the deny list": {
+			healthFile:      "testdata/health-status-deadlocked.json",
+			heuristics:      func(h *config.Heuristics) { h.WaitStepDenyList = []string{"WaitAllRsMembersUp"} },
+			isReadyExpected: false,
+		},
+		"Not Ready when the wait step is shorter than the grace period": {
+			healthFile:      "testdata/health-status-deadlocked.json",
+			heuristics:      func(h *config.Heuristics) { h.WaitStepGracePeriod = 100 * 365 * 24 * time.Hour },
+			isReadyExpected: false,
+		},
+		"Ready when the step is in the allow list": {
+			healthFile:      "testdata/health-status-enterprise-upgrade-interrupted.json",
+			heuristics:      func(h *config.Heuristics) { h.WaitStepAllowList = []string{"Stop"} },
+			isReadyExpected: true,
+		},
+		"Not Ready when mongod has been down for longer than the configured interval": {
+			healthFile:      "testdata/health-status-ok.json",
+			heuristics:      func(h *config.Heuristics) { h.MongodNotReadyInterval = 5 * time.Second },
+			isReadyExpected: false,
+		},
+	}
+	for testName := range tests {
+		tc := tests[testName]
+		t.Run(testName, func(t *testing.T) {
+			c := testConfigWithMongoUp(tc.healthFile, 15*time.Second)
+			tc.heuristics(&c.Heuristics)
+			ready, err := isPodReady(ctx, c)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.isReadyExpected, ready)
+		})
+	}
+}
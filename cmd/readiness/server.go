@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/config"
+)
+
+const (
+	healthStatusPollInterval = time.Second
+	// The readiness of the Pod also depends on the time spent on wait steps and since mongod was last up,
+	// so it is re-evaluated periodically even if the health status file does not change.
+	maxEvaluationInterval = 5 * time.Second
+)
+
+// probeStatus is the result of the last readiness evaluation, as served by the /status endpoint.
+type probeStatus struct {
+	Ready               bool      `json:"ready"`
+	Error               string    `json:"error,omitempty"`
+	LastEvaluation      time.Time `json:"lastEvaluation"`
+	HealthStatusModTime time.Time `json:"healthStatusModTime"`
+}
+
+// probeServer is the long-running mode of the readiness probe. It watches the agent health status file,
+// re-evaluates the readiness of the Pod when it changes and serves the result over HTTP. The Kubernetes client
+// is created once and the Pod annotation with the agent version is only patched when it changes.
+type probeServer struct {
+	conf                 config.Config
+	liveness             config.LivenessConfig
+	healthStatusFilePath string
+
+	mu           sync.RWMutex
+	status       probeStatus
+	healthStatus []byte
+}
+
+func newProbeServer(conf config.Config, liveness config.LivenessConfig) *probeServer {
+	return &probeServer{
+		conf:                 conf,
+		liveness:             liveness,
+		healthStatusFilePath: liveness.HealthStatusFilePath,
+		status:               probeStatus{Error: "the readiness has not been evaluated yet"},
+	}
+}
+
+// watch refreshes the readiness status until the context is cancelled.
+func (s *probeServer) watch(ctx context.Context) {
+	ticker := time.NewTicker(healthStatusPollInterval)
+	defer ticker.Stop()
+	for {
+		s.refresh(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh evaluates the readiness of the Pod if the health status file has changed since the last evaluation,
+// or if the last evaluation is older than maxEvaluationInterval.
+func (s *probeServer) refresh(ctx context.Context, now time.Time) {
+	info, err := os.Stat(s.healthStatusFilePath)
+	if err != nil {
+		s.setStatus(probeStatus{Error: fmt.Sprintf("health status file not available yet: %s", err), LastEvaluation: now}, nil)
+		return
+	}
+
+	s.mu.RLock()
+	healthStatus := s.healthStatus
+	changed := healthStatus == nil || !info.ModTime().Equal(s.status.HealthStatusModTime)
+	due := now.Sub(s.status.LastEvaluation) >= maxEvaluationInterval
+	s.mu.RUnlock()
+
+	if !changed && !due {
+		return
+	}
+
+	if changed {
+		healthStatus, err = os.ReadFile(s.healthStatusFilePath)
+		if err != nil {
+			s.setStatus(probeStatus{Error: fmt.Sprintf("could not read the health status file: %s", err), LastEvaluation: now}, nil)
+			return
+		}
+	}
+
+	if s.conf.MaxReplicationLag > 0 && s.conf.ReplicationLagReader == nil {
+		if s.conf.ReplicationLagReader, err = replicationLagReader(ctx, s.conf); err != nil {
+			logger.Warnf("The replication lag will not be checked: %s", err)
+		}
+	}
+
+	conf := s.conf
+	conf.HealthStatusReader = bytes.NewReader(healthStatus)
+	ready, err := isPodReady(ctx, conf)
+
+	status := probeStatus{Ready: ready, LastEvaluation: now, HealthStatusModTime: info.ModTime()}
+	if err != nil {
+		status.Ready = false
+		status.Error = err.Error()
+	}
+	s.setStatus(status, healthStatus)
+}
+
+func (s *probeServer) setStatus(status probeStatus, healthStatus []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+	s.healthStatus = healthStatus
+}
+
+func (s *probeServer) currentStatus() probeStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// handler returns the HTTP handler serving the /ready, /live and /status endpoints.
+func (s *probeServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, _ *http.Request) {
+		if s.currentStatus().Ready {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/live", func(w http.ResponseWriter, _ *http.Request) {
+		if isAgentAlive(s.currentStatus().HealthStatusModTime, s.liveness, time.Now()) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(s.currentStatus()); err != nil {
+			logger.Errorf("Could not write the probe status: %s", err)
+		}
+	})
+	return mux
+}
+
+// serve runs the readiness probe as an HTTP server on the given port.
+func serve(ctx context.Context, conf config.Config, liveness config.LivenessConfig, port int) error {
+	s := newProbeServer(conf, liveness)
+	go s.watch(ctx)
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	logger.Infof("Serving the readiness probe on port %d", port)
+	return server.ListenAndServe()
+}
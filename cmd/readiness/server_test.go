@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHealthStatus writes the given test health status to the file, with mongod being up for the given duration.
+func writeHealthStatus(t *testing.T, healthFilePath, testdataFile string, timeSinceMongoLastUp time.Duration, modTime time.Time) {
+	status, err := parseHealthStatus(testConfigWithMongoUp(testdataFile, timeSinceMongoLastUp).HealthStatusReader)
+	require.NoError(t, err)
+	data, err := json.Marshal(status)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(healthFilePath, data, 0o600))
+	require.NoError(t, os.Chtimes(healthFilePath, modTime, modTime))
+}
+
+func getStatusCode(t *testing.T, handler http.Handler, path string) int {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder.Code
+}
+
+func TestProbeServer(t *testing.T) {
+	ctx := context.Background()
+	healthFilePath := filepath.Join(t.TempDir(), "agent-health-status.json")
+	liveness := config.LivenessConfig{HealthStatusFilePath: healthFilePath, HealthStatusStaleThreshold: time.Minute}
+	s := newProbeServer(config.Config{Heuristics: config.DefaultHeuristics()}, liveness)
+	handler := s.handler()
+	now := time.Now()
+
+	t.Run("Not ready before the health status file exists", func(t *testing.T) {
+		s.refresh(ctx, now)
+		assert.Equal(t, http.StatusServiceUnavailable, getStatusCode(t, handler, "/ready"))
+		assert.Equal(t, http.StatusServiceUnavailable, getStatusCode(t, handler, "/live"))
+		assert.NotEmpty(t, s.currentStatus().Error)
+	})
+
+	t.Run("Ready once the agent reaches goal state", func(t *testing.T) {
+		writeHealthStatus(t, healthFilePath, "testdata/health-status-ok.json", 15*time.Second, now)
+		s.refresh(ctx, now)
+		assert.Equal(t, http.StatusOK, getStatusCode(t, handler, "/ready"))
+		assert.Equal(t, http.StatusOK, getStatusCode(t, handler, "/live"))
+		assert.Empty(t, s.currentStatus().Error)
+	})
+
+	t.Run("Unchanged health status file is not re-evaluated", func(t *testing.T) {
+		lastEvaluation := s.currentStatus().LastEvaluation
+		s.refresh(ctx, now.Add(time.Second))
+		assert.Equal(t, lastEvaluation, s.currentStatus().LastEvaluation)
+	})
+
+	t.Run("Changed health status file is re-evaluated", func(t *testing.T) {
+		writeHealthStatus(t, healthFilePath, "testdata/health-status-not-readable-state.json", 15*time.Second, now.Add(time.Second))
+		s.refresh(ctx, now.Add(2*time.Second))
+		assert.Equal(t, http.StatusServiceUnavailable, getStatusCode(t, handler, "/ready"))
+	})
+
+	t.Run("Status is served as JSON", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		status := probeStatus{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+		assert.False(t, status.Ready)
+		assert.Equal(t, now.Add(2*time.Second).Unix(), status.LastEvaluation.Unix())
+	})
+
+	t.Run("Not live once the health status file is stale", func(t *testing.T) {
+		writeHealthStatus(t, healthFilePath, "testdata/health-status-ok.json", 15*time.Second, now.Add(-time.Hour))
+		s.refresh(ctx, now.Add(3*time.Second))
+		assert.Equal(t, http.StatusServiceUnavailable, getStatusCode(t, handler, "/live"))
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/container"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/podtemplatespec"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/resourcerequirements"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/config"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/envvar"
 
 	corev1 "k8s.io/api/core/v1"
@@ -99,6 +100,23 @@ func TestMongoDBAgentCommand(t *testing.T) {
 	assert.Equal(t, cmd[2], baseCmd+" -logLevel INFO")
 }
 
+func TestMongoDBAgentContainer_ReadinessProbeServerMode(t *testing.T) {
+	t.Setenv(ReadinessProbeServerModeEnv, "true")
+
+	c := container.New(mongodbAgentContainer("automation-config", nil, mdbv1.LogLevelInfo, "testfile", 24, "fake-agentImage"))
+	assert.Equal(t, probes.New(HTTPReadiness()), *c.ReadinessProbe)
+	assert.Nil(t, c.ReadinessProbe.Exec)
+	assert.Equal(t, "/ready", c.ReadinessProbe.HTTPGet.Path)
+
+	agentCommand := AutomationAgentCommand(false, mdbv1.LogLevelInfo, "testfile", 24)
+	assert.Equal(t, `while true; do
+/opt/scripts/readinessprobe -serve -port=9091
+echo "The readiness probe server exited with code $?, restarting it"
+sleep 1
+done &
+`+agentCommand[2], c.Command[2])
+}
+
 func assertStatefulSetIsBuiltCorrectly(t *testing.T, mdb mdbv1.MongoDBCommunity, sts *appsv1.StatefulSet) {
 	assert.Len(t, sts.Spec.Template.Spec.Containers, 2)
 	assert.Len(t, sts.Spec.Template.Spec.InitContainers, 2)
@@ -176,3 +194,50 @@ func assertContainsVolumeMountWithName(t *testing.T, mounts []corev1.VolumeMount
 	}
 	assert.True(t, found, "Mounts should have contained a mount with name %s, but didn't. Actual mounts: %v", name, mounts)
 }
+
+func TestBuildLivenessProbesModification(t *testing.T) {
+	unresponsiveSeconds := 600
+	failureThreshold := 100
+	podTemplate := podtemplatespec.New(BuildLivenessProbesModification(
+		mdbv1.LivenessProbeConfiguration{MongodUnresponsiveSeconds: &unresponsiveSeconds},
+		&mdbv1.StartupProbeConfiguration{FailureThreshold: &failureThreshold},
+	))
+
+	agentContainer := container.GetByName(AgentName, podTemplate.Spec.Containers)
+	assert.Equal(t, []string{readinessProbePath, "-check=agent-liveness"}, agentContainer.LivenessProbe.Exec.Command)
+	assert.Equal(t, []string{readinessProbePath, "-check=agent-liveness"}, agentContainer.StartupProbe.Exec.Command)
+	assert.Equal(t, int32(3), agentContainer.LivenessProbe.FailureThreshold)
+	assert.Equal(t, int32(30), agentContainer.LivenessProbe.PeriodSeconds)
+	assert.Equal(t, int32(100), agentContainer.StartupProbe.FailureThreshold)
+
+	mongodContainer := container.GetByName(MongodbName, podTemplate.Spec.Containers)
+	assert.Equal(t, []string{readinessProbePath, "-check=mongod-liveness"}, mongodContainer.LivenessProbe.Exec.Command)
+	assert.Equal(t, []string{readinessProbePath, "-check=mongod-startup"}, mongodContainer.StartupProbe.Exec.Command)
+	assert.Contains(t, mongodContainer.Env, corev1.EnvVar{Name: config.LivenessProbeMongodUnresponsiveSeconds, Value: "600"})
+	assert.Contains(t, mongodContainer.Env, corev1.EnvVar{Name: config.LivenessProbeHealthStatusStaleSeconds, Value: "300"})
+	assert.Len(t, mongodContainer.VolumeMounts, 1)
+	assert.Equal(t, "/opt/scripts", mongodContainer.VolumeMounts[0].MountPath)
+}
+
+func TestBuildReadinessProbeHeuristicsModification(t *testing.T) {
+	waitStepsReady := false
+	gracePeriod := 30
+	notReadySeconds := 120
+	podTemplate := podtemplatespec.New(BuildReadinessProbeHeuristicsModification(mdbv1.ReadinessProbeConfiguration{
+		WaitStepGracePeriodSeconds: &gracePeriod,
+		WaitStepsReady:             &waitStepsReady,
+		WaitStepDenyList:           []string{"WaitAllRsMembersUp", "WaitRsInit"},
+		MongodNotReadySeconds:      &notReadySeconds,
+	}))
+
+	agentContainer := container.GetByName(AgentName, podTemplate.Spec.Containers)
+	assert.ElementsMatch(t, []corev1.EnvVar{
+		{Name: config.ReadinessProbeWaitStepGracePeriodSeconds, Value: "30"},
+		{Name: config.ReadinessProbeWaitStepsReady, Value: "false"},
+		{Name: config.ReadinessProbeWaitStepDenyList, Value: "WaitAllRsMembersUp,WaitRsInit"},
+		{Name: config.ReadinessProbeMongodNotReadySeconds, Value: "120"},
+	}, agentContainer.Env)
+
+	mongodContainer := container.GetByName(MongodbName, podTemplate.Spec.Containers)
+	assert.Equal(t, []corev1.EnvVar{{Name: config.ReadinessProbeMongodNotReadySeconds, Value: "120"}}, mongodContainer.Env)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/config"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/probes"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/resourcerequirements"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/statefulset"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/envvar"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/scale"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -34,6 +36,9 @@ const (
 	AgentImageEnv              = "AGENT_IMAGE"
 	VersionUpgradeHookImageEnv = "VERSION_UPGRADE_HOOK_IMAGE"
 	ReadinessProbeImageEnv     = "READINESS_PROBE_IMAGE"
+	// ReadinessProbeServerModeEnv runs the readiness probe as a long-running HTTP server in the agent container,
+	// instead of executing it on every probe. It requires a readiness probe image supporting the -serve flag.
+	ReadinessProbeServerModeEnv = "MDB_READINESS_PROBE_SERVER_MODE"
 )
 
 const (
@@ -45,6 +50,8 @@ const (
 	versionUpgradeHookName            = "mongod-posthook"
 	ReadinessProbeContainerName       = "mongodb-agent-readinessprobe"
 	readinessProbePath                = "/opt/scripts/readinessprobe"
+	agentScriptsVolumeName            = "agent-scripts"
+	agentScriptsPath                  = "/opt/scripts"
 	agentHealthStatusFilePathEnv      = "AGENT_STATUS_FILEPATH"
 	clusterFilePath                   = "/var/lib/automation/config/cluster-config.json"
 	mongodbDatabaseServiceAccountName = "mongodb-database"
@@ -173,8 +180,8 @@ func BuildMongoDBReplicaSetStatefulSetModificationFunction(mdb MongoDBStatefulSe
 		hooksVolumeMount := statefulset.CreateVolumeMount(hooksVolume.Name, "/hooks", statefulset.WithReadOnly(false))
 
 		// scripts volume is only required on the mongodb-agent pod.
-		scriptsVolume = statefulset.CreateVolumeFromEmptyDir("agent-scripts")
-		scriptsVolumeMount := statefulset.CreateVolumeMount(scriptsVolume.Name, "/opt/scripts", statefulset.WithReadOnly(false))
+		scriptsVolume = statefulset.CreateVolumeFromEmptyDir(agentScriptsVolumeName)
+		scriptsVolumeMount := statefulset.CreateVolumeMount(scriptsVolume.Name, agentScriptsPath, statefulset.WithReadOnly(false))
 
 		upgradeInitContainer = podtemplatespec.WithInitContainer(versionUpgradeHookName, versionUpgradeHookInit([]corev1.VolumeMount{hooksVolumeMount}, versionUpgradeHookImage))
 		readinessInitContainer = podtemplatespec.WithInitContainer(ReadinessProbeContainerName, readinessProbeInit([]corev1.VolumeMount{scriptsVolumeMount}, readinessProbeImage))
@@ -279,14 +286,22 @@ func AutomationAgentCommand(withAgentAPIKeyExport bool, logLevel mdbv1.LogLevel,
 
 func mongodbAgentContainer(automationConfigSecretName string, volumeMounts []corev1.VolumeMount, logLevel mdbv1.LogLevel, logFile string, maxLogFileDurationHours int, agentImage string) container.Modification {
 	_, containerSecurityContext := podtemplatespec.WithDefaultSecurityContextsModifications()
+
+	readinessProbe := DefaultReadiness()
+	agentCommand := AutomationAgentCommand(false, logLevel, logFile, maxLogFileDurationHours)
+	if envvar.ReadBool(ReadinessProbeServerModeEnv) { // nolint:forbidigo
+		readinessProbe = HTTPReadiness()
+		agentCommand = withReadinessProbeServer(agentCommand)
+	}
+
 	return container.Apply(
 		container.WithName(AgentName),
 		container.WithImage(agentImage),
 		container.WithImagePullPolicy(corev1.PullAlways),
-		container.WithReadinessProbe(DefaultReadiness()),
+		container.WithReadinessProbe(readinessProbe),
 		container.WithResourceRequirements(resourcerequirements.Defaults()),
 		container.WithVolumeMounts(volumeMounts),
-		container.WithCommand(AutomationAgentCommand(false, logLevel, logFile, maxLogFileDurationHours)),
+		container.WithCommand(agentCommand),
 		containerSecurityContext,
 		container.WithEnvs(
 			corev1.EnvVar{
@@ -311,6 +326,7 @@ func mongodbAgentContainer(automationConfigSecretName string, volumeMounts []cor
 				Value: agentHealthStatusFilePathValue,
 			},
 		),
+		container.WithEnvs(collectAgentEnvVars()...),
 	)
 }
 
@@ -335,6 +351,116 @@ func DefaultReadiness() probes.Modification {
 	)
 }
 
+// HTTPReadiness returns the readiness probe querying the readiness probe server running in the agent container.
+func HTTPReadiness() probes.Modification {
+	return probes.Apply(
+		probes.WithHTTPGet("/ready", config.DefaultServerPort),
+		probes.WithFailureThreshold(40),
+		probes.WithInitialDelaySeconds(5),
+	)
+}
+
+// BuildLivenessProbesModification adds the liveness and startup probes to the agent and mongod containers.
+// Both containers run the readiness probe binary with a different check: the agent container is restarted if the agent
+// stops updating its health status, and the mongod container if the agent reports mongod as unresponsive.
+// The probe binary is copied by the readiness probe init container, so the scripts volume is mounted in the mongod container as well.
+func BuildLivenessProbesModification(liveness mdbv1.LivenessProbeConfiguration, startup *mdbv1.StartupProbeConfiguration) podtemplatespec.Modification {
+	envs := []corev1.EnvVar{
+		{
+			Name:  config.LivenessProbeHealthStatusStaleSeconds,
+			Value: strconv.Itoa(liveness.GetHealthStatusStaleSeconds()),
+		},
+		{
+			Name:  config.LivenessProbeMongodUnresponsiveSeconds,
+			Value: strconv.Itoa(liveness.GetMongodUnresponsiveSeconds()),
+		},
+	}
+	scriptsVolumeMount := statefulset.CreateVolumeMount(agentScriptsVolumeName, agentScriptsPath, statefulset.WithReadOnly(true))
+
+	return podtemplatespec.Apply(
+		podtemplatespec.WithContainer(AgentName,
+			container.Apply(
+				container.WithLivenessProbe(livenessProbe(config.AgentLivenessCheck, liveness)),
+				container.WithStartupProbe(startupProbe(config.AgentLivenessCheck, startup)),
+				container.WithEnvs(envs...),
+			),
+		),
+		podtemplatespec.WithContainer(MongodbName,
+			container.Apply(
+				container.WithLivenessProbe(livenessProbe(config.MongodLivenessCheck, liveness)),
+				container.WithStartupProbe(startupProbe(config.MongodStartupCheck, startup)),
+				container.WithEnvs(envs...),
+				container.WithVolumeMounts([]corev1.VolumeMount{scriptsVolumeMount}),
+			),
+		),
+	)
+}
+
+// BuildReadinessProbeHeuristicsModification passes the configured readiness heuristics to the readiness probe
+// running in the agent container. Heuristics which are not set keep the defaults of the readiness probe.
+// The mongod not ready interval is also used by the startup check of the mongod container.
+func BuildReadinessProbeHeuristicsModification(readiness mdbv1.ReadinessProbeConfiguration) podtemplatespec.Modification {
+	var envs []corev1.EnvVar
+	if readiness.WaitStepGracePeriodSeconds != nil {
+		envs = append(envs, corev1.EnvVar{Name: config.ReadinessProbeWaitStepGracePeriodSeconds, Value: strconv.Itoa(*readiness.WaitStepGracePeriodSeconds)})
+	}
+	if readiness.WaitStepsReady != nil {
+		envs = append(envs, corev1.EnvVar{Name: config.ReadinessProbeWaitStepsReady, Value: strconv.FormatBool(*readiness.WaitStepsReady)})
+	}
+	if len(readiness.WaitStepAllowList) > 0 {
+		envs = append(envs, corev1.EnvVar{Name: config.ReadinessProbeWaitStepAllowList, Value: strings.Join(readiness.WaitStepAllowList, ",")})
+	}
+	if len(readiness.WaitStepDenyList) > 0 {
+		envs = append(envs, corev1.EnvVar{Name: config.ReadinessProbeWaitStepDenyList, Value: strings.Join(readiness.WaitStepDenyList, ",")})
+	}
+	var mongodEnvs []corev1.EnvVar
+	if readiness.MongodNotReadySeconds != nil {
+		notReadyEnv := corev1.EnvVar{Name: config.ReadinessProbeMongodNotReadySeconds, Value: strconv.Itoa(*readiness.MongodNotReadySeconds)}
+		envs = append(envs, notReadyEnv)
+		mongodEnvs = append(mongodEnvs, notReadyEnv)
+	}
+	return podtemplatespec.Apply(
+		podtemplatespec.WithContainer(AgentName, container.WithEnvs(envs...)),
+		podtemplatespec.WithContainer(MongodbName, container.WithEnvs(mongodEnvs...)),
+	)
+}
+
+func livenessProbe(check string, liveness mdbv1.LivenessProbeConfiguration) probes.Modification {
+	return probes.Apply(
+		probes.WithExecCommand([]string{readinessProbePath, "-check=" + check}),
+		probes.WithPeriodSeconds(liveness.GetPeriodSeconds()),
+		probes.WithFailureThreshold(liveness.GetFailureThreshold()),
+		probes.WithTimeoutSeconds(5),
+	)
+}
+
+func startupProbe(check string, startup *mdbv1.StartupProbeConfiguration) probes.Modification {
+	return probes.Apply(
+		probes.WithExecCommand([]string{readinessProbePath, "-check=" + check}),
+		probes.WithPeriodSeconds(startup.GetPeriodSeconds()),
+		probes.WithFailureThreshold(startup.GetFailureThreshold()),
+		probes.WithTimeoutSeconds(5),
+	)
+}
+
+// withReadinessProbeServer starts the readiness probe server in the background before the agent. The server is
+// restarted if it exits, so that the readiness of the Pod doesn't depend on a server that is no longer running.
+func withReadinessProbeServer(agentCommand []string) []string {
+	command := make([]string, len(agentCommand))
+	copy(command, agentCommand)
+	command[len(command)-1] = readinessProbeServerCommand() + command[len(command)-1]
+	return command
+}
+
+func readinessProbeServerCommand() string {
+	return fmt.Sprintf(`while true; do
+%s -serve -port=%d
+echo "The readiness probe server exited with code $?, restarting it"
+sleep 1
+done &
+`, readinessProbePath, config.DefaultServerPort)
+}
+
 func dataPvc(dataVolumeName string) persistentvolumeclaim.Modification {
 	return persistentvolumeclaim.Apply(
 		persistentvolumeclaim.WithName(dataVolumeName),
@@ -416,21 +542,26 @@ func collectEnvVars() []corev1.EnvVar {
 		Value: "/healthstatus/agent-health-status.json",
 	})
 
-	addEnvVarIfSet := func(name string) {
-		value := os.Getenv(name) // nolint:forbidigo
-		if value != "" {
-			envVars = append(envVars, corev1.EnvVar{
-				Name:  name,
-				Value: value,
-			})
-		}
-	}
-
-	addEnvVarIfSet(config.ReadinessProbeLoggerBackups)
-	addEnvVarIfSet(config.ReadinessProbeLoggerMaxSize)
-	addEnvVarIfSet(config.ReadinessProbeLoggerMaxAge)
-	addEnvVarIfSet(config.ReadinessProbeLoggerCompress)
-	addEnvVarIfSet(config.WithAgentFileLogging)
+	envVars = append(envVars, envVarIfSet(config.ReadinessProbeLoggerBackups)...)
+	envVars = append(envVars, envVarIfSet(config.ReadinessProbeLoggerMaxSize)...)
+	envVars = append(envVars, envVarIfSet(config.ReadinessProbeLoggerMaxAge)...)
+	envVars = append(envVars, envVarIfSet(config.ReadinessProbeLoggerCompress)...)
+	envVars = append(envVars, envVarIfSet(config.WithAgentFileLogging)...)
 
 	return envVars
 }
+
+// collectAgentEnvVars returns the environment variables of the operator which are passed to the
+// readiness probe running in the agent container.
+func collectAgentEnvVars() []corev1.EnvVar {
+	return envVarIfSet(config.ReadinessProbeMaxReplicationLagSeconds)
+}
+
+// envVarIfSet returns the operator environment variable with the given name, if it is set.
+func envVarIfSet(name string) []corev1.EnvVar {
+	value := os.Getenv(name) // nolint:forbidigo
+	if value == "" {
+		return nil
+	}
+	return []corev1.EnvVar{{Name: name, Value: value}}
+}
@@ -95,3 +95,10 @@ func TestCollectEnvVars(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectAgentEnvVars(t *testing.T) {
+	assert.Empty(t, collectAgentEnvVars())
+
+	t.Setenv(config.ReadinessProbeMaxReplicationLagSeconds, "30")
+	assert.Equal(t, []corev1.EnvVar{{Name: config.ReadinessProbeMaxReplicationLagSeconds, Value: "30"}}, collectAgentEnvVars())
+}
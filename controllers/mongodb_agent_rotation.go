@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/scram"
+)
+
+const (
+	// rotateAgentCredentials can be set on a MongoDBCommunity resource to rotate the agent password and keyfile.
+	// A new rotation is started every time its value changes.
+	rotateAgentCredentials = "mongodb.com/v1.rotateAgentCredentials"
+)
+
+// ensureAgentCredentialsRotation moves the rotation of the agent password and keyfile forward. It must only be called
+// once all the processes have reached the goal state of the current automation config.
+//
+// A rotation first publishes a keyfile with both the previous and the new key together with a new agent password, and
+// then a keyfile with the new key only. Each step is published in its own automation config version.
+//
+// It returns the rotation to store in the status and the number of seconds after which the reconciliation must be
+// requeued to publish the next step, or 0 if there is none.
+func (r ReplicaSetReconciler) ensureAgentCredentialsRotation(ctx context.Context, mdb mdbv1.MongoDBCommunity) (*mdbv1.AgentCredentialsRotation, int, error) {
+	rotation := mdb.Status.AgentCredentialsRotation
+	if rotation != nil {
+		switch rotation.Phase {
+		case mdbv1.AgentCredentialsRotationAddingKey:
+			r.log.Info("All processes are using the keyfile with the new key, removing the previous key")
+			if err := scram.RemovePreviousAgentKeyfileKeys(ctx, r.client, &mdb); err != nil {
+				return nil, 0, err
+			}
+			return agentCredentialsRotation(rotation.Trigger, mdbv1.AgentCredentialsRotationRemovingKey), 1, nil
+		case mdbv1.AgentCredentialsRotationRemovingKey:
+			r.log.Info("Agent credentials rotation completed")
+			return agentCredentialsRotation(rotation.Trigger, mdbv1.AgentCredentialsRotationCompleted), 0, nil
+		}
+	}
+
+	trigger := mdb.Annotations[rotateAgentCredentials]
+	if trigger == "" || (rotation != nil && rotation.Trigger == trigger) {
+		return rotation, 0, nil
+	}
+	if mdb.Spec.Security.Authentication.AgentKeyfileSecretRef != nil {
+		return nil, 0, fmt.Errorf("the agent keyfile is read from spec.security.authentication.agentKeyfileSecretRef, it must be rotated in its secret store")
+	}
+
+	r.log.Infof("Rotating the agent credentials, requested by annotation %s=%s", rotateAgentCredentials, trigger)
+	if err := scram.AddAgentKeyfileKey(ctx, r.client, &mdb); err != nil {
+		return nil, 0, fmt.Errorf("could not add a new key to the agent keyfile: %s", err)
+	}
+	return agentCredentialsRotation(trigger, mdbv1.AgentCredentialsRotationAddingKey), 1, nil
+}
+
+func agentCredentialsRotation(trigger string, phase mdbv1.AgentCredentialsRotationPhase) *mdbv1.AgentCredentialsRotation {
+	return &mdbv1.AgentCredentialsRotation{
+		Trigger:            trigger,
+		Phase:              phase,
+		LastTransitionTime: metav1.Now(),
+	}
+}
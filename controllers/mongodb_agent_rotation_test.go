@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+)
+
+func TestAgentCredentialsRotation(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	previousAc := assertAutomationConfigVersion(ctx, t, mgr.Client, mdb, 1)
+	previousKey, previousPassword := previousAc.Auth.Key, previousAc.Auth.AutoPwd
+
+	err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	mdb.Annotations[rotateAgentCredentials] = "first"
+	require.NoError(t, mgr.Client.Update(ctx, &mdb))
+
+	t.Run("A new key is added to the keyfile", func(t *testing.T) {
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, res.RequeueAfter)
+		assert.Equal(t, mdbv1.AgentCredentialsRotationAddingKey, agentCredentialsRotationPhase(ctx, t, mgr.Client, mdb))
+
+		res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, res.RequeueAfter)
+		assert.Equal(t, mdbv1.AgentCredentialsRotationRemovingKey, agentCredentialsRotationPhase(ctx, t, mgr.Client, mdb))
+
+		ac := assertAutomationConfigVersion(ctx, t, mgr.Client, mdb, 2)
+		assert.True(t, strings.HasPrefix(ac.Auth.Key, "- "+previousKey+"\n- "), "the previous key must be used until all processes have the new key")
+		assert.NotEqual(t, previousPassword, ac.Auth.AutoPwd)
+	})
+
+	t.Run("The previous key is removed from the keyfile", func(t *testing.T) {
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+		assert.Equal(t, mdbv1.AgentCredentialsRotationCompleted, agentCredentialsRotationPhase(ctx, t, mgr.Client, mdb))
+
+		ac := assertAutomationConfigVersion(ctx, t, mgr.Client, mdb, 3)
+		assert.NotContains(t, ac.Auth.Key, previousKey)
+		assert.NotContains(t, ac.Auth.Key, "\n")
+	})
+
+	t.Run("The credentials are not rotated again for the same annotation", func(t *testing.T) {
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+		assertAutomationConfigVersion(ctx, t, mgr.Client, mdb, 3)
+	})
+}
+
+func agentCredentialsRotationPhase(ctx context.Context, t *testing.T, c client.Client, mdb mdbv1.MongoDBCommunity) mdbv1.AgentCredentialsRotationPhase {
+	err := c.Get(ctx, types.NamespacedName{Name: mdb.Name, Namespace: mdb.Namespace}, &mdb)
+	require.NoError(t, err)
+	require.NotNil(t, mdb.Status.AgentCredentialsRotation)
+	return mdb.Status.AgentCredentialsRotation.Phase
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"strings"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+)
+
+// auditLogFileName is the name of the audit log file, without its extension. It is written to the logs volume, next
+// to the log of mongod, so that it outlives the pods and is rotated by the agent with spec.agent.auditLogRotate.
+const auditLogFileName = "mongodb-audit"
+
+// auditLogPath returns the path of the audit log file, with the extension of its format.
+func auditLogPath(auditLog mdbv1.AuditLog) string {
+	return automationconfig.DefaultAgentLogPath + "/" + auditLogFileName + "." + strings.ToLower(string(auditLog.GetFormat()))
+}
+
+// getAuditLogModification renders spec.auditLog into the auditLog options of the processes.
+func getAuditLogModification(mdb mdbv1.MongoDBCommunity) automationconfig.Modification {
+	auditLog := mdb.Spec.AuditLog
+	if auditLog == nil {
+		return automationconfig.NOOP()
+	}
+
+	return func(config *automationconfig.AutomationConfig) {
+		for i := range config.Processes {
+			args := config.Processes[i].Args26
+			args.Set("auditLog.destination", string(auditLog.GetDestination()))
+			if auditLog.GetDestination() == mdbv1.AuditLogDestinationFile {
+				args.Set("auditLog.format", string(auditLog.GetFormat()))
+				args.Set("auditLog.path", auditLogPath(*auditLog))
+			}
+			if auditLog.Filter != "" {
+				args.Set("auditLog.filter", auditLog.Filter)
+			}
+			if auditLog.IsRuntimeConfiguration() {
+				args.Set("auditLog.runtimeConfiguration", true)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/controllers/construct"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+)
+
+func TestAuditLog_IsConfiguredForEnterpriseImages(t *testing.T) {
+	t.Setenv(construct.MongoDBAssumeEnterpriseEnv, "true")
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mdb.Spec.AuditLog = &mdbv1.AuditLog{Format: mdbv1.AuditLogFormatBSON, Filter: `{"atype": "authenticate"}`}
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	t.Run("The processes write the audit log file to the logs volume", func(t *testing.T) {
+		ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+		require.NoError(t, err)
+		for _, p := range ac.Processes {
+			assert.Equal(t, "file", p.Args26.Get("auditLog.destination").Str())
+			assert.Equal(t, "BSON", p.Args26.Get("auditLog.format").Str())
+			assert.Equal(t, "/var/log/mongodb-mms-automation/mongodb-audit.bson", p.Args26.Get("auditLog.path").Str())
+			assert.Equal(t, `{"atype": "authenticate"}`, p.Args26.Get("auditLog.filter").Str())
+			assert.Nil(t, p.Args26.Get("auditLog.runtimeConfiguration").Data())
+		}
+
+		sts := appsv1.StatefulSet{}
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &sts))
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name == construct.MongodbName {
+				assert.Contains(t, c.VolumeMounts, corev1.VolumeMount{Name: mdb.LogsVolumeName(), MountPath: automationconfig.DefaultAgentLogPath})
+			}
+		}
+	})
+
+	t.Run("The audit log can be written to the console", func(t *testing.T) {
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+		mdb.Spec.AuditLog = &mdbv1.AuditLog{Destination: mdbv1.AuditLogDestinationConsole}
+		require.NoError(t, mgr.Client.Update(ctx, &mdb))
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+
+		ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+		require.NoError(t, err)
+		for _, p := range ac.Processes {
+			assert.Equal(t, "console", p.Args26.Get("auditLog.destination").Str())
+			assert.Nil(t, p.Args26.Get("auditLog.path").Data())
+			assert.Nil(t, p.Args26.Get("auditLog.filter").Data())
+		}
+	})
+}
+
+func TestAuditLog_RequiresEnterprise(t *testing.T) {
+	t.Setenv(construct.MongoDBAssumeEnterpriseEnv, "false")
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mdb.Spec.AuditLog = &mdbv1.AuditLog{}
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+	assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+	assert.Contains(t, mdb.Status.Message, "the audit log requires MongoDB Enterprise")
+}
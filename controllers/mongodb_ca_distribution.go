@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	k8sClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/configmap"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+)
+
+const (
+	// caDistributionNameLabel and caDistributionNamespaceLabel identify the resource a distributed CA certificate
+	// belongs to. Owner references can't be used, as they can't reference a resource of another namespace.
+	caDistributionNameLabel      = "mongodb.com/v1.caOfName"
+	caDistributionNamespaceLabel = "mongodb.com/v1.caOfNamespace"
+
+	// caDistributionResyncSeconds is how often the CA certificate is distributed again, so that it reaches the
+	// namespaces created or labeled since.
+	caDistributionResyncSeconds = 300
+)
+
+// caDistributionLabels returns the labels of the ConfigMaps and Secrets the CA certificate of the resource is
+// distributed in.
+func caDistributionLabels(mdb mdbv1.MongoDBCommunity) map[string]string {
+	return map[string]string{
+		caDistributionNameLabel:      mdb.Name,
+		caDistributionNamespaceLabel: mdb.Namespace,
+	}
+}
+
+// isDistributedCAOf returns true if the object is a distributed CA certificate of the resource.
+func isDistributedCAOf(obj metav1.Object, mdb mdbv1.MongoDBCommunity) bool {
+	objLabels := obj.GetLabels()
+	return objLabels[caDistributionNameLabel] == mdb.Name && objLabels[caDistributionNamespaceLabel] == mdb.Namespace
+}
+
+// ensureCADistribution publishes the CA certificate in each namespace selected by spec.security.tls.distributeCA,
+// and deletes the copies which aren't selected anymore, which includes all of them once TLS or the distribution is
+// disabled. The copies are recorded in the status of the resource, so that they are only looked up in all the
+// namespaces when some of them must be deleted.
+//
+// It returns the number of seconds after which the CA certificate must be distributed again, or 0 if it isn't
+// distributed.
+func (r ReplicaSetReconciler) ensureCADistribution(ctx context.Context, mdb *mdbv1.MongoDBCommunity) (int, error) {
+	distributeCA := mdb.Spec.Security.TLS.DistributeCA
+	previous := mdb.Status.DistributedCA
+	var current *mdbv1.DistributedCAStatus
+	distributed := map[types.NamespacedName]bool{}
+	retryAfter := 0
+
+	if distributeCA != nil && mdb.IsTLSConfiguredThisReconciliation() {
+		ca, err := getCaCrt(ctx, r.client, r.client, *mdb)
+		if err != nil {
+			return 0, err
+		}
+		namespaces, err := r.caDistributionNamespaces(ctx, *distributeCA)
+		if err != nil {
+			return 0, err
+		}
+		current = &mdbv1.DistributedCAStatus{Kind: distributeCA.GetKind(), Name: mdb.DistributedCAName(), Namespaces: namespaces}
+		if previous == nil {
+			// the copies are recorded before being created, so that they are deleted even if some of them fail.
+			mdb.Status.DistributedCA = current
+		}
+		for _, namespace := range namespaces {
+			nsName := types.NamespacedName{Name: current.Name, Namespace: namespace}
+			if current.Kind == mdbv1.DistributeCAKindSecret {
+				err = r.ensureDistributedCASecret(ctx, *mdb, nsName, ca)
+			} else {
+				err = r.ensureDistributedCAConfigMap(ctx, *mdb, nsName, ca)
+			}
+			if err != nil {
+				return 0, err
+			}
+			distributed[nsName] = true
+		}
+		retryAfter = caDistributionResyncSeconds
+	}
+
+	if previous != nil && (current == nil || !previous.IsDistributedIn(current.Kind, current.Name, current.Namespaces)) {
+		kind := mdbv1.DistributeCAKind("")
+		if current != nil {
+			kind = current.Kind
+		}
+		if err := r.deleteStaleDistributedCAs(ctx, *mdb, kind, distributed); err != nil {
+			return 0, err
+		}
+	}
+	mdb.Status.DistributedCA = current
+	return retryAfter, nil
+}
+
+// caDistributionNamespaces returns the namespaces selected by the namespace selector. Namespaces being deleted are
+// skipped, as nothing can be created in them anymore.
+func (r ReplicaSetReconciler) caDistributionNamespaces(ctx context.Context, distributeCA mdbv1.DistributeCA) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&distributeCA.NamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaceList := corev1.NamespaceList{}
+	if err := r.client.List(ctx, &namespaceList); err != nil {
+		return nil, fmt.Errorf("could not list the namespaces: %s", err)
+	}
+
+	var namespaces []string
+	for _, namespace := range namespaceList.Items {
+		if namespace.Status.Phase == corev1.NamespaceTerminating || !selector.Matches(labels.Set(namespace.Labels)) {
+			continue
+		}
+		namespaces = append(namespaces, namespace.Name)
+	}
+	return namespaces, nil
+}
+
+// caDistributionOwnerReferences returns the owner references of a distributed CA certificate. Only the copy in the
+// namespace of the resource can be owned by it, and garbage collected with it.
+func caDistributionOwnerReferences(mdb mdbv1.MongoDBCommunity, namespace string) []metav1.OwnerReference {
+	if namespace != mdb.Namespace {
+		return nil
+	}
+	return mdb.GetOwnerReferences()
+}
+
+func (r ReplicaSetReconciler) ensureDistributedCAConfigMap(ctx context.Context, mdb mdbv1.MongoDBCommunity, nsName types.NamespacedName, ca string) error {
+	existing, err := r.client.GetConfigMap(ctx, nsName)
+	if err != nil && !apiErrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && !isDistributedCAOf(&existing, mdb) {
+		return fmt.Errorf("the ConfigMap %s already exists and is not managed by the operator", nsName)
+	}
+
+	cm := configmap.Builder().
+		SetName(nsName.Name).
+		SetNamespace(nsName.Namespace).
+		SetLabels(caDistributionLabels(mdb)).
+		SetOwnerReferences(caDistributionOwnerReferences(mdb, nsName.Namespace)).
+		SetDataField(tlsCACertName, ca).
+		Build()
+	if err := configmap.CreateOrUpdate(ctx, r.client, cm); err != nil {
+		return err
+	}
+
+	r.configMapWatcher.Watch(ctx, nsName, mdb.NamespacedName())
+	return nil
+}
+
+func (r ReplicaSetReconciler) ensureDistributedCASecret(ctx context.Context, mdb mdbv1.MongoDBCommunity, nsName types.NamespacedName, ca string) error {
+	existing, err := r.client.GetSecret(ctx, nsName)
+	if err != nil && !apiErrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && !isDistributedCAOf(&existing, mdb) {
+		return fmt.Errorf("the Secret %s already exists and is not managed by the operator", nsName)
+	}
+
+	s := secret.Builder().
+		SetName(nsName.Name).
+		SetNamespace(nsName.Namespace).
+		SetLabels(caDistributionLabels(mdb)).
+		SetOwnerReferences(caDistributionOwnerReferences(mdb, nsName.Namespace)).
+		SetField(tlsCACertName, ca).
+		Build()
+	if err := secret.CreateOrUpdate(ctx, r.client, s); err != nil {
+		return err
+	}
+
+	r.secretWatcher.Watch(ctx, nsName, mdb.NamespacedName())
+	return nil
+}
+
+// deleteStaleDistributedCAs deletes the distributed CA certificates of the resource which are not of the given kind
+// or not in the given set, such as the ones of namespaces which aren't selected anymore or of a previous name.
+func (r ReplicaSetReconciler) deleteStaleDistributedCAs(ctx context.Context, mdb mdbv1.MongoDBCommunity, kind mdbv1.DistributeCAKind, distributed map[types.NamespacedName]bool) error {
+	matchingLabels := k8sClient.MatchingLabels(caDistributionLabels(mdb))
+
+	configMaps := corev1.ConfigMapList{}
+	if err := r.client.List(ctx, &configMaps, matchingLabels); err != nil {
+		return fmt.Errorf("could not list the distributed CA ConfigMaps: %s", err)
+	}
+	for _, cm := range configMaps.Items {
+		nsName := types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}
+		if kind == mdbv1.DistributeCAKindConfigMap && distributed[nsName] {
+			continue
+		}
+		r.log.Infof("Deleting the distributed CA ConfigMap %s", nsName)
+		if err := r.client.DeleteConfigMap(ctx, nsName); err != nil && !apiErrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	secrets := corev1.SecretList{}
+	if err := r.client.List(ctx, &secrets, matchingLabels); err != nil {
+		return fmt.Errorf("could not list the distributed CA Secrets: %s", err)
+	}
+	for _, s := range secrets.Items {
+		nsName := types.NamespacedName{Name: s.Name, Namespace: s.Namespace}
+		if kind == mdbv1.DistributeCAKindSecret && distributed[nsName] {
+			continue
+		}
+		r.log.Infof("Deleting the distributed CA Secret %s", nsName)
+		if err := r.client.DeleteSecret(ctx, nsName); err != nil && !apiErrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
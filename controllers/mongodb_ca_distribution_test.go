@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/configmap"
+)
+
+func newCADistributionReplicaSet(ctx context.Context, t *testing.T) (mdbv1.MongoDBCommunity, client.Client) {
+	mdb := newTestReplicaSetWithTLSCaCertificateReferences(&corev1.LocalObjectReference{Name: "caConfigMap"}, nil)
+	mdb.Spec.Security.TLS.DistributeCA = &mdbv1.DistributeCA{
+		NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "apps"}},
+	}
+	mgr := client.NewManager(ctx, &mdb)
+	require.NoError(t, createTLSSecret(ctx, mgr.Client, mdb, "CERT", "KEY", ""))
+	require.NoError(t, createTLSConfigMap(ctx, mgr.Client, mdb))
+
+	for name, labels := range map[string]map[string]string{
+		"apps-1": {"team": "apps"},
+		"apps-2": {"team": "apps"},
+		"other":  nil,
+	} {
+		namespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+		require.NoError(t, mgr.Client.Create(ctx, &namespace))
+	}
+	return mdb, mgr.Client
+}
+
+func TestCADistribution(t *testing.T) {
+	ctx := context.Background()
+	mdb, c := newCADistributionReplicaSet(ctx, t)
+	r := NewReconciler(client.NewManagerWithClient(c), "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	reconcileAndUpdate := func(t *testing.T, update func(mdb *mdbv1.MongoDBCommunity)) {
+		require.NoError(t, c.Get(ctx, mdb.NamespacedName(), &mdb))
+		update(&mdb)
+		require.NoError(t, c.Update(ctx, &mdb))
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+	}
+
+	t.Run("The CA is distributed to the selected namespaces", func(t *testing.T) {
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+		assert.Equal(t, caDistributionResyncSeconds*time.Second, res.RequeueAfter)
+
+		for _, namespace := range []string{"apps-1", "apps-2"} {
+			cm, err := c.GetConfigMap(ctx, types.NamespacedName{Name: "my-rs-ca", Namespace: namespace})
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{tlsCACertName: "CERT"}, cm.Data)
+			assert.Equal(t, caDistributionLabels(mdb), cm.Labels)
+			assert.Empty(t, cm.OwnerReferences)
+		}
+		_, err = c.GetConfigMap(ctx, types.NamespacedName{Name: "my-rs-ca", Namespace: "other"})
+		assert.Error(t, err)
+	})
+
+	t.Run("The CA is updated when it changes", func(t *testing.T) {
+		require.NoError(t, configmap.UpdateField(ctx, c, mdb.TLSConfigMapNamespacedName(), tlsCACertName, "NEW-CERT"))
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+
+		cm, err := c.GetConfigMap(ctx, types.NamespacedName{Name: "my-rs-ca", Namespace: "apps-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "NEW-CERT", cm.Data[tlsCACertName])
+	})
+
+	t.Run("The CA is removed from the namespaces which aren't selected anymore", func(t *testing.T) {
+		namespace := corev1.Namespace{}
+		require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "apps-2"}, &namespace))
+		namespace.Labels = nil
+		require.NoError(t, c.Update(ctx, &namespace))
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+
+		_, err = c.GetConfigMap(ctx, types.NamespacedName{Name: "my-rs-ca", Namespace: "apps-2"})
+		assert.Error(t, err)
+		_, err = c.GetConfigMap(ctx, types.NamespacedName{Name: "my-rs-ca", Namespace: "apps-1"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("The CA is moved to a Secret of another name", func(t *testing.T) {
+		reconcileAndUpdate(t, func(mdb *mdbv1.MongoDBCommunity) {
+			mdb.Spec.Security.TLS.DistributeCA.Kind = mdbv1.DistributeCAKindSecret
+			mdb.Spec.Security.TLS.DistributeCA.Name = "mongodb-ca"
+		})
+
+		s, err := c.GetSecret(ctx, types.NamespacedName{Name: "mongodb-ca", Namespace: "apps-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "NEW-CERT", string(s.Data[tlsCACertName]))
+		_, err = c.GetConfigMap(ctx, types.NamespacedName{Name: "my-rs-ca", Namespace: "apps-1"})
+		assert.Error(t, err)
+	})
+
+	t.Run("The CA is removed once it isn't distributed anymore", func(t *testing.T) {
+		reconcileAndUpdate(t, func(mdb *mdbv1.MongoDBCommunity) {
+			mdb.Spec.Security.TLS.DistributeCA = nil
+		})
+
+		_, err := c.GetSecret(ctx, types.NamespacedName{Name: "mongodb-ca", Namespace: "apps-1"})
+		assert.Error(t, err)
+	})
+}
+
+// caDistributionListCounter counts the lists of the distributed CA certificates.
+type caDistributionListCounter struct {
+	k8sClient.Client
+	lists int
+}
+
+func (c *caDistributionListCounter) List(ctx context.Context, list k8sClient.ObjectList, opts ...k8sClient.ListOption) error {
+	listOptions := k8sClient.ListOptions{}
+	listOptions.ApplyOptions(opts)
+	if listOptions.LabelSelector != nil && strings.Contains(listOptions.LabelSelector.String(), caDistributionNameLabel) {
+		c.lists++
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestCADistribution_CopiesAreOnlyListedWhenSomeAreStale(t *testing.T) {
+	ctx := context.Background()
+	mdb, c := newCADistributionReplicaSet(ctx, t)
+	counter := &caDistributionListCounter{Client: c}
+	r := NewReconciler(client.NewManagerWithClient(counter), "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	for i := 0; i < 2; i++ {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 0, counter.lists)
+	require.NoError(t, c.Get(ctx, mdb.NamespacedName(), &mdb))
+	assert.Equal(t, &mdbv1.DistributedCAStatus{Kind: mdbv1.DistributeCAKindConfigMap, Name: "my-rs-ca", Namespaces: []string{"apps-1", "apps-2"}}, mdb.Status.DistributedCA)
+
+	namespace := corev1.Namespace{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: "apps-2"}, &namespace))
+	namespace.Labels = nil
+	require.NoError(t, c.Update(ctx, &namespace))
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, counter.lists, "the ConfigMaps and the Secrets are listed once")
+	require.NoError(t, c.Get(ctx, mdb.NamespacedName(), &mdb))
+	assert.Equal(t, []string{"apps-1"}, mdb.Status.DistributedCA.Namespaces)
+	_, err = c.GetConfigMap(ctx, types.NamespacedName{Name: "my-rs-ca", Namespace: "apps-2"})
+	assert.Error(t, err)
+}
+
+func TestCADistribution_RequiresWatchingAllNamespaces(t *testing.T) {
+	ctx := context.Background()
+	mdb, c := newCADistributionReplicaSet(ctx, t)
+	t.Setenv(watchNamespace, mdb.Namespace)
+	r := NewReconciler(client.NewManagerWithClient(c), "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Get(ctx, mdb.NamespacedName(), &mdb))
+	assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+	assert.Contains(t, mdb.Status.Message, "spec.security.tls.distributeCA can only be set if the operator watches all namespaces")
+	_, err = c.GetConfigMap(ctx, types.NamespacedName{Name: "my-rs-ca", Namespace: "apps-1"})
+	assert.Error(t, err)
+}
+
+func TestCADistribution_UnmanagedResourceIsNotOverwritten(t *testing.T) {
+	ctx := context.Background()
+	mdb, c := newCADistributionReplicaSet(ctx, t)
+	existing := configmap.Builder().SetName("my-rs-ca").SetNamespace("apps-1").SetDataField(tlsCACertName, "OTHER-CERT").Build()
+	require.NoError(t, c.Create(ctx, &existing))
+	r := NewReconciler(client.NewManagerWithClient(c), "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Get(ctx, mdb.NamespacedName(), &mdb))
+	assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+	assert.Contains(t, mdb.Status.Message, "the ConfigMap apps-1/my-rs-ca already exists and is not managed by the operator")
+
+	cm, err := c.GetConfigMap(ctx, types.NamespacedName{Name: "my-rs-ca", Namespace: "apps-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "OTHER-CERT", cm.Data[tlsCACertName])
+}
+
+func TestConnectionStringSecretContainsTheCA(t *testing.T) {
+	ctx := context.Background()
+	mdb := newTestReplicaSetWithTLS()
+	mdb.Spec.Users = []mdbv1.MongoDBUser{{
+		Name:                       "app-user",
+		DB:                         "admin",
+		PasswordSecretRef:          mdbv1.SecretKeyReference{Name: "app-user-password"},
+		ScramCredentialsSecretName: "app-user",
+	}}
+	mgr := client.NewManager(ctx, &mdb)
+	require.NoError(t, createTLSSecret(ctx, mgr.Client, mdb, "CERT", "KEY", ""))
+	require.NoError(t, createTLSConfigMap(ctx, mgr.Client, mdb))
+	require.NoError(t, createUserPasswordSecret(ctx, mgr.Client, mdb, "app-user-password", "pass"))
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	connectionStringSecret, err := mgr.Client.GetSecret(ctx, types.NamespacedName{Name: mdb.GetAuthUsers()[0].ConnectionStringSecretName, Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	assert.Equal(t, "CERT", string(connectionStringSecret.Data[tlsCACertName]))
+}
@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/certificates"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+)
+
+const (
+	certificateExpiringReason = "CertificateExpiring"
+	certificateExpiredReason  = "CertificateExpired"
+	certificatesValidReason   = "CertificatesValid"
+)
+
+// certificateExpiryTimestamp exposes the expiry of the certificates in the metrics of the operator, so that alerts can
+// be defined on them.
+var certificateExpiryTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "mongodbcommunity_certificate_expiry_timestamp_seconds",
+	Help: "The time at which a certificate of a MongoDBCommunity resource expires, in seconds since the epoch.",
+}, []string{"namespace", "name", "type", "source", "subject"})
+
+func init() {
+	metrics.Registry.MustRegister(certificateExpiryTimestamp)
+}
+
+// validateServerCertificateHostnames checks that the server certificates are valid for the hostnames of the members
+// which use them, so that they are not rolled out otherwise. The certificates which can't be parsed are not checked.
+func (r *ReplicaSetReconciler) validateServerCertificateHostnames(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb mdbv1.MongoDBCommunity, clusterDomain string) error {
+	if !mdb.IsTLSConfiguredThisReconciliation() {
+		return nil
+	}
+
+	certKey, memberCertKeys, err := getServerCertificateKeys(ctx, secrets, mdb)
+	if err != nil {
+		return err
+	}
+
+	memberHostnames := tlsMemberHostnames(mdb, clusterDomain)
+	for _, podName := range tlsMemberPodNames(mdb) {
+		memberCertKey := certKey
+		if memberCertKeys != nil {
+			memberCertKey = memberCertKeys[podName]
+		}
+		certs, err := certificates.ParseCertificates(memberCertKey)
+		if err != nil {
+			r.log.Debugf("Could not parse the server certificate of %s, its hostnames are not validated: %s", podName, err)
+			continue
+		}
+		for _, hostname := range memberHostnames[podName] {
+			if err := certs[0].VerifyHostname(hostname); err != nil {
+				return fmt.Errorf("the server certificate of %s is not valid for %s: %s", podName, hostname, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ensureCertificateExpiry records the expiry of the certificates the deployment is configured with in the status of
+// mdb, together with the CertificatesExpiring condition, and emits a warning event when a certificate crosses one of
+// the warning thresholds. The status is recorded by every following status update, including the ones of failed
+// reconciliations, as an expired certificate is usually why they fail.
+//
+// It returns the number of seconds after which the next certificate crosses a warning threshold, or 0 if there is none.
+func (r ReplicaSetReconciler) ensureCertificateExpiry(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb *mdbv1.MongoDBCommunity) (int, error) {
+	certs, err := r.readCertificates(ctx, secrets, *mdb)
+	if err != nil {
+		return 0, err
+	}
+
+	previousThresholds := map[string]*int{}
+	for _, cert := range mdb.Status.Certificates {
+		previousThresholds[certificateStatusKey(cert)] = cert.WarningThresholdDays
+	}
+
+	now := time.Now()
+	thresholds := mdb.Spec.GetCertificateExpiryWarningThresholdDays()
+	certificateExpiryTimestamp.DeletePartialMatch(prometheus.Labels{"namespace": mdb.Namespace, "name": mdb.Name})
+
+	var nextCrossing time.Time
+	var expiring []string
+	reason := certificatesValidReason
+	for i := range certs {
+		cert := &certs[i]
+		certificateExpiryTimestamp.WithLabelValues(mdb.Namespace, mdb.Name, string(cert.Type), cert.Source, cert.Subject).Set(float64(cert.NotAfter.Unix()))
+
+		var crossing time.Time
+		cert.WarningThresholdDays, crossing = certificateWarningThreshold(cert.NotAfter.Time, thresholds, now)
+		if !crossing.IsZero() && (nextCrossing.IsZero() || crossing.Before(nextCrossing)) {
+			nextCrossing = crossing
+		}
+		if cert.WarningThresholdDays == nil {
+			continue
+		}
+
+		eventReason, message := certificateExpiringReason, fmt.Sprintf("%s certificate %s of %s expires at %s", cert.Type, cert.Subject, cert.Source, cert.NotAfter.UTC().Format(time.RFC3339))
+		if *cert.WarningThresholdDays == 0 {
+			eventReason, message = certificateExpiredReason, fmt.Sprintf("%s certificate %s of %s expired at %s", cert.Type, cert.Subject, cert.Source, cert.NotAfter.UTC().Format(time.RFC3339))
+		}
+		if reason != certificateExpiredReason {
+			reason = eventReason
+		}
+		expiring = append(expiring, message)
+
+		if previous := previousThresholds[certificateStatusKey(*cert)]; previous == nil || *previous != *cert.WarningThresholdDays {
+			r.log.Warn(message)
+			r.recorder.Event(mdb, corev1.EventTypeWarning, eventReason, message)
+		}
+	}
+
+	mdb.Status.Certificates = certs
+	if len(certs) == 0 {
+		meta.RemoveStatusCondition(&mdb.Status.Conditions, mdbv1.ConditionCertificatesExpiring)
+	} else {
+		condition := metav1.Condition{
+			Type:               mdbv1.ConditionCertificatesExpiring,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            fmt.Sprintf("No certificate expires within %d days", thresholds[0]),
+			ObservedGeneration: mdb.Generation,
+		}
+		if len(expiring) > 0 {
+			condition.Status = metav1.ConditionTrue
+			condition.Message = strings.Join(expiring, "; ")
+		}
+		meta.SetStatusCondition(&mdb.Status.Conditions, condition)
+	}
+
+	if nextCrossing.IsZero() {
+		return 0, nil
+	}
+	return int(nextCrossing.Sub(now).Seconds()) + 1, nil
+}
+
+// readCertificates returns the certificates the deployment is configured with: the CA and server certificates, the
+// certificates the agent and the members present and the certificate of the Prometheus endpoint. The certificates which can't be
+// parsed are skipped.
+func (r ReplicaSetReconciler) readCertificates(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb mdbv1.MongoDBCommunity) ([]mdbv1.CertificateStatus, error) {
+	var certs []mdbv1.CertificateStatus
+	add := func(certificateType mdbv1.CertificateType, source, pem string, all bool) {
+		parsed, err := certificates.ParseCertificates(pem)
+		if err != nil {
+			r.log.Debugf("Could not parse the %s certificate of %s, its expiry is not monitored: %s", certificateType, source, err)
+			return
+		}
+		if !all {
+			// the certificate is followed by its intermediate certificates, which are monitored through the CA.
+			parsed = parsed[:1]
+		}
+		for _, cert := range parsed {
+			certs = append(certs, certificateStatus(certificateType, source, cert))
+		}
+	}
+	addKeyPair := func(certificateType mdbv1.CertificateType, secretName types.NamespacedName) error {
+		certKey, err := getPemOrConcatenatedCrtAndKey(ctx, secrets, secretName)
+		if err != nil {
+			return err
+		}
+		add(certificateType, secretName.Name, certKey, false)
+		return nil
+	}
+
+	if mdb.IsTLSConfiguredThisReconciliation() {
+		caCert, err := getCaCrt(ctx, r.client, secrets, mdb)
+		if err != nil {
+			return nil, err
+		}
+		var caSource string
+		if mdb.HasTLSCaCertificateSecret() {
+			caSource = mdb.TLSCaCertificateSecretNamespacedName().Name
+		} else {
+			caSource = mdb.TLSConfigMapNamespacedName().Name
+		}
+		add(mdbv1.CertificateTypeCA, caSource, caCert, true)
+
+		for _, secretName := range tlsServerSecretNamespacedNames(mdb) {
+			if err := addKeyPair(mdbv1.CertificateTypeServer, secretName); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if mdb.Spec.IsAgentCertificateRequired() {
+		if err := addKeyPair(mdbv1.CertificateTypeAgent, mdb.AgentCertificateSecretNamespacedName()); err != nil {
+			return nil, err
+		}
+	}
+	if mdb.IsClusterCertificateRequired() {
+		if err := addKeyPair(mdbv1.CertificateTypeCluster, mdb.ClusterCertificateSecretNamespacedName()); err != nil {
+			return nil, err
+		}
+	}
+	if mdb.Spec.Prometheus != nil && mdb.Spec.Prometheus.TLSSecretRef.Name != "" {
+		if err := addKeyPair(mdbv1.CertificateTypePrometheus, mdb.PrometheusTLSSecretNamespacedName()); err != nil {
+			return nil, err
+		}
+	}
+	return certs, nil
+}
+
+func certificateStatus(certificateType mdbv1.CertificateType, source string, cert *x509.Certificate) mdbv1.CertificateStatus {
+	return mdbv1.CertificateStatus{
+		Type:     certificateType,
+		Source:   source,
+		Subject:  cert.Subject.String(),
+		NotAfter: metav1.NewTime(cert.NotAfter),
+	}
+}
+
+func certificateStatusKey(cert mdbv1.CertificateStatus) string {
+	return fmt.Sprintf("%s/%s/%s", cert.Type, cert.Source, cert.Subject)
+}
+
+// certificateWarningThreshold returns the smallest of the thresholds, sorted from the largest to the smallest, which
+// a certificate expiring at notAfter expires within, or 0 if it has expired. It also returns the time at which the
+// certificate crosses its next threshold, or the zero time if it has expired.
+func certificateWarningThreshold(notAfter time.Time, thresholds []int, now time.Time) (*int, time.Time) {
+	if !now.Before(notAfter) {
+		return ptr.To(0), time.Time{}
+	}
+
+	var threshold *int
+	for _, days := range thresholds {
+		crossing := notAfter.Add(-time.Duration(days) * 24 * time.Hour)
+		if now.Before(crossing) {
+			return threshold, crossing
+		}
+		threshold = ptr.To(days)
+	}
+	return threshold, notAfter
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/certificates"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/configmap"
+)
+
+func newCertificateExpiryReplicaSet(ctx context.Context, t *testing.T, caValidity, serverValidity time.Duration, dnsNames ...string) (mdbv1.MongoDBCommunity, client.Client) {
+	mdb := newTestReplicaSetWithTLSCaCertificateReferences(&corev1.LocalObjectReference{Name: "caConfigMap"}, nil)
+	mgr := client.NewManager(ctx, &mdb)
+
+	ca, err := certificates.NewCA("CN=my-rs-ca,O=MongoDB", caValidity)
+	require.NoError(t, err)
+	server, err := certificates.Issue(ca, certificates.Request{
+		Subject:     "CN=my-rs,O=MongoDB",
+		DNSNames:    dnsNames,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		Validity:    serverValidity,
+	})
+	require.NoError(t, err)
+
+	caConfigMap := configmap.Builder().SetName("caConfigMap").SetNamespace(mdb.Namespace).SetDataField(tlsCACertName, ca.Certificate).Build()
+	require.NoError(t, mgr.Client.Create(ctx, &caConfigMap))
+	require.NoError(t, createTLSSecret(ctx, mgr.Client, mdb, server.Certificate, server.Key, ""))
+	return mdb, mgr.Client
+}
+
+func TestCertificateExpiry(t *testing.T) {
+	ctx := context.Background()
+	mdb, c := newCertificateExpiryReplicaSet(ctx, t, 20*24*time.Hour, 5*24*time.Hour,
+		"*.my-rs-svc.my-ns.svc.cluster.local")
+	r := NewReconciler(client.NewManagerWithClient(c), "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	recorder := record.NewFakeRecorder(10)
+	r.recorder = recorder
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+	assert.Greater(t, res.RequeueAfter, time.Duration(0), "the reconciliation is scheduled when the CA expires within 7 days")
+
+	require.NoError(t, c.Get(ctx, mdb.NamespacedName(), &mdb))
+
+	t.Run("The expiry of the certificates is in the status", func(t *testing.T) {
+		require.Len(t, mdb.Status.Certificates, 2)
+		assert.Equal(t, mdbv1.CertificateTypeCA, mdb.Status.Certificates[0].Type)
+		assert.Equal(t, "caConfigMap", mdb.Status.Certificates[0].Source)
+		assert.Equal(t, "CN=my-rs-ca,O=MongoDB", mdb.Status.Certificates[0].Subject)
+		assert.Equal(t, ptr.To(30), mdb.Status.Certificates[0].WarningThresholdDays)
+		assert.Equal(t, mdbv1.CertificateTypeServer, mdb.Status.Certificates[1].Type)
+		assert.Equal(t, "certificateKeySecret", mdb.Status.Certificates[1].Source)
+		assert.Equal(t, ptr.To(7), mdb.Status.Certificates[1].WarningThresholdDays)
+
+		condition := meta.FindStatusCondition(mdb.Status.Conditions, mdbv1.ConditionCertificatesExpiring)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, certificateExpiringReason, condition.Reason)
+		assert.Contains(t, condition.Message, "Server certificate CN=my-rs,O=MongoDB of certificateKeySecret expires at")
+	})
+
+	t.Run("The expiry of the certificates is exposed as a metric", func(t *testing.T) {
+		notAfter := mdb.Status.Certificates[1].NotAfter.Unix()
+		assert.Equal(t, float64(notAfter), testutil.ToFloat64(certificateExpiryTimestamp.WithLabelValues("my-ns", "my-rs", "Server", "certificateKeySecret", "CN=my-rs,O=MongoDB")))
+	})
+
+	t.Run("A warning event is emitted once per threshold", func(t *testing.T) {
+		assert.Len(t, recorder.Events, 2)
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+		assert.Len(t, recorder.Events, 2)
+	})
+}
+
+func TestCertificateExpiry_ServerCertificateMustCoverTheMembers(t *testing.T) {
+	ctx := context.Background()
+	mdb, c := newCertificateExpiryReplicaSet(ctx, t, 365*24*time.Hour, 365*24*time.Hour,
+		"my-rs-0.my-rs-svc.my-ns.svc.cluster.local", "my-rs-1.my-rs-svc.my-ns.svc.cluster.local")
+	r := NewReconciler(client.NewManagerWithClient(c), "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Get(ctx, mdb.NamespacedName(), &mdb))
+	assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+	assert.Contains(t, mdb.Status.Message, "the server certificate of my-rs-2 is not valid for my-rs-2.my-rs-svc.my-ns.svc.cluster.local")
+
+	_, err = c.GetSecret(ctx, mdb.TLSOperatorSecretNamespacedName())
+	assert.Error(t, err, "the certificate is not rolled out")
+}
+
+func TestCertificateWarningThreshold(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	tests := []struct {
+		name             string
+		notAfter         time.Time
+		expectedDays     *int
+		expectedCrossing time.Time
+	}{
+		{name: "Far from its expiry", notAfter: now.Add(100 * day), expectedCrossing: now.Add(70 * day)},
+		{name: "Within the first threshold", notAfter: now.Add(10 * day), expectedDays: ptr.To(30), expectedCrossing: now.Add(3 * day)},
+		{name: "Within the last threshold", notAfter: now.Add(2 * day), expectedDays: ptr.To(7), expectedCrossing: now.Add(2 * day)},
+		{name: "Expired", notAfter: now.Add(-day), expectedDays: ptr.To(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, crossing := certificateWarningThreshold(tt.notAfter, []int{30, 7}, now)
+			assert.Equal(t, tt.expectedDays, days)
+			assert.Equal(t, tt.expectedCrossing, crossing)
+		})
+	}
+}
@@ -11,11 +11,7 @@ import (
 
 // cleanupPemSecret cleans up the old pem secret generated for the agent certificate.
 func (r *ReplicaSetReconciler) cleanupPemSecret(ctx context.Context, currentMDBSpec mdbv1.MongoDBCommunitySpec, lastAppliedMDBSpec mdbv1.MongoDBCommunitySpec, namespace string) {
-	if currentMDBSpec.GetAgentAuthMode() == lastAppliedMDBSpec.GetAgentAuthMode() {
-		return
-	}
-
-	if !currentMDBSpec.IsAgentX509() && lastAppliedMDBSpec.IsAgentX509() {
+	if !currentMDBSpec.IsAgentCertificateRequired() && lastAppliedMDBSpec.IsAgentCertificateRequired() {
 		agentCertSecret := lastAppliedMDBSpec.GetAgentCertificateRef()
 		if err := r.client.DeleteSecret(ctx, types.NamespacedName{
 			Namespace: namespace,
@@ -30,6 +26,22 @@ func (r *ReplicaSetReconciler) cleanupPemSecret(ctx context.Context, currentMDBS
 	}
 }
 
+// cleanupOidcConnectionStringSecret cleans up the OIDC connection string secret once no identity provider
+// authenticates workloads anymore.
+func (r *ReplicaSetReconciler) cleanupOidcConnectionStringSecret(ctx context.Context, mdb mdbv1.MongoDBCommunity, lastAppliedMDBSpec mdbv1.MongoDBCommunitySpec) {
+	lastApplied := mdbv1.MongoDBCommunity{Spec: lastAppliedMDBSpec}
+	if hasOidcWorkloadIdentityProvider(mdb) || !hasOidcWorkloadIdentityProvider(lastApplied) {
+		return
+	}
+
+	nsName := mdb.OidcConnectionStringSecretNamespacedName()
+	if err := r.client.DeleteSecret(ctx, nsName); err != nil && !apiErrors.IsNotFound(err) {
+		r.log.Warnf("Could not cleanup old secret %s: %s", nsName.Name, err)
+	} else {
+		r.log.Debugf("Sucessfully cleaned up secret: %s", nsName.Name)
+	}
+}
+
 // cleanupScramSecrets cleans up old scram secrets based on the last successful applied mongodb spec.
 func (r *ReplicaSetReconciler) cleanupScramSecrets(ctx context.Context, currentMDBSpec mdbv1.MongoDBCommunitySpec, lastAppliedMDBSpec mdbv1.MongoDBCommunitySpec, namespace string) {
 	secretsToDelete := getScramSecretsToDelete(currentMDBSpec, lastAppliedMDBSpec)
@@ -46,6 +58,30 @@ func (r *ReplicaSetReconciler) cleanupScramSecrets(ctx context.Context, currentM
 	}
 }
 
+// cleanupUserResourceScramSecrets cleans up the scram secrets of the users of the MongoDBCommunityUser resources
+// which no longer reference the resource. The connection string secrets are owned by the MongoDBCommunityUser resources.
+func (r *ReplicaSetReconciler) cleanupUserResourceScramSecrets(ctx context.Context, currentUserResources []mdbv1.UserResourceReference, previousUserResources []mdbv1.UserResourceReference, namespace string) {
+	current := map[mdbv1.UserResourceReference]bool{}
+	for _, userResource := range currentUserResources {
+		current[userResource] = true
+	}
+
+	for _, userResource := range previousUserResources {
+		if current[userResource] || userResource.DB == constants.ExternalDB {
+			continue
+		}
+		s := userResource.GetScramCredentialsSecretName()
+		if err := r.client.DeleteSecret(ctx, types.NamespacedName{
+			Name:      s,
+			Namespace: namespace,
+		}); err != nil && !apiErrors.IsNotFound(err) {
+			r.log.Warnf("Could not cleanup old secret %s: %s", s, err)
+		} else {
+			r.log.Debugf("Sucessfully cleaned up secret: %s", s)
+		}
+	}
+}
+
 // cleanupConnectionStringSecrets cleans up old scram secrets based on the last successful applied mongodb spec.
 func (r *ReplicaSetReconciler) cleanupConnectionStringSecrets(ctx context.Context, currentMDBSpec mdbv1.MongoDBCommunitySpec, lastAppliedMDBSpec mdbv1.MongoDBCommunitySpec, namespace string, resourceName string) {
 	secretsToDelete := getConnectionStringSecretsToDelete(currentMDBSpec, lastAppliedMDBSpec, resourceName)
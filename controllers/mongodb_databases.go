@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/provisioning"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+// databaseServerSelectionTimeout is how long the operator waits for a member of the deployment to accept
+// the provisioning commands.
+const databaseServerSelectionTimeout = 10 * time.Second
+
+// ensureDatabases provisions the databases, collections and indexes of spec.databases, connecting as the agent.
+// It returns the differences which were not applied because they require destructive changes.
+func (r ReplicaSetReconciler) ensureDatabases(ctx context.Context, mdb mdbv1.MongoDBCommunity) ([]mdbv1.DatabaseDrift, error) {
+	if len(mdb.Spec.Databases) == 0 {
+		return nil, nil
+	}
+
+	clientOptions, err := r.databaseClientOptions(ctx, mdb)
+	if err != nil {
+		return nil, err
+	}
+	client, err := r.newDatabaseClient(ctx, clientOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := client.Disconnect(ctx); err != nil {
+			r.log.Warnf("Could not disconnect from the deployment: %s", err)
+		}
+	}()
+
+	r.log.Debug("Provisioning databases")
+	return provisioning.Converge(ctx, client, mdb.Spec.Databases)
+}
+
+// databaseClientOptions returns the options to connect to the deployment with the credentials of the agent.
+func (r ReplicaSetReconciler) databaseClientOptions(ctx context.Context, mdb mdbv1.MongoDBCommunity) (*options.ClientOptions, error) {
+	clientOptions := options.Client().
+		SetHosts(mdb.Hosts(os.Getenv(clusterDomain))). // nolint:forbidigo
+		SetReplicaSet(mdb.Name).
+		SetServerSelectionTimeout(databaseServerSelectionTimeout)
+
+	var tlsConfig *tls.Config
+	if mdb.IsTLSEnabledForClients() {
+		caCert, err := getCaCrt(ctx, r.client, r.client, mdb)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the CA certificate: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, fmt.Errorf("the CA certificate of %s is invalid", mdb.NamespacedName())
+		}
+		tlsConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
+
+	if mdb.Spec.IsAgentCertificateRequired() {
+		pem, err := getPemOrConcatenatedCrtAndKey(ctx, r.client, mdb.AgentCertificateSecretNamespacedName())
+		if err != nil {
+			return nil, fmt.Errorf("could not read the agent certificate: %s", err)
+		}
+		certificate, err := tls.X509KeyPair([]byte(pem), []byte(pem))
+		if err != nil {
+			return nil, fmt.Errorf("the agent certificate is invalid: %s", err)
+		}
+		if tlsConfig == nil {
+			return nil, fmt.Errorf("X509 agent authentication requires TLS")
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if mdb.Spec.IsAgentX509() {
+		return clientOptions.SetAuth(options.Credential{AuthMechanism: constants.X509}), nil
+	}
+
+	password, err := secret.ReadKey(ctx, r.client, constants.AgentPasswordKey, mdb.GetAgentPasswordSecretNamespacedName())
+	if err != nil {
+		return nil, fmt.Errorf("could not read the agent password: %s", err)
+	}
+	authMechanism := "SCRAM-SHA-256"
+	if mdb.Spec.GetAgentAuthMode() == "SCRAM-SHA-1" {
+		authMechanism = "SCRAM-SHA-1"
+	}
+	return clientOptions.SetAuth(options.Credential{
+		AuthMechanism: authMechanism,
+		AuthSource:    "admin",
+		Username:      constants.AgentName,
+		Password:      password,
+	}), nil
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/provisioning"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+func TestDatabases_AreProvisionedWithTheAgentCredentials(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mdb.Spec.Databases = []mdbv1.Database{{
+		Name: "app",
+		Collections: []mdbv1.Collection{{
+			Name:    "orders",
+			Indexes: []mdbv1.Index{{Name: "customer", Keys: []mdbv1.IndexKey{{Field: "customer", Type: intstr.FromInt32(1)}}}},
+		}},
+	}}
+	mgr := client.NewManager(ctx, &mdb)
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	databaseClient := provisioning.NewMockedClient()
+	var credential *options.Credential
+	r.newDatabaseClient = func(_ context.Context, clientOptions *options.ClientOptions) (provisioning.Client, error) {
+		credential = clientOptions.Auth
+		return databaseClient, nil
+	}
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	assert.Equal(t, []string{"create collection app.orders", "create index app.orders.customer"}, databaseClient.Operations)
+
+	agentPassword, err := secret.ReadKey(ctx, mgr.Client, constants.AgentPasswordKey, mdb.GetAgentPasswordSecretNamespacedName())
+	require.NoError(t, err)
+	require.NotNil(t, credential)
+	assert.Equal(t, constants.AgentName, credential.Username)
+	assert.Equal(t, agentPassword, credential.Password)
+	assert.Equal(t, "SCRAM-SHA-256", credential.AuthMechanism)
+}
+
+func TestDatabases_DriftIsReportedInStatus(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mdb.Spec.Databases = []mdbv1.Database{{
+		Name:        "app",
+		Collections: []mdbv1.Collection{{Name: "events", Capped: &mdbv1.CappedCollectionOptions{Size: 4096}}},
+	}}
+	mgr := client.NewManager(ctx, &mdb)
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	databaseClient := provisioning.NewMockedClient()
+	databaseClient.Databases["app"] = map[string]provisioning.Collection{"events": {Collection: mdbv1.Collection{Name: "events"}}}
+	r.newDatabaseClient = func(context.Context, *options.ClientOptions) (provisioning.Client, error) {
+		return databaseClient, nil
+	}
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	assert.Empty(t, databaseClient.Operations)
+
+	err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	assert.Equal(t, []mdbv1.DatabaseDrift{{
+		Database:   "app",
+		Collection: "events",
+		Message:    "the collection is not capped, the collection must be dropped and created again",
+	}}, mdb.Status.DatabaseDrift)
+}
@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/controllers/construct"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/configmap"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/container"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/podtemplatespec"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/resourcerequirements"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/statefulset"
+)
+
+const (
+	encryptionAtRestVolumeName    = "encryption-at-rest"
+	encryptionAtRestMountPath     = "/var/lib/encryption-at-rest"
+	encryptionKeyVolumeName       = "encryption-key"
+	encryptionKeyMountPath        = "/var/lib/encryption-key"
+	encryptionKeyFileName         = "encryption.key"
+	kmipClientCertificateFileName = "kmip-client.pem"
+	kmipCAFileName                = "kmip-ca.crt"
+
+	encryptionAtRestInitContainerName = "mongodb-encryption-at-rest"
+	// kmipKeyIdentifierEnv is the identifier of the master key the init container encrypts the data files with. It
+	// is part of the pod template, so that changing it restarts the members one at a time.
+	kmipKeyIdentifierEnv = "KMIP_KEY_IDENTIFIER"
+	// kmipKeyIdentifierFileName is the file of the data directory storing the identifier of the master key the data
+	// files are encrypted with.
+	kmipKeyIdentifierFileName = ".kmip-key-identifier"
+	// kmipKeyStoreDirectoryName is the directory of the data directory storing the keys of the databases, which
+	// exists once the data files are encrypted.
+	kmipKeyStoreDirectoryName = "key.db"
+)
+
+// ensureEncryptionAtRestSecret copies the key material of the encryption at rest into the Secret mounted in the pods:
+// the local master key, or the client certificate and CA certificate of the KMIP server. The local master key can't
+// be changed, as the data files encrypted with it couldn't be read anymore.
+func (r ReplicaSetReconciler) ensureEncryptionAtRestSecret(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb mdbv1.MongoDBCommunity) error {
+	if !mdb.Spec.IsEncryptionAtRestEnabled() {
+		return nil
+	}
+
+	encryptionAtRest := mdb.Spec.Security.EncryptionAtRest
+	operatorSecretBuilder := secret.Builder().
+		SetName(mdb.EncryptionAtRestSecretNamespacedName().Name).
+		SetNamespace(mdb.EncryptionAtRestSecretNamespacedName().Namespace).
+		SetOwnerReferences(mdb.GetOwnerReferences())
+
+	if ref := encryptionAtRest.KeyFileSecretRef; ref != nil {
+		keyFileSecretNamespacedName := types.NamespacedName{Name: ref.Name, Namespace: mdb.Namespace}
+		r.secretWatcher.Watch(ctx, keyFileSecretNamespacedName, mdb.NamespacedName())
+		key, err := secret.ReadKey(ctx, secrets, encryptionAtRest.GetKeyFileKey(), keyFileSecretNamespacedName)
+		if err != nil {
+			return fmt.Errorf("could not read the local master key: %s", err)
+		}
+
+		existing, err := r.client.GetSecret(ctx, mdb.EncryptionAtRestSecretNamespacedName())
+		if err != nil && !apiErrors.IsNotFound(err) {
+			return err
+		}
+		if existingKey, ok := existing.Data[encryptionKeyFileName]; ok && string(existingKey) != key {
+			return errors.New("the local master key can't be changed once the data files are encrypted with it")
+		}
+		operatorSecretBuilder.SetField(encryptionKeyFileName, key)
+	}
+
+	if kmip := encryptionAtRest.Kmip; kmip != nil {
+		clientCertificateSecretNamespacedName := types.NamespacedName{Name: kmip.ClientCertificateSecretRef.Name, Namespace: mdb.Namespace}
+		r.secretWatcher.Watch(ctx, clientCertificateSecretNamespacedName, mdb.NamespacedName())
+		certKey, err := getPemOrConcatenatedCrtAndKey(ctx, secrets, clientCertificateSecretNamespacedName)
+		if err != nil {
+			return fmt.Errorf("could not read the KMIP client certificate: %s", err)
+		}
+		operatorSecretBuilder.SetField(kmipClientCertificateFileName, certKey)
+
+		if kmip.CaConfigMapRef != nil {
+			caConfigMapNamespacedName := types.NamespacedName{Name: kmip.CaConfigMapRef.Name, Namespace: mdb.Namespace}
+			r.configMapWatcher.Watch(ctx, caConfigMapNamespacedName, mdb.NamespacedName())
+			ca, err := configmap.ReadKey(ctx, r.client, tlsCACertName, caConfigMapNamespacedName)
+			if err != nil {
+				return fmt.Errorf("could not read the KMIP CA certificate: %s", err)
+			}
+			operatorSecretBuilder.SetField(kmipCAFileName, ca)
+		}
+	}
+
+	return secret.CreateOrUpdate(ctx, r.client, operatorSecretBuilder.Build())
+}
+
+// getEncryptionAtRestModification renders the encryption at rest of the resource into the security options of the
+// processes.
+func getEncryptionAtRestModification(mdb mdbv1.MongoDBCommunity) automationconfig.Modification {
+	if !mdb.Spec.IsEncryptionAtRestEnabled() {
+		return automationconfig.NOOP()
+	}
+
+	encryptionAtRest := mdb.Spec.Security.EncryptionAtRest
+	return func(config *automationconfig.AutomationConfig) {
+		for i := range config.Processes {
+			args := config.Processes[i].Args26
+			args.Set("security.enableEncryption", true)
+			args.Set("security.encryptionCipherMode", string(encryptionAtRest.GetCipherMode()))
+			if encryptionAtRest.KeyFileSecretRef != nil {
+				args.Set("security.encryptionKeyFile", encryptionKeyMountPath+"/"+encryptionKeyFileName)
+			}
+			if kmip := encryptionAtRest.Kmip; kmip != nil {
+				args.Set("security.kmip.serverName", strings.Join(kmip.ServerNames, ","))
+				args.Set("security.kmip.port", kmip.GetPort())
+				args.Set("security.kmip.clientCertificateFile", encryptionAtRestMountPath+"/"+kmipClientCertificateFileName)
+				if kmip.CaConfigMapRef != nil {
+					args.Set("security.kmip.serverCAFile", encryptionAtRestMountPath+"/"+kmipCAFileName)
+				}
+			}
+		}
+	}
+}
+
+// buildEncryptionAtRestPodSpecModification mounts the key material of the encryption at rest into the agent and
+// mongod containers, and adds the init container preparing it before mongod starts.
+func buildEncryptionAtRestPodSpecModification(mdb mdbv1.MongoDBCommunity, mongodbImage string) podtemplatespec.Modification {
+	if !mdb.Spec.IsEncryptionAtRestEnabled() {
+		return podtemplatespec.NOOP()
+	}
+
+	encryptionAtRestVolume := statefulset.CreateVolumeFromSecret(encryptionAtRestVolumeName, mdb.EncryptionAtRestSecretNamespacedName().Name)
+	encryptionAtRestVolumeMount := statefulset.CreateVolumeMount(encryptionAtRestVolume.Name, encryptionAtRestMountPath, statefulset.WithReadOnly(true))
+
+	// mongod refuses a key file which can be read by other users than its owner, which the files of a secret volume
+	// can be once the pod has a fsGroup: the init container copies the local master key into a volume of its own.
+	encryptionKeyVolume := statefulset.CreateVolumeFromEmptyDir(encryptionKeyVolumeName)
+	encryptionKeyVolumeMount := statefulset.CreateVolumeMount(encryptionKeyVolume.Name, encryptionKeyMountPath, statefulset.WithReadOnly(true))
+
+	return podtemplatespec.Apply(
+		podtemplatespec.WithVolume(encryptionAtRestVolume),
+		podtemplatespec.WithVolume(encryptionKeyVolume),
+		podtemplatespec.WithVolumeMounts(construct.AgentName, encryptionAtRestVolumeMount, encryptionKeyVolumeMount),
+		podtemplatespec.WithVolumeMounts(construct.MongodbName, encryptionAtRestVolumeMount, encryptionKeyVolumeMount),
+		podtemplatespec.WithInitContainer(encryptionAtRestInitContainerName, encryptionAtRestInit(mdb, mongodbImage)),
+	)
+}
+
+// encryptionAtRestInit returns the init container preparing the encryption at rest before mongod starts. It copies
+// the local master key with the permissions mongod requires, or encrypts the data files with the KMIP master key of
+// spec.security.encryptionAtRest.kmip.keyIdentifier: the data files of a new member are created with it, and the
+// master key of existing data files is rotated to it. As the key identifier is part of the pod template, changing it
+// rotates the master key of the members one at a time.
+func encryptionAtRestInit(mdb mdbv1.MongoDBCommunity, mongodbImage string) container.Modification {
+	encryptionAtRest := mdb.Spec.Security.EncryptionAtRest
+	dbPath := mdb.GetMongodConfiguration().GetDBDataDir()
+
+	dataVolumeMount := statefulset.CreateVolumeMount(mdb.DataVolumeName(), dbPath, statefulset.WithSubPath("data"))
+	if mdb.HasSeparateDataAndLogsVolumes() {
+		dataVolumeMount = statefulset.CreateVolumeMount(mdb.DataVolumeName(), dbPath)
+	}
+	volumeMounts := []corev1.VolumeMount{
+		dataVolumeMount,
+		statefulset.CreateVolumeMount("tmp", "/tmp", statefulset.WithReadOnly(false)),
+		statefulset.CreateVolumeMount(encryptionAtRestVolumeName, encryptionAtRestMountPath, statefulset.WithReadOnly(true)),
+		statefulset.CreateVolumeMount(encryptionKeyVolumeName, encryptionKeyMountPath, statefulset.WithReadOnly(false)),
+	}
+
+	script := "set -e\n"
+	keyIdentifier := ""
+	if encryptionAtRest.KeyFileSecretRef != nil {
+		keyFile := encryptionKeyMountPath + "/" + encryptionKeyFileName
+		script += fmt.Sprintf(`
+# mongod requires the local master key to only be readable by its owner
+rm -f %[2]s
+cp %[1]s %[2]s
+chmod 400 %[2]s
+`, encryptionAtRestMountPath+"/"+encryptionKeyFileName, keyFile)
+	}
+	if kmip := encryptionAtRest.Kmip; kmip != nil {
+		keyIdentifier = kmip.KeyIdentifier
+		options := []string{
+			"--dbpath", dbPath,
+			"--enableEncryption",
+			"--encryptionCipherMode", string(encryptionAtRest.GetCipherMode()),
+			"--kmipServerName", strings.Join(kmip.ServerNames, ","),
+			"--kmipPort", fmt.Sprint(kmip.GetPort()),
+			"--kmipClientCertificateFile", encryptionAtRestMountPath + "/" + kmipClientCertificateFileName,
+		}
+		if kmip.CaConfigMapRef != nil {
+			options = append(options, "--kmipServerCAFile", encryptionAtRestMountPath+"/"+kmipCAFileName)
+		}
+		script += fmt.Sprintf(`
+if [ -n "${%[1]s}" ] && [ "$(cat %[2]s 2>/dev/null)" != "${%[1]s}" ]; then
+	if [ -d %[3]s ]; then
+		# rotate the master key of the existing data files, mongod exits once it is rotated
+		mongod %[4]s --kmipRotateMasterKey --kmipKeyIdentifier "${%[1]s}"
+	else
+		# create the data files encrypted with the master key
+		mongod %[4]s --kmipKeyIdentifier "${%[1]s}" --bind_ip localhost --port 27099 --fork --logpath /tmp/mongod-encryption-at-rest.log
+		mongod --dbpath %[5]s --shutdown
+	fi
+	echo -n "${%[1]s}" > %[2]s
+fi
+`, kmipKeyIdentifierEnv, dbPath+"/"+kmipKeyIdentifierFileName, dbPath+"/"+kmipKeyStoreDirectoryName, strings.Join(options, " "), dbPath)
+	}
+
+	_, containerSecurityContext := podtemplatespec.WithDefaultSecurityContextsModifications()
+	return container.Apply(
+		container.WithName(encryptionAtRestInitContainerName),
+		container.WithImage(mongodbImage),
+		container.WithResourceRequirements(resourcerequirements.Defaults()),
+		container.WithCommand([]string{"/bin/sh", "-c", script}),
+		// The official image provides both CMD and ENTRYPOINT. We're reusing the former and need to replace
+		// the latter with an empty string.
+		container.WithArgs([]string{""}),
+		container.WithEnvs(corev1.EnvVar{Name: kmipKeyIdentifierEnv, Value: keyIdentifier}),
+		container.WithVolumeMounts(volumeMounts),
+		containerSecurityContext,
+	)
+}
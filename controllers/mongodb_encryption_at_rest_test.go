@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/controllers/construct"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/configmap"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+)
+
+func newEncryptionAtRestReplicaSet(encryptionAtRest mdbv1.EncryptionAtRest) mdbv1.MongoDBCommunity {
+	mdb := newScramReplicaSet()
+	encryptionAtRest.Enabled = true
+	mdb.Spec.Security.EncryptionAtRest = &encryptionAtRest
+	return mdb
+}
+
+func getEncryptionAtRestInitContainer(ctx context.Context, t *testing.T, c client.Client, mdb mdbv1.MongoDBCommunity) corev1.Container {
+	sts := appsv1.StatefulSet{}
+	require.NoError(t, c.Get(ctx, mdb.NamespacedName(), &sts))
+	for _, initContainer := range sts.Spec.Template.Spec.InitContainers {
+		if initContainer.Name == encryptionAtRestInitContainerName {
+			return initContainer
+		}
+	}
+	require.Fail(t, "the encryption at rest init container is missing")
+	return corev1.Container{}
+}
+
+func TestEncryptionAtRest_LocalKeyFile(t *testing.T) {
+	t.Setenv(construct.MongoDBAssumeEnterpriseEnv, "true")
+	ctx := context.Background()
+	mdb := newEncryptionAtRestReplicaSet(mdbv1.EncryptionAtRest{
+		CipherMode:       mdbv1.EncryptionCipherModeAES256GCM,
+		KeyFileSecretRef: &mdbv1.SecretKeyReference{Name: "encryption-key"},
+	})
+	mgr := client.NewManager(ctx, &mdb)
+	keySecret := secret.Builder().SetName("encryption-key").SetNamespace(mdb.Namespace).SetField("encryption-key", "MASTER-KEY").Build()
+	require.NoError(t, mgr.Client.Create(ctx, &keySecret))
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	t.Run("The processes encrypt their data files with the local master key", func(t *testing.T) {
+		ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+		require.NoError(t, err)
+		for _, p := range ac.Processes {
+			assert.True(t, p.Args26.Get("security.enableEncryption").Bool())
+			assert.Equal(t, "AES256-GCM", p.Args26.Get("security.encryptionCipherMode").Str())
+			assert.Equal(t, "/var/lib/encryption-key/encryption.key", p.Args26.Get("security.encryptionKeyFile").Str())
+			assert.Nil(t, p.Args26.Get("security.kmip").Data())
+		}
+	})
+
+	t.Run("The local master key is copied by the init container", func(t *testing.T) {
+		key, err := secret.ReadKey(ctx, mgr.Client, encryptionKeyFileName, mdb.EncryptionAtRestSecretNamespacedName())
+		require.NoError(t, err)
+		assert.Equal(t, "MASTER-KEY", key)
+
+		initContainer := getEncryptionAtRestInitContainer(ctx, t, mgr.Client, mdb)
+		assert.Equal(t, "fake-mongodbRepoUrl/fake-mongodbImage:4.2.2", initContainer.Image)
+		assert.Contains(t, initContainer.Command[2], "cp /var/lib/encryption-at-rest/encryption.key /var/lib/encryption-key/encryption.key")
+		assert.Contains(t, initContainer.Command[2], "chmod 400 /var/lib/encryption-key/encryption.key")
+		assert.Contains(t, initContainer.VolumeMounts, corev1.VolumeMount{Name: encryptionKeyVolumeName, MountPath: encryptionKeyMountPath})
+	})
+
+	t.Run("The local master key can't be changed", func(t *testing.T) {
+		require.NoError(t, secret.UpdateField(ctx, mgr.Client, types.NamespacedName{Name: "encryption-key", Namespace: mdb.Namespace}, "encryption-key", "OTHER-KEY"))
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+		assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+		assert.Contains(t, mdb.Status.Message, "the local master key can't be changed once the data files are encrypted with it")
+	})
+}
+
+func TestEncryptionAtRest_Kmip(t *testing.T) {
+	t.Setenv(construct.MongoDBAssumeEnterpriseEnv, "true")
+	ctx := context.Background()
+	mdb := newEncryptionAtRestReplicaSet(mdbv1.EncryptionAtRest{
+		Kmip: &mdbv1.Kmip{
+			ServerNames:                []string{"pykmip-0.pykmip", "pykmip-1.pykmip"},
+			ClientCertificateSecretRef: corev1.LocalObjectReference{Name: "kmip-client"},
+			CaConfigMapRef:             &corev1.LocalObjectReference{Name: "kmip-ca"},
+			KeyIdentifier:              "1",
+		},
+	})
+	mgr := client.NewManager(ctx, &mdb)
+	clientSecret := secret.Builder().SetName("kmip-client").SetNamespace(mdb.Namespace).SetField(tlsSecretCertName, "CERT").SetField(tlsSecretKeyName, "KEY").Build()
+	require.NoError(t, mgr.Client.Create(ctx, &clientSecret))
+	caConfigMap := configmap.Builder().SetName("kmip-ca").SetNamespace(mdb.Namespace).SetDataField(tlsCACertName, "CA").Build()
+	require.NoError(t, mgr.Client.Create(ctx, &caConfigMap))
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	t.Run("The processes encrypt their data files with the KMIP master key", func(t *testing.T) {
+		ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+		require.NoError(t, err)
+		for _, p := range ac.Processes {
+			assert.True(t, p.Args26.Get("security.enableEncryption").Bool())
+			assert.Equal(t, "AES256-CBC", p.Args26.Get("security.encryptionCipherMode").Str())
+			assert.Equal(t, "pykmip-0.pykmip,pykmip-1.pykmip", p.Args26.Get("security.kmip.serverName").Str())
+			assert.Equal(t, 5696, p.Args26.Get("security.kmip.port").Int())
+			assert.Equal(t, "/var/lib/encryption-at-rest/kmip-client.pem", p.Args26.Get("security.kmip.clientCertificateFile").Str())
+			assert.Equal(t, "/var/lib/encryption-at-rest/kmip-ca.crt", p.Args26.Get("security.kmip.serverCAFile").Str())
+			assert.Nil(t, p.Args26.Get("security.encryptionKeyFile").Data())
+		}
+	})
+
+	t.Run("The KMIP certificates are mounted", func(t *testing.T) {
+		data, err := secret.ReadStringData(ctx, mgr.Client, mdb.EncryptionAtRestSecretNamespacedName())
+		require.NoError(t, err)
+		assert.Contains(t, data[kmipClientCertificateFileName], "CERT")
+		assert.Contains(t, data[kmipClientCertificateFileName], "KEY")
+		assert.Equal(t, "CA", data[kmipCAFileName])
+
+		sts := appsv1.StatefulSet{}
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &sts))
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			assert.Contains(t, c.VolumeMounts, corev1.VolumeMount{Name: encryptionAtRestVolumeName, MountPath: encryptionAtRestMountPath, ReadOnly: true})
+		}
+	})
+
+	t.Run("The master key is rotated when its identifier changes", func(t *testing.T) {
+		initContainer := getEncryptionAtRestInitContainer(ctx, t, mgr.Client, mdb)
+		assert.Contains(t, initContainer.Env, corev1.EnvVar{Name: kmipKeyIdentifierEnv, Value: "1"})
+		assert.Contains(t, initContainer.Command[2], "--kmipRotateMasterKey")
+		assert.Contains(t, initContainer.Command[2], "--kmipServerCAFile /var/lib/encryption-at-rest/kmip-ca.crt")
+
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+		mdb.Spec.Security.EncryptionAtRest.Kmip.KeyIdentifier = "2"
+		require.NoError(t, mgr.Client.Update(ctx, &mdb))
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+
+		initContainer = getEncryptionAtRestInitContainer(ctx, t, mgr.Client, mdb)
+		assert.Contains(t, initContainer.Env, corev1.EnvVar{Name: kmipKeyIdentifierEnv, Value: "2"})
+	})
+}
+
+// runEncryptionAtRestInitScript runs the script of the init container with a fake mongod, which records its
+// arguments and creates the key store when it creates the data files. It returns the arguments of each mongod run.
+func runEncryptionAtRestInitScript(t *testing.T, script, keyIdentifier string, mongodFails bool) ([]string, error) {
+	binDir := t.TempDir()
+	callsFile := filepath.Join(binDir, "calls")
+	fakeMongod := `#!/bin/sh
+echo "$@" >> "$MONGOD_CALLS"
+if [ -n "$MONGOD_FAILS" ]; then
+	exit 1
+fi
+for arg in "$@"; do
+	if [ "$arg" = "--fork" ]; then
+		mkdir -p "$DB_PATH/key.db"
+	fi
+done
+`
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "mongod"), []byte(fakeMongod), 0o755))
+
+	dbPath := strings.Fields(script[strings.Index(script, "--dbpath"):])[1]
+	cmd := exec.Command("/bin/sh", "-c", script)
+	cmd.Env = append(os.Environ(),
+		"PATH="+binDir+":"+os.Getenv("PATH"),
+		"MONGOD_CALLS="+callsFile,
+		"DB_PATH="+dbPath,
+		kmipKeyIdentifierEnv+"="+keyIdentifier,
+	)
+	if mongodFails {
+		cmd.Env = append(cmd.Env, "MONGOD_FAILS=true")
+	}
+	err := cmd.Run()
+
+	calls, readErr := os.ReadFile(callsFile)
+	if os.IsNotExist(readErr) {
+		return nil, err
+	}
+	require.NoError(t, readErr)
+	return strings.Split(strings.TrimSpace(string(calls)), "\n"), err
+}
+
+func TestEncryptionAtRest_KmipInitScript(t *testing.T) {
+	dbPath := t.TempDir()
+	mdb := newEncryptionAtRestReplicaSet(mdbv1.EncryptionAtRest{
+		Kmip: &mdbv1.Kmip{
+			ServerNames:                []string{"pykmip"},
+			ClientCertificateSecretRef: corev1.LocalObjectReference{Name: "kmip-client"},
+		},
+	})
+	mdb.Spec.AdditionalMongodConfig = mdbv1.NewMongodConfiguration()
+	mdb.Spec.AdditionalMongodConfig.SetOption("storage.dbPath", dbPath)
+	initContainer := corev1.Container{}
+	encryptionAtRestInit(mdb, "fake-mongodbImage")(&initContainer)
+	script := initContainer.Command[2]
+	readKeyIdentifier := func(t *testing.T) string {
+		keyIdentifier, err := os.ReadFile(filepath.Join(dbPath, kmipKeyIdentifierFileName))
+		require.NoError(t, err)
+		return string(keyIdentifier)
+	}
+
+	t.Run("A failure of mongod fails the init container", func(t *testing.T) {
+		calls, err := runEncryptionAtRestInitScript(t, script, "1", true)
+		assert.Error(t, err)
+		assert.Len(t, calls, 1)
+		assert.NoFileExists(t, filepath.Join(dbPath, kmipKeyIdentifierFileName))
+	})
+
+	t.Run("The data files of a new member are created with the master key", func(t *testing.T) {
+		calls, err := runEncryptionAtRestInitScript(t, script, "1", false)
+		require.NoError(t, err)
+		require.Len(t, calls, 2)
+		assert.Contains(t, calls[0], "--kmipKeyIdentifier 1 --bind_ip localhost --port 27099 --fork")
+		assert.NotContains(t, calls[0], "--kmipRotateMasterKey")
+		assert.Equal(t, "--dbpath "+dbPath+" --shutdown", calls[1])
+		assert.Equal(t, "1", readKeyIdentifier(t))
+	})
+
+	t.Run("Nothing is done while the master key doesn't change", func(t *testing.T) {
+		calls, err := runEncryptionAtRestInitScript(t, script, "1", false)
+		require.NoError(t, err)
+		assert.Empty(t, calls)
+	})
+
+	t.Run("The master key of existing data files is rotated", func(t *testing.T) {
+		calls, err := runEncryptionAtRestInitScript(t, script, "2", false)
+		require.NoError(t, err)
+		require.Len(t, calls, 1)
+		assert.Contains(t, calls[0], "--kmipRotateMasterKey --kmipKeyIdentifier 2")
+		assert.NotContains(t, calls[0], "--fork")
+		assert.Equal(t, "2", readKeyIdentifier(t))
+	})
+
+	t.Run("Without a key identifier the KMIP server's key is kept", func(t *testing.T) {
+		calls, err := runEncryptionAtRestInitScript(t, script, "", false)
+		require.NoError(t, err)
+		assert.Empty(t, calls)
+	})
+}
+
+func TestEncryptionAtRest_RequiresEnterprise(t *testing.T) {
+	t.Setenv(construct.MongoDBAssumeEnterpriseEnv, "false")
+	ctx := context.Background()
+	mdb := newEncryptionAtRestReplicaSet(mdbv1.EncryptionAtRest{KeyFileSecretRef: &mdbv1.SecretKeyReference{Name: "encryption-key"}})
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+	assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+	assert.Contains(t, mdb.Status.Message, "encryption at rest requires MongoDB Enterprise")
+}
@@ -0,0 +1,363 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	k8sClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+)
+
+const (
+	// rerunInitScripts can be set on a MongoDBCommunity resource to run all the init scripts again.
+	// A new run is started every time its value changes.
+	rerunInitScripts = "mongodb.com/v1.rerunInitScripts"
+
+	// initScriptsAnnotation lists the names of the scripts run by an init scripts Job.
+	initScriptsAnnotation = "mongodb.com/v1.initScripts"
+
+	initScriptsMountPath   = "/var/lib/init-scripts/"
+	initScriptsCAMountPath = "/var/lib/init-scripts-ca/"
+	initScriptsCAVolume    = "ca"
+
+	// initScriptsJobBackoffLimit is the number of times a failed init scripts Job is retried.
+	initScriptsJobBackoffLimit = 2
+
+	// initScriptsRetryAfter is the number of seconds after which the creation of a Job is retried when the previous
+	// Job is still being deleted.
+	initScriptsRetryAfter = 10
+)
+
+// ensureInitScriptsPassword creates the password secret of the user the init scripts connect as, if it doesn't exist.
+func (r ReplicaSetReconciler) ensureInitScriptsPassword(ctx context.Context, mdb mdbv1.MongoDBCommunity) error {
+	if len(mdb.Spec.InitScripts) == 0 {
+		return nil
+	}
+
+	user := mdb.InitScriptsUser()
+	secretNamespacedName := mdb.InitScriptsPasswordSecretNamespacedName()
+	exists, err := secret.Exists(ctx, r.client, secretNamespacedName)
+	if err != nil || exists {
+		return err
+	}
+
+	password, err := generateUserPassword(nil)
+	if err != nil {
+		return fmt.Errorf("could not generate password for user %s: %s", user.Username, err)
+	}
+	_, err = secret.EnsureSecretWithKey(ctx, r.client, secretNamespacedName, mdb.GetOwnerReferences(), user.PasswordSecretKey, password)
+	return err
+}
+
+// addRemovedInitScriptsUser removes the user the init scripts connect as from the deployment once spec.initScripts
+// has been removed.
+func addRemovedInitScriptsUser(auth *automationconfig.Auth, mdb mdbv1.MongoDBCommunity, lastAppliedSpec *mdbv1.MongoDBCommunitySpec) {
+	if lastAppliedSpec == nil || len(lastAppliedSpec.InitScripts) == 0 || len(mdb.Spec.InitScripts) > 0 {
+		return
+	}
+	user := mdb.InitScriptsUser()
+	auth.UsersDeleted = append(auth.UsersDeleted, automationconfig.DeletedUser{
+		User: user.Username,
+		Dbs:  []string{user.Database},
+	})
+}
+
+// ensureInitScripts runs the init scripts which have not completed yet through a Job. It must only be called once
+// the deployment is running.
+//
+// The scripts are run by the containers of a single Job, in order. Once the Job succeeds, its scripts are recorded as
+// completed and the Job is deleted. A failed Job is kept so that its logs can be inspected, and no other Job is created
+// until it is deleted or a re-run is requested.
+//
+// It returns the state of the scripts to store in the status and the number of seconds after which the reconciliation
+// must be requeued, or 0 if there is no need to.
+func (r ReplicaSetReconciler) ensureInitScripts(ctx context.Context, mdb mdbv1.MongoDBCommunity) (*mdbv1.InitScriptsStatus, int, error) {
+	if len(mdb.Spec.InitScripts) == 0 && mdb.Status.InitScripts == nil {
+		return nil, 0, nil
+	}
+
+	scriptsStatus := &mdbv1.InitScriptsStatus{}
+	if mdb.Status.InitScripts != nil {
+		scriptsStatus = mdb.Status.InitScripts.DeepCopy()
+	}
+
+	job := batchv1.Job{}
+	jobNamespacedName := mdb.InitScriptsJobNamespacedName()
+	jobExists := true
+	if err := r.client.Get(ctx, jobNamespacedName, &job); err != nil {
+		if !apiErrors.IsNotFound(err) {
+			return nil, 0, err
+		}
+		jobExists = false
+	}
+
+	if trigger := mdb.Annotations[rerunInitScripts]; trigger != scriptsStatus.RerunTrigger {
+		r.log.Infof("Running the init scripts again, requested by annotation %s=%s", rerunInitScripts, trigger)
+		if jobExists {
+			if err := r.deleteInitScriptsJob(ctx, job); err != nil {
+				return nil, 0, err
+			}
+			jobExists = false
+		}
+		scriptsStatus = &mdbv1.InitScriptsStatus{RerunTrigger: trigger}
+	}
+
+	if jobExists {
+		scripts := strings.Split(job.Annotations[initScriptsAnnotation], ",")
+		if job.Status.Succeeded > 0 {
+			r.log.Infof("Init scripts %s completed", strings.Join(scripts, ", "))
+			for _, script := range scripts {
+				if !scriptsStatus.IsCompleted(script) {
+					scriptsStatus.Completed = append(scriptsStatus.Completed, script)
+				}
+			}
+			scriptsStatus.Running = nil
+			if err := r.deleteInitScriptsJob(ctx, job); err != nil {
+				return nil, 0, err
+			}
+		} else if failed, message := isJobFailed(job); failed {
+			scriptsStatus.Running = nil
+			scriptsStatus.Failed = scripts
+			scriptsStatus.Message = fmt.Sprintf("Job %s failed: %s", jobNamespacedName, message)
+			return scriptsStatus, 0, nil
+		} else {
+			scriptsStatus.Running = scripts
+			return scriptsStatus, 0, nil
+		}
+	}
+	scriptsStatus.Failed = nil
+	scriptsStatus.Message = ""
+
+	var pending []mdbv1.InitScript
+	for _, script := range mdb.Spec.InitScripts {
+		if !scriptsStatus.IsCompleted(script.Name) {
+			pending = append(pending, script)
+		}
+	}
+	if len(pending) == 0 {
+		return scriptsStatus, 0, nil
+	}
+
+	for _, script := range pending {
+		if err := r.checkInitScriptSource(ctx, mdb, script); err != nil {
+			return nil, 0, fmt.Errorf("init script %s: %s", script.Name, err)
+		}
+	}
+
+	job = buildInitScriptsJob(mdb, pending, getMongoDBImage(r.mongodbRepoUrl, r.mongodbImage, r.mongodbImageType, mdb.GetMongoDBVersion()), os.Getenv(clusterDomain)) // nolint:forbidigo
+	if err := r.client.Create(ctx, &job); err != nil {
+		if apiErrors.IsAlreadyExists(err) {
+			r.log.Debugf("Job %s is still being deleted, retrying in %d seconds", jobNamespacedName, initScriptsRetryAfter)
+			return scriptsStatus, initScriptsRetryAfter, nil
+		}
+		return nil, 0, fmt.Errorf("could not create Job %s: %s", jobNamespacedName, err)
+	}
+
+	scriptsStatus.Running = initScriptNames(pending)
+	r.log.Infof("Running init scripts %s", strings.Join(scriptsStatus.Running, ", "))
+	return scriptsStatus, 0, nil
+}
+
+func (r ReplicaSetReconciler) deleteInitScriptsJob(ctx context.Context, job batchv1.Job) error {
+	if err := r.client.Delete(ctx, &job, k8sClient.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apiErrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete Job %s: %s", job.Name, err)
+	}
+	return nil
+}
+
+// checkInitScriptSource checks that the key holding the script exists, otherwise the pod of the Job would never start.
+func (r ReplicaSetReconciler) checkInitScriptSource(ctx context.Context, mdb mdbv1.MongoDBCommunity, script mdbv1.InitScript) error {
+	if script.ConfigMapRef != nil {
+		cm, err := r.client.GetConfigMap(ctx, types.NamespacedName{Name: script.ConfigMapRef.Name, Namespace: mdb.Namespace})
+		if err != nil {
+			return err
+		}
+		if _, ok := cm.Data[script.ConfigMapRef.Key]; ok {
+			return nil
+		}
+		if _, ok := cm.BinaryData[script.ConfigMapRef.Key]; ok {
+			return nil
+		}
+		return fmt.Errorf("ConfigMap %s has no key %s", script.ConfigMapRef.Name, script.ConfigMapRef.Key)
+	}
+
+	s, err := r.client.GetSecret(ctx, types.NamespacedName{Name: script.SecretRef.Name, Namespace: mdb.Namespace})
+	if err != nil {
+		return err
+	}
+	if _, ok := s.Data[script.SecretRef.Key]; !ok {
+		return fmt.Errorf("Secret %s has no key %s", script.SecretRef.Name, script.SecretRef.Key)
+	}
+	return nil
+}
+
+// isJobFailed returns true and the reason if the given Job has failed.
+func isJobFailed(job batchv1.Job) (bool, string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true, condition.Message
+		}
+	}
+	return false, ""
+}
+
+func initScriptNames(scripts []mdbv1.InitScript) []string {
+	names := make([]string, len(scripts))
+	for i, script := range scripts {
+		names[i] = script.Name
+	}
+	return names
+}
+
+// buildInitScriptsJob returns a Job running the given scripts in order. Every script but the last runs in an init
+// container, so that a script only runs once the previous one has succeeded.
+func buildInitScriptsJob(mdb mdbv1.MongoDBCommunity, scripts []mdbv1.InitScript, defaultImage, clusterDomain string) batchv1.Job {
+	user := mdb.InitScriptsUser()
+	env := []corev1.EnvVar{
+		{
+			Name:  "MONGODB_URI",
+			Value: fmt.Sprintf("mongodb://%s/?replicaSet=%s", strings.Join(mdb.Hosts(clusterDomain), ","), mdb.Name),
+		},
+		{
+			Name:  "MONGODB_USERNAME",
+			Value: user.Username,
+		},
+		{
+			Name: "MONGODB_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: user.PasswordSecretName},
+					Key:                  user.PasswordSecretKey,
+				},
+			},
+		},
+	}
+
+	var volumes []corev1.Volume
+	if mdb.IsTLSEnabledForClients() {
+		volumes = append(volumes, initScriptsCAVolumeFor(mdb))
+	}
+
+	containers := make([]corev1.Container, len(scripts))
+	for i, script := range scripts {
+		volume, key := initScriptVolume(script)
+		volumes = append(volumes, volume)
+
+		mounts := []corev1.VolumeMount{{Name: volume.Name, MountPath: initScriptsMountPath + script.Name, ReadOnly: true}}
+		if mdb.IsTLSEnabledForClients() {
+			mounts = append(mounts, corev1.VolumeMount{Name: initScriptsCAVolume, MountPath: initScriptsCAMountPath, ReadOnly: true})
+		}
+
+		image := script.Image
+		if image == "" {
+			image = defaultImage
+		}
+		containers[i] = corev1.Container{
+			Name:         script.Name,
+			Image:        image,
+			Command:      initScriptCommand(script, path.Join(initScriptsMountPath+script.Name, key), user.Database, mdb.IsTLSEnabledForClients()),
+			Env:          env,
+			VolumeMounts: mounts,
+		}
+	}
+
+	jobNamespacedName := mdb.InitScriptsJobNamespacedName()
+	return batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            jobNamespacedName.Name,
+			Namespace:       jobNamespacedName.Namespace,
+			Annotations:     map[string]string{initScriptsAnnotation: strings.Join(initScriptNames(scripts), ",")},
+			OwnerReferences: mdb.GetOwnerReferences(),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To(int32(initScriptsJobBackoffLimit)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:  corev1.RestartPolicyNever,
+					InitContainers: containers[:len(containers)-1],
+					Containers:     containers[len(containers)-1:],
+					Volumes:        volumes,
+				},
+			},
+		},
+	}
+}
+
+// initScriptVolume returns the volume holding the given script, and the name of the file of the script in it.
+func initScriptVolume(script mdbv1.InitScript) (corev1.Volume, string) {
+	volume := corev1.Volume{Name: "script-" + script.Name}
+	if script.ConfigMapRef != nil {
+		volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: script.ConfigMapRef.Name},
+			Items:                []corev1.KeyToPath{{Key: script.ConfigMapRef.Key, Path: script.ConfigMapRef.Key}},
+		}
+		return volume, script.ConfigMapRef.Key
+	}
+	volume.Secret = &corev1.SecretVolumeSource{
+		SecretName: script.SecretRef.Name,
+		Items:      []corev1.KeyToPath{{Key: script.SecretRef.Key, Path: script.SecretRef.Key}},
+	}
+	return volume, script.SecretRef.Key
+}
+
+// initScriptsCAVolumeFor returns the volume holding the CA certificate the scripts use to verify the members.
+func initScriptsCAVolumeFor(mdb mdbv1.MongoDBCommunity) corev1.Volume {
+	items := []corev1.KeyToPath{{Key: tlsCACertName, Path: tlsCACertName}}
+	if mdb.HasTLSCaCertificateSecret() {
+		return corev1.Volume{
+			Name: initScriptsCAVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: mdb.TLSCaCertificateSecretNamespacedName().Name, Items: items},
+			},
+		}
+	}
+	return corev1.Volume{
+		Name: initScriptsCAVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: mdb.TLSConfigMapNamespacedName().Name},
+				Items:                items,
+			},
+		},
+	}
+}
+
+// initScriptCommand returns the command running the given script. The credentials are expanded by Kubernetes from
+// the environment of the container, so no shell is needed.
+func initScriptCommand(script mdbv1.InitScript, file, authenticationDatabase string, tls bool) []string {
+	credentials := []string{"--username", "$(MONGODB_USERNAME)", "--password", "$(MONGODB_PASSWORD)", "--authenticationDatabase", authenticationDatabase}
+	caFile := initScriptsCAMountPath + tlsCACertName
+
+	switch script.GetType() {
+	case mdbv1.InitScriptJSON:
+		command := append([]string{"mongoimport", "--uri", "$(MONGODB_URI)"}, credentials...)
+		if tls {
+			command = append(command, "--ssl", "--sslCAFile", caFile)
+		}
+		return append(command, "--db", script.Database, "--collection", script.Collection, "--jsonArray", "--file", file)
+	case mdbv1.InitScriptBSON:
+		command := append([]string{"mongorestore", "--uri", "$(MONGODB_URI)"}, credentials...)
+		if tls {
+			command = append(command, "--ssl", "--sslCAFile", caFile)
+		}
+		return append(command, "--db", script.Database, "--collection", script.Collection, file)
+	default:
+		command := append([]string{"mongosh", "$(MONGODB_URI)"}, credentials...)
+		if tls {
+			command = append(command, "--tls", "--tlsCAFile", caFile)
+		}
+		return append(command, "--quiet", "--file", file)
+	}
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+)
+
+func TestInitScripts_RunOnceThroughAJob(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mdb.Spec.InitScripts = []mdbv1.InitScript{
+		{Name: "schema", ConfigMapRef: &mdbv1.InitScriptSourceReference{Name: "seed", Key: "schema.js"}},
+		{Name: "countries", Type: mdbv1.InitScriptJSON, ConfigMapRef: &mdbv1.InitScriptSourceReference{Name: "seed", Key: "countries.json"}, Database: "app", Collection: "countries"},
+	}
+	mgr := client.NewManager(ctx, &mdb)
+	err := mgr.Client.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "seed", Namespace: mdb.Namespace},
+		Data:       map[string]string{"schema.js": "db.getSiblingDB('app').createCollection('countries')", "countries.json": "[]"},
+	})
+	require.NoError(t, err)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	t.Run("The scripts connect as an operator created user", func(t *testing.T) {
+		user := acUser(ctx, t, mgr.Client, mdb, mdbv1.InitScriptsUsername)
+		require.NotNil(t, user)
+		assert.Equal(t, "admin", user.Database)
+	})
+
+	t.Run("The scripts run in order in a Job", func(t *testing.T) {
+		job := initScriptsJob(ctx, t, mgr.Client, mdb)
+		require.Len(t, job.Spec.Template.Spec.InitContainers, 1)
+		require.Len(t, job.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, "schema", job.Spec.Template.Spec.InitContainers[0].Name)
+		assert.Equal(t, "mongosh", job.Spec.Template.Spec.InitContainers[0].Command[0])
+		assert.Equal(t, "countries", job.Spec.Template.Spec.Containers[0].Name)
+		assert.Equal(t, "mongoimport", job.Spec.Template.Spec.Containers[0].Command[0])
+		assert.Equal(t, "fake-mongodbRepoUrl/fake-mongodbImage:4.2.2", job.Spec.Template.Spec.Containers[0].Image)
+		assert.Equal(t, []string{"schema", "countries"}, initScriptsStatus(ctx, t, mgr.Client, mdb).Running)
+	})
+
+	t.Run("Completed scripts are recorded and the Job is deleted", func(t *testing.T) {
+		job := initScriptsJob(ctx, t, mgr.Client, mdb)
+		job.Status.Succeeded = 1
+		require.NoError(t, mgr.Client.Update(ctx, &job))
+
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+		scriptsStatus := initScriptsStatus(ctx, t, mgr.Client, mdb)
+		assert.Equal(t, []string{"schema", "countries"}, scriptsStatus.Completed)
+		assert.Empty(t, scriptsStatus.Running)
+
+		err = mgr.Client.Get(ctx, mdb.InitScriptsJobNamespacedName(), &job)
+		assert.True(t, apiErrors.IsNotFound(err), "completed scripts must not run again")
+
+		res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+		err = mgr.Client.Get(ctx, mdb.InitScriptsJobNamespacedName(), &job)
+		assert.True(t, apiErrors.IsNotFound(err), "completed scripts must not run again")
+	})
+
+	t.Run("The scripts run again when requested", func(t *testing.T) {
+		err := mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+		require.NoError(t, err)
+		mdb.Annotations[rerunInitScripts] = "again"
+		require.NoError(t, mgr.Client.Update(ctx, &mdb))
+
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+		scriptsStatus := initScriptsStatus(ctx, t, mgr.Client, mdb)
+		assert.Empty(t, scriptsStatus.Completed)
+		assert.Equal(t, []string{"schema", "countries"}, scriptsStatus.Running)
+		assert.Equal(t, "again", scriptsStatus.RerunTrigger)
+	})
+}
+
+func TestInitScripts_FailedJobIsNotRecreated(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mdb.Spec.InitScripts = []mdbv1.InitScript{{Name: "schema", SecretRef: &mdbv1.InitScriptSourceReference{Name: "seed", Key: "schema.js"}}}
+	mgr := client.NewManager(ctx, &mdb)
+	err := mgr.Client.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "seed", Namespace: mdb.Namespace},
+		Data:       map[string][]byte{"schema.js": []byte("throw new Error('failed')")},
+	})
+	require.NoError(t, err)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	job := initScriptsJob(ctx, t, mgr.Client, mdb)
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "Job has reached the specified backoff limit"}}
+	require.NoError(t, mgr.Client.Update(ctx, &job))
+
+	res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	scriptsStatus := initScriptsStatus(ctx, t, mgr.Client, mdb)
+	assert.Equal(t, []string{"schema"}, scriptsStatus.Failed)
+	assert.Contains(t, scriptsStatus.Message, "backoff limit")
+
+	require.NoError(t, mgr.Client.Delete(ctx, &job))
+	res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	scriptsStatus = initScriptsStatus(ctx, t, mgr.Client, mdb)
+	assert.Empty(t, scriptsStatus.Failed)
+	assert.Equal(t, []string{"schema"}, scriptsStatus.Running, "the scripts run again once the failed Job is deleted")
+}
+
+func TestInitScripts_UserIsRemovedWithTheScripts(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mdb.Spec.InitScripts = []mdbv1.InitScript{{Name: "schema", SecretRef: &mdbv1.InitScriptSourceReference{Name: "seed", Key: "schema.js"}}}
+	mgr := client.NewManager(ctx, &mdb)
+	err := mgr.Client.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "seed", Namespace: mdb.Namespace},
+		Data:       map[string][]byte{"schema.js": []byte("db.version()")},
+	})
+	require.NoError(t, err)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	require.NotNil(t, acUser(ctx, t, mgr.Client, mdb, mdbv1.InitScriptsUsername))
+
+	err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	mdb.Spec.InitScripts = nil
+	require.NoError(t, mgr.Client.Update(ctx, &mdb))
+
+	res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	assert.Nil(t, acUser(ctx, t, mgr.Client, mdb, mdbv1.InitScriptsUsername))
+}
+
+func initScriptsJob(ctx context.Context, t *testing.T, c client.Client, mdb mdbv1.MongoDBCommunity) batchv1.Job {
+	job := batchv1.Job{}
+	err := c.Get(ctx, mdb.InitScriptsJobNamespacedName(), &job)
+	require.NoError(t, err)
+	return job
+}
+
+func initScriptsStatus(ctx context.Context, t *testing.T, c client.Client, mdb mdbv1.MongoDBCommunity) mdbv1.InitScriptsStatus {
+	err := c.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	require.NotNil(t, mdb.Status.InitScripts)
+	return *mdb.Status.InitScripts
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/controllers/construct"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/container"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/podtemplatespec"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/statefulset"
+)
+
+const (
+	ldapCAVolumeName = "ldap-ca"
+	ldapCAMountPath  = "/var/lib/ldap-ca"
+	ldapCAKey        = "ca.crt"
+	// ldapCACertEnv is read by the OpenLDAP client library mongod uses to connect to the LDAP servers.
+	ldapCACertEnv = "LDAPTLS_CACERT"
+)
+
+// ldapUserToDNMapping is a rule of the mongod security.ldap.userToDNMapping option.
+type ldapUserToDNMapping struct {
+	Match        string `json:"match"`
+	Substitution string `json:"substitution,omitempty"`
+	LdapQuery    string `json:"ldapQuery,omitempty"`
+}
+
+// getLdapModification renders the LDAP configuration of the resource into the security.ldap options of the
+// processes. LDAP authentication is only available in MongoDB Enterprise.
+func getLdapModification(ctx context.Context, getter secret.Getter, mdb mdbv1.MongoDBCommunity, isEnterprise bool) (automationconfig.Modification, error) {
+	if !mdb.Spec.IsLdapEnabled() {
+		return automationconfig.NOOP(), nil
+	}
+	if !isEnterprise {
+		return automationconfig.NOOP(), errors.New("LDAP authentication requires MongoDB Enterprise")
+	}
+
+	ldap := mdb.Spec.Security.Authentication.Ldap
+	if ldap == nil {
+		return automationconfig.NOOP(), errors.New("LDAP authentication is enabled but spec.security.authentication.ldap is not configured")
+	}
+
+	bindQueryPassword := ""
+	if ldap.BindQueryPasswordSecretRef != nil {
+		secretNamespacedName := types.NamespacedName{Name: ldap.BindQueryPasswordSecretRef.Name, Namespace: mdb.Namespace}
+		password, err := secret.ReadKey(ctx, getter, ldap.GetBindQueryPasswordKey(), secretNamespacedName)
+		if err != nil {
+			return automationconfig.NOOP(), fmt.Errorf("could not read the LDAP bind query password: %s", err)
+		}
+		bindQueryPassword = password
+	}
+
+	userToDNMapping := ""
+	if len(ldap.UserToDNMapping) > 0 {
+		rules := make([]ldapUserToDNMapping, len(ldap.UserToDNMapping))
+		for i, rule := range ldap.UserToDNMapping {
+			rules[i] = ldapUserToDNMapping(rule)
+		}
+		bytes, err := json.Marshal(rules)
+		if err != nil {
+			return automationconfig.NOOP(), fmt.Errorf("could not render the LDAP user to DN mapping: %s", err)
+		}
+		userToDNMapping = string(bytes)
+	}
+
+	return func(config *automationconfig.AutomationConfig) {
+		for i := range config.Processes {
+			args := config.Processes[i].Args26
+			args.Set("security.ldap.servers", strings.Join(ldap.Servers, ","))
+			args.Set("security.ldap.transportSecurity", string(ldap.GetTransportSecurity()))
+			args.Set("security.ldap.bind.method", "simple")
+			if ldap.BindQueryUser != "" {
+				args.Set("security.ldap.bind.queryUser", ldap.BindQueryUser)
+				args.Set("security.ldap.bind.queryPassword", bindQueryPassword)
+			}
+			if userToDNMapping != "" {
+				args.Set("security.ldap.userToDNMapping", userToDNMapping)
+			}
+			if ldap.AuthzQueryTemplate != "" {
+				args.Set("security.ldap.authz.queryTemplate", ldap.AuthzQueryTemplate)
+			}
+			if ldap.TimeoutMS != nil {
+				args.Set("security.ldap.timeoutMS", *ldap.TimeoutMS)
+			}
+			if ldap.ValidateLDAPServerConfig != nil {
+				args.Set("security.ldap.validateLDAPServerConfig", *ldap.ValidateLDAPServerConfig)
+			}
+			if ldap.UserCacheInvalidationIntervalSeconds != nil {
+				args.Set("setParameter.ldapUserCacheInvalidationInterval", *ldap.UserCacheInvalidationIntervalSeconds)
+			}
+		}
+	}, nil
+}
+
+// buildLdapPodSpecModification mounts the CA of the LDAP servers into the mongod container, if it is configured.
+func buildLdapPodSpecModification(mdb mdbv1.MongoDBCommunity) podtemplatespec.Modification {
+	ldap := mdb.Spec.Security.Authentication.Ldap
+	if !mdb.Spec.IsLdapEnabled() || ldap == nil || ldap.CaConfigMap == nil {
+		return podtemplatespec.Apply(
+			podtemplatespec.RemoveVolume(ldapCAVolumeName),
+			podtemplatespec.RemoveVolumeMount(construct.MongodbName, ldapCAVolumeName),
+			podtemplatespec.WithContainer(construct.MongodbName, removeEnv(ldapCACertEnv)),
+		)
+	}
+
+	caVolume := statefulset.CreateVolumeFromConfigMap(ldapCAVolumeName, ldap.CaConfigMap.Name)
+	caVolumeMount := statefulset.CreateVolumeMount(caVolume.Name, ldapCAMountPath, statefulset.WithReadOnly(true))
+
+	return podtemplatespec.Apply(
+		podtemplatespec.WithVolume(caVolume),
+		podtemplatespec.WithVolumeMounts(construct.MongodbName, caVolumeMount),
+		podtemplatespec.WithContainer(construct.MongodbName, container.WithEnvs(corev1.EnvVar{
+			Name:  ldapCACertEnv,
+			Value: ldapCAMountPath + "/" + ldapCAKey,
+		})),
+	)
+}
+
+// removeEnv removes the environment variable with the given name from the container.
+func removeEnv(name string) container.Modification {
+	return func(c *corev1.Container) {
+		var envs []corev1.EnvVar
+		for _, env := range c.Env {
+			if env.Name != name {
+				envs = append(envs, env)
+			}
+		}
+		c.Env = envs
+	}
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/controllers/construct"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+func newLdapReplicaSet() mdbv1.MongoDBCommunity {
+	mdb := newScramReplicaSet(mdbv1.MongoDBUser{
+		Name:  "alice",
+		DB:    constants.ExternalDB,
+		Roles: []mdbv1.Role{{Name: "readWrite", DB: "app"}},
+	})
+	mdb.Spec.Security.Authentication.Modes = []mdbv1.AuthMode{"SCRAM", "LDAP"}
+	mdb.Spec.Security.Authentication.Ldap = &mdbv1.LdapConfiguration{
+		Servers:                    []string{"openldap.ldap.svc.cluster.local:636"},
+		CaConfigMap:                &corev1.LocalObjectReference{Name: "ldap-ca"},
+		BindQueryUser:              "cn=admin,dc=example,dc=org",
+		BindQueryPasswordSecretRef: &mdbv1.SecretKeyReference{Name: "ldap-bind"},
+		UserToDNMapping:            []mdbv1.LdapUserToDNMapping{{Match: "(.+)", Substitution: "cn={0},ou=users,dc=example,dc=org"}},
+	}
+	return mdb
+}
+
+func TestLdap_IsConfiguredForEnterpriseImages(t *testing.T) {
+	t.Setenv(construct.MongoDBAssumeEnterpriseEnv, "true")
+	ctx := context.Background()
+	mdb := newLdapReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	err := mgr.Client.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ldap-bind", Namespace: mdb.Namespace},
+		Data:       map[string][]byte{"password": []byte("bind-password")},
+	})
+	require.NoError(t, err)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+	require.NoError(t, err)
+
+	t.Run("Clients can authenticate with LDAP", func(t *testing.T) {
+		assert.Contains(t, ac.Auth.DeploymentAuthMechanisms, constants.Ldap)
+		assert.Equal(t, constants.Sha256, ac.Auth.AutoAuthMechanism)
+		user := acUser(ctx, t, mgr.Client, mdb, "alice")
+		require.NotNil(t, user)
+		assert.Equal(t, constants.ExternalDB, user.Database)
+	})
+
+	t.Run("The LDAP servers are configured on every process", func(t *testing.T) {
+		for _, p := range ac.Processes {
+			assert.Equal(t, "openldap.ldap.svc.cluster.local:636", p.Args26.Get("security.ldap.servers").Str())
+			assert.Equal(t, "tls", p.Args26.Get("security.ldap.transportSecurity").Str())
+			assert.Equal(t, "cn=admin,dc=example,dc=org", p.Args26.Get("security.ldap.bind.queryUser").Str())
+			assert.Equal(t, "bind-password", p.Args26.Get("security.ldap.bind.queryPassword").Str())
+			assert.Equal(t, `[{"match":"(.+)","substitution":"cn={0},ou=users,dc=example,dc=org"}]`, p.Args26.Get("security.ldap.userToDNMapping").Str())
+		}
+	})
+
+	t.Run("The CA of the LDAP servers is mounted", func(t *testing.T) {
+		sts := appsv1.StatefulSet{}
+		err := mgr.Client.Get(ctx, mdb.NamespacedName(), &sts)
+		require.NoError(t, err)
+		var mongod corev1.Container
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name == construct.MongodbName {
+				mongod = c
+			}
+		}
+		assert.Contains(t, mongod.Env, corev1.EnvVar{Name: ldapCACertEnv, Value: "/var/lib/ldap-ca/ca.crt"})
+		assert.Contains(t, mongod.VolumeMounts, corev1.VolumeMount{Name: ldapCAVolumeName, MountPath: ldapCAMountPath, ReadOnly: true})
+	})
+}
+
+func TestLdap_RequiresEnterprise(t *testing.T) {
+	t.Setenv(construct.MongoDBAssumeEnterpriseEnv, "false")
+	ctx := context.Background()
+	mdb := newLdapReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+	assert.Contains(t, mdb.Status.Message, "LDAP authentication requires MongoDB Enterprise")
+}
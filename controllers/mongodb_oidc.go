@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+)
+
+// oidcIdentityProvider is an identity provider of the mongod oidcIdentityProviders server parameter.
+type oidcIdentityProvider struct {
+	AuthNamePrefix        string   `json:"authNamePrefix"`
+	Issuer                string   `json:"issuer"`
+	Audience              string   `json:"audience"`
+	ClientId              string   `json:"clientId,omitempty"`
+	RequestScopes         []string `json:"requestScopes,omitempty"`
+	PrincipalName         string   `json:"principalName"`
+	UseAuthorizationClaim bool     `json:"useAuthorizationClaim"`
+	AuthorizationClaim    string   `json:"authorizationClaim,omitempty"`
+	SupportsHumanFlows    bool     `json:"supportsHumanFlows"`
+}
+
+// getOidcModification renders the OIDC identity providers of the resource into the oidcIdentityProviders server
+// parameter of the processes. OIDC authentication is only available in MongoDB Enterprise.
+func getOidcModification(mdb mdbv1.MongoDBCommunity, isEnterprise bool) (automationconfig.Modification, error) {
+	if !mdb.Spec.IsOidcEnabled() {
+		return automationconfig.NOOP(), nil
+	}
+	if !isEnterprise {
+		return automationconfig.NOOP(), errors.New("OIDC authentication requires MongoDB Enterprise")
+	}
+
+	providers := make([]oidcIdentityProvider, len(mdb.Spec.Security.Authentication.OidcProviderConfigs))
+	for i, config := range mdb.Spec.Security.Authentication.OidcProviderConfigs {
+		providers[i] = oidcIdentityProvider{
+			AuthNamePrefix:     config.ConfigurationName,
+			Issuer:             config.IssuerURI,
+			Audience:           config.Audience,
+			ClientId:           config.ClientId,
+			RequestScopes:      config.RequestedScopes,
+			PrincipalName:      config.GetUserClaim(),
+			SupportsHumanFlows: config.GetAuthorizationMethod() == mdbv1.OidcAuthorizationMethodWorkforceIdentityFederation,
+		}
+		if config.GetAuthorizationType() == mdbv1.OidcAuthorizationTypeGroupMembership {
+			providers[i].UseAuthorizationClaim = true
+			providers[i].AuthorizationClaim = config.GroupsClaim
+		}
+	}
+	bytes, err := json.Marshal(providers)
+	if err != nil {
+		return automationconfig.NOOP(), fmt.Errorf("could not render the OIDC identity providers: %s", err)
+	}
+
+	return func(config *automationconfig.AutomationConfig) {
+		for i := range config.Processes {
+			config.Processes[i].Args26.Set("setParameter.oidcIdentityProviders", string(bytes))
+		}
+	}, nil
+}
+
+// updateOidcConnectionStringSecret updates the secret storing the connection strings workloads use to authenticate
+// with the token of their Kubernetes service account, if they can.
+func (r ReplicaSetReconciler) updateOidcConnectionStringSecret(ctx context.Context, mdb mdbv1.MongoDBCommunity, clusterDomain string) error {
+	if !hasOidcWorkloadIdentityProvider(mdb) {
+		return nil
+	}
+
+	ca, err := r.connectionStringCA(ctx, mdb)
+	if err != nil {
+		return err
+	}
+
+	nsName := mdb.OidcConnectionStringSecretNamespacedName()
+	connectionStringSecretBuilder := secret.Builder().
+		SetName(nsName.Name).
+		SetNamespace(nsName.Namespace).
+		SetField("connectionString.standard", mdb.MongoOIDCURI(clusterDomain)).
+		SetField("connectionString.standardSrv", mdb.MongoOIDCSRVURI(clusterDomain)).
+		SetOwnerReferences(mdb.GetOwnerReferences())
+	if ca != "" {
+		connectionStringSecretBuilder.SetField(tlsCACertName, ca)
+	}
+	connectionStringSecret := connectionStringSecretBuilder.Build()
+
+	return secret.CreateOrUpdate(ctx, r.client, connectionStringSecret)
+}
+
+// hasOidcWorkloadIdentityProvider returns true if OIDC is enabled with an identity provider authenticating workloads.
+func hasOidcWorkloadIdentityProvider(mdb mdbv1.MongoDBCommunity) bool {
+	if !mdb.Spec.IsOidcEnabled() {
+		return false
+	}
+	for _, config := range mdb.Spec.Security.Authentication.OidcProviderConfigs {
+		if config.GetAuthorizationMethod() == mdbv1.OidcAuthorizationMethodWorkloadIdentityFederation {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/objx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/controllers/construct"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+func TestOidc_WorkloadIdentityFederation(t *testing.T) {
+	t.Setenv(construct.MongoDBAssumeEnterpriseEnv, "true")
+	ctx := context.Background()
+	mdb := newScramReplicaSet(mdbv1.MongoDBUser{
+		Name:  "workload/system:serviceaccount:apps:billing",
+		DB:    constants.ExternalDB,
+		Roles: []mdbv1.Role{{Name: "readWrite", DB: "billing"}},
+	})
+	mdb.Spec.Version = "7.0.12"
+	mdb.Spec.Security.Authentication.Modes = []mdbv1.AuthMode{"SCRAM", "OIDC"}
+	mdb.Spec.Security.Authentication.OidcProviderConfigs = []mdbv1.OidcProviderConfig{{
+		ConfigurationName: "workload",
+		IssuerURI:         "https://kubernetes.default.svc.cluster.local",
+		Audience:          "mongodb",
+	}}
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	t.Run("The identity providers are configured on every process", func(t *testing.T) {
+		ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+		require.NoError(t, err)
+		assert.Contains(t, ac.Auth.DeploymentAuthMechanisms, constants.Oidc)
+		for _, p := range ac.Processes {
+			assert.JSONEq(t, `[{"authNamePrefix":"workload","issuer":"https://kubernetes.default.svc.cluster.local","audience":"mongodb","principalName":"sub","useAuthorizationClaim":false,"supportsHumanFlows":false}]`,
+				p.Args26.Get("setParameter.oidcIdentityProviders").Str())
+		}
+		user := acUser(ctx, t, mgr.Client, mdb, "workload/system:serviceaccount:apps:billing")
+		require.NotNil(t, user)
+		assert.Equal(t, constants.ExternalDB, user.Database)
+	})
+
+	t.Run("Workloads get a connection string", func(t *testing.T) {
+		s := corev1.Secret{}
+		err := mgr.Client.Get(ctx, mdb.OidcConnectionStringSecretNamespacedName(), &s)
+		require.NoError(t, err)
+		assert.Equal(t, mdb.MongoOIDCURI(""), string(s.Data["connectionString.standard"]))
+		assert.Contains(t, string(s.Data["connectionString.standard"]), "authMechanism=MONGODB-OIDC&authMechanismProperties=ENVIRONMENT:k8s")
+	})
+
+	t.Run("The connection string is removed with OIDC", func(t *testing.T) {
+		err := mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+		require.NoError(t, err)
+		mdb.Spec.Users = nil
+		mdb.Spec.Security.Authentication.Modes = []mdbv1.AuthMode{"SCRAM"}
+		mdb.Spec.Security.Authentication.OidcProviderConfigs = nil
+		require.NoError(t, mgr.Client.Update(ctx, &mdb))
+
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+		err = mgr.Client.Get(ctx, mdb.OidcConnectionStringSecretNamespacedName(), &corev1.Secret{})
+		assert.True(t, apiErrors.IsNotFound(err))
+	})
+}
+
+func TestGetOidcModification(t *testing.T) {
+	mdb := newScramReplicaSet()
+	mdb.Spec.Security.Authentication.Modes = []mdbv1.AuthMode{"SCRAM", "OIDC"}
+
+	render := func(t *testing.T, configs ...mdbv1.OidcProviderConfig) string {
+		mdb.Spec.Security.Authentication.OidcProviderConfigs = configs
+		modification, err := getOidcModification(mdb, true)
+		require.NoError(t, err)
+		ac := automationconfig.AutomationConfig{Processes: []automationconfig.Process{{Name: "my-rs-0", Args26: objx.New(map[string]interface{}{})}}}
+		modification(&ac)
+		return ac.Processes[0].Args26.Get("setParameter.oidcIdentityProviders").Str()
+	}
+
+	t.Run("Workforce identity providers support human flows", func(t *testing.T) {
+		providers := render(t, mdbv1.OidcProviderConfig{
+			ConfigurationName:   "okta",
+			IssuerURI:           "https://example.okta.com",
+			Audience:            "mongodb",
+			AuthorizationMethod: mdbv1.OidcAuthorizationMethodWorkforceIdentityFederation,
+			ClientId:            "0oa1",
+			RequestedScopes:     []string{"openid", "groups"},
+		})
+		assert.JSONEq(t, `[{"authNamePrefix":"okta","issuer":"https://example.okta.com","audience":"mongodb","clientId":"0oa1","requestScopes":["openid","groups"],"principalName":"sub","useAuthorizationClaim":false,"supportsHumanFlows":true}]`, providers)
+	})
+
+	t.Run("The principal is read from the user claim", func(t *testing.T) {
+		providers := render(t, mdbv1.OidcProviderConfig{
+			ConfigurationName: "workload",
+			IssuerURI:         "https://kubernetes.default.svc.cluster.local",
+			Audience:          "mongodb",
+			UserClaim:         "email",
+		})
+		assert.JSONEq(t, `[{"authNamePrefix":"workload","issuer":"https://kubernetes.default.svc.cluster.local","audience":"mongodb","principalName":"email","useAuthorizationClaim":false,"supportsHumanFlows":false}]`, providers)
+	})
+
+	t.Run("Group membership is read from the groups claim", func(t *testing.T) {
+		providers := render(t, mdbv1.OidcProviderConfig{
+			ConfigurationName: "workload",
+			IssuerURI:         "https://kubernetes.default.svc.cluster.local",
+			Audience:          "mongodb",
+			AuthorizationType: mdbv1.OidcAuthorizationTypeGroupMembership,
+			GroupsClaim:       "groups",
+		})
+		assert.JSONEq(t, `[{"authNamePrefix":"workload","issuer":"https://kubernetes.default.svc.cluster.local","audience":"mongodb","principalName":"sub","useAuthorizationClaim":true,"authorizationClaim":"groups","supportsHumanFlows":false}]`, providers)
+	})
+
+	t.Run("OIDC requires MongoDB Enterprise", func(t *testing.T) {
+		_, err := getOidcModification(mdb, false)
+		assert.EqualError(t, err, "OIDC authentication requires MongoDB Enterprise")
+	})
+}
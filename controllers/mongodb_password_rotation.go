@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"time"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/scram"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/status"
+)
+
+// ensureUserPasswordRotations moves the password rotations of the users configured with passwordRotation forward
+// and stores them in the status of the resource.
+//
+// A rotation starts when the password of a user changes. While it is Overlapping, the user keeps its previous
+// password and a shadow user is granted the new one, the connection string secret of the user points to the shadow
+// user. Once the grace period has elapsed the rotation is Retiring: the user is granted the new password and the
+// connection string secret points to the user again. After another grace period the shadow user is deleted and the
+// rotation is Completed. Completed rotations are removed from the status once the agents reached the automation config
+// deleting their shadow users, see pendingUserPasswordRotations.
+//
+// It returns the number of seconds after which the next rotation moves on, or 0 if no rotation is in progress.
+func (r ReplicaSetReconciler) ensureUserPasswordRotations(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb *mdbv1.MongoDBCommunity) (int, error) {
+	now := time.Now()
+	authUsers := mdb.GetAuthUsers()
+	seen := map[string]bool{}
+
+	var rotations []mdbv1.UserPasswordRotation
+	var nextTransition time.Duration
+	for i, user := range mdb.Spec.Users {
+		authUser := authUsers[i]
+		if authUser.Database == constants.ExternalDB {
+			continue
+		}
+		seen[authUser.Database+"/"+authUser.Username] = true
+
+		rotation, ok := mdb.Status.GetUserPasswordRotation(authUser.Username, authUser.Database)
+		if user.PasswordRotation == nil {
+			// rotations are disabled for this user, the shadow user of an ongoing rotation is deleted straight away.
+			if ok && rotation.IsInProgress() {
+				rotation = r.completePasswordRotation(ctx, mdb, user, rotation, now)
+			}
+			if ok {
+				rotations = append(rotations, rotation)
+			}
+			continue
+		}
+
+		gracePeriod := user.PasswordRotation.GetGracePeriod()
+		switch {
+		case !ok || rotation.Phase == mdbv1.PasswordRotationCompleted:
+			changed, err := scram.PasswordChanged(ctx, secrets, authUser, mdb.NamespacedName())
+			if err != nil {
+				return 0, err
+			}
+			if changed {
+				r.log.Infof("Password of user %s has changed, granting it to shadow user %s for %s", authUser.Username, user.GetShadowUsername(), gracePeriod)
+				rotation = mdbv1.UserPasswordRotation{
+					Username:           authUser.Username,
+					DB:                 authUser.Database,
+					ShadowUsername:     user.GetShadowUsername(),
+					Phase:              mdbv1.PasswordRotationOverlapping,
+					StartedAt:          metav1.NewTime(now),
+					LastTransitionTime: metav1.NewTime(now),
+				}
+			}
+		case now.Before(rotation.LastTransitionTime.Add(gracePeriod)):
+			// the grace period of the current phase has not elapsed yet.
+		case rotation.Phase == mdbv1.PasswordRotationOverlapping:
+			r.log.Infof("Grace period of the previous password of user %s has elapsed, granting it the new password", authUser.Username)
+			rotation.Phase = mdbv1.PasswordRotationRetiring
+			rotation.LastTransitionTime = metav1.NewTime(now)
+		case rotation.Phase == mdbv1.PasswordRotationRetiring:
+			rotation = r.completePasswordRotation(ctx, mdb, user, rotation, now)
+		}
+
+		if rotation.IsInProgress() {
+			untilTransition := rotation.LastTransitionTime.Add(gracePeriod).Sub(now)
+			if nextTransition == 0 || untilTransition < nextTransition {
+				nextTransition = untilTransition
+			}
+		}
+		if rotation.Username != "" {
+			rotations = append(rotations, rotation)
+		}
+	}
+
+	// the shadow users of the users which have been removed are deleted as well.
+	for _, rotation := range mdb.Status.UserPasswordRotations {
+		if seen[rotation.DB+"/"+rotation.Username] || !rotation.IsInProgress() {
+			continue
+		}
+		rotation.Phase = mdbv1.PasswordRotationCompleted
+		rotation.LastTransitionTime = metav1.NewTime(now)
+		rotations = append(rotations, rotation)
+	}
+
+	if !userPasswordRotationsEqual(rotations, mdb.Status.UserPasswordRotations) {
+		if _, err := status.Update(ctx, r.client.Status(), mdb, statusOptions().withUserPasswordRotations(rotations, 0)); err != nil {
+			return 0, err
+		}
+	}
+
+	if nextTransition <= 0 {
+		return 0, nil
+	}
+	return int(math.Ceil(nextTransition.Seconds())), nil
+}
+
+// completePasswordRotation deletes the credentials of the shadow user of the given rotation. The shadow user
+// itself is deleted from the automation config by the Completed rotation.
+func (r ReplicaSetReconciler) completePasswordRotation(ctx context.Context, mdb *mdbv1.MongoDBCommunity, user mdbv1.MongoDBUser, rotation mdbv1.UserPasswordRotation, now time.Time) mdbv1.UserPasswordRotation {
+	r.log.Infof("Deleting shadow user %s of user %s", rotation.ShadowUsername, rotation.Username)
+	shadowCredentials := types.NamespacedName{Name: user.GetShadowScramCredentialsSecretName(), Namespace: mdb.Namespace}
+	if err := r.client.DeleteSecret(ctx, shadowCredentials); err != nil && !apiErrors.IsNotFound(err) {
+		r.log.Warnf("Could not delete the credentials of shadow user %s: %s", rotation.ShadowUsername, err)
+	}
+
+	rotation.Phase = mdbv1.PasswordRotationCompleted
+	rotation.LastTransitionTime = metav1.NewTime(now)
+	return rotation
+}
+
+// pendingUserPasswordRotations returns the rotations which are not Completed. It is called once the deployment is
+// ready, when the agents have deleted the shadow users of the Completed rotations, so that the deletions are not
+// sent again with every automation config.
+func pendingUserPasswordRotations(rotations []mdbv1.UserPasswordRotation) []mdbv1.UserPasswordRotation {
+	var pending []mdbv1.UserPasswordRotation
+	for _, rotation := range rotations {
+		if rotation.Phase != mdbv1.PasswordRotationCompleted {
+			pending = append(pending, rotation)
+		}
+	}
+	return pending
+}
+
+func userPasswordRotationsEqual(a, b []mdbv1.UserPasswordRotation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Username != b[i].Username || a[i].DB != b[i].DB || a[i].Phase != b[i].Phase ||
+			!a[i].LastTransitionTime.Equal(&b[i].LastTransitionTime) {
+			return false
+		}
+	}
+	return true
+}
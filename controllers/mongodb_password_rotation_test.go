@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+)
+
+func TestUserPasswordRotation(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet(mdbv1.MongoDBUser{
+		Name:                       "app-user",
+		DB:                         "admin",
+		PasswordSecretRef:          mdbv1.SecretKeyReference{Name: "app-user-password"},
+		ScramCredentialsSecretName: "app-user",
+		PasswordRotation:           &mdbv1.PasswordRotationConfiguration{GracePeriodSeconds: ptr.To(600)},
+	})
+	mgr := client.NewManager(ctx, &mdb)
+	setUserPassword(ctx, t, mgr.Client, mdb, "first-password")
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	previousCredentials := acUser(ctx, t, mgr.Client, mdb, "app-user").ScramSha256Creds.StoredKey
+
+	t.Run("The new password is granted to a shadow user", func(t *testing.T) {
+		setUserPassword(ctx, t, mgr.Client, mdb, "second-password")
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+		assert.InDelta(t, 600*time.Second, res.RequeueAfter, float64(5*time.Second))
+
+		rotation := userPasswordRotation(ctx, t, mgr.Client, mdb)
+		assert.Equal(t, mdbv1.PasswordRotationOverlapping, rotation.Phase)
+		assert.Equal(t, "app-user-rotating", rotation.ShadowUsername)
+
+		assert.Equal(t, previousCredentials, acUser(ctx, t, mgr.Client, mdb, "app-user").ScramSha256Creds.StoredKey)
+		assert.NotNil(t, acUser(ctx, t, mgr.Client, mdb, "app-user-rotating"))
+		assertConnectionStringUsername(ctx, t, mgr.Client, mdb, "app-user-rotating", "second-password")
+	})
+
+	t.Run("The user is granted the new password after the grace period", func(t *testing.T) {
+		elapseUserPasswordRotationGracePeriod(ctx, t, mgr.Client, mdb)
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+		assert.Greater(t, res.RequeueAfter, time.Duration(0))
+
+		assert.Equal(t, mdbv1.PasswordRotationRetiring, userPasswordRotation(ctx, t, mgr.Client, mdb).Phase)
+		assert.NotEqual(t, previousCredentials, acUser(ctx, t, mgr.Client, mdb, "app-user").ScramSha256Creds.StoredKey)
+		assert.NotNil(t, acUser(ctx, t, mgr.Client, mdb, "app-user-rotating"))
+		assertConnectionStringUsername(ctx, t, mgr.Client, mdb, "app-user", "second-password")
+	})
+
+	t.Run("The shadow user is deleted after the grace period", func(t *testing.T) {
+		elapseUserPasswordRotationGracePeriod(ctx, t, mgr.Client, mdb)
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+
+		assert.Nil(t, acUser(ctx, t, mgr.Client, mdb, "app-user-rotating"))
+
+		ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+		require.NoError(t, err)
+		assert.Contains(t, ac.Auth.UsersDeleted, automationconfig.DeletedUser{User: "app-user-rotating", Dbs: []string{"admin"}})
+
+		// the deployment deleting the shadow user is ready, the rotation is removed from the status
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+		assert.Empty(t, mdb.Status.UserPasswordRotations)
+
+		exists, err := secret.Exists(ctx, mgr.Client, types.NamespacedName{Name: "app-user-scram-credentials-rotating", Namespace: mdb.Namespace})
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("The shadow user is only deleted once", func(t *testing.T) {
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+
+		ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+		require.NoError(t, err)
+		assert.NotContains(t, ac.Auth.UsersDeleted, automationconfig.DeletedUser{User: "app-user-rotating", Dbs: []string{"admin"}})
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+		assert.Empty(t, mdb.Status.UserPasswordRotations)
+		assertConnectionStringUsername(ctx, t, mgr.Client, mdb, "app-user", "second-password")
+	})
+}
+
+func TestUserPasswordRotation_PasswordIsSwappedWithoutRotationConfigured(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet(mdbv1.MongoDBUser{
+		Name:                       "app-user",
+		DB:                         "admin",
+		PasswordSecretRef:          mdbv1.SecretKeyReference{Name: "app-user-password"},
+		ScramCredentialsSecretName: "app-user",
+	})
+	mgr := client.NewManager(ctx, &mdb)
+	setUserPassword(ctx, t, mgr.Client, mdb, "first-password")
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	setUserPassword(ctx, t, mgr.Client, mdb, "second-password")
+	res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	assert.Empty(t, mdb.Status.UserPasswordRotations)
+	assert.Nil(t, acUser(ctx, t, mgr.Client, mdb, "app-user-rotating"))
+	assertConnectionStringUsername(ctx, t, mgr.Client, mdb, "app-user", "second-password")
+}
+
+func setUserPassword(ctx context.Context, t *testing.T, c client.Client, mdb mdbv1.MongoDBCommunity, password string) {
+	err := secret.CreateOrUpdate(ctx, c, secret.Builder().
+		SetName(mdb.Spec.Users[0].PasswordSecretRef.Name).
+		SetNamespace(mdb.Namespace).
+		SetField(mdb.Spec.Users[0].GetPasswordSecretKey(), password).
+		Build())
+	require.NoError(t, err)
+}
+
+func acUser(ctx context.Context, t *testing.T, c client.Client, mdb mdbv1.MongoDBCommunity, username string) *automationconfig.MongoDBUser {
+	ac, err := automationconfig.ReadFromSecret(ctx, c, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	for i := range ac.Auth.Users {
+		if ac.Auth.Users[i].Username == username {
+			return &ac.Auth.Users[i]
+		}
+	}
+	return nil
+}
+
+func userPasswordRotation(ctx context.Context, t *testing.T, c client.Client, mdb mdbv1.MongoDBCommunity) mdbv1.UserPasswordRotation {
+	err := c.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	require.Len(t, mdb.Status.UserPasswordRotations, 1)
+	return mdb.Status.UserPasswordRotations[0]
+}
+
+// elapseUserPasswordRotationGracePeriod moves the current phase of the rotation back in time.
+func elapseUserPasswordRotationGracePeriod(ctx context.Context, t *testing.T, c client.Client, mdb mdbv1.MongoDBCommunity) {
+	err := c.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	require.Len(t, mdb.Status.UserPasswordRotations, 1)
+	mdb.Status.UserPasswordRotations[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	require.NoError(t, c.Status().Update(ctx, &mdb))
+}
+
+func assertConnectionStringUsername(ctx context.Context, t *testing.T, c client.Client, mdb mdbv1.MongoDBCommunity, username, password string) {
+	connectionStringSecret, err := c.GetSecret(ctx, types.NamespacedName{Name: mdb.GetAuthUsers()[0].ConnectionStringSecretName, Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	assert.Equal(t, username, string(connectionStringSecret.Data["username"]))
+	assert.Equal(t, password, string(connectionStringSecret.Data["password"]))
+	assert.Contains(t, string(connectionStringSecret.Data["connectionString.standard"]), username+":"+password+"@")
+}
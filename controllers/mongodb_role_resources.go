@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	k8sClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/controllers/validation"
+)
+
+// roleResource is a MongoDBCommunityRole resource referencing the reconciled MongoDBCommunity resource.
+type roleResource struct {
+	resource mdbv1.MongoDBCommunityRole
+
+	// phase is Running if the role can be created, the reason why it cannot is stored in message.
+	phase   mdbv1.Phase
+	message string
+}
+
+// getRoleResources returns the MongoDBCommunityRole resources referencing the given resource, ordered by namespace
+// and name. A role is only created if its namespace is allowed, it is valid and its name is not already used by
+// another role. Roles whose inheritance is invalid are not created either, unless they are defined in the spec of
+// the resource, in which case an error is returned.
+//
+// It is called once per reconciliation, the custom roles are passed down to build the automation config.
+func (r ReplicaSetReconciler) getRoleResources(ctx context.Context, mdb mdbv1.MongoDBCommunity) ([]roleResource, error) {
+	list := mdbv1.MongoDBCommunityRoleList{}
+	if err := r.client.List(ctx, &list, referencingResourcesListOptions(mdb)...); err != nil {
+		return nil, fmt.Errorf("could not list MongoDBCommunityRole resources: %s", err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return k8sClient.ObjectKeyFromObject(&list.Items[i]).String() < k8sClient.ObjectKeyFromObject(&list.Items[j]).String()
+	})
+
+	definedBy := map[mdbv1.Role]string{}
+	for _, role := range mdb.Spec.Security.Roles {
+		definedBy[mdbv1.Role{Name: role.Role, DB: role.DB}] = "spec.security.roles"
+	}
+
+	var roleResources []roleResource
+	for _, resource := range list.Items {
+		if resource.MongoDBResourceNamespacedName() != mdb.NamespacedName() {
+			continue
+		}
+
+		role := roleResource{resource: resource, phase: mdbv1.Running}
+		key := mdbv1.Role{Name: resource.Spec.Role, DB: resource.Spec.DB}
+		if !mdb.Spec.Security.Authentication.IsUserNamespaceAllowed(resource.Namespace, mdb.Namespace) {
+			role.phase = mdbv1.Failed
+			role.message = fmt.Sprintf("namespace %s is not allowed to create roles in MongoDBCommunity %s", resource.Namespace, mdb.NamespacedName())
+		} else if err := validation.ValidateCustomRole(resource.Spec.CustomRole); err != nil {
+			role.phase = mdbv1.Failed
+			role.message = err.Error()
+		} else if definedBy[key] != "" {
+			role.phase = mdbv1.Failed
+			role.message = fmt.Sprintf("role %s@%s is already defined by %s", key.Name, key.DB, definedBy[key])
+		} else {
+			definedBy[key] = fmt.Sprintf("MongoDBCommunityRole %s", k8sClient.ObjectKeyFromObject(&resource))
+			if unknown := validation.UnknownActions(resource.Spec.CustomRole); len(unknown) > 0 {
+				r.log.Warnf("MongoDBCommunityRole %s has actions unknown to the operator, MongoDB may reject them: %s", k8sClient.ObjectKeyFromObject(&resource), strings.Join(unknown, ", "))
+			}
+		}
+		roleResources = append(roleResources, role)
+	}
+
+	// the roles with an invalid inheritance are removed one at a time, as they can be inherited by other roles.
+	for {
+		err := validation.ValidateRoleInheritance(customRoles(mdb, roleResources))
+		if err == nil {
+			return roleResources, nil
+		}
+		roleErr := validation.RoleValidationError{}
+		if !errors.As(err, &roleErr) {
+			return nil, err
+		}
+		i := findRoleResource(roleResources, roleErr.Roles)
+		if i < 0 {
+			return nil, err
+		}
+		roleResources[i].phase = mdbv1.Failed
+		roleResources[i].message = err.Error()
+	}
+}
+
+// findRoleResource returns the index of the first running role resource defining one of the given roles, or -1.
+func findRoleResource(roleResources []roleResource, roles []mdbv1.Role) int {
+	for i, role := range roleResources {
+		if role.phase != mdbv1.Running {
+			continue
+		}
+		for _, r := range roles {
+			if role.resource.Spec.Role == r.Name && role.resource.Spec.DB == r.DB {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// customRoles returns the custom roles defined in the spec of the resource followed by the roles of the
+// MongoDBCommunityRole resources which can be created. It returns nil if no role is defined at all.
+func customRoles(mdb mdbv1.MongoDBCommunity, roleResources []roleResource) []mdbv1.CustomRole {
+	var roles []mdbv1.CustomRole
+	if mdb.Spec.Security.Roles != nil {
+		roles = append([]mdbv1.CustomRole{}, mdb.Spec.Security.Roles...)
+	}
+	for _, role := range roleResources {
+		if role.phase == mdbv1.Running {
+			roles = append(roles, role.resource.Spec.CustomRole)
+		}
+	}
+	return roles
+}
+
+// validateUserRoles checks that the roles granted to the users defined in the spec of the resource exist.
+func validateUserRoles(mdb mdbv1.MongoDBCommunity, roles []mdbv1.CustomRole) error {
+	for _, user := range mdb.GetAuthUsers() {
+		if err := validation.ValidateUserRoles(user, roles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateRoleResources updates the status of the MongoDBCommunityRole resources.
+func (r ReplicaSetReconciler) updateRoleResources(ctx context.Context, roleResources []roleResource) {
+	for _, role := range roleResources {
+		resource := role.resource
+		roleStatus := mdbv1.MongoDBCommunityRoleStatus{Phase: role.phase, Message: role.message}
+		if resource.Status == roleStatus {
+			continue
+		}
+		resource.Status = roleStatus
+		if err := r.client.Status().Update(ctx, &resource); err != nil {
+			r.log.Errorf("Could not update the status of MongoDBCommunityRole %s: %s", k8sClient.ObjectKeyFromObject(&resource), err)
+		}
+	}
+}
+
+// roleResourceToMongoDBCommunity returns a reconcile request for the MongoDBCommunity resource referenced by a
+// MongoDBCommunityRole resource.
+func roleResourceToMongoDBCommunity(_ context.Context, obj k8sClient.Object) []reconcile.Request {
+	role, ok := obj.(*mdbv1.MongoDBCommunityRole)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: role.MongoDBResourceNamespacedName()}}
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+)
+
+func TestRoleResource_RoleIsCreated(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	roleResource := newRoleResource("app-role", "appRole", mdb)
+	require.NoError(t, mgr.Client.Create(ctx, &roleResource))
+	userResource := newUserResource("app-user", mdb.Namespace, mdb)
+	userResource.Spec.Roles = []mdbv1.Role{{Name: "appRole", DB: "admin"}}
+	createUserResource(ctx, t, mgr.Client, userResource, "app-password")
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	require.Len(t, ac.Roles, 1)
+	assert.Equal(t, "appRole", ac.Roles[0].Role)
+	assert.Equal(t, []automationconfig.Role{{Role: "readWrite", Database: "admin"}}, ac.Roles[0].Roles)
+
+	assert.Equal(t, mdbv1.Running, getRoleResource(ctx, t, mgr.Client, roleResource).Status.Phase)
+	assert.Equal(t, mdbv1.Running, getUserResource(ctx, t, mgr.Client, userResource).Status.Phase)
+}
+
+func TestRoleResource_InvalidRolesAreNotCreated(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+
+	unknownAction := newRoleResource("unknown-action", "unknownActionRole", mdb)
+	unknownAction.Spec.Privileges = []mdbv1.Privilege{{Resource: mdbv1.Resource{Cluster: true}, Actions: []string{"fnid"}}}
+	require.NoError(t, mgr.Client.Create(ctx, &unknownAction))
+
+	cycleA := newRoleResource("cycle-a", "cycleA", mdb)
+	cycleA.Spec.Roles = []mdbv1.Role{{Name: "cycleB", DB: "admin"}}
+	require.NoError(t, mgr.Client.Create(ctx, &cycleA))
+	cycleB := newRoleResource("cycle-b", "cycleB", mdb)
+	cycleB.Spec.Roles = []mdbv1.Role{{Name: "cycleA", DB: "admin"}}
+	require.NoError(t, mgr.Client.Create(ctx, &cycleB))
+
+	userResource := newUserResource("app-user", mdb.Namespace, mdb)
+	userResource.Spec.Roles = []mdbv1.Role{{Name: "cycleA", DB: "admin"}}
+	createUserResource(ctx, t, mgr.Client, userResource, "app-password")
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	// cycleB inherits from cycleA, which is removed to break the cycle. Unknown
+	// actions are only warned about, since newer server versions may add them.
+	require.Len(t, ac.Roles, 1)
+	assert.Equal(t, "unknownActionRole", ac.Roles[0].Role)
+
+	assert.Equal(t, mdbv1.Running, getRoleResource(ctx, t, mgr.Client, unknownAction).Status.Phase)
+	assert.Contains(t, getRoleResource(ctx, t, mgr.Client, cycleA).Status.Message, "cycleA@admin -> cycleB@admin -> cycleA@admin")
+	assert.Contains(t, getRoleResource(ctx, t, mgr.Client, cycleB).Status.Message, "unknown role cycleA@admin")
+
+	userResource = getUserResource(ctx, t, mgr.Client, userResource)
+	assert.Equal(t, mdbv1.Failed, userResource.Status.Phase)
+	assert.Contains(t, userResource.Status.Message, "unknown role cycleA@admin")
+	assert.Nil(t, acUser(ctx, t, mgr.Client, mdb, "app-user"))
+}
+
+func TestRoleResource_SpecUserWithUnknownRoleFails(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet(mdbv1.MongoDBUser{
+		Name:                       "app-user",
+		DB:                         "admin",
+		PasswordSecretRef:          mdbv1.SecretKeyReference{Name: "app-user-password"},
+		ScramCredentialsSecretName: "app-user",
+		Roles:                      []mdbv1.Role{{Name: "appRole", DB: "admin"}},
+	})
+	mgr := client.NewManager(ctx, &mdb)
+	setUserPassword(ctx, t, mgr.Client, mdb, "password")
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+	assert.Contains(t, mdb.Status.Message, "unknown role appRole@admin")
+
+	roleResource := newRoleResource("app-role", "appRole", mdb)
+	require.NoError(t, mgr.Client.Create(ctx, &roleResource))
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	assert.NotNil(t, acUser(ctx, t, mgr.Client, mdb, "app-user"))
+}
+
+func newRoleResource(name, role string, mdb mdbv1.MongoDBCommunity) mdbv1.MongoDBCommunityRole {
+	return mdbv1.MongoDBCommunityRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: mdb.Namespace,
+		},
+		Spec: mdbv1.MongoDBCommunityRoleSpec{
+			MongoDBResourceRef: mdbv1.MongoDBCommunityReference{Name: mdb.Name},
+			CustomRole: mdbv1.CustomRole{
+				Role:  role,
+				DB:    "admin",
+				Roles: []mdbv1.Role{{Name: "readWrite", DB: "admin"}},
+			},
+		},
+	}
+}
+
+func getRoleResource(ctx context.Context, t *testing.T, c client.Client, roleResource mdbv1.MongoDBCommunityRole) mdbv1.MongoDBCommunityRole {
+	err := c.Get(ctx, types.NamespacedName{Name: roleResource.Name, Namespace: roleResource.Namespace}, &roleResource)
+	require.NoError(t, err)
+	return roleResource
+}
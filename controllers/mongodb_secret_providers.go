@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/envvar"
+)
+
+const (
+	// secretsStorePathEnv is the directory the secrets of the file providers are mounted at in the operator container.
+	secretsStorePathEnv     = "MDB_SECRETS_STORE_PATH"
+	defaultSecretsStorePath = "/mnt/secrets-store"
+
+	vaultCAKey         = "ca.crt"
+	vaultClientTimeout = 10 * time.Second
+
+	// secretProvidersResyncSeconds is how often the secrets read from a secret provider are read again, as the
+	// operator isn't notified when they change.
+	secretProvidersResyncSeconds = 300
+)
+
+// secretClient returns the client the secrets referenced by the resource are read with. Secrets referenced with a
+// secret provider are read from it, every other secret is read from and written to Kubernetes.
+func (r ReplicaSetReconciler) secretClient(ctx context.Context, mdb mdbv1.MongoDBCommunity) (secret.GetUpdateCreateDeleter, error) {
+	references := secretProviderReferences(mdb)
+	keyfile := mdb.Spec.Security.Authentication.AgentKeyfileSecretRef
+	if len(references) == 0 && keyfile == nil {
+		return r.client, nil
+	}
+
+	providers := map[string]secret.Getter{}
+	getProvider := func(name string) (secret.Getter, error) {
+		if name == "" {
+			return r.client, nil
+		}
+		if provider, ok := providers[name]; ok {
+			return provider, nil
+		}
+		config, ok := mdb.Spec.Security.GetSecretProvider(name)
+		if !ok {
+			return nil, fmt.Errorf("secret provider %s is not configured in spec.security.secretProviders", name)
+		}
+		provider, err := r.buildSecretProvider(ctx, mdb, config)
+		if err != nil {
+			return nil, fmt.Errorf("could not configure secret provider %s: %s", name, err)
+		}
+		providers[name] = provider
+		return provider, nil
+	}
+
+	routes := map[types.NamespacedName]secret.Getter{}
+	for nsName, providerName := range references {
+		provider, err := getProvider(providerName)
+		if err != nil {
+			return nil, err
+		}
+		routes[nsName] = provider
+	}
+
+	if keyfile != nil {
+		provider, err := getProvider(keyfile.Provider)
+		if err != nil {
+			return nil, err
+		}
+		key := keyfile.Key
+		if key == "" {
+			key = constants.AgentKeyfileKey
+		}
+		source := types.NamespacedName{Name: keyfile.Name, Namespace: mdb.Namespace}
+		routes[mdb.GetAgentKeyfileSecretNamespacedName()] = secret.WithKey(provider, source, key, mdb.GetAgentKeyfileSecretNamespacedName(), constants.AgentKeyfileKey)
+	}
+
+	return secret.WithProviders(r.client, routes), nil
+}
+
+// secretProvidersResync returns the number of seconds after which the secrets read from a secret provider must be
+// read again, or 0 if the resource doesn't read any.
+func secretProvidersResync(mdb mdbv1.MongoDBCommunity) int {
+	if keyfile := mdb.Spec.Security.Authentication.AgentKeyfileSecretRef; keyfile != nil && keyfile.Provider != "" {
+		return secretProvidersResyncSeconds
+	}
+	if len(secretProviderReferences(mdb)) > 0 {
+		return secretProvidersResyncSeconds
+	}
+	return 0
+}
+
+// secretProviderReferences returns the secrets referenced by the resource which are read from a secret provider,
+// with the name of their provider.
+func secretProviderReferences(mdb mdbv1.MongoDBCommunity) map[types.NamespacedName]string {
+	references := map[types.NamespacedName]string{}
+	add := func(name, provider string) {
+		if name != "" && provider != "" {
+			references[types.NamespacedName{Name: name, Namespace: mdb.Namespace}] = provider
+		}
+	}
+
+	for _, user := range mdb.Spec.Users {
+		add(user.PasswordSecretRef.Name, user.PasswordSecretRef.Provider)
+	}
+	if mdb.Spec.Prometheus != nil {
+		add(mdb.Spec.Prometheus.PasswordSecretRef.Name, mdb.Spec.Prometheus.PasswordSecretRef.Provider)
+		add(mdb.Spec.Prometheus.TLSSecretRef.Name, mdb.Spec.Prometheus.TLSSecretRef.Provider)
+	}
+	if ldap := mdb.Spec.Security.Authentication.Ldap; ldap != nil && ldap.BindQueryPasswordSecretRef != nil {
+		add(ldap.BindQueryPasswordSecretRef.Name, ldap.BindQueryPasswordSecretRef.Provider)
+	}
+	if encryptionAtRest := mdb.Spec.Security.EncryptionAtRest; encryptionAtRest != nil && encryptionAtRest.KeyFileSecretRef != nil {
+		add(encryptionAtRest.KeyFileSecretRef.Name, encryptionAtRest.KeyFileSecretRef.Provider)
+	}
+	if mdb.IsTLSConfiguredThisReconciliation() {
+		for _, secretName := range tlsServerSecretNamespacedNames(mdb) {
+			add(secretName.Name, mdb.Spec.Security.TLS.CertificateKeySecretProvider)
+		}
+	}
+	return references
+}
+
+// buildSecretProvider returns the provider reading the secrets of the given store. The Vault token is read from a
+// Kubernetes Secret.
+func (r ReplicaSetReconciler) buildSecretProvider(ctx context.Context, mdb mdbv1.MongoDBCommunity, config mdbv1.SecretProvider) (secret.Getter, error) {
+	switch config.Type {
+	case mdbv1.SecretProviderFile:
+		return secret.NewFileProvider(envvar.GetEnvOrDefault(secretsStorePathEnv, defaultSecretsStorePath)), nil // nolint:forbidigo
+	case mdbv1.SecretProviderVault:
+		vault := config.Vault
+		if vault == nil {
+			return nil, errors.New("the Vault provider is not configured")
+		}
+
+		tokenSecret := types.NamespacedName{Name: vault.TokenSecretRef.Name, Namespace: mdb.Namespace}
+		r.secretWatcher.Watch(ctx, tokenSecret, mdb.NamespacedName())
+		token, err := secret.ReadKey(ctx, r.client, vault.GetTokenKey(), tokenSecret)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the Vault token: %s", err)
+		}
+
+		httpClient := &http.Client{Timeout: vaultClientTimeout}
+		if vault.CaConfigMap != nil {
+			caConfigMap := types.NamespacedName{Name: vault.CaConfigMap.Name, Namespace: mdb.Namespace}
+			r.configMapWatcher.Watch(ctx, caConfigMap, mdb.NamespacedName())
+			cm, err := r.client.GetConfigMap(ctx, caConfigMap)
+			if err != nil {
+				return nil, fmt.Errorf("could not read the CA of the Vault server: %s", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM([]byte(cm.Data[vaultCAKey])) {
+				return nil, fmt.Errorf("ConfigMap %s does not contain a PEM encoded certificate under the key %s", caConfigMap.Name, vaultCAKey)
+			}
+			httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}
+		}
+
+		return secret.NewVaultProvider(vault.Address, vault.GetMountPath(), vault.PathPrefix, token, httpClient), nil
+	default:
+		return nil, fmt.Errorf("unknown secret provider type %s", config.Type)
+	}
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+)
+
+func newSecretProvidersReplicaSet(vaultAddress string) mdbv1.MongoDBCommunity {
+	mdb := newScramReplicaSet(mdbv1.MongoDBUser{
+		Name:                       "app",
+		DB:                         "admin",
+		PasswordSecretRef:          mdbv1.SecretKeyReference{Name: "app-password", Provider: "csi"},
+		Roles:                      []mdbv1.Role{{Name: "readWrite", DB: "app"}},
+		ScramCredentialsSecretName: "app-scram",
+	})
+	mdb.Spec.Security.SecretProviders = []mdbv1.SecretProvider{
+		{Name: "csi", Type: mdbv1.SecretProviderFile},
+		{Name: "vault", Type: mdbv1.SecretProviderVault, Vault: &mdbv1.VaultSecretProvider{
+			Address:        vaultAddress,
+			PathPrefix:     "mongodb",
+			TokenSecretRef: mdbv1.SecretKeyReference{Name: "vault-token"},
+		}},
+	}
+	mdb.Spec.Security.Authentication.AgentKeyfileSecretRef = &mdbv1.SecretKeyReference{Name: "agent-keyfile", Provider: "vault"}
+	return mdb
+}
+
+func TestSecretProviders_CredentialsAreReadFromTheirProviders(t *testing.T) {
+	ctx := context.Background()
+	storePath := t.TempDir()
+	t.Setenv(secretsStorePathEnv, storePath)
+	require.NoError(t, os.MkdirAll(filepath.Join(storePath, "my-ns", "app-password"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(storePath, "my-ns", "app-password", "password"), []byte("csi-password"), 0o600))
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "root" || req.URL.Path != "/v1/secret/data/mongodb/agent-keyfile" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": map[string]string{"keyfile": "vault-keyfile"}}})
+	}))
+	defer vault.Close()
+
+	mdb := newSecretProvidersReplicaSet(vault.URL)
+	mgr := client.NewManager(ctx, &mdb)
+	err := mgr.Client.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "vault-token", Namespace: mdb.Namespace},
+		Data:       map[string][]byte{"token": []byte("root")},
+	})
+	require.NoError(t, err)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	t.Run("The reconciliation is requeued to read the secrets again", func(t *testing.T) {
+		assert.Equal(t, secretProvidersResyncSeconds*time.Second, res.RequeueAfter)
+		err := mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+		require.NoError(t, err)
+		assert.Equal(t, mdbv1.Running, mdb.Status.Phase)
+	})
+
+	t.Run("The user password is read from the mounted files", func(t *testing.T) {
+		assertConnectionStringUsername(ctx, t, mgr.Client, mdb, "app", "csi-password")
+		_, err := mgr.Client.GetSecret(ctx, types.NamespacedName{Name: "app-password", Namespace: mdb.Namespace})
+		assert.True(t, apiErrors.IsNotFound(err), "the password must not be copied to a Kubernetes Secret")
+	})
+
+	t.Run("The agent keyfile is read from Vault", func(t *testing.T) {
+		ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+		require.NoError(t, err)
+		assert.Equal(t, "vault-keyfile", ac.Auth.Key)
+		_, err = mgr.Client.GetSecret(ctx, mdb.GetAgentKeyfileSecretNamespacedName())
+		assert.True(t, apiErrors.IsNotFound(err), "the keyfile must not be generated")
+	})
+}
+
+func TestSecretProviders_MissingSecretIsNotCreated(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv(secretsStorePathEnv, t.TempDir())
+	mdb := newSecretProvidersReplicaSet("http://vault.invalid:8200")
+	mdb.Spec.Security.SecretProviders = mdb.Spec.Security.SecretProviders[:1]
+	mdb.Spec.Security.Authentication.AgentKeyfileSecretRef = nil
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+	assert.Contains(t, mdb.Status.Message, "secret my-ns/app-password not found in its secret provider")
+}
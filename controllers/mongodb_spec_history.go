@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/configmap"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/result"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/status"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// rollbackToRevision can be set on a MongoDBCommunity resource to restore the spec stored
+	// under the given revision of its spec history.
+	rollbackToRevision = "mongodb.com/v1.rollbackToRevision"
+	// rollbackRejectedReason is the reason of the event recorded when the requested revision can't be restored.
+	rollbackRejectedReason = "RollbackRejected"
+
+	specRevisionKeyPrefix = "revision-"
+)
+
+// specRevision is a single entry of the spec history of a MongoDBCommunity resource.
+type specRevision struct {
+	Revision                int                        `json:"revision"`
+	AutomationConfigVersion int                        `json:"automationConfigVersion"`
+	AppliedAt               string                     `json:"appliedAt"`
+	Spec                    mdbv1.MongoDBCommunitySpec `json:"spec"`
+}
+
+// readSpecHistory returns all the revisions stored in the spec history ConfigMap, ordered from the oldest
+// to the most recent one. An empty history is returned if the ConfigMap does not exist yet.
+func readSpecHistory(ctx context.Context, getter configmap.Getter, nsName types.NamespacedName) ([]specRevision, error) {
+	data, err := configmap.ReadData(ctx, getter, nsName)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var history []specRevision
+	for key, value := range data {
+		if !strings.HasPrefix(key, specRevisionKeyPrefix) {
+			continue
+		}
+		revision := specRevision{}
+		if err := json.Unmarshal([]byte(value), &revision); err != nil {
+			return nil, fmt.Errorf("could not parse spec history entry %s: %s", key, err)
+		}
+		history = append(history, revision)
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Revision < history[j].Revision
+	})
+	return history, nil
+}
+
+// findSpecRevision returns the revision with the given number from the history.
+func findSpecRevision(history []specRevision, revisionNumber int) (specRevision, bool) {
+	for _, revision := range history {
+		if revision.Revision == revisionNumber {
+			return revision, true
+		}
+	}
+	return specRevision{}, false
+}
+
+// appendSpecRevision adds a revision for the given spec to the history, unless the spec is the same as the one
+// of the most recent revision. The oldest revisions are pruned so that at most limit revisions are kept.
+func appendSpecRevision(history []specRevision, spec mdbv1.MongoDBCommunitySpec, acVersion int, appliedAt time.Time, limit int) ([]specRevision, bool, error) {
+	nextRevision := 1
+	if len(history) > 0 {
+		latest := history[len(history)-1]
+		equal, err := specsAreEqual(latest.Spec, spec)
+		if err != nil {
+			return nil, false, err
+		}
+		if equal {
+			return history, false, nil
+		}
+		nextRevision = latest.Revision + 1
+	}
+
+	history = append(history, specRevision{
+		Revision:                nextRevision,
+		AutomationConfigVersion: acVersion,
+		AppliedAt:               appliedAt.UTC().Format(time.RFC3339),
+		Spec:                    spec,
+	})
+
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return history, true, nil
+}
+
+// specsAreEqual compares both specs after a JSON round trip, as this is how they are stored in the history.
+func specsAreEqual(a, b mdbv1.MongoDBCommunitySpec) (bool, error) {
+	aBytes, err := normalizedSpecBytes(a)
+	if err != nil {
+		return false, err
+	}
+	bBytes, err := normalizedSpecBytes(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aBytes, bBytes), nil
+}
+
+func normalizedSpecBytes(spec mdbv1.MongoDBCommunitySpec) ([]byte, error) {
+	specBytes, err := json.Marshal(&spec)
+	if err != nil {
+		return nil, err
+	}
+	normalizedSpec := mdbv1.MongoDBCommunitySpec{}
+	if err := json.Unmarshal(specBytes, &normalizedSpec); err != nil {
+		return nil, err
+	}
+	return json.Marshal(&normalizedSpec)
+}
+
+// recordSpecRevision stores the current spec, together with the automation config version it produced,
+// in the spec history ConfigMap of the resource.
+func (r *ReplicaSetReconciler) recordSpecRevision(ctx context.Context, mdb mdbv1.MongoDBCommunity) error {
+	historyNsName := mdb.SpecHistoryConfigMapNamespacedName()
+	history, err := readSpecHistory(ctx, r.client, historyNsName)
+	if err != nil {
+		return err
+	}
+
+	ac, err := automationconfig.ReadFromSecret(ctx, r.client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+	if err != nil {
+		return fmt.Errorf("could not read existing automation config: %s", err)
+	}
+
+	history, changed, err := appendSpecRevision(history, mdb.Spec, ac.Version, time.Now(), mdb.Spec.GetRevisionHistoryLimit())
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	historyBuilder := configmap.Builder().
+		SetName(historyNsName.Name).
+		SetNamespace(historyNsName.Namespace).
+		SetOwnerReferences(mdb.GetOwnerReferences())
+
+	for i := range history {
+		revisionBytes, err := json.Marshal(&history[i])
+		if err != nil {
+			return err
+		}
+		historyBuilder.SetDataField(specRevisionKeyPrefix+strconv.Itoa(history[i].Revision), string(revisionBytes))
+	}
+
+	r.log.Debugf("Recording the spec history, %d revisions retained", len(history))
+	return configmap.CreateOrUpdate(ctx, r.client, historyBuilder.Build())
+}
+
+// rollbackToSpecRevision replaces the spec of the resource with the one stored under the revision
+// requested with the rollbackToRevision annotation. The annotation is removed once the spec has been restored.
+// If the requested revision is invalid or not present in the history, the annotation is removed, a warning event
+// is recorded and false is returned, so that the reconciliation continues with the current spec.
+func (r *ReplicaSetReconciler) rollbackToSpecRevision(ctx context.Context, mdb *mdbv1.MongoDBCommunity) (bool, reconcile.Result, error) {
+	requestedRevision := mdb.Annotations[rollbackToRevision]
+	revisionNumber, err := strconv.Atoi(requestedRevision)
+	if err != nil {
+		return r.rejectRollback(ctx, mdb, fmt.Sprintf("Invalid value for annotation %s: %s", rollbackToRevision, requestedRevision))
+	}
+
+	history, err := readSpecHistory(ctx, r.client, mdb.SpecHistoryConfigMapNamespacedName())
+	if err != nil {
+		res, err := status.Update(ctx, r.client.Status(), mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error reading the spec history: %s", err)).
+			withFailedPhase())
+		return true, res, err
+	}
+
+	revision, ok := findSpecRevision(history, revisionNumber)
+	if !ok {
+		return r.rejectRollback(ctx, mdb, fmt.Sprintf("Revision %d is not present in the spec history", revisionNumber))
+	}
+
+	r.log.Infof("Rolling back to spec revision %d, applied at %s with automation config version %d", revision.Revision, revision.AppliedAt, revision.AutomationConfigVersion)
+	mdb.Spec = revision.Spec
+	delete(mdb.Annotations, rollbackToRevision)
+	if err := r.client.Update(ctx, mdb); err != nil {
+		r.log.Errorf("Could not roll back to spec revision %d: %s", revision.Revision, err)
+		res, err := result.Failed()
+		return true, res, err
+	}
+
+	// The spec update triggers a new reconciliation which applies the restored spec.
+	res, err := result.OK()
+	return true, res, err
+}
+
+// rejectRollback removes the rollbackToRevision annotation and records the reason the rollback was not performed
+// as a warning event. It returns false once the annotation is removed, so that the reconciliation continues.
+func (r *ReplicaSetReconciler) rejectRollback(ctx context.Context, mdb *mdbv1.MongoDBCommunity, message string) (bool, reconcile.Result, error) {
+	r.log.Warn(message)
+	r.recorder.Event(mdb, corev1.EventTypeWarning, rollbackRejectedReason, message)
+	delete(mdb.Annotations, rollbackToRevision)
+	if err := r.client.Update(ctx, mdb); err != nil {
+		r.log.Errorf("Could not remove the annotation %s: %s", rollbackToRevision, err)
+		res, err := result.Failed()
+		return true, res, err
+	}
+	return false, reconcile.Result{}, nil
+}
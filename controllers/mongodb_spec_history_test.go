@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestAppendSpecRevision(t *testing.T) {
+	mdb := newTestReplicaSet()
+	now := time.Now()
+
+	history, changed, err := appendSpecRevision(nil, mdb.Spec, 1, now, 2)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Len(t, history, 1)
+	assert.Equal(t, 1, history[0].Revision)
+	assert.Equal(t, 1, history[0].AutomationConfigVersion)
+
+	t.Run("Same spec is not recorded twice", func(t *testing.T) {
+		sameHistory, changed, err := appendSpecRevision(history, mdb.Spec, 2, now, 2)
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, history, sameHistory)
+	})
+
+	t.Run("Oldest revisions are pruned", func(t *testing.T) {
+		spec := mdb.Spec
+		spec.AgentConfiguration.LogLevel = mdbv1.LogLevelDebug
+		history, changed, err := appendSpecRevision(history, spec, 2, now, 2)
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		spec.AgentConfiguration.LogLevel = mdbv1.LogLevelWarn
+		history, changed, err = appendSpecRevision(history, spec, 3, now, 2)
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		assert.Len(t, history, 2)
+		assert.Equal(t, 2, history[0].Revision)
+		assert.Equal(t, 3, history[1].Revision)
+		assert.Equal(t, 3, history[1].AutomationConfigVersion)
+	})
+}
+
+func TestSpecHistory_IsRecordedOnSuccessfulReconciliation(t *testing.T) {
+	ctx := context.Background()
+	mdb := newTestReplicaSet()
+
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	// reconciling again without changes does not add a revision
+	res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	history, err := readSpecHistory(ctx, mgr.Client, mdb.SpecHistoryConfigMapNamespacedName())
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 1, history[0].Revision)
+	assert.Equal(t, 1, history[0].AutomationConfigVersion)
+	assert.NotEmpty(t, history[0].AppliedAt)
+}
+
+func TestSpecHistory_RollbackToRevision(t *testing.T) {
+	ctx := context.Background()
+	mdb := newTestReplicaSet()
+
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	mdb.Spec.AdditionalMongodConfig = mdbv1.NewMongodConfiguration()
+	mdb.Spec.AdditionalMongodConfig.SetOption("storage.wiredTiger.engineConfig.cacheSizeGB", int64(1))
+	require.NoError(t, mgr.GetClient().Update(ctx, &mdb))
+	res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	history, err := readSpecHistory(ctx, mgr.Client, mdb.SpecHistoryConfigMapNamespacedName())
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 2, history[1].AutomationConfigVersion)
+
+	mdb.Annotations[rollbackToRevision] = "1"
+	require.NoError(t, mgr.GetClient().Update(ctx, &mdb))
+	res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	rolledBack := mdbv1.MongoDBCommunity{}
+	require.NoError(t, mgr.GetClient().Get(ctx, mdb.NamespacedName(), &rolledBack))
+	assert.NotContains(t, rolledBack.Annotations, rollbackToRevision)
+	assert.Empty(t, rolledBack.Spec.AdditionalMongodConfig.Object)
+}
+
+func TestSpecHistory_RollbackToInvalidRevisionIsRejected(t *testing.T) {
+	for _, revision := range []string{"5", "latest"} {
+		t.Run(revision, func(t *testing.T) {
+			ctx := context.Background()
+			mdb := newTestReplicaSet()
+			mdb.Annotations[rollbackToRevision] = revision
+
+			mgr := client.NewManager(ctx, &mdb)
+			r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+			recorder := record.NewFakeRecorder(10)
+			r.recorder = recorder
+			res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+			assertReconciliationSuccessful(t, res, err)
+
+			reconciled := mdbv1.MongoDBCommunity{}
+			require.NoError(t, mgr.GetClient().Get(ctx, mdb.NamespacedName(), &reconciled))
+			assert.NotContains(t, reconciled.Annotations, rollbackToRevision)
+			require.Len(t, recorder.Events, 1)
+			assert.Contains(t, <-recorder.Events, rollbackRejectedReason)
+
+			// later changes of the spec are applied
+			reconciled.Spec.AgentConfiguration.LogLevel = mdbv1.LogLevelDebug
+			require.NoError(t, mgr.GetClient().Update(ctx, &reconciled))
+			res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+			assertReconciliationSuccessful(t, res, err)
+
+			history, err := readSpecHistory(ctx, mgr.Client, mdb.SpecHistoryConfigMapNamespacedName())
+			require.NoError(t, err)
+			require.Len(t, history, 2)
+			assert.Equal(t, mdbv1.LogLevelDebug, history[1].Spec.AgentConfiguration.LogLevel)
+		})
+	}
+}
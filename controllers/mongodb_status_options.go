@@ -2,6 +2,7 @@ package controllers
 
 import (
 	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/apierrors"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/result"
 	"go.uber.org/zap"
@@ -168,6 +169,12 @@ func (o *optionBuilder) withPendingPhase(retryAfter int) *optionBuilder {
 	return o.withPhase(mdbv1.Pending, retryAfter)
 }
 
+// withPendingPhaseUntilNextEvent sets the Pending phase without requeuing the resource: it is reconciled again once a
+// watched resource changes.
+func (o *optionBuilder) withPendingPhaseUntilNextEvent() *optionBuilder {
+	return o.withPhase(mdbv1.Pending, -1)
+}
+
 func (o *optionBuilder) withRunningPhase() *optionBuilder {
 	return o.withPhase(mdbv1.Running, -1)
 }
@@ -185,6 +192,9 @@ func (p phaseOption) GetResult() (reconcile.Result, error) {
 	if p.phase == mdbv1.Running {
 		return result.OK()
 	}
+	if p.phase == mdbv1.Pending && p.retryAfter < 0 {
+		return result.OK()
+	}
 	if p.phase == mdbv1.Pending {
 		return result.Retry(p.retryAfter)
 	}
@@ -241,3 +251,253 @@ func (s statefulSetArbitersOption) ApplyOption(mdb *mdbv1.MongoDBCommunity) {
 func (s statefulSetArbitersOption) GetResult() (reconcile.Result, error) {
 	return result.OK()
 }
+
+func (o *optionBuilder) withUserPasswordRotations(rotations []mdbv1.UserPasswordRotation, retryAfter int) *optionBuilder {
+	o.options = append(o.options, userPasswordRotationsOption{
+		rotations:  rotations,
+		retryAfter: retryAfter,
+	})
+	return o
+}
+
+type userPasswordRotationsOption struct {
+	rotations  []mdbv1.UserPasswordRotation
+	retryAfter int
+}
+
+func (u userPasswordRotationsOption) ApplyOption(mdb *mdbv1.MongoDBCommunity) {
+	mdb.Status.UserPasswordRotations = u.rotations
+}
+
+// GetResult requeues the reconciliation when the next password rotation moves on.
+func (u userPasswordRotationsOption) GetResult() (reconcile.Result, error) {
+	if u.retryAfter > 0 {
+		return result.Retry(u.retryAfter)
+	}
+	return result.OK()
+}
+
+func (o *optionBuilder) withAgentCredentialsRotation(rotation *mdbv1.AgentCredentialsRotation, retryAfter int) *optionBuilder {
+	o.options = append(o.options, agentCredentialsRotationOption{
+		rotation:   rotation,
+		retryAfter: retryAfter,
+	})
+	return o
+}
+
+type agentCredentialsRotationOption struct {
+	rotation   *mdbv1.AgentCredentialsRotation
+	retryAfter int
+}
+
+func (a agentCredentialsRotationOption) ApplyOption(mdb *mdbv1.MongoDBCommunity) {
+	mdb.Status.AgentCredentialsRotation = a.rotation
+}
+
+// GetResult requeues the reconciliation when the next step of the rotation must be published.
+func (a agentCredentialsRotationOption) GetResult() (reconcile.Result, error) {
+	if a.retryAfter > 0 {
+		return result.Retry(a.retryAfter)
+	}
+	return result.OK()
+}
+
+func (o *optionBuilder) withUserResources(userResources []mdbv1.UserResourceReference) *optionBuilder {
+	o.options = append(o.options, userResourcesOption{
+		userResources: userResources,
+	})
+	return o
+}
+
+type userResourcesOption struct {
+	userResources []mdbv1.UserResourceReference
+}
+
+func (u userResourcesOption) ApplyOption(mdb *mdbv1.MongoDBCommunity) {
+	mdb.Status.UserResources = u.userResources
+}
+
+func (u userResourcesOption) GetResult() (reconcile.Result, error) {
+	return result.OK()
+}
+
+func (o *optionBuilder) withDatabaseDrift(databaseDrift []mdbv1.DatabaseDrift) *optionBuilder {
+	o.options = append(o.options, databaseDriftOption{
+		databaseDrift: databaseDrift,
+	})
+	return o
+}
+
+type databaseDriftOption struct {
+	databaseDrift []mdbv1.DatabaseDrift
+}
+
+func (d databaseDriftOption) ApplyOption(mdb *mdbv1.MongoDBCommunity) {
+	mdb.Status.DatabaseDrift = d.databaseDrift
+}
+
+func (d databaseDriftOption) GetResult() (reconcile.Result, error) {
+	return result.OK()
+}
+
+func (o *optionBuilder) withInitScripts(initScripts *mdbv1.InitScriptsStatus, retryAfter int) *optionBuilder {
+	o.options = append(o.options, initScriptsOption{
+		initScripts: initScripts,
+		retryAfter:  retryAfter,
+	})
+	return o
+}
+
+type initScriptsOption struct {
+	initScripts *mdbv1.InitScriptsStatus
+	retryAfter  int
+}
+
+func (i initScriptsOption) ApplyOption(mdb *mdbv1.MongoDBCommunity) {
+	mdb.Status.InitScripts = i.initScripts
+}
+
+// GetResult requeues the reconciliation when the Job running the init scripts could not be created yet.
+func (i initScriptsOption) GetResult() (reconcile.Result, error) {
+	if i.retryAfter > 0 {
+		return result.Retry(i.retryAfter)
+	}
+	return result.OK()
+}
+
+func (o *optionBuilder) withCADistribution(retryAfter int) *optionBuilder {
+	o.options = append(o.options, caDistributionOption{
+		retryAfter: retryAfter,
+	})
+	return o
+}
+
+type caDistributionOption struct {
+	retryAfter int
+}
+
+func (c caDistributionOption) ApplyOption(_ *mdbv1.MongoDBCommunity) {}
+
+// GetResult requeues the reconciliation to distribute the CA certificate to the namespaces selected since.
+func (c caDistributionOption) GetResult() (reconcile.Result, error) {
+	if c.retryAfter > 0 {
+		return result.Retry(c.retryAfter)
+	}
+	return result.OK()
+}
+
+func (o *optionBuilder) withSecretProviders(retryAfter int) *optionBuilder {
+	o.options = append(o.options, secretProvidersOption{
+		retryAfter: retryAfter,
+	})
+	return o
+}
+
+type secretProvidersOption struct {
+	retryAfter int
+}
+
+func (s secretProvidersOption) ApplyOption(_ *mdbv1.MongoDBCommunity) {}
+
+// GetResult requeues the reconciliation to read the secrets of the secret providers again.
+func (s secretProvidersOption) GetResult() (reconcile.Result, error) {
+	if s.retryAfter > 0 {
+		return result.Retry(s.retryAfter)
+	}
+	return result.OK()
+}
+
+func (o *optionBuilder) withCertificateRenewal(retryAfter int) *optionBuilder {
+	o.options = append(o.options, certificateRenewalOption{
+		retryAfter: retryAfter,
+	})
+	return o
+}
+
+type certificateRenewalOption struct {
+	retryAfter int
+}
+
+func (c certificateRenewalOption) ApplyOption(_ *mdbv1.MongoDBCommunity) {}
+
+// GetResult requeues the reconciliation when the next auto-generated certificate must be renewed.
+func (c certificateRenewalOption) GetResult() (reconcile.Result, error) {
+	if c.retryAfter > 0 {
+		return result.Retry(c.retryAfter)
+	}
+	return result.OK()
+}
+
+func (o *optionBuilder) withTLSMode(mode automationconfig.TLSMode, retryAfter int) *optionBuilder {
+	o.options = append(o.options, tlsModeOption{
+		mode:       mode,
+		retryAfter: retryAfter,
+	})
+	return o
+}
+
+type tlsModeOption struct {
+	mode       automationconfig.TLSMode
+	retryAfter int
+}
+
+func (t tlsModeOption) ApplyOption(mdb *mdbv1.MongoDBCommunity) {
+	mdb.Status.TLSMode = t.mode
+}
+
+// GetResult requeues the reconciliation when the processes reached a new TLS mode, to move on to the next one.
+func (t tlsModeOption) GetResult() (reconcile.Result, error) {
+	if t.retryAfter > 0 {
+		return result.Retry(t.retryAfter)
+	}
+	return result.OK()
+}
+
+func (o *optionBuilder) withClusterAuthMode(mode mdbv1.ClusterAuthMode, retryAfter int) *optionBuilder {
+	o.options = append(o.options, clusterAuthModeOption{
+		mode:       mode,
+		retryAfter: retryAfter,
+	})
+	return o
+}
+
+type clusterAuthModeOption struct {
+	mode       mdbv1.ClusterAuthMode
+	retryAfter int
+}
+
+func (c clusterAuthModeOption) ApplyOption(mdb *mdbv1.MongoDBCommunity) {
+	mdb.Status.ClusterAuthMode = c.mode
+}
+
+// GetResult requeues the reconciliation when the processes reached a new cluster authentication mode, to move on to
+// the next one.
+func (c clusterAuthModeOption) GetResult() (reconcile.Result, error) {
+	if c.retryAfter > 0 {
+		return result.Retry(c.retryAfter)
+	}
+	return result.OK()
+}
+
+func (o *optionBuilder) withCertificateExpiry(retryAfter int) *optionBuilder {
+	o.options = append(o.options, certificateExpiryOption{
+		retryAfter: retryAfter,
+	})
+	return o
+}
+
+type certificateExpiryOption struct {
+	retryAfter int
+}
+
+// ApplyOption does nothing, the expiry of the certificates is recorded in the status before the deployment is
+// reconciled.
+func (c certificateExpiryOption) ApplyOption(_ *mdbv1.MongoDBCommunity) {}
+
+// GetResult requeues the reconciliation when the next certificate crosses a warning threshold.
+func (c certificateExpiryOption) GetResult() (reconcile.Result, error) {
+	if c.retryAfter > 0 {
+		return result.Retry(c.retryAfter)
+	}
+	return result.OK()
+}
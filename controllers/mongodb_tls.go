@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"net"
 	"strings"
 
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
@@ -15,9 +16,12 @@ import (
 
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/configmap"
 
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/container"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/podtemplatespec"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/resourcerequirements"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/statefulset"
 
+	corev1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -32,19 +36,36 @@ const (
 	tlsSecretCertName            = "tls.crt"
 	tlsSecretKeyName             = "tls.key"
 	tlsSecretPemName             = "tls.pem"
+	tlsCrlName                   = "crl.pem"
+	tlsCAVolumeName              = "tls-ca"
+	tlsSecretVolumeName          = "tls-secret"
 	automationAgentPemMountPath  = "/var/lib/mongodb-mms-automation/agent-certs"
+
+	// When each member has its own certificate, the operator stores it in a Secret per pod. The Secrets of all the
+	// pods are mounted into an init container, which copies the certificate of its own pod into a volume shared
+	// with the agent and mongod containers. The kubelet still writes the keys of all the members on every node
+	// running a member, so the keys are not isolated from each other.
+	tlsMemberSecretsVolumeName            = "tls-member-secrets"
+	tlsMemberSecretsMountPath             = "/var/lib/tls/members/" //nolint
+	tlsMemberCertificateVolumeName        = "tls-member-certificate"
+	tlsMemberCertificateMountPath         = "/var/lib/tls/member/"
+	tlsMemberCertificateFileName          = "server.pem"
+	tlsMemberCertificateInitContainerName = "mongodb-tls-member-certificate"
+	// tlsMemberCertificatesHashEnv is the hash of the certificates of the members. It is part of the pod template, so
+	// that changing a certificate restarts the members one at a time with their new certificate.
+	tlsMemberCertificatesHashEnv = "TLS_MEMBER_CERTIFICATES_HASH"
 )
 
 // validateTLSConfig will check that the configured ConfigMap and Secret exist and that they have the correct fields.
-func (r *ReplicaSetReconciler) validateTLSConfig(ctx context.Context, mdb mdbv1.MongoDBCommunity) (bool, error) {
-	if !mdb.Spec.Security.TLS.Enabled {
+func (r *ReplicaSetReconciler) validateTLSConfig(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb mdbv1.MongoDBCommunity) (bool, error) {
+	if !mdb.IsTLSConfiguredThisReconciliation() {
 		return true, nil
 	}
 
 	r.log.Info("Ensuring TLS is correctly configured")
 
 	// Ensure CA cert is configured
-	_, err := getCaCrt(ctx, r.client, r.client, mdb)
+	_, err := getCaCrt(ctx, r.client, secrets, mdb)
 
 	if err != nil {
 		if apiErrors.IsNotFound(err) {
@@ -55,35 +76,71 @@ func (r *ReplicaSetReconciler) validateTLSConfig(ctx context.Context, mdb mdbv1.
 		return false, err
 	}
 
-	// Ensure Secret exists
-	_, err = secret.ReadStringData(ctx, r.client, mdb.TLSSecretNamespacedName())
+	// Ensure the certificate revocation list is configured
+	_, err = getCrl(ctx, r.client, r.client, mdb)
 	if err != nil {
 		if apiErrors.IsNotFound(err) {
-			r.log.Warnf(`Secret "%s" not found`, mdb.TLSSecretNamespacedName())
+			r.log.Warnf("CRL resource not found: %s", err)
 			return false, nil
 		}
 
 		return false, err
 	}
 
-	// validate whether the secret contains "tls.crt" and "tls.key", or it contains "tls.pem"
-	// if it contains all three, then the pem entry should be equal to the concatenation of crt and key
-	_, err = getPemOrConcatenatedCrtAndKey(ctx, r.client, mdb.TLSSecretNamespacedName())
-	if err != nil {
-		r.log.Warnf(err.Error())
-		return false, nil
+	for _, secretName := range tlsServerSecretNamespacedNames(mdb) {
+		// Ensure Secret exists
+		_, err = secret.ReadStringData(ctx, secrets, secretName)
+		if err != nil {
+			if apiErrors.IsNotFound(err) {
+				r.log.Warnf(`Secret "%s" not found`, secretName)
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		// validate whether the secret contains "tls.crt" and "tls.key", or it contains "tls.pem"
+		// if it contains all three, then the pem entry should be equal to the concatenation of crt and key
+		_, err = getPemOrConcatenatedCrtAndKey(ctx, secrets, secretName)
+		if err != nil {
+			r.log.Warnf(err.Error())
+			return false, nil
+		}
+
+		// Watch certificate-key secret to handle rotations
+		r.secretWatcher.Watch(ctx, secretName, mdb.NamespacedName())
 	}
 
-	// Watch certificate-key secret to handle rotations
-	r.secretWatcher.Watch(ctx, mdb.TLSSecretNamespacedName(), mdb.NamespacedName())
+	if mdb.IsClusterCertificateRequired() {
+		clusterSecretName := mdb.ClusterCertificateSecretNamespacedName()
+		if _, err := secret.ReadStringData(ctx, secrets, clusterSecretName); err != nil {
+			if apiErrors.IsNotFound(err) {
+				r.log.Warnf(`Secret "%s" not found`, clusterSecretName)
+				return false, nil
+			}
+			return false, err
+		}
+		if _, err := getClusterCertificateKey(ctx, secrets, mdb); err != nil {
+			r.log.Warnf(err.Error())
+			return false, nil
+		}
+		r.secretWatcher.Watch(ctx, clusterSecretName, mdb.NamespacedName())
+	}
 
 	// Watch CA certificate changes
-	if mdb.Spec.Security.TLS.CaCertificateSecret != nil {
+	if mdb.HasTLSCaCertificateSecret() {
 		r.secretWatcher.Watch(ctx, mdb.TLSCaCertificateSecretNamespacedName(), mdb.NamespacedName())
 	} else {
 		r.configMapWatcher.Watch(ctx, mdb.TLSConfigMapNamespacedName(), mdb.NamespacedName())
 	}
 
+	// Watch certificate revocation list changes
+	if mdb.Spec.Security.TLS.CrlSecret != nil {
+		r.secretWatcher.Watch(ctx, mdb.TLSCrlSecretNamespacedName(), mdb.NamespacedName())
+	} else if mdb.Spec.Security.TLS.CrlConfigMap != nil {
+		r.configMapWatcher.Watch(ctx, mdb.TLSCrlConfigMapNamespacedName(), mdb.NamespacedName())
+	}
+
 	r.log.Infof("Successfully validated TLS config")
 	return true, nil
 }
@@ -91,7 +148,7 @@ func (r *ReplicaSetReconciler) validateTLSConfig(ctx context.Context, mdb mdbv1.
 // getTLSConfigModification creates a modification function which enables TLS in the automation config.
 // It will also ensure that the combined cert-key secret is created.
 func getTLSConfigModification(ctx context.Context, cmGetter configmap.Getter, secretGetter secret.Getter, mdb mdbv1.MongoDBCommunity) (automationconfig.Modification, error) {
-	if !mdb.Spec.Security.TLS.Enabled {
+	if mdb.TLSModeThisReconciliation() == automationconfig.TLSModeDisabled {
 		return automationconfig.NOOP(), nil
 	}
 
@@ -100,12 +157,133 @@ func getTLSConfigModification(ctx context.Context, cmGetter configmap.Getter, se
 		return automationconfig.NOOP(), err
 	}
 
-	certKey, err := getPemOrConcatenatedCrtAndKey(ctx, secretGetter, mdb.TLSSecretNamespacedName())
+	crl, err := getCrl(ctx, cmGetter, secretGetter, mdb)
+	if err != nil {
+		return automationconfig.NOOP(), err
+	}
+
+	certKey, memberCertKeys, err := getServerCertificateKeys(ctx, secretGetter, mdb)
+	if err != nil {
+		return automationconfig.NOOP(), err
+	}
+
+	clusterCertKey, err := getClusterCertificateKey(ctx, secretGetter, mdb)
 	if err != nil {
 		return automationconfig.NOOP(), err
 	}
 
-	return tlsConfigModification(mdb, certKey, caCert), nil
+	return tlsConfigModification(mdb, certKey, memberCertKeys, clusterCertKey, caCert, crl), nil
+}
+
+// ensureDeployedTLSMode records the TLS mode of the deployed processes for the resources without a recorded mode
+// which are already deployed, the ones created by a previous version of the operator, so that TLS is enabled or
+// disabled one mode at a time from the mode the processes actually use.
+func (r *ReplicaSetReconciler) ensureDeployedTLSMode(ctx context.Context, mdb *mdbv1.MongoDBCommunity) error {
+	if mdb.Status.TLSMode != "" {
+		return nil
+	}
+
+	ac, err := automationconfig.ReadFromSecret(ctx, r.client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+	if err != nil {
+		return err
+	}
+	if len(ac.Processes) == 0 {
+		return nil
+	}
+
+	// all the processes are configured with the same mode.
+	mdb.Status.TLSMode = automationconfig.TLSModeDisabled
+	if mode := ac.Processes[0].Args26.Get("net.tls.mode").Str(); mode != "" {
+		mdb.Status.TLSMode = automationconfig.TLSMode(mode)
+	}
+	r.log.Infof("The deployed processes use TLS mode %s", mdb.Status.TLSMode)
+	return nil
+}
+
+// tlsMemberPodNames returns the names of the pods of the members and arbiters, including the ones being removed by
+// a scale down.
+func tlsMemberPodNames(mdb mdbv1.MongoDBCommunity) []string {
+	var podNames []string
+	for i := 0; i < max(mdb.Spec.Members, mdb.Status.CurrentStatefulSetReplicas); i++ {
+		podNames = append(podNames, fmt.Sprintf("%s-%d", mdb.Name, i))
+	}
+	for i := 0; i < max(mdb.Spec.Arbiters, mdb.Status.CurrentStatefulSetArbitersReplicas); i++ {
+		podNames = append(podNames, fmt.Sprintf("%s-arb-%d", mdb.Name, i))
+	}
+	return podNames
+}
+
+// tlsMemberHostnames returns the hostnames each member is reached at, by name of its pod: the hostname of its pod and
+// the hostnames of its horizons.
+func tlsMemberHostnames(mdb mdbv1.MongoDBCommunity, clusterDomain string) map[string][]string {
+	domain := getDomain(mdb.ServiceName(), mdb.Namespace, clusterDomain)
+	hostnames := map[string][]string{}
+	for i, podName := range tlsMemberPodNames(mdb) {
+		hostnames[podName] = []string{fmt.Sprintf("%s.%s", podName, domain)}
+		// the horizons are configured for the members only, which are listed first.
+		if i < len(mdb.Spec.ReplicaSetHorizons) && i < max(mdb.Spec.Members, mdb.Status.CurrentStatefulSetReplicas) {
+			hostnames[podName] = append(hostnames[podName], horizonHostnames(mdb.Spec.ReplicaSetHorizons[i])...)
+		}
+	}
+	return hostnames
+}
+
+// horizonHostnames returns the hostnames of the horizons of a member, without their ports.
+func horizonHostnames(horizons automationconfig.ReplicaSetHorizons) []string {
+	var hostnames []string
+	for _, address := range horizons {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+		hostnames = append(hostnames, host)
+	}
+	return hostnames
+}
+
+// tlsServerSecretNamespacedNames returns the user-provided Secrets containing the server certificates: one per
+// member if spec.security.tls.memberCertificates is set, otherwise the one shared by all the members.
+func tlsServerSecretNamespacedNames(mdb mdbv1.MongoDBCommunity) []types.NamespacedName {
+	if !mdb.HasTLSMemberCertificates() {
+		return []types.NamespacedName{mdb.TLSSecretNamespacedName()}
+	}
+	var secretNames []types.NamespacedName
+	for _, podName := range tlsMemberPodNames(mdb) {
+		secretNames = append(secretNames, mdb.TLSMemberSecretNamespacedName(podName))
+	}
+	return secretNames
+}
+
+// getServerCertificateKeys returns the combined certificate and key shared by all the members or, if each member has
+// its own certificate, the combined certificate and key of each member by name of its pod.
+func getServerCertificateKeys(ctx context.Context, getter secret.Getter, mdb mdbv1.MongoDBCommunity) (string, map[string]string, error) {
+	if !mdb.HasTLSMemberCertificates() {
+		certKey, err := getPemOrConcatenatedCrtAndKey(ctx, getter, mdb.TLSSecretNamespacedName())
+		return certKey, nil, err
+	}
+
+	memberCertKeys := map[string]string{}
+	for _, podName := range tlsMemberPodNames(mdb) {
+		certKey, err := getPemOrConcatenatedCrtAndKey(ctx, getter, mdb.TLSMemberSecretNamespacedName(podName))
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid certificate of member %s: %s", podName, err)
+		}
+		memberCertKeys[podName] = certKey
+	}
+	return "", memberCertKeys, nil
+}
+
+// getClusterCertificateKey returns the combined certificate and key the members present to each other, or an empty
+// string if they authenticate to each other with the keyfile only.
+func getClusterCertificateKey(ctx context.Context, getter secret.Getter, mdb mdbv1.MongoDBCommunity) (string, error) {
+	if !mdb.IsClusterCertificateRequired() {
+		return "", nil
+	}
+	certKey, err := getPemOrConcatenatedCrtAndKey(ctx, getter, mdb.ClusterCertificateSecretNamespacedName())
+	if err != nil {
+		return "", fmt.Errorf("invalid cluster certificate: %s", err)
+	}
+	return certKey, nil
 }
 
 // getCertAndKey will fetch the certificate and key from the user-provided Secret.
@@ -164,7 +342,7 @@ func getCaCrt(ctx context.Context, cmGetter configmap.Getter, secretGetter secre
 	var caResourceName types.NamespacedName
 	var caData map[string]string
 	var err error
-	if mdb.Spec.Security.TLS.CaCertificateSecret != nil {
+	if mdb.HasTLSCaCertificateSecret() {
 		caResourceName = mdb.TLSCaCertificateSecretNamespacedName()
 		caData, err = secret.ReadStringData(ctx, secretGetter, caResourceName)
 	} else if mdb.Spec.Security.TLS.CaConfigMap != nil {
@@ -187,48 +365,125 @@ func getCaCrt(ctx context.Context, cmGetter configmap.Getter, secretGetter secre
 	}
 }
 
+// getCrl returns the certificate revocation list from the user provided Secret or ConfigMap, or an empty string if
+// none is configured.
+func getCrl(ctx context.Context, cmGetter configmap.Getter, secretGetter secret.Getter, mdb mdbv1.MongoDBCommunity) (string, error) {
+	var crlResourceName types.NamespacedName
+	var crlData map[string]string
+	var err error
+	if mdb.Spec.Security.TLS.CrlSecret != nil {
+		crlResourceName = mdb.TLSCrlSecretNamespacedName()
+		crlData, err = secret.ReadStringData(ctx, secretGetter, crlResourceName)
+	} else if mdb.Spec.Security.TLS.CrlConfigMap != nil {
+		crlResourceName = mdb.TLSCrlConfigMapNamespacedName()
+		crlData, err = configmap.ReadData(ctx, cmGetter, crlResourceName)
+	} else {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if crl, ok := crlData[tlsCrlName]; !ok || crl == "" {
+		return "", fmt.Errorf(`CRL resource "%s" should have a certificate revocation list in field "%s"`, crlResourceName, tlsCrlName)
+	} else {
+		return crl, nil
+	}
+}
+
 // ensureCASecret will create or update the operator managed Secret containing
 // the CA certficate from the user provided Secret or ConfigMap.
+// The certificate revocation list, if configured, is stored in the same Secret.
 func ensureCASecret(ctx context.Context, cmGetter configmap.Getter, secretGetter secret.Getter, getUpdateCreator secret.GetUpdateCreator, mdb mdbv1.MongoDBCommunity) error {
 	cert, err := getCaCrt(ctx, cmGetter, secretGetter, mdb)
 	if err != nil {
 		return err
 	}
 
+	crl, err := getCrl(ctx, cmGetter, secretGetter, mdb)
+	if err != nil {
+		return err
+	}
+
 	caFileName := tlsOperatorSecretFileName(cert)
 
-	operatorSecret := secret.Builder().
+	operatorSecretBuilder := secret.Builder().
 		SetName(mdb.TLSOperatorCASecretNamespacedName().Name).
 		SetNamespace(mdb.TLSOperatorCASecretNamespacedName().Namespace).
 		SetField(caFileName, cert).
-		SetOwnerReferences(mdb.GetOwnerReferences()).
-		Build()
+		SetOwnerReferences(mdb.GetOwnerReferences())
+	if crl != "" {
+		operatorSecretBuilder.SetField(tlsOperatorCrlFileName(crl), crl)
+	}
+	operatorSecret := operatorSecretBuilder.Build()
 
 	return secret.CreateOrUpdate(ctx, getUpdateCreator, operatorSecret)
 }
 
 // ensureTLSSecret will create or update the operator-managed Secret containing
 // the concatenated certificate and key from the user-provided Secret.
+// If each member has its own certificate, the concatenated certificate and key of each member is stored in a
+// Secret of its own pod instead.
+// The cluster certificate, if the members present one to each other, is stored in the shared Secret.
 func ensureTLSSecret(ctx context.Context, getUpdateCreator secret.GetUpdateCreator, mdb mdbv1.MongoDBCommunity) error {
-	certKey, err := getPemOrConcatenatedCrtAndKey(ctx, getUpdateCreator, mdb.TLSSecretNamespacedName())
+	certKey, memberCertKeys, err := getServerCertificateKeys(ctx, getUpdateCreator, mdb)
 	if err != nil {
 		return err
 	}
-	// Calculate file name from certificate and key
-	fileName := tlsOperatorSecretFileName(certKey)
 
-	operatorSecret := secret.Builder().
+	clusterCertKey, err := getClusterCertificateKey(ctx, getUpdateCreator, mdb)
+	if err != nil {
+		return err
+	}
+
+	operatorSecretBuilder := secret.Builder().
 		SetName(mdb.TLSOperatorSecretNamespacedName().Name).
 		SetNamespace(mdb.TLSOperatorSecretNamespacedName().Namespace).
-		SetField(fileName, certKey).
-		SetOwnerReferences(mdb.GetOwnerReferences()).
-		Build()
+		SetOwnerReferences(mdb.GetOwnerReferences())
+	if memberCertKeys == nil {
+		// Calculate file name from certificate and key
+		operatorSecretBuilder.SetField(tlsOperatorSecretFileName(certKey), certKey)
+	}
+	if clusterCertKey != "" {
+		operatorSecretBuilder.SetField(tlsOperatorSecretFileName(clusterCertKey), clusterCertKey)
+	}
+	operatorSecret := operatorSecretBuilder.Build()
 
-	return secret.CreateOrUpdate(ctx, getUpdateCreator, operatorSecret)
+	if err := secret.CreateOrUpdate(ctx, getUpdateCreator, operatorSecret); err != nil {
+		return err
+	}
+
+	for podName, memberCertKey := range memberCertKeys {
+		memberSecret := secret.Builder().
+			SetName(mdb.TLSOperatorMemberSecretNamespacedName(podName).Name).
+			SetNamespace(mdb.TLSOperatorMemberSecretNamespacedName(podName).Namespace).
+			SetField(tlsMemberCertificateFileName, memberCertKey).
+			SetOwnerReferences(mdb.GetOwnerReferences()).
+			Build()
+		if err := secret.CreateOrUpdate(ctx, getUpdateCreator, memberSecret); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tlsMemberCertificatesHash returns the hash of the certificates of the members stored by the operator, so that
+// the members are restarted when one of them changes.
+func tlsMemberCertificatesHash(ctx context.Context, getter secret.Getter, mdb mdbv1.MongoDBCommunity) (string, error) {
+	hash := sha256.New()
+	for _, podName := range tlsMemberPodNames(mdb) {
+		certKey, err := secret.ReadKey(ctx, getter, tlsMemberCertificateFileName, mdb.TLSOperatorMemberSecretNamespacedName(podName))
+		if err != nil {
+			return "", err
+		}
+		hash.Write([]byte(certKey))
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
 
 func ensureAgentCertSecret(ctx context.Context, getUpdateCreator secret.GetUpdateCreator, mdb mdbv1.MongoDBCommunity) error {
-	if mdb.Spec.GetAgentAuthMode() != "X509" {
+	if !mdb.Spec.IsAgentCertificateRequired() {
 		return nil
 	}
 
@@ -278,19 +533,40 @@ func tlsOperatorSecretFileName(certKey string) string {
 	return fmt.Sprintf("%x.pem", hash)
 }
 
+// tlsOperatorCrlFileName calculates the file name to use for the mounted certificate
+// revocation list, so that the processes are restarted with the new list when it changes.
+func tlsOperatorCrlFileName(crl string) string {
+	hash := sha256.Sum256([]byte(crl))
+	return fmt.Sprintf("%x.crl", hash)
+}
+
 // tlsConfigModification will enable TLS in the automation config.
-func tlsConfigModification(mdb mdbv1.MongoDBCommunity, certKey, caCert string) automationconfig.Modification {
+// Each process uses the certificate of its member, copied into its pod by the init container, if it is present in
+// memberCertKeys, or certKey otherwise.
+// The processes present clusterCertKey to each other unless they authenticate to each other with the keyfile.
+// The certificate revocation list is only configured if crl isn't empty.
+func tlsConfigModification(mdb mdbv1.MongoDBCommunity, certKey string, memberCertKeys map[string]string, clusterCertKey, caCert, crl string) automationconfig.Modification {
 	caCertificatePath := tlsCAMountPath + tlsOperatorSecretFileName(caCert)
-	certificateKeyPath := tlsOperatorSecretMountPath + tlsOperatorSecretFileName(certKey)
+	tls := mdb.Spec.Security.TLS
 
-	mode := automationconfig.TLSModeRequired
-	if mdb.Spec.Security.TLS.Optional {
-		// TLSModePreferred requires server-server connections to use TLS but makes it optional for clients.
-		mode = automationconfig.TLSModePreferred
+	// TLS is enabled and disabled one mode at a time, see TLSModeThisReconciliation.
+	mode := mdb.TLSModeThisReconciliation()
+	clusterAuthMode := mdb.ClusterAuthModeThisReconciliation()
+
+	// while TLS is being disabled, the clients are not required to present a certificate anymore.
+	requireClientCertificates := tls.Enabled && tls.RequireClientCertificates
+	clientCertificateMode := automationconfig.ClientCertificateModeOptional
+	if requireClientCertificates {
+		clientCertificateMode = automationconfig.ClientCertificateModeRequired
+	}
+
+	disabledProtocols := make([]string, len(tls.DisabledProtocols))
+	for i, protocol := range tls.DisabledProtocols {
+		disabledProtocols[i] = string(protocol)
 	}
 
 	automationAgentPemFilePath := ""
-	if mdb.Spec.IsAgentX509() {
+	if mdb.Spec.IsAgentCertificateRequired() {
 		automationAgentPemFilePath = automationAgentPemMountPath + "/" + mdb.AgentCertificatePemSecretNamespacedName().Name
 	}
 
@@ -298,32 +574,78 @@ func tlsConfigModification(mdb mdbv1.MongoDBCommunity, certKey, caCert string) a
 		// Configure CA certificate for agent
 		config.TLSConfig.CAFilePath = caCertificatePath
 		config.TLSConfig.AutoPEMKeyFilePath = automationAgentPemFilePath
+		config.TLSConfig.ClientCertificateMode = clientCertificateMode
 
 		for i := range config.Processes {
 			args := config.Processes[i].Args26
 
+			certificateKeyPath := tlsOperatorSecretMountPath + tlsOperatorSecretFileName(certKey)
+			if _, ok := memberCertKeys[config.Processes[i].Name]; ok {
+				certificateKeyPath = tlsMemberCertificateMountPath + tlsMemberCertificateFileName
+			}
+
 			args.Set("net.tls.mode", mode)
 			args.Set("net.tls.CAFile", caCertificatePath)
 			args.Set("net.tls.certificateKeyFile", certificateKeyPath)
-			args.Set("net.tls.allowConnectionsWithoutCertificates", true)
+			args.Set("net.tls.allowConnectionsWithoutCertificates", !requireClientCertificates)
+
+			if clusterAuthMode != mdbv1.ClusterAuthModeKeyFile {
+				args.Set("security.clusterAuthMode", string(clusterAuthMode))
+				args.Set("net.tls.clusterFile", tlsOperatorSecretMountPath+tlsOperatorSecretFileName(clusterCertKey))
+			}
+			if crl != "" {
+				args.Set("net.tls.CRLFile", tlsCAMountPath+tlsOperatorCrlFileName(crl))
+			}
+			if len(disabledProtocols) > 0 {
+				args.Set("net.tls.disabledProtocols", strings.Join(disabledProtocols, ","))
+			}
+			if tls.CipherConfig != "" {
+				args.Set("setParameter.opensslCipherConfig", tls.CipherConfig)
+			}
+			if tls.CipherSuiteConfig != "" {
+				args.Set("setParameter.opensslCipherSuiteConfig", tls.CipherSuiteConfig)
+			}
+			if ocsp := tls.Ocsp; ocsp != nil {
+				if ocsp.Enabled != nil {
+					args.Set("setParameter.ocspEnabled", *ocsp.Enabled)
+				}
+				if ocsp.StaplingRefreshPeriodSecs != nil {
+					args.Set("setParameter.ocspStaplingRefreshPeriodSecs", *ocsp.StaplingRefreshPeriodSecs)
+				}
+				if ocsp.StaplingTimeoutSecs != nil {
+					args.Set("setParameter.tlsOCSPStaplingTimeoutSecs", *ocsp.StaplingTimeoutSecs)
+				}
+				if ocsp.VerifyTimeoutSecs != nil {
+					args.Set("setParameter.tlsOCSPVerifyTimeoutSecs", *ocsp.VerifyTimeoutSecs)
+				}
+			}
 		}
 	}
 }
 
-// buildTLSPodSpecModification will add the TLS init container and volumes to the pod template if TLS is enabled.
-func buildTLSPodSpecModification(mdb mdbv1.MongoDBCommunity) podtemplatespec.Modification {
-	if !mdb.Spec.Security.TLS.Enabled {
-		return podtemplatespec.NOOP()
+// buildTLSPodSpecModification will add the TLS init container and volumes to the pod template if TLS is enabled,
+// and remove the volumes once TLS has been disabled in all processes.
+func buildTLSPodSpecModification(mdb mdbv1.MongoDBCommunity, mongodbImage string) podtemplatespec.Modification {
+	if !mdb.IsTLSConfiguredThisReconciliation() {
+		return podtemplatespec.Apply(
+			podtemplatespec.RemoveVolume(tlsCAVolumeName),
+			podtemplatespec.RemoveVolume(tlsSecretVolumeName),
+			podtemplatespec.RemoveVolumeMount(construct.AgentName, tlsCAVolumeName),
+			podtemplatespec.RemoveVolumeMount(construct.AgentName, tlsSecretVolumeName),
+			podtemplatespec.RemoveVolumeMount(construct.MongodbName, tlsCAVolumeName),
+			podtemplatespec.RemoveVolumeMount(construct.MongodbName, tlsSecretVolumeName),
+			removeTLSMemberCertificate(),
+		)
 	}
 
 	// Configure a volume which mounts the CA certificate from either a Secret or a ConfigMap
 	// The certificate is used by both mongod and the agent
-	caVolume := statefulset.CreateVolumeFromSecret("tls-ca", mdb.TLSOperatorCASecretNamespacedName().Name)
+	caVolume := statefulset.CreateVolumeFromSecret(tlsCAVolumeName, mdb.TLSOperatorCASecretNamespacedName().Name)
 	caVolumeMount := statefulset.CreateVolumeMount(caVolume.Name, tlsCAMountPath, statefulset.WithReadOnly(true))
 
 	// Configure a volume which mounts the secret holding the server key and certificate
-	// The same key-certificate pair is used for all servers
-	tlsSecretVolume := statefulset.CreateVolumeFromSecret("tls-secret", mdb.TLSOperatorSecretNamespacedName().Name)
+	// The same key-certificate pair is used for all servers, unless each member has its own key-certificate pair
+	tlsSecretVolume := statefulset.CreateVolumeFromSecret(tlsSecretVolumeName, mdb.TLSOperatorSecretNamespacedName().Name)
 	tlsSecretVolumeMount := statefulset.CreateVolumeMount(tlsSecretVolume.Name, tlsOperatorSecretMountPath, statefulset.WithReadOnly(true))
 
 	// MongoDB expects both key and certificate to be provided in a single PEM file
@@ -334,6 +656,95 @@ func buildTLSPodSpecModification(mdb mdbv1.MongoDBCommunity) podtemplatespec.Mod
 		podtemplatespec.WithVolume(tlsSecretVolume),
 		podtemplatespec.WithVolumeMounts(construct.AgentName, tlsSecretVolumeMount, caVolumeMount),
 		podtemplatespec.WithVolumeMounts(construct.MongodbName, tlsSecretVolumeMount, caVolumeMount),
+		buildTLSMemberCertificate(mdb, mongodbImage),
+	)
+}
+
+// buildTLSMemberCertificate mounts the certificate of its own member into the agent and mongod containers, if each
+// member has its own certificate. A pod template is shared by all the pods, so the Secrets of all the members are
+// mounted into the init container, which copies the certificate of its pod into a volume mounted by the agent and
+// mongod. This does not isolate the private keys: every pod receives the keys of all the members, and the kubelet
+// writes them on each node running a member.
+func buildTLSMemberCertificate(mdb mdbv1.MongoDBCommunity, mongodbImage string) podtemplatespec.Modification {
+	if !mdb.HasTLSMemberCertificates() {
+		return removeTLSMemberCertificate()
+	}
+
+	var sources []corev1.VolumeProjection
+	for _, podName := range tlsMemberPodNames(mdb) {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: mdb.TLSOperatorMemberSecretNamespacedName(podName).Name},
+				Items:                []corev1.KeyToPath{{Key: tlsMemberCertificateFileName, Path: podName + ".pem"}},
+			},
+		})
+	}
+	permission := int32(416)
+	memberSecretsVolume := corev1.Volume{
+		Name: tlsMemberSecretsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources, DefaultMode: &permission},
+		},
+	}
+
+	memberCertificateVolume := statefulset.CreateVolumeFromEmptyDir(tlsMemberCertificateVolumeName)
+	memberCertificateVolumeMount := statefulset.CreateVolumeMount(memberCertificateVolume.Name, tlsMemberCertificateMountPath, statefulset.WithReadOnly(true))
+
+	return podtemplatespec.Apply(
+		podtemplatespec.WithVolume(memberSecretsVolume),
+		podtemplatespec.WithVolume(memberCertificateVolume),
+		podtemplatespec.WithVolumeMounts(construct.AgentName, memberCertificateVolumeMount),
+		podtemplatespec.WithVolumeMounts(construct.MongodbName, memberCertificateVolumeMount),
+		podtemplatespec.WithInitContainer(tlsMemberCertificateInitContainerName, tlsMemberCertificateInit(mongodbImage)),
+	)
+}
+
+// tlsMemberCertificateInit returns the init container copying the certificate of the member of its pod.
+func tlsMemberCertificateInit(mongodbImage string) container.Modification {
+	script := fmt.Sprintf(`set -e
+rm -f %[2]s
+cp %[1]s"${POD_NAME}.pem" %[2]s
+`, tlsMemberSecretsMountPath, tlsMemberCertificateMountPath+tlsMemberCertificateFileName)
+
+	_, containerSecurityContext := podtemplatespec.WithDefaultSecurityContextsModifications()
+	return container.Apply(
+		container.WithName(tlsMemberCertificateInitContainerName),
+		container.WithImage(mongodbImage),
+		container.WithResourceRequirements(resourcerequirements.Defaults()),
+		container.WithCommand([]string{"/bin/sh", "-c", script}),
+		// The official image provides both CMD and ENTRYPOINT. We're reusing the former and need to replace
+		// the latter with an empty string.
+		container.WithArgs([]string{""}),
+		container.WithEnvs(corev1.EnvVar{
+			Name: "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.name"},
+			},
+		}),
+		container.WithVolumeMounts([]corev1.VolumeMount{
+			statefulset.CreateVolumeMount(tlsMemberSecretsVolumeName, tlsMemberSecretsMountPath, statefulset.WithReadOnly(true)),
+			statefulset.CreateVolumeMount(tlsMemberCertificateVolumeName, tlsMemberCertificateMountPath, statefulset.WithReadOnly(false)),
+		}),
+		containerSecurityContext,
+	)
+}
+
+// withTLSMemberCertificatesHash sets the hash of the certificates of the members on the init container copying them,
+// so that the members are restarted one at a time when a certificate changes.
+func withTLSMemberCertificatesHash(hash string) podtemplatespec.Modification {
+	return podtemplatespec.WithInitContainer(tlsMemberCertificateInitContainerName,
+		container.WithEnvs(corev1.EnvVar{Name: tlsMemberCertificatesHashEnv, Value: hash}),
+	)
+}
+
+// removeTLSMemberCertificate removes the init container and volumes of the certificates of the members.
+func removeTLSMemberCertificate() podtemplatespec.Modification {
+	return podtemplatespec.Apply(
+		podtemplatespec.RemoveInitContainer(tlsMemberCertificateInitContainerName),
+		podtemplatespec.RemoveVolume(tlsMemberSecretsVolumeName),
+		podtemplatespec.RemoveVolume(tlsMemberCertificateVolumeName),
+		podtemplatespec.RemoveVolumeMount(construct.AgentName, tlsMemberCertificateVolumeName),
+		podtemplatespec.RemoveVolumeMount(construct.MongodbName, tlsMemberCertificateVolumeName),
 	)
 }
 
@@ -361,7 +772,7 @@ func buildTLSPrometheus(mdb mdbv1.MongoDBCommunity) podtemplatespec.Modification
 }
 
 func buildAgentX509(mdb mdbv1.MongoDBCommunity) podtemplatespec.Modification {
-	if mdb.Spec.GetAgentAuthMode() != "X509" {
+	if !mdb.Spec.IsAgentCertificateRequired() {
 		return podtemplatespec.Apply(
 			podtemplatespec.RemoveVolume(constants.AgentPemFile),
 			podtemplatespec.RemoveVolumeMount(construct.AgentName, constants.AgentPemFile),
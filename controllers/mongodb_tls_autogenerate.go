@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/certificates"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+const (
+	tlsCAKeyName = "ca.key"
+	// tlsNextCACertName and tlsNextCAKeyName store the CA replacing the current one while both are trusted, before
+	// it signs the certificates.
+	tlsNextCACertName = "next-ca.crt"
+	tlsNextCAKeyName  = "next-ca.key"
+	// tlsPreviousCACertName stores the replaced CA, which is trusted until the certificates it signed are replaced.
+	tlsPreviousCACertName = "previous-ca.crt"
+	// tlsCARotationTimeName stores when the rotation of the CA moved on to its current step.
+	tlsCARotationTimeName = "ca-rotation-time"
+
+	autoGeneratedCAValidity          = 10 * 365 * 24 * time.Hour
+	autoGeneratedCertificateValidity = 365 * 24 * time.Hour
+	// autoGeneratedCAOverlap is the minimum time each step of a CA rotation is rolled out for, so that the clients
+	// reading the CA from the Secrets of the certificates trust the new CA before it signs the certificates.
+	autoGeneratedCAOverlap = time.Hour
+)
+
+// autoGeneratedCertificate is a certificate the operator issues with its CA, and the secret it is stored in.
+type autoGeneratedCertificate struct {
+	secret  types.NamespacedName
+	request certificates.Request
+}
+
+// ensureAutoGeneratedCertificates creates the CA of the resource and issues the certificates signed by it, when
+// spec.security.tls.autoGenerate is enabled. Certificates which are about to expire, or which don't match the resource
+// anymore, are issued again.
+//
+// It returns the number of seconds after which the next certificate must be renewed, or 0 if there is none.
+func (r ReplicaSetReconciler) ensureAutoGeneratedCertificates(ctx context.Context, mdb mdbv1.MongoDBCommunity, clusterDomain string) (int, error) {
+	if !mdb.IsTLSConfiguredThisReconciliation() || !mdb.Spec.Security.TLS.AutoGenerate {
+		return 0, nil
+	}
+
+	ca, nextRenewal, err := r.ensureAutoGeneratedCA(ctx, mdb)
+	if err != nil {
+		return 0, fmt.Errorf("could not ensure the CA: %s", err)
+	}
+
+	for _, certificate := range autoGeneratedCertificates(mdb, clusterDomain) {
+		renewal, err := r.ensureAutoGeneratedCertificate(ctx, mdb, ca, certificate)
+		if err != nil {
+			return 0, fmt.Errorf("could not issue the certificate of secret %s: %s", certificate.secret.Name, err)
+		}
+		if renewal.Before(nextRenewal) {
+			nextRenewal = renewal
+		}
+	}
+
+	// the next step of a CA rotation may already be due, waiting for the resource to be Running.
+	return max(int(math.Ceil(time.Until(nextRenewal).Seconds())), 1), nil
+}
+
+// ensureAutoGeneratedCA returns the CA of the resource, creating it if it doesn't exist or is expired, and the time
+// after which it must be renewed or its rotation moves on.
+//
+// The certificate of the returned CA is the bundle of the trusted CAs, the CA signing the certificates first. A CA
+// which is about to expire is rotated without interrupting the connections, each step moving on once the previous
+// one has been rolled out, that is once autoGeneratedCAOverlap has elapsed and the resource is Running:
+//  1. a new CA is created and trusted next to the current one, which still signs the certificates.
+//  2. the new CA signs the certificates, which are issued again, the previous CA is still trusted.
+//  3. the previous CA is no longer trusted.
+func (r ReplicaSetReconciler) ensureAutoGeneratedCA(ctx context.Context, mdb mdbv1.MongoDBCommunity) (certificates.KeyPair, time.Time, error) {
+	nsName := mdb.TLSAutoGeneratedCANamespacedName()
+	existing, err := r.readOperatorManagedSecret(ctx, mdb, nsName)
+	if err != nil {
+		return certificates.KeyPair{}, time.Time{}, err
+	}
+
+	now := time.Now()
+	ca := certificates.KeyPair{Certificate: string(existing.Data[tlsCACertName]), Key: string(existing.Data[tlsCAKeyName])}
+	cert, err := certificates.ParseCertificate(ca.Certificate)
+	if err != nil || ca.Key == "" || !now.Before(cert.NotAfter) {
+		// the certificates signed by an expired CA are already rejected, it is replaced straight away.
+		r.log.Infof("Creating the CA of secret %s", nsName)
+		ca, err = certificates.NewCA(fmt.Sprintf("CN=%s-ca,OU=%s,O=MongoDB", mdb.Name, mdb.Namespace), autoGeneratedCAValidity)
+		if err != nil {
+			return certificates.KeyPair{}, time.Time{}, err
+		}
+		if err := r.updateAutoGeneratedCASecret(ctx, mdb, ca, nil); err != nil {
+			return certificates.KeyPair{}, time.Time{}, err
+		}
+		return ca, now.Add(autoGeneratedCAValidity * 2 / 3), nil
+	}
+
+	nextCA := certificates.KeyPair{Certificate: string(existing.Data[tlsNextCACertName]), Key: string(existing.Data[tlsNextCAKeyName])}
+	previousCA := string(existing.Data[tlsPreviousCACertName])
+	if nextCA.Certificate == "" && previousCA == "" {
+		if now.Before(certificates.RenewalTime(cert)) {
+			return ca, certificates.RenewalTime(cert), nil
+		}
+
+		r.log.Infof("Rotating the CA of secret %s, trusting the new CA", nsName)
+		nextCA, err = certificates.NewCA(fmt.Sprintf("CN=%s-ca,OU=%s,O=MongoDB", mdb.Name, mdb.Namespace), autoGeneratedCAValidity)
+		if err != nil {
+			return certificates.KeyPair{}, time.Time{}, err
+		}
+		ca.Certificate = caBundle(ca.Certificate, nextCA.Certificate)
+		err = r.updateAutoGeneratedCASecret(ctx, mdb, ca, map[string]string{
+			tlsNextCACertName:     nextCA.Certificate,
+			tlsNextCAKeyName:      nextCA.Key,
+			tlsCARotationTimeName: now.Format(time.RFC3339),
+		})
+		return ca, now.Add(autoGeneratedCAOverlap), err
+	}
+
+	stepTime, err := time.Parse(time.RFC3339, string(existing.Data[tlsCARotationTimeName]))
+	if err != nil {
+		return certificates.KeyPair{}, time.Time{}, fmt.Errorf("could not read the time the CA rotation moved on: %s", err)
+	}
+	nextStep := stepTime.Add(autoGeneratedCAOverlap)
+	if now.Before(nextStep) || mdb.Status.Phase != mdbv1.Running {
+		return ca, nextStep, nil
+	}
+
+	if nextCA.Certificate != "" {
+		r.log.Infof("Rotating the CA of secret %s, signing the certificates with the new CA", nsName)
+		previousCA = encodeCertificate(cert)
+		ca = certificates.KeyPair{Certificate: caBundle(nextCA.Certificate, previousCA), Key: nextCA.Key}
+		err = r.updateAutoGeneratedCASecret(ctx, mdb, ca, map[string]string{
+			tlsPreviousCACertName: previousCA,
+			tlsCARotationTimeName: now.Format(time.RFC3339),
+		})
+		return ca, now.Add(autoGeneratedCAOverlap), err
+	}
+
+	r.log.Infof("Rotating the CA of secret %s, no longer trusting the previous CA", nsName)
+	ca.Certificate = encodeCertificate(cert)
+	if err := r.updateAutoGeneratedCASecret(ctx, mdb, ca, nil); err != nil {
+		return certificates.KeyPair{}, time.Time{}, err
+	}
+	return ca, certificates.RenewalTime(cert), nil
+}
+
+// updateAutoGeneratedCASecret stores the CA, and the given fields describing its rotation, in the secret of the CA.
+func (r ReplicaSetReconciler) updateAutoGeneratedCASecret(ctx context.Context, mdb mdbv1.MongoDBCommunity, ca certificates.KeyPair, rotation map[string]string) error {
+	nsName := mdb.TLSAutoGeneratedCANamespacedName()
+	caSecretBuilder := secret.Builder().
+		SetName(nsName.Name).
+		SetNamespace(nsName.Namespace).
+		SetField(tlsCACertName, ca.Certificate).
+		SetField(tlsCAKeyName, ca.Key).
+		SetOwnerReferences(mdb.GetOwnerReferences())
+	for key, value := range rotation {
+		caSecretBuilder.SetField(key, value)
+	}
+	return secret.CreateOrUpdate(ctx, r.client, caSecretBuilder.Build())
+}
+
+// caBundle returns the bundle of the given PEM encoded certificates.
+func caBundle(certs ...string) string {
+	bundle := ""
+	for _, cert := range certs {
+		bundle += strings.TrimSpace(cert) + "\n"
+	}
+	return bundle
+}
+
+// encodeCertificate returns the PEM encoding of the given certificate.
+func encodeCertificate(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+// ensureAutoGeneratedCertificate issues the certificate if its secret doesn't store a matching certificate signed by
+// the CA, updates the CA stored next to it, and returns the time after which it must be renewed.
+func (r ReplicaSetReconciler) ensureAutoGeneratedCertificate(ctx context.Context, mdb mdbv1.MongoDBCommunity, ca certificates.KeyPair, certificate autoGeneratedCertificate) (time.Time, error) {
+	existing, err := r.readOperatorManagedSecret(ctx, mdb, certificate.secret)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	keyPair := certificates.KeyPair{Certificate: string(existing.Data[tlsSecretCertName]), Key: string(existing.Data[tlsSecretKeyName])}
+	renewal := time.Now().Add(autoGeneratedCertificateValidity * 2 / 3)
+	if !certificates.NeedsRenewal(keyPair, ca, certificate.request, time.Now()) {
+		cert, err := certificates.ParseCertificate(keyPair.Certificate)
+		if err != nil {
+			return time.Time{}, err
+		}
+		renewal = certificates.RenewalTime(cert)
+		if string(existing.Data[tlsCACertName]) == ca.Certificate {
+			return renewal, nil
+		}
+		// the trusted CAs changed during a CA rotation, the certificate is still signed by one of them.
+		r.log.Infof("Updating the CA of secret %s", certificate.secret)
+	} else {
+		r.log.Infof("Issuing the certificate of secret %s", certificate.secret)
+		keyPair, err = certificates.Issue(ca, certificate.request)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	certificateSecret := secret.Builder().
+		SetName(certificate.secret.Name).
+		SetNamespace(certificate.secret.Namespace).
+		SetDataType(corev1.SecretTypeTLS).
+		SetField(tlsSecretCertName, keyPair.Certificate).
+		SetField(tlsSecretKeyName, keyPair.Key).
+		SetField(tlsSecretPemName, combineCertificateAndKey(keyPair.Certificate, keyPair.Key)).
+		SetField(tlsCACertName, ca.Certificate).
+		SetOwnerReferences(mdb.GetOwnerReferences()).
+		Build()
+	if err := secret.CreateOrUpdate(ctx, r.client, certificateSecret); err != nil {
+		return time.Time{}, err
+	}
+	return renewal, nil
+}
+
+// readOperatorManagedSecret returns the secret with the given name, or an empty secret if it doesn't exist. Secrets
+// which were not created by the operator for this resource are never overwritten.
+func (r ReplicaSetReconciler) readOperatorManagedSecret(ctx context.Context, mdb mdbv1.MongoDBCommunity, nsName types.NamespacedName) (corev1.Secret, error) {
+	existing, err := r.client.GetSecret(ctx, nsName)
+	if err != nil {
+		if apiErrors.IsNotFound(err) {
+			return corev1.Secret{}, nil
+		}
+		return corev1.Secret{}, err
+	}
+	if !secret.HasOwnerReferences(existing, mdb.GetOwnerReferences()) {
+		return corev1.Secret{}, fmt.Errorf("secret %s already exists and is not managed by the operator", nsName.Name)
+	}
+	return existing, nil
+}
+
+// autoGeneratedCertificates returns the certificates the operator issues for the resource: the server certificates,
+// the agent certificate if the agent presents one, the cluster certificate if the members present one to each other,
+// and the certificates of the X.509 users.
+func autoGeneratedCertificates(mdb mdbv1.MongoDBCommunity, clusterDomain string) []autoGeneratedCertificate {
+	certs := autoGeneratedServerCertificates(mdb, clusterDomain)
+
+	if mdb.Spec.IsAgentCertificateRequired() {
+		certs = append(certs, autoGeneratedCertificate{
+			secret: mdb.AgentCertificateSecretNamespacedName(),
+			request: certificates.Request{
+				// the organizational unit differs from the one of the members, so that the agent isn't considered a member.
+				Subject:     fmt.Sprintf("CN=mms-automation-agent,OU=%s-agent,O=MongoDB,C=US", mdb.Name),
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+				Validity:    autoGeneratedCertificateValidity,
+			},
+		})
+	}
+
+	if mdb.IsClusterCertificateRequired() {
+		certs = append(certs, autoGeneratedCertificate{
+			secret: mdb.ClusterCertificateSecretNamespacedName(),
+			request: certificates.Request{
+				// the organization and organizational unit are the ones of the server certificates, so that the members
+				// recognize each other.
+				Subject:     fmt.Sprintf("CN=%s-cluster,OU=%s,O=MongoDB", mdb.Name, mdb.Namespace),
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+				Validity:    autoGeneratedCertificateValidity,
+			},
+		})
+	}
+
+	if mdbv1.IsAuthPresent(mdb.Spec.Security.Authentication.Modes, "X509") {
+		for _, user := range mdb.Spec.Users {
+			// the other $external users, such as LDAP users, are not named after a distinguished name.
+			if user.DB != constants.ExternalDB {
+				continue
+			}
+			if _, err := certificates.ParseSubject(user.Name); err != nil {
+				continue
+			}
+			certs = append(certs, autoGeneratedCertificate{
+				secret: types.NamespacedName{Name: user.GetCertificateSecretName(mdb.Name), Namespace: mdb.Namespace},
+				request: certificates.Request{
+					Subject:     user.Name,
+					ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+					Validity:    autoGeneratedCertificateValidity,
+				},
+			})
+		}
+	}
+	return certs
+}
+
+// autoGeneratedServerCertificates returns the server certificate shared by all the members or, if each member has its
+// own certificate, the certificate of each member covering its hostnames only.
+func autoGeneratedServerCertificates(mdb mdbv1.MongoDBCommunity, clusterDomain string) []autoGeneratedCertificate {
+	domain := getDomain(mdb.ServiceName(), mdb.Namespace, clusterDomain)
+	serverCertificate := func(secretName types.NamespacedName, commonName string, dnsNames []string) autoGeneratedCertificate {
+		sort.Strings(dnsNames)
+		dnsNames = slices.Compact(dnsNames)
+		return autoGeneratedCertificate{
+			secret: secretName,
+			request: certificates.Request{
+				Subject:     fmt.Sprintf("CN=%s,OU=%s,O=MongoDB", commonName, mdb.Namespace),
+				DNSNames:    dnsNames,
+				ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+				Validity:    autoGeneratedCertificateValidity,
+			},
+		}
+	}
+
+	memberHostnames := tlsMemberHostnames(mdb, clusterDomain)
+	if !mdb.HasTLSMemberCertificates() {
+		dnsNames := []string{domain}
+		for _, podName := range tlsMemberPodNames(mdb) {
+			dnsNames = append(dnsNames, memberHostnames[podName]...)
+		}
+		return []autoGeneratedCertificate{serverCertificate(mdb.TLSSecretNamespacedName(), mdb.Name, dnsNames)}
+	}
+
+	var certs []autoGeneratedCertificate
+	for _, podName := range tlsMemberPodNames(mdb) {
+		dnsNames := append([]string{domain}, memberHostnames[podName]...)
+		certs = append(certs, serverCertificate(mdb.TLSMemberSecretNamespacedName(podName), podName, dnsNames))
+	}
+	return certs
+}
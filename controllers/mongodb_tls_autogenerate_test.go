@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/certificates"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+)
+
+func newAutoGeneratedTLSReplicaSet() mdbv1.MongoDBCommunity {
+	mdb := newScramReplicaSet(mdbv1.MongoDBUser{
+		Name:  "CN=app,OU=billing,O=example",
+		DB:    "$external",
+		Roles: []mdbv1.Role{{Name: "readWrite", DB: "app"}},
+	})
+	mdb.Spec.Security.TLS = mdbv1.TLS{Enabled: true, AutoGenerate: true}
+	mdb.Spec.Security.Authentication.Modes = []mdbv1.AuthMode{"X509"}
+	mdb.Spec.Security.Authentication.AgentMode = "X509"
+	mdb.Spec.ReplicaSetHorizons = mdbv1.ReplicaSetHorizonConfiguration{
+		{"external": "my-rs-0.example.com:27017"},
+		{"external": "my-rs-1.example.com:27017"},
+		{"external": "my-rs-2.example.com:27017"},
+	}
+	return mdb
+}
+
+func readKeyPair(ctx context.Context, t *testing.T, c client.Client, nsName types.NamespacedName, certKey, keyKey string) certificates.KeyPair {
+	s, err := c.GetSecret(ctx, nsName)
+	require.NoError(t, err)
+	return certificates.KeyPair{Certificate: string(s.Data[certKey]), Key: string(s.Data[keyKey])}
+}
+
+func TestAutoGeneratedTLS_CertificatesAreIssued(t *testing.T) {
+	ctx := context.Background()
+	mdb := newAutoGeneratedTLSReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+	assert.Greater(t, res.RequeueAfter.Hours(), float64(24*200), "the reconciliation is scheduled when the certificates must be renewed")
+
+	ca := readKeyPair(ctx, t, mgr.Client, mdb.TLSAutoGeneratedCANamespacedName(), tlsCACertName, tlsCAKeyName)
+	caCert, err := certificates.ParseCertificate(ca.Certificate)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+
+	t.Run("The server certificate covers all the hosts", func(t *testing.T) {
+		server := readKeyPair(ctx, t, mgr.Client, types.NamespacedName{Name: "my-rs-server-tls", Namespace: mdb.Namespace}, tlsSecretCertName, tlsSecretKeyName)
+		cert, err := certificates.ParseCertificate(server.Certificate)
+		require.NoError(t, err)
+		for _, address := range append(mdb.Hosts(""), "my-rs-svc.my-ns.svc.cluster.local:27017", "my-rs-0.example.com:27017") {
+			host, _, err := net.SplitHostPort(address)
+			require.NoError(t, err)
+			_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: host, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+			assert.NoError(t, err, host)
+		}
+	})
+
+	t.Run("The agent and user certificates are issued", func(t *testing.T) {
+		agent := readKeyPair(ctx, t, mgr.Client, mdb.AgentCertificateSecretNamespacedName(), tlsSecretCertName, tlsSecretKeyName)
+		agentCert, err := certificates.ParseCertificate(agent.Certificate)
+		require.NoError(t, err)
+		assert.Equal(t, "CN=mms-automation-agent,OU=my-rs-agent,O=MongoDB,C=US", agentCert.Subject.String())
+
+		user := readKeyPair(ctx, t, mgr.Client, types.NamespacedName{Name: "my-rs-cn-app-ou-billing-o-example-certificate", Namespace: mdb.Namespace}, tlsSecretCertName, tlsSecretKeyName)
+		userCert, err := certificates.ParseCertificate(user.Certificate)
+		require.NoError(t, err)
+		_, err = userCert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("The automation config uses the generated certificates", func(t *testing.T) {
+		ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+		require.NoError(t, err)
+		assert.Equal(t, tlsCAMountPath+tlsOperatorSecretFileName(ca.Certificate), ac.TLSConfig.CAFilePath)
+		assert.Equal(t, "CN=mms-automation-agent,OU=my-rs-agent,O=MongoDB,C=US", ac.Auth.AutoUser)
+		for _, process := range ac.Processes {
+			assert.Equal(t, string(automationconfig.TLSModeRequired), process.Args26.Get("net.tls.mode").Data())
+		}
+	})
+
+	t.Run("Valid certificates are not issued again", func(t *testing.T) {
+		server := readKeyPair(ctx, t, mgr.Client, mdb.TLSSecretNamespacedName(), tlsSecretCertName, tlsSecretKeyName)
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+
+		assert.Equal(t, server, readKeyPair(ctx, t, mgr.Client, mdb.TLSSecretNamespacedName(), tlsSecretCertName, tlsSecretKeyName))
+		assert.Equal(t, ca, readKeyPair(ctx, t, mgr.Client, mdb.TLSAutoGeneratedCANamespacedName(), tlsCACertName, tlsCAKeyName))
+	})
+
+	t.Run("The server certificate is issued again when a member is added", func(t *testing.T) {
+		server := readKeyPair(ctx, t, mgr.Client, mdb.TLSSecretNamespacedName(), tlsSecretCertName, tlsSecretKeyName)
+		err := mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+		require.NoError(t, err)
+		mdb.Spec.Members = 4
+		err = mgr.Client.Update(ctx, &mdb)
+		require.NoError(t, err)
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+
+		renewed := readKeyPair(ctx, t, mgr.Client, mdb.TLSSecretNamespacedName(), tlsSecretCertName, tlsSecretKeyName)
+		assert.NotEqual(t, server, renewed)
+		cert, err := certificates.ParseCertificate(renewed.Certificate)
+		require.NoError(t, err)
+		assert.Contains(t, cert.DNSNames, "my-rs-3.my-rs-svc.my-ns.svc.cluster.local")
+	})
+}
+
+func TestAutoGeneratedTLS_CAIsRotatedInSteps(t *testing.T) {
+	ctx := context.Background()
+	mdb := newAutoGeneratedTLSReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	// the CA is still valid, but two thirds of its validity elapsed.
+	oldCA, err := certificates.NewCA("CN=my-rs-ca,OU=my-ns,O=MongoDB", time.Minute)
+	require.NoError(t, err)
+	err = mgr.Client.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: mdb.TLSAutoGeneratedCANamespacedName().Name, Namespace: mdb.Namespace, OwnerReferences: mdb.GetOwnerReferences()},
+		Data:       map[string][]byte{tlsCACertName: []byte(oldCA.Certificate), tlsCAKeyName: []byte(oldCA.Key)},
+	})
+	require.NoError(t, err)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	reconcileCA := func(t *testing.T) corev1.Secret {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+		err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+		require.NoError(t, err)
+		require.Equal(t, mdbv1.Running, mdb.Status.Phase)
+		s, err := mgr.Client.GetSecret(ctx, mdb.TLSAutoGeneratedCANamespacedName())
+		require.NoError(t, err)
+		return s
+	}
+	// endOverlap makes the current step of the rotation look rolled out for long enough.
+	endOverlap := func(t *testing.T) {
+		s, err := mgr.Client.GetSecret(ctx, mdb.TLSAutoGeneratedCANamespacedName())
+		require.NoError(t, err)
+		s.Data[tlsCARotationTimeName] = []byte(time.Now().Add(-2 * autoGeneratedCAOverlap).Format(time.RFC3339))
+		require.NoError(t, mgr.Client.UpdateSecret(ctx, s))
+	}
+	serverCertificateSecret := func(t *testing.T) corev1.Secret {
+		s, err := mgr.Client.GetSecret(ctx, mdb.TLSSecretNamespacedName())
+		require.NoError(t, err)
+		return s
+	}
+	assertSignedBy := func(t *testing.T, ca string) {
+		caCert, err := certificates.ParseCertificate(ca)
+		require.NoError(t, err)
+		cert, err := certificates.ParseCertificate(string(serverCertificateSecret(t).Data[tlsSecretCertName]))
+		require.NoError(t, err)
+		assert.NoError(t, cert.CheckSignatureFrom(caCert))
+	}
+
+	var newCA certificates.KeyPair
+	t.Run("The new CA is trusted next to the current one", func(t *testing.T) {
+		s := reconcileCA(t)
+		newCA = certificates.KeyPair{Certificate: string(s.Data[tlsNextCACertName]), Key: string(s.Data[tlsNextCAKeyName])}
+		require.NotEmpty(t, newCA.Certificate)
+		bundle := caBundle(oldCA.Certificate, newCA.Certificate)
+		assert.Equal(t, bundle, string(s.Data[tlsCACertName]))
+		assert.Equal(t, oldCA.Key, string(s.Data[tlsCAKeyName]))
+		assertSignedBy(t, oldCA.Certificate)
+		assert.Equal(t, bundle, string(serverCertificateSecret(t).Data[tlsCACertName]))
+	})
+
+	t.Run("The rotation waits for the overlap period", func(t *testing.T) {
+		s := reconcileCA(t)
+		assert.Equal(t, newCA.Certificate, string(s.Data[tlsNextCACertName]))
+		assertSignedBy(t, oldCA.Certificate)
+	})
+
+	t.Run("The new CA signs the certificates", func(t *testing.T) {
+		endOverlap(t)
+		s := reconcileCA(t)
+		bundle := caBundle(newCA.Certificate, oldCA.Certificate)
+		assert.Equal(t, bundle, string(s.Data[tlsCACertName]))
+		assert.Equal(t, newCA.Key, string(s.Data[tlsCAKeyName]))
+		assert.Equal(t, oldCA.Certificate, string(s.Data[tlsPreviousCACertName]))
+		assert.NotContains(t, s.Data, tlsNextCACertName)
+		assertSignedBy(t, newCA.Certificate)
+		assert.Equal(t, bundle, string(serverCertificateSecret(t).Data[tlsCACertName]))
+	})
+
+	t.Run("The previous CA is no longer trusted", func(t *testing.T) {
+		endOverlap(t)
+		s := reconcileCA(t)
+		assert.Equal(t, newCA.Certificate, string(s.Data[tlsCACertName]))
+		assert.NotContains(t, s.Data, tlsPreviousCACertName)
+		assert.NotContains(t, s.Data, tlsCARotationTimeName)
+		assertSignedBy(t, newCA.Certificate)
+		assert.Equal(t, newCA.Certificate, string(serverCertificateSecret(t).Data[tlsCACertName]))
+	})
+}
+
+func TestAutoGeneratedTLS_UnmanagedSecretIsNotOverwritten(t *testing.T) {
+	ctx := context.Background()
+	mdb := newAutoGeneratedTLSReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	err := mgr.Client.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-rs-server-tls", Namespace: mdb.Namespace},
+		Data:       map[string][]byte{tlsSecretCertName: []byte("CERT"), tlsSecretKeyName: []byte("KEY")},
+	})
+	require.NoError(t, err)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+	assert.Contains(t, mdb.Status.Message, "secret my-rs-server-tls already exists and is not managed by the operator")
+
+	s, err := mgr.Client.GetSecret(ctx, types.NamespacedName{Name: "my-rs-server-tls", Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	assert.Equal(t, "CERT", string(s.Data[tlsSecretCertName]))
+}
+
+func TestAutoGeneratedTLS_MemberCertificatesCoverTheirMemberOnly(t *testing.T) {
+	ctx := context.Background()
+	mdb := newAutoGeneratedTLSReplicaSet()
+	mdb.Spec.Security.TLS.MemberCertificates = &mdbv1.MemberCertificates{SecretNameTemplate: "{pod}-cert"}
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	for i, podName := range []string{"my-rs-0", "my-rs-1", "my-rs-2"} {
+		member := readKeyPair(ctx, t, mgr.Client, types.NamespacedName{Name: podName + "-cert", Namespace: mdb.Namespace}, tlsSecretCertName, tlsSecretKeyName)
+		cert, err := certificates.ParseCertificate(member.Certificate)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{
+			"my-rs-svc.my-ns.svc.cluster.local",
+			podName + ".my-rs-svc.my-ns.svc.cluster.local",
+			fmt.Sprintf("my-rs-%d.example.com", i),
+		}, cert.DNSNames)
+	}
+
+	certificateKeys := map[string]bool{}
+	for _, podName := range []string{"my-rs-0", "my-rs-1", "my-rs-2"} {
+		memberSecret, err := mgr.Client.GetSecret(ctx, mdb.TLSOperatorMemberSecretNamespacedName(podName))
+		require.NoError(t, err)
+		certificateKeys[string(memberSecret.Data[tlsMemberCertificateFileName])] = true
+	}
+	assert.Len(t, certificateKeys, 3, "each pod is given its own certificate")
+}
+
+func TestAutoGeneratedTLS_ClusterCertificateIsIssued(t *testing.T) {
+	ctx := context.Background()
+	mdb := newAutoGeneratedTLSReplicaSet()
+	mdb.Spec.Security.Authentication.ClusterAuthMode = mdbv1.ClusterAuthModeX509
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	cluster := readKeyPair(ctx, t, mgr.Client, types.NamespacedName{Name: "my-rs-cluster-tls", Namespace: mdb.Namespace}, tlsSecretCertName, tlsSecretKeyName)
+	cert, err := certificates.ParseCertificate(cluster.Certificate)
+	require.NoError(t, err)
+	assert.Equal(t, "CN=my-rs-cluster,OU=my-ns,O=MongoDB", cert.Subject.String())
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}, cert.ExtKeyUsage)
+
+	ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	for _, process := range ac.Processes {
+		assert.Equal(t, "x509", process.Args26.Get("security.clusterAuthMode").Data())
+		assert.Equal(t, tlsOperatorSecretMountPath+tlsOperatorSecretFileName(combineCertificateAndKey(cluster.Certificate, cluster.Key)), process.Args26.Get("net.tls.clusterFile").Data())
+	}
+}
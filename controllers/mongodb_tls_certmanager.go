@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/certificates"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/contains"
+)
+
+// certManagerCertificateGVK is the kind of the cert-manager Certificate resources. They are handled as unstructured
+// objects, so that the operator doesn't depend on cert-manager being installed unless it is used.
+var certManagerCertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// certManagerCertificateSpecFields are the fields of the spec of the Certificates set by the operator. The other
+// fields, set by the users or defaulted by cert-manager, are left as they are.
+var certManagerCertificateSpecFields = []string{
+	"secretName",
+	"issuerRef",
+	"usages",
+	"commonName",
+	"literalSubject",
+	"subject",
+	"dnsNames",
+	"duration",
+	"renewBefore",
+}
+
+// newCertManagerCertificate returns an empty Certificate.
+func newCertManagerCertificate() *unstructured.Unstructured {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certManagerCertificateGVK)
+	return cert
+}
+
+// isCertManagerInstalled returns true if the Certificate kind is served by the cluster.
+func isCertManagerInstalled(mapper meta.RESTMapper) bool {
+	_, err := mapper.RESTMapping(certManagerCertificateGVK.GroupKind(), certManagerCertificateGVK.Version)
+	return err == nil
+}
+
+// ensureCertManagerCertificates creates or updates a cert-manager Certificate for each certificate of the resource,
+// when spec.security.tls.certManager is configured. The Certificates are named after the secrets cert-manager stores
+// the certificates in, which are the secrets the operator reads the certificates from.
+//
+// It returns true once every Certificate is ready.
+func (r ReplicaSetReconciler) ensureCertManagerCertificates(ctx context.Context, mdb mdbv1.MongoDBCommunity, clusterDomain string) (bool, error) {
+	if !mdb.IsTLSConfiguredThisReconciliation() || mdb.Spec.Security.TLS.CertManager == nil {
+		return true, nil
+	}
+
+	allReady := true
+	for _, certificate := range autoGeneratedCertificates(mdb, clusterDomain) {
+		ready, err := r.ensureCertManagerCertificate(ctx, mdb, certificate)
+		if err != nil {
+			return false, fmt.Errorf("could not ensure the cert-manager Certificate %s: %s", certificate.secret.Name, err)
+		}
+		allReady = allReady && ready
+	}
+	return allReady, nil
+}
+
+// ensureCertManagerCertificate creates the Certificate, or updates it if it doesn't request the certificate anymore,
+// and returns true if it is ready. Certificates which were not created by the operator for this resource are never
+// overwritten.
+func (r ReplicaSetReconciler) ensureCertManagerCertificate(ctx context.Context, mdb mdbv1.MongoDBCommunity, certificate autoGeneratedCertificate) (bool, error) {
+	desired, err := buildCertManagerCertificate(mdb, certificate)
+	if err != nil {
+		return false, err
+	}
+
+	existing := newCertManagerCertificate()
+	if err := r.client.Get(ctx, certificate.secret, existing); err != nil {
+		if !apiErrors.IsNotFound(err) {
+			return false, err
+		}
+		r.log.Infof("Creating the cert-manager Certificate %s", certificate.secret)
+		return false, r.client.Create(ctx, desired)
+	}
+
+	for _, ref := range mdb.GetOwnerReferences() {
+		if !contains.OwnerReferences(existing.GetOwnerReferences(), ref) {
+			return false, fmt.Errorf("the Certificate %s already exists and is not managed by the operator", certificate.secret.Name)
+		}
+	}
+
+	existingSpec, _, _ := unstructured.NestedMap(existing.Object, "spec")
+	if existingSpec == nil {
+		existingSpec = map[string]interface{}{}
+	}
+	desiredSpec := desired.Object["spec"].(map[string]interface{})
+	changed := false
+	for _, field := range certManagerCertificateSpecFields {
+		if equality.Semantic.DeepEqual(existingSpec[field], desiredSpec[field]) {
+			continue
+		}
+		changed = true
+		if value, ok := desiredSpec[field]; ok {
+			existingSpec[field] = value
+		} else {
+			delete(existingSpec, field)
+		}
+	}
+	if changed {
+		r.log.Infof("Updating the cert-manager Certificate %s", certificate.secret)
+		existing.Object["spec"] = existingSpec
+		return false, r.client.Update(ctx, existing)
+	}
+
+	if !isCertManagerCertificateReady(existing) {
+		r.log.Infof("Waiting for the cert-manager Certificate %s to be ready", certificate.secret)
+		return false, nil
+	}
+	return true, nil
+}
+
+// buildCertManagerCertificate returns the Certificate requesting the certificate from the issuer of
+// spec.security.tls.certManager.
+func buildCertManagerCertificate(mdb mdbv1.MongoDBCommunity, certificate autoGeneratedCertificate) (*unstructured.Unstructured, error) {
+	certManager := mdb.Spec.Security.TLS.CertManager
+	issuerRef := map[string]interface{}{"name": certManager.IssuerRef.Name}
+	if certManager.IssuerRef.Kind != "" {
+		issuerRef["kind"] = certManager.IssuerRef.Kind
+	}
+	if certManager.IssuerRef.Group != "" {
+		issuerRef["group"] = certManager.IssuerRef.Group
+	}
+
+	usages := []interface{}{"digital signature", "key encipherment"}
+	for _, usage := range certificate.request.ExtKeyUsage {
+		switch usage {
+		case x509.ExtKeyUsageServerAuth:
+			usages = append(usages, "server auth")
+		case x509.ExtKeyUsageClientAuth:
+			usages = append(usages, "client auth")
+		}
+	}
+
+	spec := map[string]interface{}{
+		"secretName": certificate.secret.Name,
+		"issuerRef":  issuerRef,
+		"usages":     usages,
+	}
+	if err := setCertManagerSubject(spec, certificate.request.Subject); err != nil {
+		return nil, err
+	}
+	if len(certificate.request.DNSNames) > 0 {
+		dnsNames := make([]interface{}, 0, len(certificate.request.DNSNames))
+		for _, dnsName := range certificate.request.DNSNames {
+			dnsNames = append(dnsNames, dnsName)
+		}
+		spec["dnsNames"] = dnsNames
+	}
+	if certManager.Duration != nil {
+		spec["duration"] = certManager.Duration.Duration.String()
+	}
+	if certManager.RenewBefore != nil {
+		spec["renewBefore"] = certManager.RenewBefore.Duration.String()
+	}
+
+	cert := newCertManagerCertificate()
+	cert.Object["spec"] = spec
+	cert.SetName(certificate.secret.Name)
+	cert.SetNamespace(certificate.secret.Namespace)
+	cert.SetOwnerReferences(mdb.GetOwnerReferences())
+	return cert, nil
+}
+
+// setCertManagerSubject sets the subject of the Certificate. The X.509 users and the agent authenticate with the
+// subject of their certificate, so it must be issued exactly as requested: the structured subject fields of
+// cert-manager are used when they encode the same subject, and the literal subject otherwise.
+func setCertManagerSubject(spec map[string]interface{}, subject string) error {
+	rdns, err := certificates.ParseSubject(subject)
+	if err != nil {
+		return err
+	}
+	var name pkix.Name
+	name.FillFromRDNSequence(&rdns)
+	if name.ToRDNSequence().String() != rdns.String() {
+		spec["literalSubject"] = subject
+		return nil
+	}
+
+	if name.CommonName != "" {
+		spec["commonName"] = name.CommonName
+	}
+	fields := map[string]interface{}{}
+	for field, values := range map[string][]string{
+		"countries":           name.Country,
+		"organizations":       name.Organization,
+		"organizationalUnits": name.OrganizationalUnit,
+		"localities":          name.Locality,
+		"provinces":           name.Province,
+		"streetAddresses":     name.StreetAddress,
+		"postalCodes":         name.PostalCode,
+	} {
+		if len(values) == 0 {
+			continue
+		}
+		list := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			list = append(list, value)
+		}
+		fields[field] = list
+	}
+	if name.SerialNumber != "" {
+		fields["serialNumber"] = name.SerialNumber
+	}
+	if len(fields) > 0 {
+		spec["subject"] = fields
+	}
+	return nil
+}
+
+// isCertManagerCertificateReady returns true if the Ready condition of the Certificate is true for its current spec.
+func isCertManagerCertificateReady(cert *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		if observedGeneration, found, _ := unstructured.NestedInt64(condition, "observedGeneration"); found && observedGeneration != cert.GetGeneration() {
+			return false
+		}
+		return condition["status"] == "True"
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/certificates"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+)
+
+func newCertManagerTLSReplicaSet() mdbv1.MongoDBCommunity {
+	mdb := newAutoGeneratedTLSReplicaSet()
+	mdb.Spec.Security.TLS = mdbv1.TLS{
+		Enabled: true,
+		CertManager: &mdbv1.CertManager{
+			IssuerRef: mdbv1.CertManagerIssuerReference{Name: "ca-issuer", Kind: "ClusterIssuer"},
+			Duration:  &metav1.Duration{Duration: 2160 * time.Hour},
+		},
+	}
+	return mdb
+}
+
+func getCertManagerCertificate(ctx context.Context, t *testing.T, c client.Client, nsName types.NamespacedName) *unstructured.Unstructured {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certManagerCertificateGVK)
+	require.NoError(t, c.Get(ctx, nsName, cert))
+	return cert
+}
+
+// issueCertManagerCertificates does what cert-manager does for the Certificates of the resource: it stores the
+// certificates, signed by ca, in their secrets and marks the Certificates as ready.
+func issueCertManagerCertificates(ctx context.Context, t *testing.T, c client.Client, mdb mdbv1.MongoDBCommunity, ca certificates.KeyPair) {
+	for _, certificate := range autoGeneratedCertificates(mdb, "") {
+		keyPair, err := certificates.Issue(ca, certificate.request)
+		require.NoError(t, err)
+		s := secret.Builder().
+			SetName(certificate.secret.Name).
+			SetNamespace(certificate.secret.Namespace).
+			SetDataType(corev1.SecretTypeTLS).
+			SetField(tlsSecretCertName, keyPair.Certificate).
+			SetField(tlsSecretKeyName, keyPair.Key).
+			SetField(tlsCACertName, ca.Certificate).
+			Build()
+		require.NoError(t, secret.CreateOrUpdate(ctx, c, s))
+
+		cert := getCertManagerCertificate(ctx, t, c, certificate.secret)
+		require.NoError(t, unstructured.SetNestedSlice(cert.Object, []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		}, "status", "conditions"))
+		require.NoError(t, c.Update(ctx, cert))
+	}
+}
+
+func TestCertManagerTLS_CertificatesAreRequested(t *testing.T) {
+	ctx := context.Background()
+	mdb := newCertManagerTLSReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	t.Run("The reconciliation waits for the certificates", func(t *testing.T) {
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+		assert.Equal(t, mdbv1.Pending, mdb.Status.Phase)
+		assert.Equal(t, "The cert-manager TLS certificates are not yet ready, retrying in 10 seconds", mdb.Status.Message)
+	})
+
+	t.Run("The server certificate covers all the hosts", func(t *testing.T) {
+		cert := getCertManagerCertificate(ctx, t, mgr.Client, types.NamespacedName{Name: "my-rs-server-tls", Namespace: mdb.Namespace})
+		assert.Equal(t, mdb.GetOwnerReferences(), cert.GetOwnerReferences())
+
+		spec := cert.Object["spec"].(map[string]interface{})
+		assert.Equal(t, "my-rs-server-tls", spec["secretName"])
+		assert.Equal(t, map[string]interface{}{"name": "ca-issuer", "kind": "ClusterIssuer"}, spec["issuerRef"])
+		assert.Equal(t, "2160h0m0s", spec["duration"])
+		assert.Equal(t, "my-rs", spec["commonName"])
+		assert.Equal(t, map[string]interface{}{"organizations": []interface{}{"MongoDB"}, "organizationalUnits": []interface{}{"my-ns"}}, spec["subject"])
+		assert.Equal(t, []interface{}{"digital signature", "key encipherment", "server auth", "client auth"}, spec["usages"])
+		assert.Subset(t, spec["dnsNames"], []interface{}{
+			"my-rs-svc.my-ns.svc.cluster.local",
+			"my-rs-0.my-rs-svc.my-ns.svc.cluster.local",
+			"my-rs-2.my-rs-svc.my-ns.svc.cluster.local",
+			"my-rs-0.example.com",
+		})
+	})
+
+	t.Run("The agent and user certificates are requested", func(t *testing.T) {
+		agent := getCertManagerCertificate(ctx, t, mgr.Client, mdb.AgentCertificateSecretNamespacedName())
+		assert.Equal(t, "mms-automation-agent", agent.Object["spec"].(map[string]interface{})["commonName"])
+		assert.Equal(t, []interface{}{"digital signature", "key encipherment", "client auth"}, agent.Object["spec"].(map[string]interface{})["usages"])
+
+		user := getCertManagerCertificate(ctx, t, mgr.Client, types.NamespacedName{Name: "my-rs-cn-app-ou-billing-o-example-certificate", Namespace: mdb.Namespace})
+		assert.Equal(t, "app", user.Object["spec"].(map[string]interface{})["commonName"])
+	})
+
+	t.Run("The issued certificates are used once ready", func(t *testing.T) {
+		ca, err := certificates.NewCA("CN=ca,O=example", 24*time.Hour*365)
+		require.NoError(t, err)
+		issueCertManagerCertificates(ctx, t, mgr.Client, mdb, ca)
+
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationRequeuedForCertificateExpiry(t, res, err)
+
+		ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+		require.NoError(t, err)
+		assert.Equal(t, tlsCAMountPath+tlsOperatorSecretFileName(ca.Certificate), ac.TLSConfig.CAFilePath)
+		assert.Equal(t, "CN=mms-automation-agent,OU=my-rs-agent,O=MongoDB,C=US", ac.Auth.AutoUser)
+	})
+
+	t.Run("The server certificate is requested again when a member is added", func(t *testing.T) {
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+		mdb.Spec.Members = 4
+		require.NoError(t, mgr.Client.Update(ctx, &mdb))
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+
+		cert := getCertManagerCertificate(ctx, t, mgr.Client, mdb.TLSSecretNamespacedName())
+		assert.Contains(t, cert.Object["spec"].(map[string]interface{})["dnsNames"], "my-rs-3.my-rs-svc.my-ns.svc.cluster.local")
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+		assert.Equal(t, mdbv1.Pending, mdb.Status.Phase)
+	})
+}
+
+func TestCertManagerTLS_WatchedCertificatesAreNotPolled(t *testing.T) {
+	ctx := context.Background()
+	mdb := newCertManagerTLSReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	r.certManagerCertificatesWatched = true
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, res)
+
+	require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+	assert.Equal(t, mdbv1.Pending, mdb.Status.Phase)
+	assert.Equal(t, "The cert-manager TLS certificates are not yet ready", mdb.Status.Message)
+}
+
+func TestCertManagerTLS_OnlyOperatorFieldsAreUpdated(t *testing.T) {
+	ctx := context.Background()
+	mdb := newCertManagerTLSReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	privateKey := map[string]interface{}{"rotationPolicy": "Always"}
+	cert := getCertManagerCertificate(ctx, t, mgr.Client, mdb.TLSSecretNamespacedName())
+	require.NoError(t, unstructured.SetNestedMap(cert.Object, privateKey, "spec", "privateKey"))
+	require.NoError(t, mgr.Client.Update(ctx, cert))
+	resourceVersion := getCertManagerCertificate(ctx, t, mgr.Client, mdb.TLSSecretNamespacedName()).GetResourceVersion()
+
+	t.Run("Fields not set by the operator don't update the Certificate", func(t *testing.T) {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+
+		cert := getCertManagerCertificate(ctx, t, mgr.Client, mdb.TLSSecretNamespacedName())
+		assert.Equal(t, resourceVersion, cert.GetResourceVersion())
+	})
+
+	t.Run("Fields not set by the operator are kept when the Certificate is updated", func(t *testing.T) {
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+		mdb.Spec.Security.TLS.CertManager.Duration = nil
+		require.NoError(t, mgr.Client.Update(ctx, &mdb))
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+
+		spec := getCertManagerCertificate(ctx, t, mgr.Client, mdb.TLSSecretNamespacedName()).Object["spec"].(map[string]interface{})
+		assert.NotContains(t, spec, "duration")
+		assert.Equal(t, privateKey, spec["privateKey"])
+	})
+}
+
+func TestCertManagerTLS_UnmanagedCertificateIsNotOverwritten(t *testing.T) {
+	ctx := context.Background()
+	mdb := newCertManagerTLSReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"secretName": "my-rs-server-tls"}}}
+	existing.SetGroupVersionKind(certManagerCertificateGVK)
+	existing.SetName("my-rs-server-tls")
+	existing.SetNamespace(mdb.Namespace)
+	require.NoError(t, mgr.Client.Create(ctx, existing))
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+	assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+	assert.Contains(t, mdb.Status.Message, "the Certificate my-rs-server-tls already exists and is not managed by the operator")
+}
+
+func TestSetCertManagerSubject(t *testing.T) {
+	tests := []struct {
+		name         string
+		subject      string
+		expectedSpec map[string]interface{}
+	}{
+		{
+			name:    "Subject encoded by the subject fields",
+			subject: "CN=mms-automation-agent,OU=my-rs-agent,O=MongoDB,C=US",
+			expectedSpec: map[string]interface{}{
+				"commonName": "mms-automation-agent",
+				"subject": map[string]interface{}{
+					"organizationalUnits": []interface{}{"my-rs-agent"},
+					"organizations":       []interface{}{"MongoDB"},
+					"countries":           []interface{}{"US"},
+				},
+			},
+		},
+		{
+			name:         "Subject in a different order",
+			subject:      "O=example,CN=app",
+			expectedSpec: map[string]interface{}{"literalSubject": "O=example,CN=app"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := map[string]interface{}{}
+			require.NoError(t, setCertManagerSubject(spec, tt.subject))
+			assert.Equal(t, tt.expectedSpec, spec)
+		})
+	}
+}
+
+func TestIsCertManagerCertificateReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		generation int64
+		conditions []interface{}
+		expected   bool
+	}{
+		{name: "No condition"},
+		{name: "Ready", conditions: []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}}, expected: true},
+		{name: "Not ready", conditions: []interface{}{map[string]interface{}{"type": "Ready", "status": "False"}}},
+		{name: "Ready for the current spec", generation: 2, conditions: []interface{}{map[string]interface{}{"type": "Ready", "status": "True", "observedGeneration": int64(2)}}, expected: true},
+		{name: "Ready for a previous spec", generation: 2, conditions: []interface{}{map[string]interface{}{"type": "Ready", "status": "True", "observedGeneration": int64(1)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			cert.SetGeneration(tt.generation)
+			if tt.conditions != nil {
+				require.NoError(t, unstructured.SetNestedSlice(cert.Object, tt.conditions, "status", "conditions"))
+			}
+			assert.Equal(t, tt.expected, isCertManagerCertificateReady(cert))
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/mongodb/mongodb-kubernetes-operator/controllers/construct"
 
@@ -20,7 +21,11 @@ import (
 	kubeClient "github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/configmap"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/statefulset"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
 )
 
 func TestStatefulSetIsCorrectlyConfiguredWithTLS(t *testing.T) {
@@ -63,7 +68,7 @@ func TestStatefulSetIsCorrectlyConfiguredWithTLSAndX509(t *testing.T) {
 
 	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", "fake-agentImage", "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
 	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: mdb.Namespace, Name: mdb.Name}})
-	assertReconciliationSuccessful(t, res, err)
+	assertReconciliationRequeuedForCertificateExpiry(t, res, err)
 
 	sts := appsv1.StatefulSet{}
 	err = mgr.GetClient().Get(ctx, types.NamespacedName{Name: mdb.Name, Namespace: mdb.Namespace}, &sts)
@@ -373,6 +378,232 @@ func TestAutomationConfigIsCorrectlyConfiguredWithTLS(t *testing.T) {
 	})
 }
 
+func TestAutomationConfigIsCorrectlyConfiguredWithTLSSettings(t *testing.T) {
+	ctx := context.Background()
+	mdb := newTestReplicaSetWithTLS()
+	mdb.Spec.Security.TLS.RequireClientCertificates = true
+	mdb.Spec.Security.TLS.CrlConfigMap = &corev1.LocalObjectReference{Name: "crl"}
+	mdb.Spec.Security.TLS.DisabledProtocols = []mdbv1.TLSProtocol{mdbv1.TLSProtocol10, mdbv1.TLSProtocol11}
+	mdb.Spec.Security.TLS.CipherConfig = "HIGH:!EXPORT:!aNULL@STRENGTH"
+	mdb.Spec.Security.TLS.CipherSuiteConfig = "TLS_AES_256_GCM_SHA384"
+	mdb.Spec.Security.TLS.Ocsp = &mdbv1.TLSOcsp{Enabled: ptr.To(false), VerifyTimeoutSecs: ptr.To(10)}
+
+	mgr := kubeClient.NewManager(ctx, &mdb)
+	client := kubeClient.NewClient(mgr.GetClient())
+	err := createTLSSecret(ctx, client, mdb, "CERT", "KEY", "")
+	assert.NoError(t, err)
+	err = createTLSConfigMap(ctx, client, mdb)
+	assert.NoError(t, err)
+	crt, key, err := x509.CreateAgentCertificate()
+	assert.NoError(t, err)
+	err = createAgentCertSecret(ctx, client, mdb, crt, key, "")
+	assert.NoError(t, err)
+	crlConfigMap := configmap.Builder().SetName("crl").SetNamespace(mdb.Namespace).SetDataField(tlsCrlName, "CRL").Build()
+	err = client.Create(ctx, &crlConfigMap)
+	assert.NoError(t, err)
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationRequeuedForCertificateExpiry(t, res, err)
+
+	ac, err := automationconfig.ReadFromSecret(ctx, client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+	assert.NoError(t, err)
+
+	t.Run("The agent presents its certificate", func(t *testing.T) {
+		assert.Equal(t, automationconfig.ClientCertificateModeRequired, ac.TLSConfig.ClientCertificateMode)
+		assert.Equal(t, automationAgentPemMountPath+"/"+mdb.AgentCertificatePemSecretNamespacedName().Name, ac.TLSConfig.AutoPEMKeyFilePath)
+		assert.NotEmpty(t, ac.Auth.AutoPwd, "the agent still authenticates with SCRAM")
+
+		sts, err := client.GetStatefulSet(ctx, mdb.NamespacedName())
+		assert.NoError(t, err)
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name == construct.AgentName {
+				assert.True(t, statefulset.VolumeMountWithNameExists(c.VolumeMounts, constants.AgentPemFile))
+			}
+		}
+	})
+
+	t.Run("The processes are configured with the TLS settings", func(t *testing.T) {
+		for _, process := range ac.Processes {
+			assert.False(t, process.Args26.Get("net.tls.allowConnectionsWithoutCertificates").MustBool())
+			assert.Equal(t, tlsCAMountPath+tlsOperatorCrlFileName("CRL"), process.Args26.Get("net.tls.CRLFile").Data())
+			assert.Equal(t, "TLS1_0,TLS1_1", process.Args26.Get("net.tls.disabledProtocols").Data())
+			assert.Equal(t, "HIGH:!EXPORT:!aNULL@STRENGTH", process.Args26.Get("setParameter.opensslCipherConfig").Data())
+			assert.Equal(t, "TLS_AES_256_GCM_SHA384", process.Args26.Get("setParameter.opensslCipherSuiteConfig").Data())
+			assert.False(t, process.Args26.Get("setParameter.ocspEnabled").MustBool())
+			assert.Equal(t, 10, process.Args26.Get("setParameter.tlsOCSPVerifyTimeoutSecs").MustInt())
+			assert.False(t, process.Args26.Has("setParameter.tlsOCSPStaplingTimeoutSecs"))
+		}
+	})
+
+	t.Run("The CRL is mounted with the CA", func(t *testing.T) {
+		crl, err := secret.ReadKey(ctx, client, tlsOperatorCrlFileName("CRL"), mdb.TLSOperatorCASecretNamespacedName())
+		assert.NoError(t, err)
+		assert.Equal(t, "CRL", crl)
+	})
+}
+
+func TestTLSIsEnabledAndDisabledInStages(t *testing.T) {
+	ctx := context.Background()
+	mdb := newTestReplicaSetWithTLS()
+	mdb.Spec.Security.TLS.Enabled = false
+	mgr := kubeClient.NewManager(ctx, &mdb)
+	client := kubeClient.NewClient(mgr.GetClient())
+	assert.NoError(t, createTLSSecret(ctx, client, mdb, "CERT", "KEY", ""))
+	assert.NoError(t, createTLSConfigMap(ctx, client, mdb))
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	assert.Equal(t, automationconfig.TLSModeDisabled, tlsMode(ctx, t, mgr.Client, mdb))
+
+	setTLSEnabled := func(enabled bool) {
+		err := mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+		assert.NoError(t, err)
+		mdb.Spec.Security.TLS.Enabled = enabled
+		assert.NoError(t, mgr.Client.Update(ctx, &mdb))
+	}
+	assertStages := func(t *testing.T, stages ...automationconfig.TLSMode) {
+		for _, stage := range stages {
+			res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+			assert.NoError(t, err)
+			assert.Equal(t, time.Second, res.RequeueAfter)
+			assert.Equal(t, stage, tlsMode(ctx, t, mgr.Client, mdb))
+
+			ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+			assert.NoError(t, err)
+			for _, process := range ac.Processes {
+				if stage == automationconfig.TLSModeDisabled {
+					assert.False(t, process.Args26.Has("net.tls"))
+				} else {
+					assert.Equal(t, string(stage), process.Args26.Get("net.tls.mode").Data())
+				}
+			}
+		}
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+	}
+
+	t.Run("TLS is enabled one mode at a time", func(t *testing.T) {
+		setTLSEnabled(true)
+		assertStages(t, automationconfig.TLSModeAllowed, automationconfig.TLSModePreferred, automationconfig.TLSModeRequired)
+
+		sts, err := mgr.Client.GetStatefulSet(ctx, mdb.NamespacedName())
+		assert.NoError(t, err)
+		assertStatefulSetVolumesAndVolumeMounts(t, sts, mdb.TLSOperatorCASecretNamespacedName().Name, mdb.TLSOperatorSecretNamespacedName().Name, "", "")
+	})
+
+	t.Run("TLS is disabled one mode at a time", func(t *testing.T) {
+		setTLSEnabled(false)
+		assertStages(t, automationconfig.TLSModePreferred, automationconfig.TLSModeAllowed, automationconfig.TLSModeDisabled)
+
+		sts, err := mgr.Client.GetStatefulSet(ctx, mdb.NamespacedName())
+		assert.NoError(t, err)
+		for _, volume := range sts.Spec.Template.Spec.Volumes {
+			assert.NotEqual(t, "tls-secret", volume.Name, "the certificates are not mounted once TLS is disabled")
+		}
+	})
+}
+
+func TestTLSIsDisabledInStagesWithoutARecordedMode(t *testing.T) {
+	ctx := context.Background()
+	mdb := newTestReplicaSetWithTLS()
+	mgr := kubeClient.NewManager(ctx, &mdb)
+	client := kubeClient.NewClient(mgr.GetClient())
+	assert.NoError(t, createTLSSecret(ctx, client, mdb, "CERT", "KEY", ""))
+	assert.NoError(t, createTLSConfigMap(ctx, client, mdb))
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	assert.Equal(t, automationconfig.TLSModeRequired, tlsMode(ctx, t, mgr.Client, mdb))
+
+	// a resource deployed by a previous version of the operator doesn't have a recorded mode.
+	err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+	assert.NoError(t, err)
+	mdb.Status.TLSMode = ""
+	assert.NoError(t, mgr.Client.Status().Update(ctx, &mdb))
+	mdb.Spec.Security.TLS.Enabled = false
+	assert.NoError(t, mgr.Client.Update(ctx, &mdb))
+
+	res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, res.RequeueAfter)
+	assert.Equal(t, automationconfig.TLSModePreferred, tlsMode(ctx, t, mgr.Client, mdb))
+
+	ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+	assert.NoError(t, err)
+	for _, process := range ac.Processes {
+		assert.Equal(t, string(automationconfig.TLSModePreferred), process.Args26.Get("net.tls.mode").Data())
+	}
+}
+
+func tlsMode(ctx context.Context, t *testing.T, c kubeClient.Client, mdb mdbv1.MongoDBCommunity) automationconfig.TLSMode {
+	err := c.Get(ctx, mdb.NamespacedName(), &mdb)
+	assert.NoError(t, err)
+	return mdb.Status.TLSMode
+}
+
+func TestClusterAuthModeIsChangedInStages(t *testing.T) {
+	ctx := context.Background()
+	mdb := newTestReplicaSetWithTLS()
+	mgr := kubeClient.NewManager(ctx, &mdb)
+	client := kubeClient.NewClient(mgr.GetClient())
+	assert.NoError(t, createTLSSecret(ctx, client, mdb, "CERT", "KEY", ""))
+	assert.NoError(t, createTLSConfigMap(ctx, client, mdb))
+	assert.NoError(t, createTLSSecretWithNamespaceAndName(ctx, client, mdb.Namespace, "my-rs-cluster-tls", "CLUSTER_CERT", "CLUSTER_KEY", ""))
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	setClusterAuthMode := func(mode mdbv1.ClusterAuthMode) {
+		err := mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+		assert.NoError(t, err)
+		mdb.Spec.Security.Authentication.ClusterAuthMode = mode
+		assert.NoError(t, mgr.Client.Update(ctx, &mdb))
+	}
+	clusterFile := tlsOperatorSecretMountPath + tlsOperatorSecretFileName("CLUSTER_CERT\nCLUSTER_KEY")
+	assertStages := func(t *testing.T, stages ...mdbv1.ClusterAuthMode) {
+		for _, stage := range stages {
+			res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+			assert.NoError(t, err)
+			assert.Equal(t, time.Second, res.RequeueAfter)
+			err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+			assert.NoError(t, err)
+			assert.Equal(t, stage, mdb.Status.ClusterAuthMode)
+
+			ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+			assert.NoError(t, err)
+			for _, process := range ac.Processes {
+				if stage == mdbv1.ClusterAuthModeKeyFile {
+					assert.False(t, process.Args26.Has("security.clusterAuthMode"))
+					assert.False(t, process.Args26.Has("net.tls.clusterFile"))
+				} else {
+					assert.Equal(t, string(stage), process.Args26.Get("security.clusterAuthMode").Data())
+					assert.Equal(t, clusterFile, process.Args26.Get("net.tls.clusterFile").Data())
+				}
+			}
+		}
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assertReconciliationSuccessful(t, res, err)
+	}
+
+	t.Run("The members move to X.509 one mode at a time", func(t *testing.T) {
+		setClusterAuthMode(mdbv1.ClusterAuthModeX509)
+		assertStages(t, mdbv1.ClusterAuthModeSendKeyFile, mdbv1.ClusterAuthModeSendX509, mdbv1.ClusterAuthModeX509)
+
+		operatorSecret, err := mgr.Client.GetSecret(ctx, mdb.TLSOperatorSecretNamespacedName())
+		assert.NoError(t, err)
+		assert.Equal(t, "CLUSTER_CERT\nCLUSTER_KEY", string(operatorSecret.Data[tlsOperatorSecretFileName("CLUSTER_CERT\nCLUSTER_KEY")]))
+	})
+
+	t.Run("The members move back to the keyfile one mode at a time", func(t *testing.T) {
+		setClusterAuthMode(mdbv1.ClusterAuthModeKeyFile)
+		assertStages(t, mdbv1.ClusterAuthModeSendX509, mdbv1.ClusterAuthModeSendKeyFile, mdbv1.ClusterAuthModeKeyFile)
+	})
+}
+
 func TestTLSOperatorSecret(t *testing.T) {
 	ctx := context.Background()
 	t.Run("Secret is created if it doesn't exist", func(t *testing.T) {
@@ -385,7 +616,7 @@ func TestTLSOperatorSecret(t *testing.T) {
 
 		r := NewReconciler(kubeClient.NewManagerWithClient(c), "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", "fake-agentImage", "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
 
-		err = r.ensureTLSResources(ctx, mdb)
+		err = r.ensureTLSResources(ctx, r.client, mdb)
 		assert.NoError(t, err)
 
 		// Operator-managed secret should have been created and contains the
@@ -415,7 +646,7 @@ func TestTLSOperatorSecret(t *testing.T) {
 
 		r := NewReconciler(kubeClient.NewManagerWithClient(k8sclient), "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", "fake-agentImage", "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
 
-		err = r.ensureTLSResources(ctx, mdb)
+		err = r.ensureTLSResources(ctx, r.client, mdb)
 		assert.NoError(t, err)
 
 		// Operator-managed secret should have been updated with the concatenated
@@ -427,6 +658,110 @@ func TestTLSOperatorSecret(t *testing.T) {
 	})
 }
 
+func TestMemberCertificates(t *testing.T) {
+	ctx := context.Background()
+	mdb := newTestReplicaSetWithTLSCaCertificateReferences(&corev1.LocalObjectReference{Name: "caConfigMap"}, nil)
+	mdb.Spec.Security.TLS.CertificateKeySecret = corev1.LocalObjectReference{}
+	mdb.Spec.Security.TLS.MemberCertificates = &mdbv1.MemberCertificates{
+		SecretNameTemplate: "{pod}-cert",
+		SecretRefs:         map[string]corev1.LocalObjectReference{"my-rs-2": {Name: "third-member-cert"}},
+	}
+	mgr := kubeClient.NewManager(ctx, &mdb)
+	c := kubeClient.NewClient(mgr.GetClient())
+	err := createTLSConfigMap(ctx, c, mdb)
+	assert.NoError(t, err)
+	for _, secretName := range []string{"my-rs-0-cert", "my-rs-1-cert", "third-member-cert"} {
+		err = createTLSSecretWithNamespaceAndName(ctx, c, mdb.Namespace, secretName, "CERT-"+secretName, "KEY-"+secretName, "")
+		assert.NoError(t, err)
+	}
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	expectedCertKeys := map[string]string{
+		"my-rs-0": "CERT-my-rs-0-cert\nKEY-my-rs-0-cert",
+		"my-rs-1": "CERT-my-rs-1-cert\nKEY-my-rs-1-cert",
+		"my-rs-2": "CERT-third-member-cert\nKEY-third-member-cert",
+	}
+
+	t.Run("Each member certificate is stored in the secret of its own pod", func(t *testing.T) {
+		operatorSecret, err := c.GetSecret(ctx, mdb.TLSOperatorSecretNamespacedName())
+		assert.NoError(t, err)
+		assert.Empty(t, operatorSecret.Data)
+
+		for podName, certKey := range expectedCertKeys {
+			memberSecret, err := c.GetSecret(ctx, mdb.TLSOperatorMemberSecretNamespacedName(podName))
+			assert.NoError(t, err)
+			assert.Equal(t, map[string][]byte{tlsMemberCertificateFileName: []byte(certKey)}, memberSecret.Data)
+		}
+	})
+
+	t.Run("Each process uses the certificate of its member", func(t *testing.T) {
+		ac, err := automationconfig.ReadFromSecret(ctx, c, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+		assert.NoError(t, err)
+		assert.Len(t, ac.Processes, len(expectedCertKeys))
+		for _, process := range ac.Processes {
+			assert.Equal(t, tlsMemberCertificateMountPath+tlsMemberCertificateFileName, process.Args26.Get("net.tls.certificateKeyFile").Data())
+		}
+	})
+
+	t.Run("The containers only mount the certificate of their own pod", func(t *testing.T) {
+		sts, err := c.GetStatefulSet(ctx, mdb.NamespacedName())
+		assert.NoError(t, err)
+		podSpec := sts.Spec.Template.Spec
+
+		for _, c := range podSpec.Containers {
+			for _, volumeMount := range c.VolumeMounts {
+				assert.NotEqual(t, tlsMemberSecretsVolumeName, volumeMount.Name, "container %s mounts the certificates of every member", c.Name)
+			}
+			assert.Contains(t, c.VolumeMounts, corev1.VolumeMount{Name: tlsMemberCertificateVolumeName, MountPath: tlsMemberCertificateMountPath, ReadOnly: true})
+		}
+
+		var memberCertificateVolume, secretVolume *corev1.Volume
+		for i, volume := range podSpec.Volumes {
+			switch volume.Name {
+			case tlsMemberCertificateVolumeName:
+				memberCertificateVolume = &podSpec.Volumes[i]
+			case tlsSecretVolumeName:
+				secretVolume = &podSpec.Volumes[i]
+			}
+		}
+		require.NotNil(t, memberCertificateVolume)
+		assert.NotNil(t, memberCertificateVolume.EmptyDir)
+		require.NotNil(t, secretVolume)
+		assert.Equal(t, mdb.TLSOperatorSecretNamespacedName().Name, secretVolume.Secret.SecretName)
+
+		var initContainer *corev1.Container
+		for i := range podSpec.InitContainers {
+			if podSpec.InitContainers[i].Name == tlsMemberCertificateInitContainerName {
+				initContainer = &podSpec.InitContainers[i]
+			}
+		}
+		require.NotNil(t, initContainer)
+		assert.Contains(t, initContainer.VolumeMounts, corev1.VolumeMount{Name: tlsMemberSecretsVolumeName, MountPath: tlsMemberSecretsMountPath, ReadOnly: true})
+		hash, err := tlsMemberCertificatesHash(ctx, c, mdb)
+		assert.NoError(t, err)
+		assert.Contains(t, initContainer.Env, corev1.EnvVar{Name: tlsMemberCertificatesHashEnv, Value: hash})
+	})
+
+	t.Run("A missing member certificate blocks the reconciliation", func(t *testing.T) {
+		err := c.Get(ctx, mdb.NamespacedName(), &mdb)
+		assert.NoError(t, err)
+		mdb.Spec.Members = 4
+		err = c.Update(ctx, &mdb)
+		assert.NoError(t, err)
+
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		assert.NoError(t, err)
+		assert.True(t, res.RequeueAfter > 0)
+
+		err = c.Get(ctx, mdb.NamespacedName(), &mdb)
+		assert.NoError(t, err)
+		assert.Equal(t, mdbv1.Pending, mdb.Status.Phase)
+	})
+}
+
 func TestCombineCertificateAndKey(t *testing.T) {
 	tests := []struct {
 		Cert     string
@@ -458,7 +793,7 @@ func TestPemSupport(t *testing.T) {
 
 		r := NewReconciler(kubeClient.NewManagerWithClient(c), "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", "fake-agentImage", "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
 
-		err = r.ensureTLSResources(ctx, mdb)
+		err = r.ensureTLSResources(ctx, r.client, mdb)
 		assert.NoError(t, err)
 
 		// Operator-managed secret should have been created and contains the
@@ -478,7 +813,7 @@ func TestPemSupport(t *testing.T) {
 
 		r := NewReconciler(kubeClient.NewManagerWithClient(c), "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", "fake-agentImage", "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
 
-		err = r.ensureTLSResources(ctx, mdb)
+		err = r.ensureTLSResources(ctx, r.client, mdb)
 		assert.NoError(t, err)
 
 		// Operator-managed secret should have been created and contains the
@@ -498,7 +833,7 @@ func TestPemSupport(t *testing.T) {
 
 		r := NewReconciler(kubeClient.NewManagerWithClient(c), "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", "fake-agentImage", "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
 
-		err = r.ensureTLSResources(ctx, mdb)
+		err = r.ensureTLSResources(ctx, r.client, mdb)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), `if all of "tls.crt", "tls.key" and "tls.pem" are present in the secret, the entry for "tls.pem" must be equal to the concatenation of "tls.crt" with "tls.key"`)
 	})
@@ -546,7 +881,7 @@ func TestTLSConfigReferencesToCACertAreValidated(t *testing.T) {
 
 			r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", "fake-agentImage", "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
 
-			_, err = r.validateTLSConfig(ctx, mdb)
+			_, err = r.validateTLSConfig(ctx, r.client, mdb)
 			if tc.expectedError != nil {
 				assert.EqualError(t, err, tc.expectedError.Error())
 			} else {
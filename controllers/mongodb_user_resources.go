@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	k8sClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/controllers/validation"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+// userResource is a MongoDBCommunityUser resource referencing the reconciled MongoDBCommunity resource.
+type userResource struct {
+	resource mdbv1.MongoDBCommunityUser
+	user     authtypes.User
+
+	// phase is Running if the user can be created, the reason why it cannot is stored in message.
+	phase   mdbv1.Phase
+	message string
+}
+
+// userResourcesConfigurable configures authentication with the users defined in the spec of the resource, the users
+// of the MongoDBCommunityUser resources referencing it and the user the init scripts connect as.
+type userResourcesConfigurable struct {
+	*mdbv1.MongoDBCommunity
+	userResources []userResource
+}
+
+func (c userResourcesConfigurable) GetAuthUsers() []authtypes.User {
+	users := c.MongoDBCommunity.GetAuthUsers()
+	for _, u := range c.userResources {
+		if u.phase == mdbv1.Running {
+			users = append(users, u.user)
+		}
+	}
+	if len(c.Spec.InitScripts) > 0 {
+		users = append(users, c.InitScriptsUser())
+	}
+	return users
+}
+
+// getUserResources returns the MongoDBCommunityUser resources referencing the given resource, ordered by namespace
+// and name. A user is only created if its namespace is allowed, its name is not already used by another user, its
+// roles are built-in roles or one of the given custom roles and its password secret exists. The password secrets
+// are watched so that the users are created once they exist.
+//
+// It is called once per reconciliation, the resources are passed down to build the automation config.
+func (r ReplicaSetReconciler) getUserResources(ctx context.Context, mdb mdbv1.MongoDBCommunity, roles []mdbv1.CustomRole) ([]userResource, error) {
+	list := mdbv1.MongoDBCommunityUserList{}
+	if err := r.client.List(ctx, &list, referencingResourcesListOptions(mdb)...); err != nil {
+		return nil, fmt.Errorf("could not list MongoDBCommunityUser resources: %s", err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return k8sClient.ObjectKeyFromObject(&list.Items[i]).String() < k8sClient.ObjectKeyFromObject(&list.Items[j]).String()
+	})
+
+	definedBy := map[string]string{}
+	for _, user := range mdb.GetAuthUsers() {
+		definedBy[user.Database+"/"+user.Username] = "spec.users"
+	}
+
+	var userResources []userResource
+	for _, resource := range list.Items {
+		if resource.MongoDBResourceNamespacedName() != mdb.NamespacedName() {
+			continue
+		}
+
+		u := userResource{resource: resource, user: resource.GetAuthUser(mdb), phase: mdbv1.Running}
+		key := u.user.Database + "/" + u.user.Username
+		rolesErr := validation.ValidateUserRoles(u.user, roles)
+		switch {
+		case !mdb.Spec.Security.Authentication.IsUserNamespaceAllowed(resource.Namespace, mdb.Namespace):
+			u.phase = mdbv1.Failed
+			u.message = fmt.Sprintf("namespace %s is not allowed to create users in MongoDBCommunity %s", resource.Namespace, mdb.NamespacedName())
+		case definedBy[key] != "":
+			u.phase = mdbv1.Failed
+			u.message = fmt.Sprintf("user %s is already defined by %s", key, definedBy[key])
+		case resource.Spec.PasswordSecretRef.Provider != "":
+			u.phase = mdbv1.Failed
+			u.message = "the password of a MongoDBCommunityUser must be stored in a Kubernetes Secret, secret providers can only be referenced by the MongoDBCommunity resource"
+		case rolesErr != nil:
+			u.phase = mdbv1.Failed
+			u.message = rolesErr.Error()
+		case u.user.Database != constants.ExternalDB:
+			passwordSecret := u.user.GetPasswordSecretNamespacedName(mdb.Namespace)
+			r.secretWatcher.Watch(ctx, passwordSecret, mdb.NamespacedName())
+			if _, err := secret.ReadKey(ctx, r.client, u.user.PasswordSecretKey, passwordSecret); err != nil {
+				if !apiErrors.IsNotFound(err) {
+					return nil, err
+				}
+				u.phase = mdbv1.Pending
+				u.message = fmt.Sprintf("user password secret %s not found", passwordSecret)
+			}
+		}
+
+		if u.phase != mdbv1.Failed {
+			definedBy[key] = fmt.Sprintf("MongoDBCommunityUser %s", k8sClient.ObjectKeyFromObject(&resource))
+		}
+		userResources = append(userResources, u)
+	}
+	return userResources, nil
+}
+
+// referencingResourcesListOptions returns the options to list the resources which can reference the given resource.
+// They are listed in all namespaces only if the operator watches all namespaces, otherwise in the namespace of the
+// resource, which is the one the operator watches.
+func referencingResourcesListOptions(mdb mdbv1.MongoDBCommunity) []k8sClient.ListOption {
+	if watchesAllNamespaces() {
+		return nil
+	}
+	return []k8sClient.ListOption{k8sClient.InNamespace(mdb.Namespace)}
+}
+
+// userResourceReferences returns the references of the user resources whose users are created.
+func userResourceReferences(userResources []userResource) []mdbv1.UserResourceReference {
+	var references []mdbv1.UserResourceReference
+	for _, u := range userResources {
+		if u.phase == mdbv1.Running {
+			references = append(references, u.resource.GetReference())
+		}
+	}
+	return references
+}
+
+// updateUserResources updates the connection string secrets of the users created by MongoDBCommunityUser resources
+// and the status of these resources.
+func (r ReplicaSetReconciler) updateUserResources(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb mdbv1.MongoDBCommunity, userResources []userResource, clusterDomain string) {
+	for _, u := range userResources {
+		resource := u.resource
+		connectionStringSecretName := ""
+		if u.phase == mdbv1.Running {
+			if err := r.updateConnectionStringSecret(ctx, secrets, mdb, u.user, resource.GetOwnerReferences(), clusterDomain); err != nil {
+				u.phase = mdbv1.Failed
+				u.message = fmt.Sprintf("could not update connection string secret: %s", err)
+			} else {
+				connectionStringSecretName = u.user.ConnectionStringSecretName
+			}
+		}
+
+		userStatus := mdbv1.MongoDBCommunityUserStatus{
+			Phase:                      u.phase,
+			Message:                    u.message,
+			ConnectionStringSecretName: connectionStringSecretName,
+		}
+		if resource.Status == userStatus {
+			continue
+		}
+		resource.Status = userStatus
+		if err := r.client.Status().Update(ctx, &resource); err != nil {
+			r.log.Errorf("Could not update the status of MongoDBCommunityUser %s: %s", k8sClient.ObjectKeyFromObject(&resource), err)
+		}
+	}
+}
+
+// userResourceToMongoDBCommunity returns a reconcile request for the MongoDBCommunity resource referenced by a
+// MongoDBCommunityUser resource.
+func userResourceToMongoDBCommunity(_ context.Context, obj k8sClient.Object) []reconcile.Request {
+	user, ok := obj.(*mdbv1.MongoDBCommunityUser)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: user.MongoDBResourceNamespacedName()}}
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+)
+
+func TestUserResource_UserIsCreated(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	userResource := newUserResource("app-user", mdb.Namespace, mdb)
+	createUserResource(ctx, t, mgr.Client, userResource, "app-password")
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	user := acUser(ctx, t, mgr.Client, mdb, "app-user")
+	require.NotNil(t, user)
+	assert.Equal(t, "admin", user.Database)
+	assert.Equal(t, []automationconfig.Role{{Role: "readWrite", Database: "app"}}, user.Roles)
+
+	exists, err := secret.Exists(ctx, mgr.Client, types.NamespacedName{Name: "my-ns-app-user-scram-credentials", Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	userResource = getUserResource(ctx, t, mgr.Client, userResource)
+	assert.Equal(t, mdbv1.Running, userResource.Status.Phase)
+	assert.Equal(t, "my-rs-admin-app-user", userResource.Status.ConnectionStringSecretName)
+
+	connectionStringSecret, err := mgr.Client.GetSecret(ctx, types.NamespacedName{Name: "my-rs-admin-app-user", Namespace: userResource.Namespace})
+	require.NoError(t, err)
+	assert.Equal(t, "app-password", string(connectionStringSecret.Data["password"]))
+	assert.Equal(t, userResource.GetOwnerReferences(), connectionStringSecret.OwnerReferences)
+
+	err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	assert.Equal(t, []mdbv1.UserResourceReference{{Name: "app-user", Namespace: "my-ns", Username: "app-user", DB: "admin"}}, mdb.Status.UserResources)
+}
+
+func TestUserResource_OtherNamespaceMustBeAllowed(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	userResource := newUserResource("app-user", "app-ns", mdb)
+	createUserResource(ctx, t, mgr.Client, userResource, "app-password")
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	assert.Nil(t, acUser(ctx, t, mgr.Client, mdb, "app-user"))
+	assert.Equal(t, mdbv1.Failed, getUserResource(ctx, t, mgr.Client, userResource).Status.Phase)
+
+	err = mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb)
+	require.NoError(t, err)
+	mdb.Spec.Security.Authentication.AllowedUserNamespaces = []string{"app-ns"}
+	require.NoError(t, mgr.Client.Update(ctx, &mdb))
+
+	res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	assert.NotNil(t, acUser(ctx, t, mgr.Client, mdb, "app-user"))
+	assert.Equal(t, mdbv1.Running, getUserResource(ctx, t, mgr.Client, userResource).Status.Phase)
+
+	exists, err := secret.Exists(ctx, mgr.Client, types.NamespacedName{Name: "my-rs-admin-app-user", Namespace: "app-ns"})
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestUserResource_OnlyWatchedNamespaceIsListed(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	t.Setenv(watchNamespace, mdb.Namespace)
+	mgr := client.NewManager(ctx, &mdb)
+	createUserResource(ctx, t, mgr.Client, newUserResource("app-user", mdb.Namespace, mdb), "app-password")
+	otherNamespaceResource := newUserResource("other-user", "app-ns", mdb)
+	createUserResource(ctx, t, mgr.Client, otherNamespaceResource, "other-password")
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	assert.NotNil(t, acUser(ctx, t, mgr.Client, mdb, "app-user"))
+	assert.Nil(t, acUser(ctx, t, mgr.Client, mdb, "other-user"))
+	assert.Empty(t, getUserResource(ctx, t, mgr.Client, otherNamespaceResource).Status.Phase)
+
+	t.Run("Other namespaces can't be allowed", func(t *testing.T) {
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+		mdb.Spec.Security.Authentication.AllowedUserNamespaces = []string{"app-ns"}
+		require.NoError(t, mgr.Client.Update(ctx, &mdb))
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+		require.NoError(t, err)
+		require.NoError(t, mgr.Client.Get(ctx, mdb.NamespacedName(), &mdb))
+		assert.Equal(t, mdbv1.Failed, mdb.Status.Phase)
+		assert.Contains(t, mdb.Status.Message, "only allow other namespaces if the operator watches all namespaces")
+	})
+}
+
+func TestUserResource_UserIsPendingWithoutPassword(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	userResource := newUserResource("app-user", mdb.Namespace, mdb)
+	require.NoError(t, mgr.Client.Create(ctx, &userResource))
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	assert.Nil(t, acUser(ctx, t, mgr.Client, mdb, "app-user"))
+	userResource = getUserResource(ctx, t, mgr.Client, userResource)
+	assert.Equal(t, mdbv1.Pending, userResource.Status.Phase)
+	assert.Contains(t, userResource.Status.Message, "app-user-password")
+}
+
+func TestUserResource_UserDefinedInSpecIsNotOverridden(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet(mdbv1.MongoDBUser{
+		Name:                       "app-user",
+		DB:                         "admin",
+		PasswordSecretRef:          mdbv1.SecretKeyReference{Name: "spec-user-password"},
+		ScramCredentialsSecretName: "app-user",
+		Roles:                      []mdbv1.Role{{Name: "root", DB: "admin"}},
+	})
+	mgr := client.NewManager(ctx, &mdb)
+	setUserPassword(ctx, t, mgr.Client, mdb, "spec-password")
+	userResource := newUserResource("app-user", mdb.Namespace, mdb)
+	createUserResource(ctx, t, mgr.Client, userResource, "app-password")
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	assert.Equal(t, []automationconfig.Role{{Role: "root", Database: "admin"}}, acUser(ctx, t, mgr.Client, mdb, "app-user").Roles)
+	userResource = getUserResource(ctx, t, mgr.Client, userResource)
+	assert.Equal(t, mdbv1.Failed, userResource.Status.Phase)
+	assert.Contains(t, userResource.Status.Message, "spec.users")
+}
+
+func TestUserResource_UserIsDeletedWithTheResource(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	userResource := newUserResource("app-user", mdb.Namespace, mdb)
+	createUserResource(ctx, t, mgr.Client, userResource, "app-password")
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+	require.NotNil(t, acUser(ctx, t, mgr.Client, mdb, "app-user"))
+
+	require.NoError(t, mgr.Client.Delete(ctx, &userResource))
+	res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	assert.Nil(t, acUser(ctx, t, mgr.Client, mdb, "app-user"))
+	ac, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	assert.Contains(t, ac.Auth.UsersDeleted, automationconfig.DeletedUser{User: "app-user", Dbs: []string{"admin"}})
+
+	exists, err := secret.Exists(ctx, mgr.Client, types.NamespacedName{Name: "my-ns-app-user-scram-credentials", Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func newUserResource(name, namespace string, mdb mdbv1.MongoDBCommunity) mdbv1.MongoDBCommunityUser {
+	return mdbv1.MongoDBCommunityUser{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: mdbv1.MongoDBCommunityUserSpec{
+			MongoDBResourceRef: mdbv1.MongoDBCommunityReference{Name: mdb.Name, Namespace: mdb.Namespace},
+			PasswordSecretRef:  mdbv1.SecretKeyReference{Name: name + "-password"},
+			Roles:              []mdbv1.Role{{Name: "readWrite", DB: "app"}},
+		},
+	}
+}
+
+func createUserResource(ctx context.Context, t *testing.T, c client.Client, userResource mdbv1.MongoDBCommunityUser, password string) {
+	require.NoError(t, c.Create(ctx, &userResource))
+	err := secret.CreateOrUpdate(ctx, c, secret.Builder().
+		SetName(userResource.Spec.PasswordSecretRef.Name).
+		SetNamespace(userResource.Namespace).
+		SetField("password", password).
+		Build())
+	require.NoError(t, err)
+}
+
+func getUserResource(ctx context.Context, t *testing.T, c client.Client, userResource mdbv1.MongoDBCommunityUser) mdbv1.MongoDBCommunityUser {
+	err := c.Get(ctx, types.NamespacedName{Name: userResource.Name, Namespace: userResource.Namespace}, &userResource)
+	require.NoError(t, err)
+	return userResource
+}
@@ -5,19 +5,29 @@ import (
 	"fmt"
 
 	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/generate"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
 // ensureUserResources will check that the configured user password secrets can be found
 // and will start monitor them so that the reconcile process is triggered every time these secrets are updated
-func (r ReplicaSetReconciler) ensureUserResources(ctx context.Context, mdb mdbv1.MongoDBCommunity) error {
+func (r ReplicaSetReconciler) ensureUserResources(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb mdbv1.MongoDBCommunity) error {
+	if err := r.ensureGeneratedUserPasswords(ctx, mdb); err != nil {
+		return err
+	}
+	if err := r.ensureInitScriptsPassword(ctx, mdb); err != nil {
+		return err
+	}
+
 	for _, user := range mdb.GetAuthUsers() {
 		if user.Database != constants.ExternalDB {
-			secretNamespacedName := types.NamespacedName{Name: user.PasswordSecretName, Namespace: mdb.Namespace}
-			if _, err := secret.ReadKey(ctx, r.client, user.PasswordSecretKey, secretNamespacedName); err != nil {
+			secretNamespacedName := user.GetPasswordSecretNamespacedName(mdb.Namespace)
+			if _, err := secret.ReadKey(ctx, secrets, user.PasswordSecretKey, secretNamespacedName); err != nil {
 				if apiErrors.IsNotFound(err) {
 					// check for SCRAM secret as well
 					scramSecretName := types.NamespacedName{Name: user.ScramCredentialsSecretName, Namespace: mdb.Namespace}
@@ -37,55 +47,135 @@ func (r ReplicaSetReconciler) ensureUserResources(ctx context.Context, mdb mdbv1
 	return nil
 }
 
-// updateConnectionStringSecrets updates secrets where user specific connection strings are stored.
-// The client applications can mount these secrets and connect to the mongodb cluster
-func (r ReplicaSetReconciler) updateConnectionStringSecrets(ctx context.Context, mdb mdbv1.MongoDBCommunity, clusterDomain string) error {
-	for _, user := range mdb.GetAuthUsers() {
-		secretName := user.ConnectionStringSecretName
-
-		secretNamespace := mdb.Namespace
-		if user.ConnectionStringSecretNamespace != "" {
-			secretNamespace = user.ConnectionStringSecretNamespace
+// ensureGeneratedUserPasswords creates the password secrets of the users configured with generatePassword
+// which do not exist yet. Existing secrets are never modified.
+func (r ReplicaSetReconciler) ensureGeneratedUserPasswords(ctx context.Context, mdb mdbv1.MongoDBCommunity) error {
+	for _, user := range mdb.Spec.Users {
+		if !user.GeneratePassword || user.DB == constants.ExternalDB {
+			continue
 		}
 
-		existingSecret, err := r.client.GetSecret(ctx, types.NamespacedName{
-			Name:      secretName,
-			Namespace: secretNamespace,
-		})
-		if err != nil && !apiErrors.IsNotFound(err) {
+		secretNamespacedName := types.NamespacedName{Name: user.PasswordSecretRef.Name, Namespace: mdb.Namespace}
+		exists, err := secret.Exists(ctx, r.client, secretNamespacedName)
+		if err != nil {
 			return err
 		}
-		if err == nil && !secret.HasOwnerReferences(existingSecret, mdb.GetOwnerReferences()) {
-			return fmt.Errorf("connection string secret %s already exists and is not managed by the operator", secretName)
+		if exists {
+			continue
 		}
 
-		pwd := ""
+		password, err := generateUserPassword(user.PasswordGeneration)
+		if err != nil {
+			return fmt.Errorf("could not generate password for user %s: %s", user.Name, err)
+		}
+		if _, err := secret.EnsureSecretWithKey(ctx, r.client, secretNamespacedName, mdb.GetOwnerReferences(), user.GetPasswordSecretKey(), password); err != nil {
+			return err
+		}
+		r.log.Infof("Generated password secret %s for user %s", secretNamespacedName, user.Name)
+	}
 
-		if user.Database != constants.ExternalDB {
-			secretNamespacedName := types.NamespacedName{Name: user.PasswordSecretName, Namespace: mdb.Namespace}
-			pwd, err = secret.ReadKey(ctx, r.client, user.PasswordSecretKey, secretNamespacedName)
-			if err != nil {
-				return err
-			}
+	return nil
+}
+
+// generateUserPassword generates a password according to the given options.
+func generateUserPassword(options *mdbv1.PasswordGenerationOptions) (string, error) {
+	characterClasses := options.GetCharacterClasses()
+	if len(characterClasses) == 0 {
+		return generate.RandomFixedLengthStringOfSize(options.GetLength())
+	}
+
+	characterSets := make([]string, len(characterClasses))
+	for i, characterClass := range characterClasses {
+		switch characterClass {
+		case mdbv1.PasswordCharacterClassLowercase:
+			characterSets[i] = generate.LowercaseCharacters
+		case mdbv1.PasswordCharacterClassUppercase:
+			characterSets[i] = generate.UppercaseCharacters
+		case mdbv1.PasswordCharacterClassDigits:
+			characterSets[i] = generate.DigitCharacters
+		case mdbv1.PasswordCharacterClassSymbols:
+			characterSets[i] = generate.SymbolCharacters
+		default:
+			return "", fmt.Errorf("unknown password character class: %s", characterClass)
 		}
+	}
+	return generate.RandomStringFromCharacterSets(options.GetLength(), characterSets...)
+}
 
-		connectionStringSecret := secret.Builder().
-			SetName(secretName).
-			SetNamespace(secretNamespace).
-			SetField("connectionString.standard", mdb.MongoAuthUserURI(user, pwd, clusterDomain)).
-			SetField("connectionString.standardSrv", mdb.MongoAuthUserSRVURI(user, pwd, clusterDomain)).
-			SetField("username", user.Username).
-			SetField("password", pwd).
-			SetOwnerReferences(mdb.GetOwnerReferences()).
-			Build()
-
-		if err := secret.CreateOrUpdate(ctx, r.client, connectionStringSecret); err != nil {
+// updateConnectionStringSecrets updates secrets where user specific connection strings are stored.
+// The client applications can mount these secrets and connect to the mongodb cluster
+func (r ReplicaSetReconciler) updateConnectionStringSecrets(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb mdbv1.MongoDBCommunity, clusterDomain string) error {
+	for _, user := range mdb.GetAuthUsers() {
+		if err := r.updateConnectionStringSecret(ctx, secrets, mdb, user, mdb.GetOwnerReferences(), clusterDomain); err != nil {
+			return err
+		}
+	}
+
+	return r.updateOidcConnectionStringSecret(ctx, mdb, clusterDomain)
+}
+
+// updateConnectionStringSecret updates the secret where the connection strings of the given user are stored.
+// The secret is owned by the given owner references.
+func (r ReplicaSetReconciler) updateConnectionStringSecret(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb mdbv1.MongoDBCommunity, user authtypes.User, ownerReferences []metav1.OwnerReference, clusterDomain string) error {
+	secretName := user.ConnectionStringSecretName
+
+	secretNamespace := mdb.Namespace
+	if user.ConnectionStringSecretNamespace != "" {
+		secretNamespace = user.ConnectionStringSecretNamespace
+	}
+
+	existingSecret, err := r.client.GetSecret(ctx, types.NamespacedName{
+		Name:      secretName,
+		Namespace: secretNamespace,
+	})
+	if err != nil && !apiErrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && !secret.HasOwnerReferences(existingSecret, ownerReferences) {
+		return fmt.Errorf("connection string secret %s already exists and is not managed by the operator", secretName)
+	}
+
+	pwd := ""
+
+	if user.Database != constants.ExternalDB {
+		pwd, err = secret.ReadKey(ctx, secrets, user.PasswordSecretKey, user.GetPasswordSecretNamespacedName(mdb.Namespace))
+		if err != nil {
 			return err
 		}
+	}
+
+	ca, err := r.connectionStringCA(ctx, mdb)
+	if err != nil {
+		return err
+	}
 
-		secretNamespacedName := types.NamespacedName{Name: connectionStringSecret.Name, Namespace: connectionStringSecret.Namespace}
-		r.secretWatcher.Watch(ctx, secretNamespacedName, mdb.NamespacedName())
+	connectionStringSecretBuilder := secret.Builder().
+		SetName(secretName).
+		SetNamespace(secretNamespace).
+		SetField("connectionString.standard", mdb.MongoAuthUserURI(user, pwd, clusterDomain)).
+		SetField("connectionString.standardSrv", mdb.MongoAuthUserSRVURI(user, pwd, clusterDomain)).
+		SetField("username", user.GetLoginUsername()).
+		SetField("password", pwd).
+		SetOwnerReferences(ownerReferences)
+	if ca != "" {
+		connectionStringSecretBuilder.SetField(tlsCACertName, ca)
 	}
+	connectionStringSecret := connectionStringSecretBuilder.Build()
 
+	if err := secret.CreateOrUpdate(ctx, r.client, connectionStringSecret); err != nil {
+		return err
+	}
+
+	secretNamespacedName := types.NamespacedName{Name: connectionStringSecret.Name, Namespace: connectionStringSecret.Namespace}
+	r.secretWatcher.Watch(ctx, secretNamespacedName, mdb.NamespacedName())
 	return nil
 }
+
+// connectionStringCA returns the CA certificate the clients verify the server certificates with, stored with the
+// connection strings, or an empty string if the clients don't connect with TLS.
+func (r ReplicaSetReconciler) connectionStringCA(ctx context.Context, mdb mdbv1.MongoDBCommunity) (string, error) {
+	if !mdb.IsTLSEnabledForClients() {
+		return "", nil
+	}
+	return getCaCrt(ctx, r.client, r.client, mdb)
+}
@@ -12,12 +12,18 @@ import (
 // that reconciliations should only happen on changes to the Spec of the resource.
 // any other changes won't trigger a reconciliation. This allows us to freely update the annotations
 // of the resource without triggering unintentional reconciliations.
-func OnlyOnSpecChange() predicate.Funcs {
+// Changes to the given trigger annotations, which users set to request an operation, also trigger a reconciliation.
+func OnlyOnSpecChange(triggerAnnotations ...string) predicate.Funcs {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			oldResource := e.ObjectOld.(*mdbv1.MongoDBCommunity)
 			newResource := e.ObjectNew.(*mdbv1.MongoDBCommunity)
 			specChanged := !reflect.DeepEqual(oldResource.Spec, newResource.Spec)
+			for _, annotation := range triggerAnnotations {
+				if oldResource.Annotations[annotation] != newResource.Annotations[annotation] {
+					return true
+				}
+			}
 			return specChanged
 		},
 	}
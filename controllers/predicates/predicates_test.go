@@ -0,0 +1,39 @@
+package predicates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+func TestOnlyOnSpecChange(t *testing.T) {
+	const trigger = "mongodb.com/v1.rollbackToRevision"
+	oldResource := &mdbv1.MongoDBCommunity{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-mongodb", Annotations: map[string]string{"other": "a"}},
+		Spec:       mdbv1.MongoDBCommunitySpec{Members: 3},
+	}
+	update := func(change func(*mdbv1.MongoDBCommunity)) event.UpdateEvent {
+		newResource := *oldResource
+		newResource.Annotations = map[string]string{"other": "a"}
+		change(&newResource)
+		return event.UpdateEvent{ObjectOld: oldResource, ObjectNew: &newResource}
+	}
+
+	t.Run("Spec changes pass", func(t *testing.T) {
+		e := update(func(mdb *mdbv1.MongoDBCommunity) { mdb.Spec.Members = 5 })
+		assert.True(t, OnlyOnSpecChange(trigger).Update(e))
+	})
+	t.Run("Other annotation changes don't pass", func(t *testing.T) {
+		e := update(func(mdb *mdbv1.MongoDBCommunity) { mdb.Annotations["other"] = "b" })
+		assert.False(t, OnlyOnSpecChange(trigger).Update(e))
+	})
+	t.Run("Trigger annotation changes pass", func(t *testing.T) {
+		e := update(func(mdb *mdbv1.MongoDBCommunity) { mdb.Annotations[trigger] = "2" })
+		assert.True(t, OnlyOnSpecChange(trigger).Update(e))
+		assert.False(t, OnlyOnSpecChange().Update(e))
+	})
+}
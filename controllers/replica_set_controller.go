@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -21,32 +22,41 @@ import (
 	kubernetesClient "github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/client"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/container"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/podtemplatespec"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/service"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/statefulset"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/provisioning"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/functions"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/merge"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/result"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/scale"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/status"
 	"github.com/stretchr/objx"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	k8sClient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
 	clusterDomain = "CLUSTER_DOMAIN"
+	// watchNamespace is the namespace watched by the operator, "*" if it watches all namespaces.
+	watchNamespace = "WATCH_NAMESPACE"
 
 	lastSuccessfulConfiguration = "mongodb.com/v1.lastSuccessfulConfiguration"
 	lastAppliedMongoDBVersion   = "mongodb.com/v1.lastAppliedMongoDBVersion"
@@ -68,6 +78,7 @@ func NewReconciler(mgr manager.Manager, mongodbRepoUrl, mongodbImage, mongodbIma
 		client:           kubernetesClient.NewClient(mgrClient),
 		scheme:           mgr.GetScheme(),
 		log:              zap.S(),
+		recorder:         mgr.GetEventRecorderFor("mongodbcommunity-controller"),
 		secretWatcher:    &secretWatcher,
 		configMapWatcher: &configMapWatcher,
 
@@ -77,18 +88,29 @@ func NewReconciler(mgr manager.Manager, mongodbRepoUrl, mongodbImage, mongodbIma
 		agentImage:              agentImage,
 		versionUpgradeHookImage: versionUpgradeHookImage,
 		readinessProbeImage:     readinessProbeImage,
+
+		newDatabaseClient: provisioning.NewClient,
 	}
 }
 
 // SetupWithManager sets up the controller with the Manager and configures the necessary watches.
 func (r *ReplicaSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{MaxConcurrentReconciles: 3}).
-		For(&mdbv1.MongoDBCommunity{}, builder.WithPredicates(predicates.OnlyOnSpecChange())).
+		For(&mdbv1.MongoDBCommunity{}, builder.WithPredicates(predicates.OnlyOnSpecChange(rollbackToRevision, rotateAgentCredentials, rerunInitScripts))).
 		Watches(&corev1.Secret{}, r.secretWatcher).
 		Watches(&corev1.ConfigMap{}, r.configMapWatcher).
+		Watches(&mdbv1.MongoDBCommunityUser{}, handler.EnqueueRequestsFromMapFunc(userResourceToMongoDBCommunity), builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&mdbv1.MongoDBCommunityRole{}, handler.EnqueueRequestsFromMapFunc(roleResourceToMongoDBCommunity), builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Owns(&appsv1.StatefulSet{}).
-		Complete(r)
+		Owns(&batchv1.Job{})
+
+	// cert-manager is optional: its Certificates are only watched if it is installed when the operator starts.
+	r.certManagerCertificatesWatched = isCertManagerInstalled(mgr.GetRESTMapper())
+	if r.certManagerCertificatesWatched {
+		controllerBuilder = controllerBuilder.Owns(newCertManagerCertificate())
+	}
+	return controllerBuilder.Complete(r)
 }
 
 // ReplicaSetReconciler reconciles a MongoDB ReplicaSet
@@ -98,22 +120,38 @@ type ReplicaSetReconciler struct {
 	client           kubernetesClient.Client
 	scheme           *runtime.Scheme
 	log              *zap.SugaredLogger
+	recorder         record.EventRecorder
 	secretWatcher    *watch.ResourceWatcher
 	configMapWatcher *watch.ResourceWatcher
 
+	// certManagerCertificatesWatched is true if the changes of the cert-manager Certificates trigger a reconciliation,
+	// otherwise the reconciliation is retried until they are ready.
+	certManagerCertificatesWatched bool
+
 	mongodbRepoUrl          string
 	mongodbImage            string
 	mongodbImageType        string
 	agentImage              string
 	versionUpgradeHookImage string
 	readinessProbeImage     string
+
+	// newDatabaseClient connects to the deployment to provision spec.databases.
+	newDatabaseClient func(ctx context.Context, clientOptions *options.ClientOptions) (provisioning.Client, error)
 }
 
 // +kubebuilder:rbac:groups=mongodbcommunity.mongodb.com,resources=mongodbcommunity,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=mongodbcommunity.mongodb.com,resources=mongodbcommunity/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=mongodbcommunity.mongodb.com,resources=mongodbcommunity/finalizers,verbs=update
+// +kubebuilder:rbac:groups=mongodbcommunity.mongodb.com,resources=mongodbcommunityusers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=mongodbcommunity.mongodb.com,resources=mongodbcommunityusers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=mongodbcommunity.mongodb.com,resources=mongodbcommunityroles,verbs=get;list;watch
+// +kubebuilder:rbac:groups=mongodbcommunity.mongodb.com,resources=mongodbcommunityroles/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 
 // Reconcile reads that state of the cluster for a MongoDB object and makes changes based on the state read
 // and what is in the MongoDB.Spec
@@ -141,6 +179,12 @@ func (r ReplicaSetReconciler) Reconcile(ctx context.Context, request reconcile.R
 	r.log = zap.S().With("ReplicaSet", request.NamespacedName)
 	r.log.Infof("Reconciling MongoDB")
 
+	if _, ok := mdb.Annotations[rollbackToRevision]; ok {
+		if done, res, err := r.rollbackToSpecRevision(ctx, &mdb); done {
+			return res, err
+		}
+	}
+
 	r.log.Debug("Validating MongoDB.Spec")
 	lastAppliedSpec, err := r.validateSpec(mdb)
 	if err != nil {
@@ -149,6 +193,19 @@ func (r ReplicaSetReconciler) Reconcile(ctx context.Context, request reconcile.R
 			withFailedPhase())
 	}
 
+	secrets, err := r.secretClient(ctx, mdb)
+	if err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error configuring the secret providers: %s", err)).
+			withFailedPhase())
+	}
+
+	if err := r.ensureDeployedTLSMode(ctx, &mdb); err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error reading the TLS mode of the deployed processes: %s", err)).
+			withFailedPhase())
+	}
+
 	r.log.Debug("Ensuring the service exists")
 	if err := r.ensureService(ctx, mdb); err != nil {
 		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
@@ -156,7 +213,33 @@ func (r ReplicaSetReconciler) Reconcile(ctx context.Context, request reconcile.R
 			withFailedPhase())
 	}
 
-	isTLSValid, err := r.validateTLSConfig(ctx, mdb)
+	certificateRenewalRetryAfter, err := r.ensureAutoGeneratedCertificates(ctx, mdb, os.Getenv(clusterDomain)) // nolint:forbidigo
+	if err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error ensuring the auto-generated TLS certificates: %s", err)).
+			withFailedPhase())
+	}
+
+	certManagerCertificatesReady, err := r.ensureCertManagerCertificates(ctx, mdb, os.Getenv(clusterDomain)) // nolint:forbidigo
+	if err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error ensuring the cert-manager TLS certificates: %s", err)).
+			withFailedPhase())
+	}
+
+	if !certManagerCertificatesReady && r.certManagerCertificatesWatched {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Info, "The cert-manager TLS certificates are not yet ready").
+			withPendingPhaseUntilNextEvent())
+	}
+
+	if !certManagerCertificatesReady {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Info, "The cert-manager TLS certificates are not yet ready, retrying in 10 seconds").
+			withPendingPhase(10))
+	}
+
+	isTLSValid, err := r.validateTLSConfig(ctx, secrets, mdb)
 	if err != nil {
 		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
 			withMessage(Error, fmt.Sprintf("Error validating TLS config: %s", err)).
@@ -169,25 +252,79 @@ func (r ReplicaSetReconciler) Reconcile(ctx context.Context, request reconcile.R
 			withPendingPhase(10))
 	}
 
-	if err := r.ensureTLSResources(ctx, mdb); err != nil {
+	if err := r.validateServerCertificateHostnames(ctx, secrets, mdb, os.Getenv(clusterDomain)); err != nil { // nolint:forbidigo
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error validating the server certificates: %s", err)).
+			withFailedPhase())
+	}
+
+	if err := r.ensureTLSResources(ctx, secrets, mdb); err != nil {
 		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
 			withMessage(Error, fmt.Sprintf("Error ensuring TLS resources: %s", err)).
 			withFailedPhase())
 	}
 
-	if err := r.ensurePrometheusTLSResources(ctx, mdb); err != nil {
+	caDistributionRetryAfter, err := r.ensureCADistribution(ctx, &mdb)
+	if err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error distributing the CA certificate: %s", err)).
+			withFailedPhase())
+	}
+
+	if err := r.ensurePrometheusTLSResources(ctx, secrets, mdb); err != nil {
 		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
 			withMessage(Error, fmt.Sprintf("Error ensuring TLS resources: %s", err)).
 			withFailedPhase())
 	}
 
-	if err := r.ensureUserResources(ctx, mdb); err != nil {
+	if err := r.ensureEncryptionAtRestSecret(ctx, secrets, mdb); err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error ensuring the encryption at rest resources: %s", err)).
+			withFailedPhase())
+	}
+
+	certificateExpiryRetryAfter, err := r.ensureCertificateExpiry(ctx, secrets, &mdb)
+	if err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error checking the expiry of the certificates: %s", err)).
+			withFailedPhase())
+	}
+
+	if err := r.ensureUserResources(ctx, secrets, mdb); err != nil {
 		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
 			withMessage(Error, fmt.Sprintf("Error ensuring User config: %s", err)).
 			withFailedPhase())
 	}
 
-	ready, err := r.deployMongoDBReplicaSet(ctx, mdb, lastAppliedSpec)
+	roleResources, err := r.getRoleResources(ctx, mdb)
+	if err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error validating custom roles: %s", err)).
+			withFailedPhase())
+	}
+
+	roles := customRoles(mdb, roleResources)
+	if err := validateUserRoles(mdb, roles); err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error validating user roles: %s", err)).
+			withFailedPhase())
+	}
+
+	userResources, err := r.getUserResources(ctx, mdb, roles)
+	if err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error ensuring MongoDBCommunityUser resources: %s", err)).
+			withFailedPhase())
+	}
+
+	passwordRotationRetryAfter, err := r.ensureUserPasswordRotations(ctx, secrets, &mdb)
+	if err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error rotating user passwords: %s", err)).
+			withFailedPhase())
+	}
+
+	ready, err := r.deployMongoDBReplicaSet(ctx, mdb, lastAppliedSpec, automationConfigInputs{roles: roles, userResources: userResources, secrets: secrets})
 	if err != nil {
 		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
 			withMessage(Error, fmt.Sprintf("Error deploying MongoDB ReplicaSet: %s", err)).
@@ -218,6 +355,40 @@ func (r ReplicaSetReconciler) Reconcile(ctx context.Context, request reconcile.R
 			withPendingPhase(10))
 	}
 
+	agentCredentialsRotation, agentCredentialsRotationRetryAfter, err := r.ensureAgentCredentialsRotation(ctx, mdb)
+	if err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error rotating the agent credentials: %s", err)).
+			withFailedPhase())
+	}
+
+	databaseDrift, err := r.ensureDatabases(ctx, mdb)
+	if err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error provisioning databases: %s", err)).
+			withFailedPhase())
+	}
+
+	initScripts, initScriptsRetryAfter, err := r.ensureInitScripts(ctx, mdb)
+	if err != nil {
+		return status.Update(ctx, r.client.Status(), &mdb, statusOptions().
+			withMessage(Error, fmt.Sprintf("Error running init scripts: %s", err)).
+			withFailedPhase())
+	}
+
+	tlsMode, tlsModeRetryAfter := mdb.TLSModeThisReconciliation(), 0
+	if tlsMode != mdb.CurrentTLSMode() {
+		r.log.Infof("All processes reached TLS mode %s, desired TLS mode is %s", tlsMode, mdb.DesiredTLSMode())
+		tlsModeRetryAfter = 1
+	}
+
+	clusterAuthMode, clusterAuthModeRetryAfter := mdb.ClusterAuthModeThisReconciliation(), 0
+	if clusterAuthMode != mdb.CurrentClusterAuthMode() {
+		r.log.Infof("All processes reached cluster authentication mode %s, desired cluster authentication mode is %s", clusterAuthMode, mdb.DesiredClusterAuthMode())
+		clusterAuthModeRetryAfter = 1
+	}
+
+	previousUserResources := mdb.Status.UserResources
 	res, err := status.Update(ctx, r.client.Status(), &mdb, statusOptions().
 		withMongoURI(mdb.MongoURI(os.Getenv(clusterDomain))). // nolint:forbidigo
 		withMongoDBMembers(mdb.AutomationConfigMembersThisReconciliation()).
@@ -226,26 +397,48 @@ func (r ReplicaSetReconciler) Reconcile(ctx context.Context, request reconcile.R
 		withMongoDBArbiters(mdb.AutomationConfigArbitersThisReconciliation()).
 		withMessage(None, "").
 		withRunningPhase().
-		withVersion(mdb.GetMongoDBVersion()))
+		withVersion(mdb.GetMongoDBVersion()).
+		// the agent credentials rotation is requeued first, as its next step is always due sooner.
+		withAgentCredentialsRotation(agentCredentialsRotation, agentCredentialsRotationRetryAfter).
+		withUserPasswordRotations(pendingUserPasswordRotations(mdb.Status.UserPasswordRotations), passwordRotationRetryAfter).
+		withUserResources(userResourceReferences(userResources)).
+		withDatabaseDrift(databaseDrift).
+		withInitScripts(initScripts, initScriptsRetryAfter).
+		withTLSMode(tlsMode, tlsModeRetryAfter).
+		withClusterAuthMode(clusterAuthMode, clusterAuthModeRetryAfter).
+		// the CA distribution and the secret providers are requeued before the certificates, delaying their renewal
+		// by at most their resync.
+		withCADistribution(caDistributionRetryAfter).
+		withSecretProviders(secretProvidersResync(mdb)).
+		withCertificateRenewal(certificateRenewalRetryAfter).
+		withCertificateExpiry(certificateExpiryRetryAfter))
 	if err != nil {
 		r.log.Errorf("Error updating the status of the MongoDB resource: %s", err)
 		return res, err
 	}
 
-	if err := r.updateConnectionStringSecrets(ctx, mdb, os.Getenv(clusterDomain)); err != nil { // nolint:forbidigo
+	if err := r.updateConnectionStringSecrets(ctx, secrets, mdb, os.Getenv(clusterDomain)); err != nil { // nolint:forbidigo
 		r.log.Errorf("Could not update connection string secrets: %s", err)
 	}
+	r.updateRoleResources(ctx, roleResources)
+	r.updateUserResources(ctx, secrets, mdb, userResources, os.Getenv(clusterDomain)) // nolint:forbidigo
+	r.cleanupUserResourceScramSecrets(ctx, mdb.Status.UserResources, previousUserResources, mdb.Namespace)
 
 	if lastAppliedSpec != nil {
 		r.cleanupScramSecrets(ctx, mdb.Spec, *lastAppliedSpec, mdb.Namespace)
 		r.cleanupPemSecret(ctx, mdb.Spec, *lastAppliedSpec, mdb.Namespace)
 		r.cleanupConnectionStringSecrets(ctx, mdb.Spec, *lastAppliedSpec, mdb.Namespace, mdb.Name)
+		r.cleanupOidcConnectionStringSecret(ctx, mdb, *lastAppliedSpec)
 	}
 
 	if err := r.updateLastSuccessfulConfiguration(ctx, mdb); err != nil {
 		r.log.Errorf("Could not save current spec as an annotation: %s", err)
 	}
 
+	if err := r.recordSpecRevision(ctx, mdb); err != nil {
+		r.log.Errorf("Could not record the current spec in the spec history: %s", err)
+	}
+
 	if res.RequeueAfter > 0 || res.Requeue {
 		r.log.Info("Requeuing reconciliation")
 		return res, nil
@@ -273,26 +466,24 @@ func (r *ReplicaSetReconciler) updateLastSuccessfulConfiguration(ctx context.Con
 
 // ensureTLSResources creates any required TLS resources that the MongoDBCommunity
 // requires for TLS configuration.
-func (r *ReplicaSetReconciler) ensureTLSResources(ctx context.Context, mdb mdbv1.MongoDBCommunity) error {
-	if !mdb.Spec.Security.TLS.Enabled {
+func (r *ReplicaSetReconciler) ensureTLSResources(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb mdbv1.MongoDBCommunity) error {
+	if !mdb.IsTLSConfiguredThisReconciliation() {
 		return nil
 	}
 	// the TLS secret needs to be created beforehand, as both the StatefulSet and AutomationConfig
 	// require the contents.
-	if mdb.Spec.Security.TLS.Enabled {
-		r.log.Infof("TLS is enabled, creating/updating CA secret")
-		if err := ensureCASecret(ctx, r.client, r.client, r.client, mdb); err != nil {
-			return fmt.Errorf("could not ensure CA secret: %s", err)
-		}
-		r.log.Infof("TLS is enabled, creating/updating TLS secret")
-		if err := ensureTLSSecret(ctx, r.client, mdb); err != nil {
-			return fmt.Errorf("could not ensure TLS secret: %s", err)
-		}
-		if mdb.Spec.IsAgentX509() {
-			r.log.Infof("Agent X509 authentication is enabled, creating/updating agent certificate secret")
-			if err := ensureAgentCertSecret(ctx, r.client, mdb); err != nil {
-				return fmt.Errorf("could not ensure Agent Certificate secret: %s", err)
-			}
+	r.log.Infof("TLS is configured, creating/updating CA secret")
+	if err := ensureCASecret(ctx, r.client, r.client, r.client, mdb); err != nil {
+		return fmt.Errorf("could not ensure CA secret: %s", err)
+	}
+	r.log.Infof("TLS is configured, creating/updating TLS secret")
+	if err := ensureTLSSecret(ctx, secrets, mdb); err != nil {
+		return fmt.Errorf("could not ensure TLS secret: %s", err)
+	}
+	if mdb.Spec.IsAgentCertificateRequired() {
+		r.log.Infof("The agent presents a certificate, creating/updating agent certificate secret")
+		if err := ensureAgentCertSecret(ctx, r.client, mdb); err != nil {
+			return fmt.Errorf("could not ensure Agent Certificate secret: %s", err)
 		}
 	}
 	return nil
@@ -300,7 +491,7 @@ func (r *ReplicaSetReconciler) ensureTLSResources(ctx context.Context, mdb mdbv1
 
 // ensurePrometheusTLSResources creates any required TLS resources that the MongoDBCommunity
 // requires for TLS configuration.
-func (r *ReplicaSetReconciler) ensurePrometheusTLSResources(ctx context.Context, mdb mdbv1.MongoDBCommunity) error {
+func (r *ReplicaSetReconciler) ensurePrometheusTLSResources(ctx context.Context, secrets secret.GetUpdateCreateDeleter, mdb mdbv1.MongoDBCommunity) error {
 	if mdb.Spec.Prometheus == nil || mdb.Spec.Prometheus.TLSSecretRef.Name == "" {
 		return nil
 	}
@@ -308,7 +499,7 @@ func (r *ReplicaSetReconciler) ensurePrometheusTLSResources(ctx context.Context,
 	// the TLS secret needs to be created beforehand, as both the StatefulSet and AutomationConfig
 	// require the contents.
 	r.log.Infof("Prometheus TLS is enabled, creating/updating TLS secret")
-	if err := ensurePrometheusTLSSecret(ctx, r.client, mdb); err != nil {
+	if err := ensurePrometheusTLSSecret(ctx, secrets, mdb); err != nil {
 		return fmt.Errorf("could not ensure TLS secret: %s", err)
 	}
 
@@ -346,7 +537,7 @@ func (r *ReplicaSetReconciler) deployStatefulSet(ctx context.Context, mdb mdbv1.
 
 // deployAutomationConfig deploys the AutomationConfig for the MongoDBCommunity resource.
 // The returned boolean indicates whether or not that Agents have all reached goal state.
-func (r *ReplicaSetReconciler) deployAutomationConfig(ctx context.Context, mdb mdbv1.MongoDBCommunity, lastAppliedSpec *mdbv1.MongoDBCommunitySpec) (bool, error) {
+func (r *ReplicaSetReconciler) deployAutomationConfig(ctx context.Context, mdb mdbv1.MongoDBCommunity, lastAppliedSpec *mdbv1.MongoDBCommunitySpec, inputs automationConfigInputs) (bool, error) {
 	r.log.Infof("Creating/Updating AutomationConfig")
 
 	sts, err := r.client.GetStatefulSet(ctx, mdb.NamespacedName())
@@ -354,7 +545,7 @@ func (r *ReplicaSetReconciler) deployAutomationConfig(ctx context.Context, mdb m
 		return false, fmt.Errorf("failed to get StatefulSet: %s", err)
 	}
 
-	ac, err := r.ensureAutomationConfig(mdb, ctx, lastAppliedSpec)
+	ac, err := r.ensureAutomationConfig(mdb, ctx, lastAppliedSpec, inputs)
 	if err != nil {
 		return false, fmt.Errorf("failed to ensure AutomationConfig: %s", err)
 	}
@@ -384,13 +575,15 @@ func (r *ReplicaSetReconciler) deployAutomationConfig(ctx context.Context, mdb m
 // shouldRunInOrder returns true if the order of execution of the AutomationConfig & StatefulSet
 // functions should be sequential or not. A value of false indicates they will run in reversed order.
 func (r *ReplicaSetReconciler) shouldRunInOrder(ctx context.Context, mdb mdbv1.MongoDBCommunity) bool {
-	// The only case when we push the StatefulSet first is when we are ensuring TLS for the already existing ReplicaSet
+	// The only case when we push the StatefulSet first is when we are ensuring TLS for the already existing ReplicaSet.
+	// Once TLS is disabled in all processes, the Automation Config is updated first and the StatefulSet stops mounting
+	// the certificates.
 	sts, err := r.client.GetStatefulSet(ctx, mdb.NamespacedName())
-	if !statefulset.IsReady(sts, mdb.StatefulSetReplicasThisReconciliation()) && mdb.Spec.Security.TLS.Enabled {
+	if !statefulset.IsReady(sts, mdb.StatefulSetReplicasThisReconciliation()) && mdb.IsTLSConfiguredThisReconciliation() {
 		r.log.Debug("Enabling TLS on a deployment with a StatefulSet that is not Ready, the Automation Config must be updated first")
 		return true
 	}
-	if err == nil && mdb.Spec.Security.TLS.Enabled {
+	if err == nil && mdb.IsTLSConfiguredThisReconciliation() {
 		r.log.Debug("Enabling TLS on an existing deployment, the StatefulSet must be updated first")
 		return false
 	}
@@ -423,10 +616,10 @@ func (r *ReplicaSetReconciler) shouldRunInOrder(ctx context.Context, mdb mdbv1.M
 // deployMongoDBReplicaSet will ensure that both the AutomationConfig secret and backing StatefulSet
 // have been successfully created. A boolean is returned indicating if the process is complete
 // and an error if there was one.
-func (r *ReplicaSetReconciler) deployMongoDBReplicaSet(ctx context.Context, mdb mdbv1.MongoDBCommunity, lastAppliedSpec *mdbv1.MongoDBCommunitySpec) (bool, error) {
+func (r *ReplicaSetReconciler) deployMongoDBReplicaSet(ctx context.Context, mdb mdbv1.MongoDBCommunity, lastAppliedSpec *mdbv1.MongoDBCommunitySpec, inputs automationConfigInputs) (bool, error) {
 	return functions.RunSequentially(r.shouldRunInOrder(ctx, mdb),
 		func() (bool, error) {
-			return r.deployAutomationConfig(ctx, mdb, lastAppliedSpec)
+			return r.deployAutomationConfig(ctx, mdb, lastAppliedSpec, inputs)
 		},
 		func() (bool, error) {
 			return r.deployStatefulSet(ctx, mdb)
@@ -496,6 +689,13 @@ func (r *ReplicaSetReconciler) createOrUpdateStatefulSet(ctx context.Context, md
 	if isArbiter {
 		buildArbitersModificationFunction(mdb)(&set)
 	}
+	if mdb.IsTLSConfiguredThisReconciliation() && mdb.HasTLSMemberCertificates() {
+		hash, err := tlsMemberCertificatesHash(ctx, r.client, mdb)
+		if err != nil {
+			return fmt.Errorf("error reading the member certificates: %s", err)
+		}
+		statefulset.WithPodSpecTemplate(withTLSMemberCertificatesHash(hash))(&set)
+	}
 
 	if _, err = statefulset.CreateOrUpdate(ctx, r.client, set); err != nil {
 		return fmt.Errorf("error creating/updating StatefulSet: %s", err)
@@ -505,8 +705,8 @@ func (r *ReplicaSetReconciler) createOrUpdateStatefulSet(ctx context.Context, md
 
 // ensureAutomationConfig makes sure the AutomationConfig secret has been successfully created. The automation config
 // that was updated/created is returned.
-func (r ReplicaSetReconciler) ensureAutomationConfig(mdb mdbv1.MongoDBCommunity, ctx context.Context, lastAppliedSpec *mdbv1.MongoDBCommunitySpec) (automationconfig.AutomationConfig, error) {
-	ac, err := r.buildAutomationConfig(ctx, mdb, lastAppliedSpec)
+func (r ReplicaSetReconciler) ensureAutomationConfig(mdb mdbv1.MongoDBCommunity, ctx context.Context, lastAppliedSpec *mdbv1.MongoDBCommunitySpec, inputs automationConfigInputs) (automationconfig.AutomationConfig, error) {
+	ac, err := r.buildAutomationConfig(ctx, mdb, lastAppliedSpec, inputs)
 	if err != nil {
 		return automationconfig.AutomationConfig{}, fmt.Errorf("could not build automation config: %s", err)
 	}
@@ -615,6 +815,24 @@ func (r *ReplicaSetReconciler) buildService(mdb mdbv1.MongoDBCommunity, portMana
 // it checks that the attempted Spec is valid in relation to the Spec that resulted from that last successful configuration.
 // The validation also returns the lastSuccessFulConfiguration Spec as mdbv1.MongoDBCommunitySpec.
 func (r ReplicaSetReconciler) validateSpec(mdb mdbv1.MongoDBCommunity) (*mdbv1.MongoDBCommunitySpec, error) {
+	// The image and the watched namespaces are only known by the operator, so that the requirement of MongoDB
+	// Enterprise, the namespaces of the MongoDBCommunityUser resources and the distribution of the CA certificate to
+	// other namespaces can't be checked with the rest of the spec.
+	if !watchesAllNamespaces() && !allowsOnlyOwnNamespace(mdb) {
+		return nil, errors.New("spec.security.authentication.allowedUserNamespaces can only allow other namespaces if the operator watches all namespaces")
+	}
+	if !watchesAllNamespaces() && mdb.Spec.Security.TLS.DistributeCA != nil {
+		return nil, errors.New("spec.security.tls.distributeCA can only be set if the operator watches all namespaces")
+	}
+	if !guessEnterprise(mdb, r.mongodbImage) {
+		if mdb.Spec.IsEncryptionAtRestEnabled() {
+			return nil, errors.New("encryption at rest requires MongoDB Enterprise")
+		}
+		if mdb.Spec.AuditLog != nil {
+			return nil, errors.New("the audit log requires MongoDB Enterprise")
+		}
+	}
+
 	lastSuccessfulConfigurationSaved, ok := mdb.Annotations[lastSuccessfulConfiguration]
 	if !ok {
 		// First version of Spec
@@ -630,8 +848,24 @@ func (r ReplicaSetReconciler) validateSpec(mdb mdbv1.MongoDBCommunity) (*mdbv1.M
 	return &lastSpec, validation.ValidateUpdate(mdb, lastSpec, r.log)
 }
 
-func getCustomRolesModification(mdb mdbv1.MongoDBCommunity) (automationconfig.Modification, error) {
-	roles := mdb.Spec.Security.Roles
+// watchesAllNamespaces returns true if the operator watches all namespaces.
+func watchesAllNamespaces() bool {
+	namespace := os.Getenv(watchNamespace) // nolint:forbidigo
+	return namespace == "" || namespace == "*"
+}
+
+// allowsOnlyOwnNamespace returns true if only the MongoDBCommunityUser and MongoDBCommunityRole resources of the
+// namespace of the given resource can reference it.
+func allowsOnlyOwnNamespace(mdb mdbv1.MongoDBCommunity) bool {
+	for _, namespace := range mdb.Spec.Security.Authentication.AllowedUserNamespaces {
+		if namespace != mdb.Namespace {
+			return false
+		}
+	}
+	return true
+}
+
+func getCustomRolesModification(roles []mdbv1.CustomRole) (automationconfig.Modification, error) {
 	if roles == nil {
 		return automationconfig.NOOP(), nil
 	}
@@ -641,13 +875,24 @@ func getCustomRolesModification(mdb mdbv1.MongoDBCommunity) (automationconfig.Mo
 	}, nil
 }
 
-func (r ReplicaSetReconciler) buildAutomationConfig(ctx context.Context, mdb mdbv1.MongoDBCommunity, lastAppliedSpec *mdbv1.MongoDBCommunitySpec) (automationconfig.AutomationConfig, error) {
-	tlsModification, err := getTLSConfigModification(ctx, r.client, r.client, mdb)
+// automationConfigInputs are the resources read once at the beginning of a reconciliation which the automation config
+// is built from.
+type automationConfigInputs struct {
+	// roles are the custom roles of the spec and of the MongoDBCommunityRole resources.
+	roles         []mdbv1.CustomRole
+	userResources []userResource
+	// secrets is the client the secrets referenced by the resource are read with.
+	secrets secret.GetUpdateCreateDeleter
+}
+
+func (r ReplicaSetReconciler) buildAutomationConfig(ctx context.Context, mdb mdbv1.MongoDBCommunity, lastAppliedSpec *mdbv1.MongoDBCommunitySpec, inputs automationConfigInputs) (automationconfig.AutomationConfig, error) {
+	secrets := inputs.secrets
+	tlsModification, err := getTLSConfigModification(ctx, r.client, secrets, mdb)
 	if err != nil {
 		return automationconfig.AutomationConfig{}, fmt.Errorf("could not configure TLS modification: %s", err)
 	}
 
-	customRolesModification, err := getCustomRolesModification(mdb)
+	customRolesModification, err := getCustomRolesModification(inputs.roles)
 	if err != nil {
 		return automationconfig.AutomationConfig{}, fmt.Errorf("could not configure custom roles: %s", err)
 	}
@@ -658,26 +903,48 @@ func (r ReplicaSetReconciler) buildAutomationConfig(ctx context.Context, mdb mdb
 	}
 
 	auth := automationconfig.Auth{}
-	if err := authentication.Enable(ctx, &auth, r.client, &mdb, mdb.AgentCertificateSecretNamespacedName()); err != nil {
+	if err := authentication.Enable(ctx, &auth, secrets, userResourcesConfigurable{MongoDBCommunity: &mdb, userResources: inputs.userResources}, mdb.AgentCertificateSecretNamespacedName()); err != nil {
 		return automationconfig.AutomationConfig{}, err
 	}
 
 	if lastAppliedSpec != nil {
 		authentication.AddRemovedUsers(&auth, mdb, lastAppliedSpec)
 	}
+	authentication.AddRemovedUserResources(&auth, mdb, userResourceReferences(inputs.userResources))
+	authentication.AddRetiredShadowUsers(&auth, mdb)
+	addRemovedInitScriptsUser(&auth, mdb, lastAppliedSpec)
 
 	prometheusModification := automationconfig.NOOP()
 	if mdb.Spec.Prometheus != nil {
 		secretNamespacedName := types.NamespacedName{Name: mdb.Spec.Prometheus.PasswordSecretRef.Name, Namespace: mdb.Namespace}
 		r.secretWatcher.Watch(ctx, secretNamespacedName, mdb.NamespacedName())
 
-		prometheusModification, err = getPrometheusModification(ctx, r.client, mdb)
+		prometheusModification, err = getPrometheusModification(ctx, secrets, mdb)
 		if err != nil {
 			return automationconfig.AutomationConfig{}, fmt.Errorf("could not enable TLS on Prometheus endpoint: %s", err)
 		}
 	}
 
-	if mdb.Spec.IsAgentX509() {
+	isEnterprise := guessEnterprise(mdb, r.mongodbImage)
+	if ldap := mdb.Spec.Security.Authentication.Ldap; mdb.Spec.IsLdapEnabled() && ldap != nil {
+		if ldap.BindQueryPasswordSecretRef != nil {
+			r.secretWatcher.Watch(ctx, types.NamespacedName{Name: ldap.BindQueryPasswordSecretRef.Name, Namespace: mdb.Namespace}, mdb.NamespacedName())
+		}
+		if ldap.CaConfigMap != nil {
+			r.configMapWatcher.Watch(ctx, types.NamespacedName{Name: ldap.CaConfigMap.Name, Namespace: mdb.Namespace}, mdb.NamespacedName())
+		}
+	}
+	ldapModification, err := getLdapModification(ctx, secrets, mdb, isEnterprise)
+	if err != nil {
+		return automationconfig.AutomationConfig{}, fmt.Errorf("could not configure LDAP: %s", err)
+	}
+
+	oidcModification, err := getOidcModification(mdb, isEnterprise)
+	if err != nil {
+		return automationconfig.AutomationConfig{}, fmt.Errorf("could not configure OIDC: %s", err)
+	}
+
+	if mdb.Spec.IsAgentCertificateRequired() {
 		r.secretWatcher.Watch(ctx, mdb.AgentCertificateSecretNamespacedName(), mdb.NamespacedName())
 		r.secretWatcher.Watch(ctx, mdb.AgentCertificatePemSecretNamespacedName(), mdb.NamespacedName())
 	}
@@ -689,12 +956,16 @@ func (r ReplicaSetReconciler) buildAutomationConfig(ctx context.Context, mdb mdb
 
 	automationConfig, err := buildAutomationConfig(
 		mdb,
-		guessEnterprise(mdb, r.mongodbImage),
+		isEnterprise,
 		auth,
 		currentAC,
 		tlsModification,
 		customRolesModification,
 		prometheusModification,
+		ldapModification,
+		oidcModification,
+		getEncryptionAtRestModification(mdb),
+		getAuditLogModification(mdb),
 		processPortManager.GetPortsModification(),
 	)
 
@@ -748,9 +1019,13 @@ func buildStatefulSetModificationFunction(mdb mdbv1.MongoDBCommunity, mongodbIma
 		statefulset.WithOwnerReference(mdb.GetOwnerReferences()),
 		statefulset.WithPodSpecTemplate(
 			podtemplatespec.Apply(
-				buildTLSPodSpecModification(mdb),
+				buildTLSPodSpecModification(mdb, mongodbImage),
 				buildTLSPrometheus(mdb),
 				buildAgentX509(mdb),
+				buildLdapPodSpecModification(mdb),
+				buildEncryptionAtRestPodSpecModification(mdb, mongodbImage),
+				buildLivenessProbes(mdb),
+				buildReadinessProbeHeuristics(mdb),
 			),
 		),
 
@@ -762,6 +1037,22 @@ func buildStatefulSetModificationFunction(mdb mdbv1.MongoDBCommunity, mongodbIma
 	)
 }
 
+// buildLivenessProbes adds the liveness and startup probes to the StatefulSet if they are enabled in the resource.
+func buildLivenessProbes(mdb mdbv1.MongoDBCommunity) podtemplatespec.Modification {
+	if mdb.Spec.LivenessProbe == nil {
+		return podtemplatespec.NOOP()
+	}
+	return construct.BuildLivenessProbesModification(*mdb.Spec.LivenessProbe, mdb.Spec.StartupProbe)
+}
+
+// buildReadinessProbeHeuristics configures the readiness probe heuristics if they are set in the resource.
+func buildReadinessProbeHeuristics(mdb mdbv1.MongoDBCommunity) podtemplatespec.Modification {
+	if mdb.Spec.ReadinessProbe == nil {
+		return podtemplatespec.NOOP()
+	}
+	return construct.BuildReadinessProbeHeuristicsModification(*mdb.Spec.ReadinessProbe)
+}
+
 func buildArbitersModificationFunction(mdb mdbv1.MongoDBCommunity) statefulset.Modification {
 	return statefulset.Apply(
 		statefulset.WithReplicas(mdb.StatefulSetArbitersThisReconciliation()),
@@ -19,6 +19,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/container"
+	readinessconfig "github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/config"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/yaml"
@@ -43,6 +44,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -209,6 +211,53 @@ func TestStatefulSet_IsCorrectlyConfigured(t *testing.T) {
 	assert.Nil(t, acVolume.ConfigMap, "automation config should be stored in a secret, not a config map!")
 }
 
+func TestStatefulSet_LivenessProbesAreConfiguredWhenEnabled(t *testing.T) {
+	ctx := context.Background()
+
+	mdb := newTestReplicaSet()
+	staleSeconds := 120
+	mdb.Spec.LivenessProbe = &mdbv1.LivenessProbeConfiguration{HealthStatusStaleSeconds: &staleSeconds}
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "docker.io/mongodb", "mongodb-community-server", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	sts := appsv1.StatefulSet{}
+	err = mgr.GetClient().Get(ctx, mdb.NamespacedName(), &sts)
+	require.NoError(t, err)
+
+	agentContainer := container.GetByName(construct.AgentName, sts.Spec.Template.Spec.Containers)
+	require.NotNil(t, agentContainer)
+	require.NotNil(t, agentContainer.LivenessProbe)
+	require.NotNil(t, agentContainer.StartupProbe)
+	assert.Contains(t, agentContainer.Env, corev1.EnvVar{Name: readinessconfig.LivenessProbeHealthStatusStaleSeconds, Value: "120"})
+
+	mongodContainer := container.GetByName(construct.MongodbName, sts.Spec.Template.Spec.Containers)
+	require.NotNil(t, mongodContainer)
+	require.NotNil(t, mongodContainer.LivenessProbe)
+	require.NotNil(t, mongodContainer.StartupProbe)
+	assert.Contains(t, mongodContainer.LivenessProbe.Exec.Command, "-check="+readinessconfig.MongodLivenessCheck)
+}
+
+func TestStatefulSet_LivenessProbesAreNotConfiguredByDefault(t *testing.T) {
+	ctx := context.Background()
+
+	mdb := newTestReplicaSet()
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "docker.io/mongodb", "mongodb-community-server", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	sts := appsv1.StatefulSet{}
+	err = mgr.GetClient().Get(ctx, mdb.NamespacedName(), &sts)
+	require.NoError(t, err)
+
+	for _, c := range sts.Spec.Template.Spec.Containers {
+		assert.Nil(t, c.LivenessProbe)
+		assert.Nil(t, c.StartupProbe)
+	}
+}
+
 func TestGuessEnterprise(t *testing.T) {
 	type testConfig struct {
 		setArgs            func(t *testing.T)
@@ -919,6 +968,71 @@ func TestExistingPasswordAndKeyfile_AreUsedWhenTheSecretExists(t *testing.T) {
 
 }
 
+func TestGeneratedUserPassword_IsStoredInOwnedSecretAndConnectionString(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet(mdbv1.MongoDBUser{
+		Name:                       "app-user",
+		DB:                         "admin",
+		PasswordSecretRef:          mdbv1.SecretKeyReference{Name: "app-user-password"},
+		ScramCredentialsSecretName: "app-user",
+		GeneratePassword:           true,
+		PasswordGeneration: &mdbv1.PasswordGenerationOptions{
+			Length:           ptr.To(16),
+			CharacterClasses: []mdbv1.PasswordCharacterClass{mdbv1.PasswordCharacterClassDigits},
+		},
+	})
+	mgr := client.NewManager(ctx, &mdb)
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	passwordSecret, err := mgr.Client.GetSecret(ctx, types.NamespacedName{Name: "app-user-password", Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	assert.Equal(t, mdb.GetOwnerReferences(), passwordSecret.OwnerReferences)
+	password := string(passwordSecret.Data["password"])
+	assert.Regexp(t, "^[0-9]{16}$", password)
+
+	user := mdb.GetAuthUsers()[0]
+	connectionStringSecret, err := mgr.Client.GetSecret(ctx, types.NamespacedName{Name: user.ConnectionStringSecretName, Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	assert.Equal(t, password, string(connectionStringSecret.Data["password"]))
+
+	res, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	passwordSecret, err = mgr.Client.GetSecret(ctx, types.NamespacedName{Name: "app-user-password", Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	assert.Equal(t, password, string(passwordSecret.Data["password"]), "the generated password must not change between reconciliations")
+}
+
+func TestGeneratedUserPassword_ExistingSecretIsNotOverwritten(t *testing.T) {
+	ctx := context.Background()
+	mdb := newScramReplicaSet(mdbv1.MongoDBUser{
+		Name:                       "app-user",
+		DB:                         "admin",
+		PasswordSecretRef:          mdbv1.SecretKeyReference{Name: "app-user-password", Key: "pwd"},
+		ScramCredentialsSecretName: "app-user",
+		GeneratePassword:           true,
+	})
+	mgr := client.NewManager(ctx, &mdb)
+	err := secret.CreateOrUpdate(ctx, mgr.Client, secret.Builder().
+		SetName("app-user-password").
+		SetNamespace(mdb.Namespace).
+		SetField("pwd", "my-password").
+		Build())
+	require.NoError(t, err)
+
+	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: mdb.NamespacedName()})
+	assertReconciliationSuccessful(t, res, err)
+
+	passwordSecret, err := mgr.Client.GetSecret(ctx, types.NamespacedName{Name: "app-user-password", Namespace: mdb.Namespace})
+	require.NoError(t, err)
+	assert.Equal(t, "my-password", string(passwordSecret.Data["pwd"]))
+	assert.Empty(t, passwordSecret.OwnerReferences)
+}
+
 func TestScramIsConfigured(t *testing.T) {
 	ctx := context.Background()
 	assertReplicaSetIsConfiguredWithScram(ctx, t, newScramReplicaSet())
@@ -1158,7 +1272,7 @@ func assertReplicaSetIsConfiguredWithX509(ctx context.Context, t *testing.T, mdb
 
 	r := NewReconciler(mgr, "fake-mongodbRepoUrl", "fake-mongodbImage", "ubi8", AgentImage, "fake-versionUpgradeHookImage", "fake-readinessProbeImage")
 	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: mdb.Namespace, Name: mdb.Name}})
-	assertReconciliationSuccessful(t, res, err)
+	assertReconciliationRequeuedForCertificateExpiry(t, res, err)
 
 	currentAc, err := automationconfig.ReadFromSecret(ctx, mgr.Client, types.NamespacedName{Name: mdb.AutomationConfigSecretName(), Namespace: mdb.Namespace})
 
@@ -1389,6 +1503,13 @@ func assertReconciliationSuccessful(t *testing.T, result reconcile.Result, err e
 	assert.Equal(t, time.Duration(0), result.RequeueAfter)
 }
 
+// assertReconciliationRequeuedForCertificateExpiry asserts that the reconciliation succeeded, and is requeued when a
+// certificate crosses the first warning threshold before its expiry.
+func assertReconciliationRequeuedForCertificateExpiry(t *testing.T, result reconcile.Result, err error) {
+	assert.NoError(t, err)
+	assert.Greater(t, result.RequeueAfter, 24*time.Hour)
+}
+
 // makeStatefulSetReady updates the StatefulSet corresponding to the
 // provided MongoDB resource to mark it as ready for the case of `statefulset.IsReady`
 func makeStatefulSetReady(ctx context.Context, t *testing.T, c k8sClient.Client, mdb mdbv1.MongoDBCommunity) {
@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+// auditLogRuntimeConfigurationMinimumVersion is the first MongoDB version supporting the configuration of the audit
+// filter at runtime.
+var auditLogRuntimeConfigurationMinimumVersion = semver.MustParse("5.0.0")
+
+// validateAuditLog checks that the audit log isn't also configured in spec.additionalMongodConfig, that the format is
+// only set for audit log files, that the filter is a JSON document and that the runtime configuration is supported
+// and used without a filter.
+func validateAuditLog(mdb mdbv1.MongoDBCommunity) error {
+	auditLog := mdb.Spec.AuditLog
+	if auditLog == nil {
+		return nil
+	}
+
+	for option := range mdb.Spec.AdditionalMongodConfig.Object {
+		if option == "auditLog" || strings.HasPrefix(option, "auditLog.") {
+			return errors.New("the audit log can't be configured in both spec.auditLog and spec.additionalMongodConfig")
+		}
+	}
+
+	if auditLog.Format != "" && auditLog.GetDestination() != mdbv1.AuditLogDestinationFile {
+		return fmt.Errorf("the audit log format only applies to the file destination, the destination is %s", auditLog.GetDestination())
+	}
+
+	if auditLog.Filter != "" {
+		filter := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(auditLog.Filter), &filter); err != nil {
+			return fmt.Errorf("the audit log filter is not a JSON document: %s", err)
+		}
+	}
+
+	if auditLog.IsRuntimeConfiguration() {
+		if auditLog.Filter != "" {
+			return errors.New("the audit log filter can't be set when it is configured at runtime")
+		}
+		version, err := semver.Make(mdb.Spec.Version)
+		if err != nil {
+			return fmt.Errorf("could not parse MongoDB version %s: %s", mdb.Spec.Version, err)
+		}
+		if version.LT(auditLogRuntimeConfigurationMinimumVersion) {
+			return fmt.Errorf("the runtime audit configuration requires MongoDB %s or later, the version is %s", auditLogRuntimeConfigurationMinimumVersion, mdb.Spec.Version)
+		}
+	}
+	return nil
+}
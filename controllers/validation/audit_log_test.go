@@ -0,0 +1,44 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+func TestValidateAuditLog(t *testing.T) {
+	tests := []struct {
+		name                   string
+		version                string
+		auditLog               *mdbv1.AuditLog
+		additionalMongodConfig map[string]interface{}
+		expectedErr            string
+	}{
+		{name: "Audit log not configured"},
+		{name: "Default audit log", auditLog: &mdbv1.AuditLog{}},
+		{name: "BSON file", auditLog: &mdbv1.AuditLog{Destination: mdbv1.AuditLogDestinationFile, Format: mdbv1.AuditLogFormatBSON}},
+		{name: "Filter", auditLog: &mdbv1.AuditLog{Destination: mdbv1.AuditLogDestinationConsole, Filter: `{"atype": {"$in": ["authenticate", "createUser"]}}`}},
+		{name: "Runtime configuration", version: "6.0.5", auditLog: &mdbv1.AuditLog{RuntimeConfiguration: ptr.To(true)}},
+		{name: "Also configured in the additional mongod configuration", auditLog: &mdbv1.AuditLog{}, additionalMongodConfig: map[string]interface{}{"auditLog": map[string]interface{}{"destination": "file"}}, expectedErr: "both spec.auditLog and spec.additionalMongodConfig"},
+		{name: "Format of the syslog", auditLog: &mdbv1.AuditLog{Destination: mdbv1.AuditLogDestinationSyslog, Format: mdbv1.AuditLogFormatJSON}, expectedErr: "only applies to the file destination"},
+		{name: "Invalid filter", auditLog: &mdbv1.AuditLog{Filter: `{atype: "authenticate"}`}, expectedErr: "not a JSON document"},
+		{name: "Filter which isn't a document", auditLog: &mdbv1.AuditLog{Filter: `["authenticate"]`}, expectedErr: "not a JSON document"},
+		{name: "Runtime configuration with a filter", version: "6.0.5", auditLog: &mdbv1.AuditLog{Filter: `{}`, RuntimeConfiguration: ptr.To(true)}, expectedErr: "can't be set when it is configured at runtime"},
+		{name: "Runtime configuration of MongoDB 4.4", version: "4.4.19", auditLog: &mdbv1.AuditLog{RuntimeConfiguration: ptr.To(true)}, expectedErr: "requires MongoDB 5.0.0 or later"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := mdbv1.MongoDBCommunity{Spec: mdbv1.MongoDBCommunitySpec{Version: tt.version, AuditLog: tt.auditLog}}
+			mdb.Spec.AdditionalMongodConfig.Object = tt.additionalMongodConfig
+			err := validateAuditLog(mdb)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
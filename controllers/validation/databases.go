@@ -0,0 +1,111 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+// reservedDatabases are used by MongoDB itself and can't be provisioned.
+var reservedDatabases = map[string]bool{"admin": true, "config": true, "local": true}
+
+// indexTypes are the index types which can be used instead of 1 or -1.
+var indexTypes = map[string]bool{"text": true, "2d": true, "2dsphere": true, "hashed": true}
+
+// validateDatabases checks that the databases, collections and indexes to provision are valid and unique.
+func validateDatabases(mdb mdbv1.MongoDBCommunity) error {
+	seenDatabases := map[string]bool{}
+	for _, database := range mdb.Spec.Databases {
+		if database.Name == "" || strings.ContainsAny(database.Name, `/\. "$`) {
+			return fmt.Errorf("database name %q is invalid", database.Name)
+		}
+		if reservedDatabases[database.Name] {
+			return fmt.Errorf("database %s is reserved by MongoDB and can't be provisioned", database.Name)
+		}
+		if seenDatabases[database.Name] {
+			return fmt.Errorf("database %s is defined more than once", database.Name)
+		}
+		seenDatabases[database.Name] = true
+
+		seenCollections := map[string]bool{}
+		for _, collection := range database.Collections {
+			if err := validateCollection(collection); err != nil {
+				return fmt.Errorf("collection %s.%s: %s", database.Name, collection.Name, err)
+			}
+			if seenCollections[collection.Name] {
+				return fmt.Errorf("collection %s.%s is defined more than once", database.Name, collection.Name)
+			}
+			seenCollections[collection.Name] = true
+		}
+	}
+	return nil
+}
+
+func validateCollection(collection mdbv1.Collection) error {
+	if collection.Name == "" || strings.Contains(collection.Name, "$") || strings.HasPrefix(collection.Name, "system.") {
+		return fmt.Errorf("the name is invalid")
+	}
+	if collection.Capped != nil && collection.TimeSeries != nil {
+		return fmt.Errorf("a collection can't be both capped and a time series collection")
+	}
+	if collection.TimeSeries != nil {
+		if collection.TimeSeries.TimeField == "" {
+			return fmt.Errorf("time series collections must have a time field")
+		}
+		if collection.Validator != nil || collection.ValidationLevel != "" || collection.ValidationAction != "" {
+			return fmt.Errorf("time series collections don't support validators")
+		}
+	}
+	if err := validateDocument(collection.Validator); err != nil {
+		return fmt.Errorf("invalid validator: %s", err)
+	}
+
+	seenIndexes := map[string]bool{}
+	for _, index := range collection.Indexes {
+		if err := validateIndex(index); err != nil {
+			return fmt.Errorf("index %s: %s", index.Name, err)
+		}
+		if seenIndexes[index.Name] {
+			return fmt.Errorf("index %s is defined more than once", index.Name)
+		}
+		seenIndexes[index.Name] = true
+	}
+	return nil
+}
+
+func validateIndex(index mdbv1.Index) error {
+	if index.Name == "" || index.Name == "_id_" {
+		return fmt.Errorf("the name is invalid")
+	}
+	if len(index.Keys) == 0 {
+		return fmt.Errorf("indexes must have at least one key")
+	}
+	for _, key := range index.Keys {
+		if key.Field == "" {
+			return fmt.Errorf("index keys must have a field")
+		}
+		if key.Type.Type == intstr.Int && key.Type.IntVal != 1 && key.Type.IntVal != -1 {
+			return fmt.Errorf("the type of field %s must be 1 or -1", key.Field)
+		}
+		if key.Type.Type == intstr.String && !indexTypes[key.Type.StrVal] {
+			return fmt.Errorf("the type of field %s must be one of text, 2d, 2dsphere and hashed", key.Field)
+		}
+	}
+	if err := validateDocument(index.PartialFilterExpression); err != nil {
+		return fmt.Errorf("invalid partial filter expression: %s", err)
+	}
+	return nil
+}
+
+// validateDocument checks that the given document is valid Extended JSON.
+func validateDocument(raw *runtime.RawExtension) error {
+	if raw == nil || len(raw.Raw) == 0 {
+		return nil
+	}
+	return bson.UnmarshalExtJSON(raw.Raw, false, &bson.D{})
+}
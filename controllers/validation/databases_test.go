@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+func TestValidateDatabases(t *testing.T) {
+	index := func(keys ...mdbv1.IndexKey) mdbv1.Index {
+		return mdbv1.Index{Name: "idx", Keys: keys}
+	}
+	tests := []struct {
+		name        string
+		database    string
+		collection  mdbv1.Collection
+		expectedErr string
+	}{
+		{name: "Valid collection", database: "app", collection: mdbv1.Collection{
+			Name:      "orders",
+			Validator: &runtime.RawExtension{Raw: []byte(`{"date": {"$gte": {"$date": "2024-01-01T00:00:00Z"}}}`)},
+			Indexes:   []mdbv1.Index{index(mdbv1.IndexKey{Field: "customer", Type: intstr.FromInt32(1)}, mdbv1.IndexKey{Field: "location", Type: intstr.FromString("2dsphere")})},
+		}},
+		{name: "Reserved database", database: "admin", collection: mdbv1.Collection{Name: "orders"}, expectedErr: "reserved by MongoDB"},
+		{name: "Invalid database name", database: "app.db", collection: mdbv1.Collection{Name: "orders"}, expectedErr: `database name "app.db" is invalid`},
+		{name: "System collection", database: "app", collection: mdbv1.Collection{Name: "system.views"}, expectedErr: "the name is invalid"},
+		{name: "Capped time series collection", database: "app", collection: mdbv1.Collection{
+			Name:       "events",
+			Capped:     &mdbv1.CappedCollectionOptions{Size: 1024},
+			TimeSeries: &mdbv1.TimeSeriesCollectionOptions{TimeField: "timestamp"},
+		}, expectedErr: "both capped and a time series collection"},
+		{name: "Invalid validator", database: "app", collection: mdbv1.Collection{
+			Name:      "orders",
+			Validator: &runtime.RawExtension{Raw: []byte(`{"date": {"$date": "yesterday"}}`)},
+		}, expectedErr: "invalid validator"},
+		{name: "Invalid index direction", database: "app", collection: mdbv1.Collection{
+			Name:    "orders",
+			Indexes: []mdbv1.Index{index(mdbv1.IndexKey{Field: "customer", Type: intstr.FromInt32(2)})},
+		}, expectedErr: "must be 1 or -1"},
+		{name: "Unknown index type", database: "app", collection: mdbv1.Collection{
+			Name:    "orders",
+			Indexes: []mdbv1.Index{index(mdbv1.IndexKey{Field: "customer", Type: intstr.FromString("btree")})},
+		}, expectedErr: "must be one of text, 2d, 2dsphere and hashed"},
+		{name: "Duplicate index", database: "app", collection: mdbv1.Collection{
+			Name: "orders",
+			Indexes: []mdbv1.Index{
+				index(mdbv1.IndexKey{Field: "customer", Type: intstr.FromInt32(1)}),
+				index(mdbv1.IndexKey{Field: "date", Type: intstr.FromInt32(1)}),
+			},
+		}, expectedErr: "index idx is defined more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := mdbv1.MongoDBCommunity{Spec: mdbv1.MongoDBCommunitySpec{
+				Databases: []mdbv1.Database{{Name: tt.database, Collections: []mdbv1.Collection{tt.collection}}},
+			}}
+			err := validateDatabases(mdb)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
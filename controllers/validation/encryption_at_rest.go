@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"errors"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+// validateEncryptionAtRest checks that the master key of the encryption at rest is either a local key or managed by a
+// KMIP server, and that the KMIP server can be reached.
+func validateEncryptionAtRest(mdb mdbv1.MongoDBCommunity) error {
+	if !mdb.Spec.IsEncryptionAtRestEnabled() {
+		return nil
+	}
+
+	encryptionAtRest := mdb.Spec.Security.EncryptionAtRest
+	if (encryptionAtRest.KeyFileSecretRef == nil) == (encryptionAtRest.Kmip == nil) {
+		return errors.New("encryption at rest requires exactly one of spec.security.encryptionAtRest.keyFileSecretRef and spec.security.encryptionAtRest.kmip")
+	}
+	if encryptionAtRest.KeyFileSecretRef != nil && encryptionAtRest.KeyFileSecretRef.Name == "" {
+		return errors.New("the secret of the local master key must be specified")
+	}
+	if kmip := encryptionAtRest.Kmip; kmip != nil {
+		if len(kmip.ServerNames) == 0 {
+			return errors.New("at least one KMIP server must be specified")
+		}
+		if kmip.ClientCertificateSecretRef.Name == "" {
+			return errors.New("the secret of the KMIP client certificate must be specified")
+		}
+	}
+	return nil
+}
+
+// validateEncryptionAtRestUpdate checks that the encryption at rest of the data files isn't enabled or disabled, and
+// that its master key isn't moved between a local key and a KMIP server, as the existing data files would have to be
+// synced again.
+func validateEncryptionAtRestUpdate(mdb mdbv1.MongoDBCommunity, oldSpec mdbv1.MongoDBCommunitySpec) error {
+	if mdb.Spec.IsEncryptionAtRestEnabled() != oldSpec.IsEncryptionAtRestEnabled() {
+		return errors.New("encryption at rest can't be enabled or disabled once the data files are created")
+	}
+	if !mdb.Spec.IsEncryptionAtRestEnabled() {
+		return nil
+	}
+	if (mdb.Spec.Security.EncryptionAtRest.Kmip == nil) != (oldSpec.Security.EncryptionAtRest.Kmip == nil) {
+		return errors.New("the master key of the encryption at rest can't be moved between a local key and a KMIP server")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+func TestValidateEncryptionAtRest(t *testing.T) {
+	keyFile := &mdbv1.SecretKeyReference{Name: "encryption-key"}
+	kmip := &mdbv1.Kmip{ServerNames: []string{"pykmip.kmip.svc.cluster.local"}, ClientCertificateSecretRef: corev1.LocalObjectReference{Name: "kmip-client"}}
+	tests := []struct {
+		name             string
+		encryptionAtRest *mdbv1.EncryptionAtRest
+		expectedErr      string
+	}{
+		{name: "Encryption at rest not configured"},
+		{name: "Encryption at rest disabled", encryptionAtRest: &mdbv1.EncryptionAtRest{}},
+		{name: "Local master key", encryptionAtRest: &mdbv1.EncryptionAtRest{Enabled: true, KeyFileSecretRef: keyFile}},
+		{name: "KMIP", encryptionAtRest: &mdbv1.EncryptionAtRest{Enabled: true, Kmip: kmip}},
+		{name: "No master key", encryptionAtRest: &mdbv1.EncryptionAtRest{Enabled: true}, expectedErr: "requires exactly one of"},
+		{name: "Local master key and KMIP", encryptionAtRest: &mdbv1.EncryptionAtRest{Enabled: true, KeyFileSecretRef: keyFile, Kmip: kmip}, expectedErr: "requires exactly one of"},
+		{name: "No KMIP server", encryptionAtRest: &mdbv1.EncryptionAtRest{Enabled: true, Kmip: &mdbv1.Kmip{ClientCertificateSecretRef: kmip.ClientCertificateSecretRef}}, expectedErr: "at least one KMIP server"},
+		{name: "No KMIP client certificate", encryptionAtRest: &mdbv1.EncryptionAtRest{Enabled: true, Kmip: &mdbv1.Kmip{ServerNames: kmip.ServerNames}}, expectedErr: "the secret of the KMIP client certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := mdbv1.MongoDBCommunity{}
+			mdb.Spec.Security.EncryptionAtRest = tt.encryptionAtRest
+			err := validateEncryptionAtRest(mdb)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestValidateEncryptionAtRestUpdate(t *testing.T) {
+	keyFile := &mdbv1.EncryptionAtRest{Enabled: true, KeyFileSecretRef: &mdbv1.SecretKeyReference{Name: "encryption-key"}}
+	kmip := &mdbv1.EncryptionAtRest{Enabled: true, Kmip: &mdbv1.Kmip{ServerNames: []string{"pykmip"}, ClientCertificateSecretRef: corev1.LocalObjectReference{Name: "kmip-client"}}}
+	rotatedKmip := kmip.DeepCopy()
+	rotatedKmip.Kmip.KeyIdentifier = "2"
+	tests := []struct {
+		name        string
+		old         *mdbv1.EncryptionAtRest
+		new         *mdbv1.EncryptionAtRest
+		expectedErr string
+	}{
+		{name: "Unencrypted"},
+		{name: "Unchanged", old: keyFile, new: keyFile},
+		{name: "KMIP master key rotated", old: kmip, new: rotatedKmip},
+		{name: "Enabled", new: keyFile, expectedErr: "can't be enabled or disabled"},
+		{name: "Disabled", old: kmip, new: &mdbv1.EncryptionAtRest{}, expectedErr: "can't be enabled or disabled"},
+		{name: "Moved to KMIP", old: keyFile, new: kmip, expectedErr: "can't be moved between a local key and a KMIP server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := mdbv1.MongoDBCommunity{}
+			mdb.Spec.Security.EncryptionAtRest = tt.new
+			oldSpec := mdbv1.MongoDBCommunitySpec{}
+			oldSpec.Security.EncryptionAtRest = tt.old
+			err := validateEncryptionAtRestUpdate(mdb, oldSpec)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+// validateInitScripts checks that the init scripts are unique, read from exactly one source and that the user they
+// connect as can be created.
+func validateInitScripts(mdb mdbv1.MongoDBCommunity) error {
+	if len(mdb.Spec.InitScripts) == 0 {
+		return nil
+	}
+
+	scram := len(mdb.Spec.Security.Authentication.Modes) == 0
+	for _, mode := range mdb.Spec.Security.Authentication.Modes {
+		mechanism := mdbv1.ConvertAuthModeToAuthMechanism(mode)
+		scram = scram || mechanism == constants.Sha256 || mechanism == constants.Sha1
+	}
+	if !scram {
+		return fmt.Errorf("init scripts require SCRAM authentication to be enabled")
+	}
+
+	for _, user := range mdb.Spec.Users {
+		if user.Name == mdbv1.InitScriptsUsername {
+			return fmt.Errorf("user name %s is reserved for the init scripts", user.Name)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, script := range mdb.Spec.InitScripts {
+		if errs := validation.IsDNS1123Label(script.Name); len(errs) > 0 {
+			return fmt.Errorf("init script name %q is invalid: %s", script.Name, strings.Join(errs, ", "))
+		}
+		if seen[script.Name] {
+			return fmt.Errorf("init script %s is defined more than once", script.Name)
+		}
+		seen[script.Name] = true
+
+		if (script.ConfigMapRef == nil) == (script.SecretRef == nil) {
+			return fmt.Errorf("init script %s must reference exactly one of a ConfigMap and a Secret", script.Name)
+		}
+		if script.GetType() == mdbv1.InitScriptJS {
+			if script.Database != "" || script.Collection != "" {
+				return fmt.Errorf("init script %s: database and collection are only supported by json and bson scripts", script.Name)
+			}
+		} else if script.Database == "" || script.Collection == "" {
+			return fmt.Errorf("init script %s: %s scripts require a database and a collection", script.Name, script.GetType())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+func TestValidateInitScripts(t *testing.T) {
+	source := &mdbv1.InitScriptSourceReference{Name: "seed", Key: "seed.js"}
+	tests := []struct {
+		name        string
+		scripts     []mdbv1.InitScript
+		users       []mdbv1.MongoDBUser
+		modes       []mdbv1.AuthMode
+		expectedErr string
+	}{
+		{name: "Valid scripts", scripts: []mdbv1.InitScript{
+			{Name: "schema", ConfigMapRef: source},
+			{Name: "countries", Type: mdbv1.InitScriptBSON, SecretRef: source, Database: "app", Collection: "countries"},
+		}},
+		{name: "Invalid name", scripts: []mdbv1.InitScript{{Name: "Schema", ConfigMapRef: source}}, expectedErr: `init script name "Schema" is invalid`},
+		{name: "Duplicate name", scripts: []mdbv1.InitScript{{Name: "schema", ConfigMapRef: source}, {Name: "schema", SecretRef: source}}, expectedErr: "defined more than once"},
+		{name: "No source", scripts: []mdbv1.InitScript{{Name: "schema"}}, expectedErr: "exactly one of a ConfigMap and a Secret"},
+		{name: "Two sources", scripts: []mdbv1.InitScript{{Name: "schema", ConfigMapRef: source, SecretRef: source}}, expectedErr: "exactly one of a ConfigMap and a Secret"},
+		{name: "JSON script without collection", scripts: []mdbv1.InitScript{{Name: "countries", Type: mdbv1.InitScriptJSON, ConfigMapRef: source, Database: "app"}}, expectedErr: "json scripts require a database and a collection"},
+		{name: "JavaScript script with database", scripts: []mdbv1.InitScript{{Name: "schema", ConfigMapRef: source, Database: "app"}}, expectedErr: "only supported by json and bson scripts"},
+		{name: "Reserved user name", scripts: []mdbv1.InitScript{{Name: "schema", ConfigMapRef: source}}, users: []mdbv1.MongoDBUser{{Name: mdbv1.InitScriptsUsername}}, expectedErr: "reserved for the init scripts"},
+		{name: "SCRAM disabled", scripts: []mdbv1.InitScript{{Name: "schema", ConfigMapRef: source}}, modes: []mdbv1.AuthMode{"X509"}, expectedErr: "require SCRAM authentication"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := mdbv1.MongoDBCommunity{Spec: mdbv1.MongoDBCommunitySpec{InitScripts: tt.scripts, Users: tt.users}}
+			mdb.Spec.Security.Authentication.Modes = tt.modes
+			err := validateInitScripts(mdb)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+// validateLdap checks that LDAP is configured when it is enabled, that the agent doesn't authenticate with it and
+// that the user to DN mapping rules are valid.
+func validateLdap(mdb mdbv1.MongoDBCommunity) error {
+	auth := mdb.Spec.Security.Authentication
+	if !mdb.Spec.IsLdapEnabled() {
+		if auth.Ldap != nil {
+			return errors.New("spec.security.authentication.ldap is configured but LDAP is not part of spec.security.authentication.modes")
+		}
+		return nil
+	}
+
+	if auth.Ldap == nil {
+		return errors.New("LDAP authentication is enabled but spec.security.authentication.ldap is not configured")
+	}
+	if len(auth.Modes) == 1 {
+		return errors.New("LDAP authentication must be enabled together with SCRAM or X.509, which the agent authenticates with")
+	}
+	if auth.AgentMode == "LDAP" {
+		return errors.New("the agent can't authenticate with LDAP, spec.security.authentication.agentMode must be SCRAM or X.509")
+	}
+
+	if len(auth.Ldap.Servers) == 0 {
+		return errors.New("at least one LDAP server must be specified")
+	}
+	if auth.Ldap.BindQueryPasswordSecretRef != nil && auth.Ldap.BindQueryUser == "" {
+		return errors.New("the LDAP bind query password requires a bind query user")
+	}
+	for i, rule := range auth.Ldap.UserToDNMapping {
+		if rule.Match == "" {
+			return fmt.Errorf("LDAP user to DN mapping rule %d is missing a match expression", i)
+		}
+		if (rule.Substitution == "") == (rule.LdapQuery == "") {
+			return fmt.Errorf("LDAP user to DN mapping rule %d must specify exactly one of a substitution and an LDAP query", i)
+		}
+	}
+	return nil
+}
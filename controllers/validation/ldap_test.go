@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+func TestValidateLdap(t *testing.T) {
+	servers := []string{"openldap.ldap.svc.cluster.local:636"}
+	tests := []struct {
+		name        string
+		modes       []mdbv1.AuthMode
+		agentMode   mdbv1.AuthMode
+		ldap        *mdbv1.LdapConfiguration
+		expectedErr string
+	}{
+		{name: "LDAP disabled"},
+		{name: "Valid configuration", modes: []mdbv1.AuthMode{"SCRAM", "LDAP"}, ldap: &mdbv1.LdapConfiguration{
+			Servers:                    servers,
+			BindQueryUser:              "cn=admin,dc=example,dc=org",
+			BindQueryPasswordSecretRef: &mdbv1.SecretKeyReference{Name: "ldap-bind"},
+			UserToDNMapping: []mdbv1.LdapUserToDNMapping{
+				{Match: "(.+)", Substitution: "cn={0},ou=users,dc=example,dc=org"},
+				{Match: "(.+)@example.org", LdapQuery: "ou=users,dc=example,dc=org??one?(mail={0})"},
+			},
+		}},
+		{name: "Configuration without LDAP", modes: []mdbv1.AuthMode{"SCRAM"}, ldap: &mdbv1.LdapConfiguration{Servers: servers}, expectedErr: "LDAP is not part of spec.security.authentication.modes"},
+		{name: "LDAP without configuration", modes: []mdbv1.AuthMode{"SCRAM", "LDAP"}, expectedErr: "spec.security.authentication.ldap is not configured"},
+		{name: "LDAP only", modes: []mdbv1.AuthMode{"LDAP"}, ldap: &mdbv1.LdapConfiguration{Servers: servers}, expectedErr: "must be enabled together with SCRAM or X.509"},
+		{name: "Agent LDAP", modes: []mdbv1.AuthMode{"SCRAM", "LDAP"}, agentMode: "LDAP", ldap: &mdbv1.LdapConfiguration{Servers: servers}, expectedErr: "the agent can't authenticate with LDAP"},
+		{name: "No servers", modes: []mdbv1.AuthMode{"SCRAM", "LDAP"}, ldap: &mdbv1.LdapConfiguration{}, expectedErr: "at least one LDAP server"},
+		{name: "Password without user", modes: []mdbv1.AuthMode{"SCRAM", "LDAP"}, ldap: &mdbv1.LdapConfiguration{Servers: servers, BindQueryPasswordSecretRef: &mdbv1.SecretKeyReference{Name: "ldap-bind"}}, expectedErr: "requires a bind query user"},
+		{name: "Mapping without match", modes: []mdbv1.AuthMode{"SCRAM", "LDAP"}, ldap: &mdbv1.LdapConfiguration{Servers: servers, UserToDNMapping: []mdbv1.LdapUserToDNMapping{{Substitution: "cn={0}"}}}, expectedErr: "missing a match expression"},
+		{name: "Mapping with substitution and query", modes: []mdbv1.AuthMode{"SCRAM", "LDAP"}, ldap: &mdbv1.LdapConfiguration{Servers: servers, UserToDNMapping: []mdbv1.LdapUserToDNMapping{{Match: "(.+)", Substitution: "cn={0}", LdapQuery: "ou=users??one?(uid={0})"}}}, expectedErr: "exactly one of a substitution and an LDAP query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := mdbv1.MongoDBCommunity{}
+			mdb.Spec.Security.Authentication.Modes = tt.modes
+			mdb.Spec.Security.Authentication.AgentMode = tt.agentMode
+			mdb.Spec.Security.Authentication.Ldap = tt.ldap
+			err := validateLdap(mdb)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestValidateUsers_LdapUsers(t *testing.T) {
+	mdb := mdbv1.MongoDBCommunity{Spec: mdbv1.MongoDBCommunitySpec{Users: []mdbv1.MongoDBUser{{Name: "alice", DB: "$external"}}}}
+	mdb.Spec.Security.Authentication.Modes = []mdbv1.AuthMode{"SCRAM"}
+	assert.ErrorContains(t, validateUsers(mdb), "none of X.509, LDAP and OIDC is enabled")
+
+	mdb.Spec.Security.Authentication.Modes = []mdbv1.AuthMode{"SCRAM", "LDAP"}
+	assert.NoError(t, validateUsers(mdb))
+}
+
+func TestValidateUsers_ExternalUserWithPassword(t *testing.T) {
+	for _, mode := range []mdbv1.AuthMode{"LDAP", "OIDC"} {
+		t.Run(string(mode), func(t *testing.T) {
+			mdb := mdbv1.MongoDBCommunity{Spec: mdbv1.MongoDBCommunitySpec{Users: []mdbv1.MongoDBUser{{
+				Name:              "alice",
+				DB:                "$external",
+				PasswordSecretRef: mdbv1.SecretKeyReference{Name: "alice-password"},
+			}}}}
+			mdb.Spec.Security.Authentication.Modes = []mdbv1.AuthMode{"SCRAM", mode}
+			assert.ErrorContains(t, validateUsers(mdb), "$external user alice should not have a password secret name")
+		})
+	}
+}
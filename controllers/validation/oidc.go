@@ -0,0 +1,126 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+// oidcMinimumVersion is the first MongoDB version supporting OIDC authentication with the identity providers
+// configured by the oidcIdentityProviders server parameter.
+var oidcMinimumVersion = semver.MustParse("7.0.11")
+
+// validateOidc checks that the identity providers are configured when OIDC is enabled, that the agent doesn't
+// authenticate with it, that the providers are consistent with their authorization type and method, that the MongoDB
+// version supports OIDC and that the $external users can be authenticated by an identity provider.
+func validateOidc(mdb mdbv1.MongoDBCommunity) error {
+	auth := mdb.Spec.Security.Authentication
+	if !mdb.Spec.IsOidcEnabled() {
+		if len(auth.OidcProviderConfigs) > 0 {
+			return errors.New("spec.security.authentication.oidcProviderConfigs is configured but OIDC is not part of spec.security.authentication.modes")
+		}
+		return nil
+	}
+
+	if len(auth.OidcProviderConfigs) == 0 {
+		return errors.New("OIDC authentication is enabled but spec.security.authentication.oidcProviderConfigs is empty")
+	}
+	if !mdbv1.IsAuthPresent(auth.Modes, "SCRAM") && !mdbv1.IsAuthPresent(auth.Modes, "SCRAM-SHA-256") &&
+		!mdbv1.IsAuthPresent(auth.Modes, "SCRAM-SHA-1") && !mdbv1.IsAuthPresent(auth.Modes, "X509") {
+		return errors.New("OIDC authentication must be enabled together with SCRAM or X.509, which the agent authenticates with")
+	}
+	if auth.AgentMode == "OIDC" {
+		return errors.New("the agent can't authenticate with OIDC, spec.security.authentication.agentMode must be SCRAM or X.509")
+	}
+
+	version, err := semver.Make(mdb.Spec.Version)
+	if err != nil {
+		return fmt.Errorf("could not parse MongoDB version %s: %s", mdb.Spec.Version, err)
+	}
+	if version.LT(oidcMinimumVersion) {
+		return fmt.Errorf("OIDC authentication requires MongoDB %s or later, the version is %s", oidcMinimumVersion, mdb.Spec.Version)
+	}
+
+	seen := map[string]bool{}
+	workforceProviders := 0
+	var userIDPrefixes []string
+	for _, config := range auth.OidcProviderConfigs {
+		if err := validateOidcProviderConfig(config); err != nil {
+			return err
+		}
+		if seen[config.ConfigurationName] {
+			return fmt.Errorf("OIDC provider configuration %s is defined more than once", config.ConfigurationName)
+		}
+		seen[config.ConfigurationName] = true
+
+		if config.GetAuthorizationMethod() == mdbv1.OidcAuthorizationMethodWorkforceIdentityFederation {
+			workforceProviders++
+		}
+		if config.GetAuthorizationType() == mdbv1.OidcAuthorizationTypeUserID {
+			userIDPrefixes = append(userIDPrefixes, config.ConfigurationName+"/")
+		}
+	}
+	if workforceProviders > 1 {
+		return errors.New("only one OIDC provider configuration can use the WorkforceIdentityFederation authorization method")
+	}
+
+	// X.509 and LDAP users are also in the $external database, they are not authenticated by the identity providers
+	if mdb.Spec.IsLdapEnabled() || mdbv1.IsAuthPresent(auth.Modes, "X509") {
+		return nil
+	}
+	for _, user := range mdb.Spec.Users {
+		if user.DB == constants.ExternalDB && !hasAnyPrefix(user.Name, userIDPrefixes) {
+			return fmt.Errorf("OIDC user %s must be named <configurationName>/<user claim> after an OIDC provider configuration with the UserID authorization type", user.Name)
+		}
+	}
+	return nil
+}
+
+func validateOidcProviderConfig(config mdbv1.OidcProviderConfig) error {
+	if config.ConfigurationName == "" {
+		return errors.New("OIDC provider configurations must have a configuration name")
+	}
+	if !strings.HasPrefix(config.IssuerURI, "https://") {
+		return fmt.Errorf("OIDC provider configuration %s must have an https issuer URI", config.ConfigurationName)
+	}
+	if config.Audience == "" {
+		return fmt.Errorf("OIDC provider configuration %s is missing an audience", config.ConfigurationName)
+	}
+
+	switch config.GetAuthorizationType() {
+	case mdbv1.OidcAuthorizationTypeGroupMembership:
+		if config.GroupsClaim == "" {
+			return fmt.Errorf("OIDC provider configuration %s requires a groups claim for the GroupMembership authorization type", config.ConfigurationName)
+		}
+	case mdbv1.OidcAuthorizationTypeUserID:
+		if config.GroupsClaim != "" {
+			return fmt.Errorf("OIDC provider configuration %s can't have a groups claim with the UserID authorization type", config.ConfigurationName)
+		}
+	}
+
+	switch config.GetAuthorizationMethod() {
+	case mdbv1.OidcAuthorizationMethodWorkforceIdentityFederation:
+		if config.ClientId == "" {
+			return fmt.Errorf("OIDC provider configuration %s requires a client id for the WorkforceIdentityFederation authorization method", config.ConfigurationName)
+		}
+	case mdbv1.OidcAuthorizationMethodWorkloadIdentityFederation:
+		if config.ClientId != "" || len(config.RequestedScopes) > 0 {
+			return fmt.Errorf("OIDC provider configuration %s can't have a client id or requested scopes with the WorkloadIdentityFederation authorization method", config.ConfigurationName)
+		}
+	}
+	return nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+func TestValidateOidc(t *testing.T) {
+	workload := mdbv1.OidcProviderConfig{ConfigurationName: "workload", IssuerURI: "https://kubernetes.default.svc.cluster.local", Audience: "mongodb"}
+	workforce := mdbv1.OidcProviderConfig{
+		ConfigurationName:   "okta",
+		IssuerURI:           "https://example.okta.com/oauth2/default",
+		Audience:            "api://default",
+		AuthorizationType:   mdbv1.OidcAuthorizationTypeGroupMembership,
+		AuthorizationMethod: mdbv1.OidcAuthorizationMethodWorkforceIdentityFederation,
+		GroupsClaim:         "groups",
+		ClientId:            "0oa1",
+	}
+	withChange := func(config mdbv1.OidcProviderConfig, change func(*mdbv1.OidcProviderConfig)) mdbv1.OidcProviderConfig {
+		change(&config)
+		return config
+	}
+	tests := []struct {
+		name        string
+		modes       []mdbv1.AuthMode
+		agentMode   mdbv1.AuthMode
+		version     string
+		configs     []mdbv1.OidcProviderConfig
+		users       []mdbv1.MongoDBUser
+		expectedErr string
+	}{
+		{name: "OIDC disabled"},
+		{name: "Valid configuration", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, configs: []mdbv1.OidcProviderConfig{workload, workforce}, users: []mdbv1.MongoDBUser{{Name: "workload/system:serviceaccount:apps:billing", DB: "$external"}}},
+		{name: "Configuration without OIDC", modes: []mdbv1.AuthMode{"SCRAM"}, configs: []mdbv1.OidcProviderConfig{workload}, expectedErr: "OIDC is not part of spec.security.authentication.modes"},
+		{name: "OIDC without configuration", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, expectedErr: "oidcProviderConfigs is empty"},
+		{name: "OIDC only", modes: []mdbv1.AuthMode{"OIDC"}, configs: []mdbv1.OidcProviderConfig{workload}, expectedErr: "must be enabled together with SCRAM or X.509"},
+		{name: "LDAP and OIDC only", modes: []mdbv1.AuthMode{"LDAP", "OIDC"}, configs: []mdbv1.OidcProviderConfig{workload}, expectedErr: "must be enabled together with SCRAM or X.509"},
+		{name: "Agent OIDC", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, agentMode: "OIDC", configs: []mdbv1.OidcProviderConfig{workload}, expectedErr: "the agent can't authenticate with OIDC"},
+		{name: "Unsupported version", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, version: "6.0.5", configs: []mdbv1.OidcProviderConfig{workload}, expectedErr: "requires MongoDB 7.0.11 or later"},
+		{name: "Duplicate configuration", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, configs: []mdbv1.OidcProviderConfig{workload, workload}, expectedErr: "defined more than once"},
+		{name: "Insecure issuer", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, configs: []mdbv1.OidcProviderConfig{withChange(workload, func(c *mdbv1.OidcProviderConfig) { c.IssuerURI = "http://issuer" })}, expectedErr: "must have an https issuer URI"},
+		{name: "Missing audience", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, configs: []mdbv1.OidcProviderConfig{withChange(workload, func(c *mdbv1.OidcProviderConfig) { c.Audience = "" })}, expectedErr: "missing an audience"},
+		{name: "Group membership without groups claim", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, configs: []mdbv1.OidcProviderConfig{withChange(workforce, func(c *mdbv1.OidcProviderConfig) { c.GroupsClaim = "" })}, expectedErr: "requires a groups claim"},
+		{name: "Workforce without client id", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, configs: []mdbv1.OidcProviderConfig{withChange(workforce, func(c *mdbv1.OidcProviderConfig) { c.ClientId = "" })}, expectedErr: "requires a client id"},
+		{name: "Workload with client id", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, configs: []mdbv1.OidcProviderConfig{withChange(workload, func(c *mdbv1.OidcProviderConfig) { c.ClientId = "0oa1" })}, expectedErr: "can't have a client id"},
+		{name: "Two workforce configurations", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, configs: []mdbv1.OidcProviderConfig{workforce, withChange(workforce, func(c *mdbv1.OidcProviderConfig) { c.ConfigurationName = "entra" })}, expectedErr: "only one OIDC provider configuration"},
+		{name: "User of no configuration", modes: []mdbv1.AuthMode{"SCRAM", "OIDC"}, configs: []mdbv1.OidcProviderConfig{workload}, users: []mdbv1.MongoDBUser{{Name: "okta/alice", DB: "$external"}}, expectedErr: "must be named <configurationName>/<user claim>"},
+		{name: "X.509 user", modes: []mdbv1.AuthMode{"X509", "OIDC"}, configs: []mdbv1.OidcProviderConfig{workload}, users: []mdbv1.MongoDBUser{{Name: "CN=alice", DB: "$external"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := mdbv1.MongoDBCommunity{Spec: mdbv1.MongoDBCommunitySpec{Version: "7.0.12", Users: tt.users}}
+			if tt.version != "" {
+				mdb.Spec.Version = tt.version
+			}
+			mdb.Spec.Security.Authentication.Modes = tt.modes
+			mdb.Spec.Security.Authentication.AgentMode = tt.agentMode
+			mdb.Spec.Security.Authentication.OidcProviderConfigs = tt.configs
+			err := validateOidc(mdb)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
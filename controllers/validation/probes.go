@@ -0,0 +1,16 @@
+package validation
+
+import (
+	"errors"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+// validateProbes checks that the startup probes are only configured together with the liveness probes, as they only
+// delay the liveness checks and are not added on their own.
+func validateProbes(mdb mdbv1.MongoDBCommunity) error {
+	if mdb.Spec.StartupProbe != nil && mdb.Spec.LivenessProbe == nil {
+		return errors.New("spec.startupProbe can only be set together with spec.livenessProbe")
+	}
+	return nil
+}
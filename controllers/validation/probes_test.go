@@ -0,0 +1,34 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+func TestValidateProbes(t *testing.T) {
+	tests := []struct {
+		name          string
+		livenessProbe *mdbv1.LivenessProbeConfiguration
+		startupProbe  *mdbv1.StartupProbeConfiguration
+		expectedErr   string
+	}{
+		{name: "Probes not configured"},
+		{name: "Liveness probe only", livenessProbe: &mdbv1.LivenessProbeConfiguration{}},
+		{name: "Liveness and startup probes", livenessProbe: &mdbv1.LivenessProbeConfiguration{}, startupProbe: &mdbv1.StartupProbeConfiguration{}},
+		{name: "Startup probe only", startupProbe: &mdbv1.StartupProbeConfiguration{}, expectedErr: "can only be set together with spec.livenessProbe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := mdbv1.MongoDBCommunity{Spec: mdbv1.MongoDBCommunitySpec{LivenessProbe: tt.livenessProbe, StartupProbe: tt.startupProbe}}
+			err := validateProbes(mdb)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
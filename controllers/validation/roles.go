@@ -0,0 +1,239 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
+)
+
+// builtInRoles are the roles provided by MongoDB, they can be granted on any database.
+var builtInRoles = map[string]bool{
+	"read": true, "readWrite": true,
+	"dbAdmin": true, "dbOwner": true, "userAdmin": true,
+	"clusterAdmin": true, "clusterManager": true, "clusterMonitor": true, "hostManager": true,
+	"enableSharding": true, "directShardOperations": true,
+	"backup": true, "restore": true,
+	"readAnyDatabase": true, "readWriteAnyDatabase": true, "userAdminAnyDatabase": true, "dbAdminAnyDatabase": true,
+	"root": true, "searchCoordinator": true,
+	"__queryableBackup": true, "__system": true,
+}
+
+// knownActions are the privilege actions of the MongoDB versions supported by the operator. Actions outside of this
+// list are only reported with UnknownActions, as newer MongoDB versions can add actions.
+var knownActions = map[string]bool{}
+
+func init() {
+	for _, action := range []string{
+		// query and write
+		"find", "insert", "remove", "update", "bypassDocumentValidation", "useUUID",
+		// database management
+		"changeCustomData", "changeOwnCustomData", "changeOwnPassword", "changePassword", "createCollection",
+		"createIndex", "createRole", "createUser", "dropCollection", "dropRole", "dropUser", "enableProfiler",
+		"grantRole", "killCursors", "killAnyCursor", "planCacheIndexFilter", "revokeRole",
+		"setAuthenticationRestriction", "unlock", "viewRole", "viewUser", "analyzeShardKey", "configureQueryAnalyzer",
+		// deployment management
+		"authSchemaUpgrade", "cleanupOrphaned", "cpuProfiler", "inprog", "invalidateUserCache", "killop",
+		"planCacheRead", "planCacheWrite", "storageDetails",
+		// change streams
+		"changeStream",
+		// replication
+		"appendOplogNote", "replSetConfigure", "replSetGetConfig", "replSetGetStatus", "replSetHeartbeat",
+		"replSetResizeOplog", "replSetStateChange", "resync",
+		// sharding
+		"addShard", "checkMetadataConsistency", "clearJumboFlag", "enableSharding", "refineCollectionShardKey",
+		"reshardCollection", "flushRouterConfig", "getClusterParameter", "setClusterParameter", "getShardMap",
+		"getShardVersion", "listShards", "moveChunk", "moveCollection", "removeShard", "shardCollection",
+		"shardingState", "splitChunk", "splitVector", "unshardCollection", "transitionFromDedicatedConfigServer",
+		"transitionToDedicatedConfigServer", "setUserWriteBlockMode", "bypassWriteBlockingMode",
+		// server administration
+		"applicationMessage", "bypassDefaultMaxTimeMS", "closeAllDatabases", "collMod", "compact",
+		"compactStructuredEncryptionData", "cleanupStructuredEncryptionData", "connPoolSync", "convertToCapped",
+		"dropConnections", "dropDatabase", "dropIndex", "forceUUID", "fsync", "getDefaultRWConcern", "getParameter",
+		"hostInfo", "logRotate", "reIndex", "renameCollectionSameDB", "rotateCertificates", "setDefaultRWConcern",
+		"setFeatureCompatibilityVersion", "setParameter", "shutdown", "touch",
+		// sessions
+		"impersonate", "listSessions", "killAnySession",
+		// free monitoring
+		"checkFreeMonitoringStatus", "setFreeMonitoring",
+		// diagnostics
+		"collStats", "connPoolStats", "dbHash", "dbStats", "getCmdLineOpts", "getLog", "indexStats", "listDatabases",
+		"listCollections", "listIndexes", "listSearchIndexes", "createSearchIndexes", "dropSearchIndex",
+		"updateSearchIndex", "netstat", "operationMetrics", "queryStatsRead", "queryStatsReadTransformed",
+		"serverStatus", "setChangeStreamState", "getChangeStreamState", "top", "validate",
+		// internal
+		"anyAction", "internal", "applyOps",
+	} {
+		knownActions[action] = true
+	}
+}
+
+// RoleValidationError is returned when the inheritance of custom roles is invalid.
+type RoleValidationError struct {
+	// Roles are the custom roles causing the error.
+	Roles   []mdbv1.Role
+	message string
+}
+
+func (e RoleValidationError) Error() string {
+	return e.message
+}
+
+// ValidateCustomRole checks that the privileges of the given role have actions and valid resources.
+func ValidateCustomRole(role mdbv1.CustomRole) error {
+	if role.Role == "" || role.DB == "" {
+		return fmt.Errorf("custom role %s@%s must have a name and a database", role.Role, role.DB)
+	}
+	if IsBuiltInRole(role.Role) {
+		return fmt.Errorf("custom role %s@%s has the name of a built-in role", role.Role, role.DB)
+	}
+
+	for i, privilege := range role.Privileges {
+		if err := validateResource(privilege.Resource); err != nil {
+			return fmt.Errorf("privilege %d of custom role %s@%s: %s", i, role.Role, role.DB, err)
+		}
+		if len(privilege.Actions) == 0 {
+			return fmt.Errorf("privilege %d of custom role %s@%s has no actions", i, role.Role, role.DB)
+		}
+	}
+
+	for _, inherited := range role.Roles {
+		if inherited.Name == "" || inherited.DB == "" {
+			return fmt.Errorf("custom role %s@%s inherits from a role without a name or a database", role.Role, role.DB)
+		}
+	}
+	return nil
+}
+
+// UnknownActions returns the actions of the privileges of the given role which are not known to the operator.
+func UnknownActions(role mdbv1.CustomRole) []string {
+	var unknown []string
+	for _, privilege := range role.Privileges {
+		for _, action := range privilege.Actions {
+			if !knownActions[action] {
+				unknown = append(unknown, action)
+			}
+		}
+	}
+	return unknown
+}
+
+// validateResource checks that a resource is exactly one of a database and collection pair, the cluster
+// or any resource.
+func validateResource(resource mdbv1.Resource) error {
+	kinds := 0
+	if resource.DB != nil || resource.Collection != nil {
+		if resource.DB == nil || resource.Collection == nil {
+			return fmt.Errorf("resource must set both db and collection")
+		}
+		kinds++
+	}
+	if resource.Cluster {
+		kinds++
+	}
+	if resource.AnyResource {
+		kinds++
+	}
+	if kinds != 1 {
+		return fmt.Errorf("resource must be exactly one of db and collection, cluster or anyResource")
+	}
+	return nil
+}
+
+// ValidateRoleInheritance checks that the custom roles only inherit from built-in roles or other custom roles,
+// and that they do not inherit from themselves. The returned error is a RoleValidationError.
+func ValidateRoleInheritance(roles []mdbv1.CustomRole) error {
+	customRoles := map[mdbv1.Role]mdbv1.CustomRole{}
+	for _, role := range roles {
+		customRoles[mdbv1.Role{Name: role.Role, DB: role.DB}] = role
+	}
+
+	for _, role := range roles {
+		for _, inherited := range role.Roles {
+			if _, ok := customRoles[inherited]; !ok && !IsBuiltInRole(inherited.Name) {
+				return RoleValidationError{
+					Roles:   []mdbv1.Role{{Name: role.Role, DB: role.DB}},
+					message: fmt.Sprintf("custom role %s@%s inherits from unknown role %s@%s", role.Role, role.DB, inherited.Name, inherited.DB),
+				}
+			}
+		}
+	}
+
+	// depth-first search for cycles, visiting marks the roles of the current path.
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[mdbv1.Role]int{}
+	var path []mdbv1.Role
+	var visit func(role mdbv1.Role) error
+	visit = func(role mdbv1.Role) error {
+		switch state[role] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := path
+			for i := range path {
+				if path[i] == role {
+					cycle = path[i:]
+					break
+				}
+			}
+			names := make([]string, len(cycle))
+			for i, r := range cycle {
+				names[i] = r.Name + "@" + r.DB
+			}
+			return RoleValidationError{
+				Roles:   append([]mdbv1.Role{}, cycle...),
+				message: fmt.Sprintf("custom roles inherit from each other: %s -> %s@%s", strings.Join(names, " -> "), role.Name, role.DB),
+			}
+		}
+
+		state[role] = visiting
+		path = append(path, role)
+		for _, inherited := range customRoles[role].Roles {
+			if _, ok := customRoles[inherited]; !ok {
+				continue
+			}
+			if err := visit(inherited); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[role] = visited
+		return nil
+	}
+
+	for _, role := range roles {
+		if err := visit(mdbv1.Role{Name: role.Role, DB: role.DB}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateUserRoles checks that the roles granted to the given user are built-in roles or custom roles.
+func ValidateUserRoles(user authtypes.User, roles []mdbv1.CustomRole) error {
+	for _, userRole := range user.Roles {
+		if IsBuiltInRole(userRole.Name) {
+			continue
+		}
+		found := false
+		for _, role := range roles {
+			if role.Role == userRole.Name && role.DB == userRole.Database {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("user %s is granted unknown role %s@%s", user.Username, userRole.Name, userRole.Database)
+		}
+	}
+	return nil
+}
+
+// IsBuiltInRole returns true if the given role is provided by MongoDB.
+func IsBuiltInRole(name string) bool {
+	return builtInRoles[name]
+}
@@ -0,0 +1,97 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
+)
+
+func TestValidateCustomRole(t *testing.T) {
+	db, collection := "app", ""
+	tests := []struct {
+		name        string
+		resource    mdbv1.Resource
+		actions     []string
+		expectedErr string
+	}{
+		{name: "Database and collection", resource: mdbv1.Resource{DB: &db, Collection: &collection}, actions: []string{"find", "insert"}},
+		{name: "Cluster", resource: mdbv1.Resource{Cluster: true}, actions: []string{"serverStatus"}},
+		{name: "Any resource", resource: mdbv1.Resource{AnyResource: true}, actions: []string{"anyAction"}},
+		{name: "Database without collection", resource: mdbv1.Resource{DB: &db}, actions: []string{"find"}, expectedErr: "must set both db and collection"},
+		{name: "Cluster and database", resource: mdbv1.Resource{DB: &db, Collection: &collection, Cluster: true}, actions: []string{"find"}, expectedErr: "exactly one of"},
+		{name: "No resource", resource: mdbv1.Resource{}, actions: []string{"find"}, expectedErr: "exactly one of"},
+		{name: "Unknown action", resource: mdbv1.Resource{Cluster: true}, actions: []string{"fnid"}},
+		{name: "No actions", resource: mdbv1.Resource{Cluster: true}, expectedErr: "has no actions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCustomRole(mdbv1.CustomRole{
+				Role:       "appRole",
+				DB:         "admin",
+				Privileges: []mdbv1.Privilege{{Resource: tt.resource, Actions: tt.actions}},
+			})
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+
+	t.Run("Built-in role name", func(t *testing.T) {
+		assert.ErrorContains(t, ValidateCustomRole(mdbv1.CustomRole{Role: "readWrite", DB: "admin"}), "built-in role")
+	})
+}
+
+func TestUnknownActions(t *testing.T) {
+	role := mdbv1.CustomRole{Role: "appRole", DB: "admin", Privileges: []mdbv1.Privilege{
+		{Resource: mdbv1.Resource{Cluster: true}, Actions: []string{"serverStatus", "fnid"}},
+		{Resource: mdbv1.Resource{AnyResource: true}, Actions: []string{"anyAction"}},
+	}}
+	assert.Equal(t, []string{"fnid"}, UnknownActions(role))
+}
+
+func TestValidateRoleInheritance(t *testing.T) {
+	role := func(name string, inherited ...string) mdbv1.CustomRole {
+		r := mdbv1.CustomRole{Role: name, DB: "admin"}
+		for _, i := range inherited {
+			r.Roles = append(r.Roles, mdbv1.Role{Name: i, DB: "admin"})
+		}
+		return r
+	}
+
+	t.Run("Built-in and custom roles", func(t *testing.T) {
+		assert.NoError(t, ValidateRoleInheritance([]mdbv1.CustomRole{role("a", "b", "read"), role("b", "clusterMonitor")}))
+	})
+
+	t.Run("Unknown role", func(t *testing.T) {
+		err := ValidateRoleInheritance([]mdbv1.CustomRole{role("a", "b"), role("b", "c")})
+		roleErr := RoleValidationError{}
+		require.ErrorAs(t, err, &roleErr)
+		assert.Equal(t, []mdbv1.Role{{Name: "b", DB: "admin"}}, roleErr.Roles)
+		assert.ErrorContains(t, err, "unknown role c@admin")
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		err := ValidateRoleInheritance([]mdbv1.CustomRole{role("a", "b"), role("b", "c"), role("c", "b")})
+		roleErr := RoleValidationError{}
+		require.ErrorAs(t, err, &roleErr)
+		assert.Equal(t, []mdbv1.Role{{Name: "b", DB: "admin"}, {Name: "c", DB: "admin"}}, roleErr.Roles)
+		assert.ErrorContains(t, err, "b@admin -> c@admin -> b@admin")
+	})
+
+	t.Run("Role inheriting from itself", func(t *testing.T) {
+		assert.Error(t, ValidateRoleInheritance([]mdbv1.CustomRole{role("a", "a")}))
+	})
+}
+
+func TestValidateUserRoles(t *testing.T) {
+	roles := []mdbv1.CustomRole{{Role: "appRole", DB: "app"}}
+
+	assert.NoError(t, ValidateUserRoles(authtypes.User{Username: "user", Roles: []authtypes.Role{{Name: "readWrite", Database: "app"}, {Name: "appRole", Database: "app"}}}, roles))
+	assert.ErrorContains(t, ValidateUserRoles(authtypes.User{Username: "user", Roles: []authtypes.Role{{Name: "appRole", Database: "admin"}}}, roles), "unknown role appRole@admin")
+}
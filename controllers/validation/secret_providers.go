@@ -0,0 +1,99 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+// validateSecretProviders checks that the secret providers are configured and that the references to secrets only
+// use configured providers.
+func validateSecretProviders(mdb mdbv1.MongoDBCommunity) error {
+	names := map[string]bool{}
+	for _, provider := range mdb.Spec.Security.SecretProviders {
+		if provider.Name == "" {
+			return errors.New("secret providers must have a name")
+		}
+		if names[provider.Name] {
+			return fmt.Errorf("secret provider %s is defined more than once", provider.Name)
+		}
+		names[provider.Name] = true
+
+		if err := validateSecretProvider(provider); err != nil {
+			return fmt.Errorf("secret provider %s: %s", provider.Name, err)
+		}
+	}
+
+	validateReference := func(field, provider string) error {
+		if provider != "" && !names[provider] {
+			return fmt.Errorf("%s references secret provider %s which is not configured in spec.security.secretProviders", field, provider)
+		}
+		return nil
+	}
+
+	for _, user := range mdb.Spec.Users {
+		if err := validateReference(fmt.Sprintf("the password of user %s", user.Name), user.PasswordSecretRef.Provider); err != nil {
+			return err
+		}
+		if user.GeneratePassword && user.PasswordSecretRef.Provider != "" {
+			return fmt.Errorf("the password of user %s can't be generated, it is read from secret provider %s", user.Name, user.PasswordSecretRef.Provider)
+		}
+	}
+
+	if prometheus := mdb.Spec.Prometheus; prometheus != nil {
+		if err := validateReference("spec.prometheus.passwordSecretRef", prometheus.PasswordSecretRef.Provider); err != nil {
+			return err
+		}
+		if err := validateReference("spec.prometheus.tlsSecretKeyRef", prometheus.TLSSecretRef.Provider); err != nil {
+			return err
+		}
+	}
+
+	auth := mdb.Spec.Security.Authentication
+	if auth.Ldap != nil && auth.Ldap.BindQueryPasswordSecretRef != nil {
+		if err := validateReference("spec.security.authentication.ldap.bindQueryPasswordSecretRef", auth.Ldap.BindQueryPasswordSecretRef.Provider); err != nil {
+			return err
+		}
+	}
+
+	if auth.AgentKeyfileSecretRef != nil {
+		if auth.AgentKeyfileSecretRef.Name == "" {
+			return errors.New("spec.security.authentication.agentKeyfileSecretRef must have a name")
+		}
+		if err := validateReference("spec.security.authentication.agentKeyfileSecretRef", auth.AgentKeyfileSecretRef.Provider); err != nil {
+			return err
+		}
+	}
+
+	return validateReference("spec.security.tls.certificateKeySecretRef", mdb.Spec.Security.TLS.CertificateKeySecretProvider)
+}
+
+func validateSecretProvider(provider mdbv1.SecretProvider) error {
+	switch provider.Type {
+	case mdbv1.SecretProviderFile:
+		if provider.Vault != nil {
+			return errors.New("vault can only be configured for providers of type Vault")
+		}
+		return nil
+	case mdbv1.SecretProviderVault:
+		vault := provider.Vault
+		if vault == nil {
+			return errors.New("providers of type Vault require vault to be configured")
+		}
+		address, err := url.Parse(vault.Address)
+		if err != nil || (address.Scheme != "https" && address.Scheme != "http") || address.Host == "" {
+			return fmt.Errorf("vault.address %q must be an http or https URL", vault.Address)
+		}
+		if vault.TokenSecretRef.Name == "" {
+			return errors.New("vault.tokenSecretRef must have a name")
+		}
+		if vault.TokenSecretRef.Provider != "" {
+			return errors.New("vault.tokenSecretRef must reference a Kubernetes Secret")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown type %s", provider.Type)
+	}
+}
@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+func TestValidateSecretProviders(t *testing.T) {
+	vault := mdbv1.SecretProvider{Name: "vault", Type: mdbv1.SecretProviderVault, Vault: &mdbv1.VaultSecretProvider{
+		Address:        "https://vault.vault.svc.cluster.local:8200",
+		TokenSecretRef: mdbv1.SecretKeyReference{Name: "vault-token"},
+	}}
+	csi := mdbv1.SecretProvider{Name: "csi", Type: mdbv1.SecretProviderFile}
+	tests := []struct {
+		name        string
+		providers   []mdbv1.SecretProvider
+		modify      func(*mdbv1.MongoDBCommunity)
+		expectedErr string
+	}{
+		{name: "No providers"},
+		{name: "Valid configuration", providers: []mdbv1.SecretProvider{vault, csi}, modify: func(mdb *mdbv1.MongoDBCommunity) {
+			mdb.Spec.Users = []mdbv1.MongoDBUser{{Name: "app", PasswordSecretRef: mdbv1.SecretKeyReference{Name: "app-password", Provider: "vault"}}}
+			mdb.Spec.Security.TLS.CertificateKeySecretProvider = "csi"
+			mdb.Spec.Security.Authentication.AgentKeyfileSecretRef = &mdbv1.SecretKeyReference{Name: "agent-keyfile", Provider: "vault"}
+		}},
+		{name: "Duplicate provider", providers: []mdbv1.SecretProvider{csi, csi}, expectedErr: "defined more than once"},
+		{name: "Vault without configuration", providers: []mdbv1.SecretProvider{{Name: "vault", Type: mdbv1.SecretProviderVault}}, expectedErr: "require vault to be configured"},
+		{name: "Vault without address", providers: []mdbv1.SecretProvider{{Name: "vault", Type: mdbv1.SecretProviderVault, Vault: &mdbv1.VaultSecretProvider{TokenSecretRef: mdbv1.SecretKeyReference{Name: "vault-token"}}}}, expectedErr: "must be an http or https URL"},
+		{name: "Vault token in a provider", providers: []mdbv1.SecretProvider{{Name: "vault", Type: mdbv1.SecretProviderVault, Vault: &mdbv1.VaultSecretProvider{Address: "http://vault:8200", TokenSecretRef: mdbv1.SecretKeyReference{Name: "vault-token", Provider: "vault"}}}}, expectedErr: "must reference a Kubernetes Secret"},
+		{name: "Unknown provider", providers: []mdbv1.SecretProvider{csi}, modify: func(mdb *mdbv1.MongoDBCommunity) {
+			mdb.Spec.Users = []mdbv1.MongoDBUser{{Name: "app", PasswordSecretRef: mdbv1.SecretKeyReference{Name: "app-password", Provider: "vault"}}}
+		}, expectedErr: "the password of user app references secret provider vault which is not configured"},
+		{name: "Generated password in a provider", providers: []mdbv1.SecretProvider{csi}, modify: func(mdb *mdbv1.MongoDBCommunity) {
+			mdb.Spec.Users = []mdbv1.MongoDBUser{{Name: "app", GeneratePassword: true, PasswordSecretRef: mdbv1.SecretKeyReference{Name: "app-password", Provider: "csi"}}}
+		}, expectedErr: "can't be generated"},
+		{name: "Unknown TLS provider", modify: func(mdb *mdbv1.MongoDBCommunity) {
+			mdb.Spec.Security.TLS.CertificateKeySecretProvider = "csi"
+		}, expectedErr: "spec.security.tls.certificateKeySecretRef references secret provider csi"},
+		{name: "Keyfile without name", modify: func(mdb *mdbv1.MongoDBCommunity) {
+			mdb.Spec.Security.Authentication.AgentKeyfileSecretRef = &mdbv1.SecretKeyReference{}
+		}, expectedErr: "agentKeyfileSecretRef must have a name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := mdbv1.MongoDBCommunity{}
+			mdb.Spec.Security.SecretProviders = tt.providers
+			if tt.modify != nil {
+				tt.modify(&mdb)
+			}
+			err := validateSecretProviders(mdb)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
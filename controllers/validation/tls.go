@@ -0,0 +1,162 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+// validateTLS checks that the certificates the operator generates don't conflict with user provided ones, that
+// every member has a certificate when each member has its own, and that the clients the operator runs can connect
+// with the protocols and certificates the processes accept.
+func validateTLS(mdb mdbv1.MongoDBCommunity) error {
+	if err := validateAutoGeneratedTLS(mdb); err != nil {
+		return err
+	}
+	if err := validateCertManagerTLS(mdb); err != nil {
+		return err
+	}
+	if err := validateMemberCertificates(mdb); err != nil {
+		return err
+	}
+	if err := validateClientCertificates(mdb); err != nil {
+		return err
+	}
+	if err := validateClusterAuthMode(mdb); err != nil {
+		return err
+	}
+	if err := validateDistributeCA(mdb); err != nil {
+		return err
+	}
+	return validateDisabledProtocols(mdb)
+}
+
+func validateAutoGeneratedTLS(mdb mdbv1.MongoDBCommunity) error {
+	tls := mdb.Spec.Security.TLS
+	if !tls.AutoGenerate {
+		return nil
+	}
+
+	if tls.CaCertificateSecret != nil || tls.CaConfigMap != nil {
+		return errors.New("spec.security.tls.autoGenerate can't be combined with caCertificateSecretRef or caConfigMapRef, the operator creates the CA")
+	}
+	if tls.CertificateKeySecretProvider != "" {
+		return errors.New("spec.security.tls.autoGenerate can't be combined with certificateKeySecretProvider, the operator stores the certificate in a Kubernetes Secret")
+	}
+	return nil
+}
+
+func validateCertManagerTLS(mdb mdbv1.MongoDBCommunity) error {
+	tls := mdb.Spec.Security.TLS
+	if tls.CertManager == nil {
+		return nil
+	}
+
+	if tls.AutoGenerate {
+		return errors.New("spec.security.tls.certManager can't be combined with autoGenerate")
+	}
+	if tls.CaCertificateSecret != nil || tls.CaConfigMap != nil {
+		return errors.New("spec.security.tls.certManager can't be combined with caCertificateSecretRef or caConfigMapRef, the CA is read from the secrets of the certificates")
+	}
+	if tls.CertificateKeySecretProvider != "" {
+		return errors.New("spec.security.tls.certManager can't be combined with certificateKeySecretProvider, cert-manager stores the certificate in a Kubernetes Secret")
+	}
+	if tls.CertManager.IssuerRef.Name == "" {
+		return errors.New("spec.security.tls.certManager.issuerRef.name must be set")
+	}
+	return nil
+}
+
+func validateMemberCertificates(mdb mdbv1.MongoDBCommunity) error {
+	memberCertificates := mdb.Spec.Security.TLS.MemberCertificates
+	if memberCertificates == nil {
+		return nil
+	}
+
+	if mdb.Spec.Security.TLS.CertificateKeySecret.Name != "" {
+		return errors.New("spec.security.tls.memberCertificates can't be combined with certificateKeySecretRef")
+	}
+	if memberCertificates.SecretNameTemplate != "" && !strings.Contains(memberCertificates.SecretNameTemplate, "{pod}") {
+		return fmt.Errorf(`spec.security.tls.memberCertificates.secretNameTemplate "%s" must contain "{pod}"`, memberCertificates.SecretNameTemplate)
+	}
+	for podName, ref := range memberCertificates.SecretRefs {
+		if ref.Name == "" {
+			return fmt.Errorf("spec.security.tls.memberCertificates.secretRefs references no secret for pod %s", podName)
+		}
+	}
+	if memberCertificates.SecretNameTemplate != "" {
+		return nil
+	}
+
+	var podNames []string
+	for i := 0; i < mdb.Spec.Members; i++ {
+		podNames = append(podNames, fmt.Sprintf("%s-%d", mdb.Name, i))
+	}
+	for i := 0; i < mdb.Spec.Arbiters; i++ {
+		podNames = append(podNames, fmt.Sprintf("%s-arb-%d", mdb.Name, i))
+	}
+	for _, podName := range podNames {
+		if _, ok := memberCertificates.SecretRefs[podName]; !ok {
+			return fmt.Errorf("spec.security.tls.memberCertificates has no secretNameTemplate and no secretRefs entry for pod %s", podName)
+		}
+	}
+	return nil
+}
+
+func validateClientCertificates(mdb mdbv1.MongoDBCommunity) error {
+	tls := mdb.Spec.Security.TLS
+	if !tls.Enabled || !tls.RequireClientCertificates {
+		return nil
+	}
+
+	// the Job running the init scripts authenticates with a password and has no certificate to present.
+	if len(mdb.Spec.InitScripts) > 0 {
+		return errors.New("spec.initScripts can't be used when spec.security.tls.requireClientCertificates is enabled")
+	}
+	return nil
+}
+
+func validateClusterAuthMode(mdb mdbv1.MongoDBCommunity) error {
+	mode := mdb.Spec.Security.Authentication.ClusterAuthMode
+	if mode == "" || mode == mdbv1.ClusterAuthModeKeyFile {
+		return nil
+	}
+
+	if !mdb.Spec.Security.TLS.Enabled {
+		return fmt.Errorf("spec.security.authentication.clusterAuthMode %s requires spec.security.tls.enabled", mode)
+	}
+	return nil
+}
+
+func validateDistributeCA(mdb mdbv1.MongoDBCommunity) error {
+	distributeCA := mdb.Spec.Security.TLS.DistributeCA
+	if distributeCA == nil {
+		return nil
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(&distributeCA.NamespaceSelector); err != nil {
+		return fmt.Errorf("spec.security.tls.distributeCA.namespaceSelector is invalid: %s", err)
+	}
+	if errs := validation.IsDNS1123Subdomain(mdb.DistributedCAName()); len(errs) > 0 {
+		return fmt.Errorf("spec.security.tls.distributeCA.name %s is invalid: %s", mdb.DistributedCAName(), strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func validateDisabledProtocols(mdb mdbv1.MongoDBCommunity) error {
+	disabled := map[mdbv1.TLSProtocol]bool{}
+	for _, protocol := range mdb.Spec.Security.TLS.DisabledProtocols {
+		disabled[protocol] = true
+	}
+	for _, protocol := range []mdbv1.TLSProtocol{mdbv1.TLSProtocol10, mdbv1.TLSProtocol11, mdbv1.TLSProtocol12, mdbv1.TLSProtocol13} {
+		if !disabled[protocol] {
+			return nil
+		}
+	}
+	return errors.New("spec.security.tls.disabledProtocols disables every TLS protocol")
+}
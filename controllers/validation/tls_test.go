@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+func TestValidateTLS(t *testing.T) {
+	tests := []struct {
+		name        string
+		tls         mdbv1.TLS
+		expectedErr string
+	}{
+		{name: "Member certificates named after a template", tls: mdbv1.TLS{Enabled: true, MemberCertificates: &mdbv1.MemberCertificates{SecretNameTemplate: "{pod}-cert"}}},
+		{name: "Member certificates listed by pod", tls: mdbv1.TLS{Enabled: true, MemberCertificates: &mdbv1.MemberCertificates{SecretRefs: map[string]corev1.LocalObjectReference{
+			"my-rs-0": {Name: "cert-0"}, "my-rs-1": {Name: "cert-1"}, "my-rs-arb-0": {Name: "cert-arb-0"},
+		}}}},
+		{name: "Member certificates missing a pod", tls: mdbv1.TLS{Enabled: true, MemberCertificates: &mdbv1.MemberCertificates{SecretRefs: map[string]corev1.LocalObjectReference{
+			"my-rs-0": {Name: "cert-0"}, "my-rs-1": {Name: "cert-1"},
+		}}}, expectedErr: "no secretRefs entry for pod my-rs-arb-0"},
+		{name: "Member certificates with a template without pod", tls: mdbv1.TLS{Enabled: true, MemberCertificates: &mdbv1.MemberCertificates{SecretNameTemplate: "cert"}}, expectedErr: `must contain "{pod}"`},
+		{name: "Client certificates required", tls: mdbv1.TLS{Enabled: true, RequireClientCertificates: true, DisabledProtocols: []mdbv1.TLSProtocol{mdbv1.TLSProtocol10, mdbv1.TLSProtocol11}}},
+		{name: "Every protocol disabled", tls: mdbv1.TLS{Enabled: true, DisabledProtocols: []mdbv1.TLSProtocol{mdbv1.TLSProtocol10, mdbv1.TLSProtocol11, mdbv1.TLSProtocol12, mdbv1.TLSProtocol13}}, expectedErr: "disables every TLS protocol"},
+		{name: "Member certificates with a shared certificate", tls: mdbv1.TLS{Enabled: true, CertificateKeySecret: corev1.LocalObjectReference{Name: "cert"}, MemberCertificates: &mdbv1.MemberCertificates{SecretNameTemplate: "{pod}-cert"}}, expectedErr: "can't be combined with certificateKeySecretRef"},
+		{name: "TLS disabled"},
+		{name: "User provided certificates", tls: mdbv1.TLS{Enabled: true, CaConfigMap: &corev1.LocalObjectReference{Name: "ca"}}},
+		{name: "Auto-generated certificates", tls: mdbv1.TLS{Enabled: true, AutoGenerate: true}},
+		{name: "Auto-generated certificates in a named secret", tls: mdbv1.TLS{Enabled: true, AutoGenerate: true, CertificateKeySecret: corev1.LocalObjectReference{Name: "server-tls"}}},
+		{name: "Auto-generated certificates while TLS is disabled", tls: mdbv1.TLS{AutoGenerate: true}},
+		{name: "Auto-generated certificates with a CA", tls: mdbv1.TLS{Enabled: true, AutoGenerate: true, CaConfigMap: &corev1.LocalObjectReference{Name: "ca"}}, expectedErr: "the operator creates the CA"},
+		{name: "Auto-generated certificates in a provider", tls: mdbv1.TLS{Enabled: true, AutoGenerate: true, CertificateKeySecretProvider: "vault"}, expectedErr: "can't be combined with certificateKeySecretProvider"},
+		{name: "cert-manager certificates", tls: mdbv1.TLS{Enabled: true, CertManager: &mdbv1.CertManager{IssuerRef: mdbv1.CertManagerIssuerReference{Name: "ca-issuer"}}}},
+		{name: "cert-manager certificates without an issuer", tls: mdbv1.TLS{Enabled: true, CertManager: &mdbv1.CertManager{}}, expectedErr: "issuerRef.name must be set"},
+		{name: "cert-manager and auto-generated certificates", tls: mdbv1.TLS{Enabled: true, AutoGenerate: true, CertManager: &mdbv1.CertManager{IssuerRef: mdbv1.CertManagerIssuerReference{Name: "ca-issuer"}}}, expectedErr: "can't be combined with autoGenerate"},
+		{name: "cert-manager certificates with a CA", tls: mdbv1.TLS{Enabled: true, CaConfigMap: &corev1.LocalObjectReference{Name: "ca"}, CertManager: &mdbv1.CertManager{IssuerRef: mdbv1.CertManagerIssuerReference{Name: "ca-issuer"}}}, expectedErr: "the CA is read from the secrets of the certificates"},
+		{name: "CA distributed to all namespaces", tls: mdbv1.TLS{Enabled: true, CaConfigMap: &corev1.LocalObjectReference{Name: "ca"}, DistributeCA: &mdbv1.DistributeCA{}}},
+		{name: "CA distributed with an invalid selector", tls: mdbv1.TLS{Enabled: true, CaConfigMap: &corev1.LocalObjectReference{Name: "ca"}, DistributeCA: &mdbv1.DistributeCA{NamespaceSelector: metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: metav1.LabelSelectorOpIn}},
+		}}}, expectedErr: "distributeCA.namespaceSelector is invalid"},
+		{name: "CA distributed with an invalid name", tls: mdbv1.TLS{Enabled: true, CaConfigMap: &corev1.LocalObjectReference{Name: "ca"}, DistributeCA: &mdbv1.DistributeCA{Name: "My_CA"}}, expectedErr: "distributeCA.name My_CA is invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdb := mdbv1.MongoDBCommunity{}
+			mdb.Name = "my-rs"
+			mdb.Spec.Members = 2
+			mdb.Spec.Arbiters = 1
+			mdb.Spec.Security.TLS = tt.tls
+			err := validateTLS(mdb)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestValidateTLS_ClientCertificatesWithInitScripts(t *testing.T) {
+	mdb := mdbv1.MongoDBCommunity{}
+	mdb.Spec.Security.TLS = mdbv1.TLS{Enabled: true, RequireClientCertificates: true}
+	mdb.Spec.InitScripts = []mdbv1.InitScript{{Name: "seed"}}
+	assert.ErrorContains(t, validateTLS(mdb), "spec.initScripts can't be used")
+
+	mdb.Spec.Security.TLS.RequireClientCertificates = false
+	assert.NoError(t, validateTLS(mdb))
+}
+
+func TestValidateTLS_ClusterAuthMode(t *testing.T) {
+	mdb := mdbv1.MongoDBCommunity{}
+	mdb.Spec.Security.Authentication.ClusterAuthMode = mdbv1.ClusterAuthModeX509
+	assert.ErrorContains(t, validateTLS(mdb), "clusterAuthMode x509 requires spec.security.tls.enabled")
+
+	mdb.Spec.Security.TLS.Enabled = true
+	assert.NoError(t, validateTLS(mdb))
+
+	mdb.Spec.Security.TLS.Enabled = false
+	mdb.Spec.Security.Authentication.ClusterAuthMode = mdbv1.ClusterAuthModeKeyFile
+	assert.NoError(t, validateTLS(mdb))
+}
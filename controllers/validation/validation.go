@@ -1,8 +1,8 @@
 package validation
 
 import (
-	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"go.uber.org/zap"
@@ -14,13 +14,19 @@ import (
 
 // ValidateInitialSpec checks if the resource's initial Spec is valid.
 func ValidateInitialSpec(mdb mdbv1.MongoDBCommunity, log *zap.SugaredLogger) error {
+	if err := validateCustomRoles(mdb, nil, log); err != nil {
+		return err
+	}
 	return validateSpec(mdb, log)
 }
 
 // ValidateUpdate validates that the new Spec, corresponding to the existing one, is still valid.
 func ValidateUpdate(mdb mdbv1.MongoDBCommunity, oldSpec mdbv1.MongoDBCommunitySpec, log *zap.SugaredLogger) error {
-	if oldSpec.Security.TLS.Enabled && !mdb.Spec.Security.TLS.Enabled {
-		return errors.New("TLS can't be set to disabled after it has been enabled")
+	if err := validateEncryptionAtRestUpdate(mdb, oldSpec); err != nil {
+		return err
+	}
+	if err := validateCustomRoles(mdb, oldSpec.Security.Roles, log); err != nil {
+		return err
 	}
 	return validateSpec(mdb, log)
 }
@@ -31,10 +37,46 @@ func validateSpec(mdb mdbv1.MongoDBCommunity, log *zap.SugaredLogger) error {
 		return err
 	}
 
+	if err := validateDatabases(mdb); err != nil {
+		return err
+	}
+
+	if err := validateInitScripts(mdb); err != nil {
+		return err
+	}
+
 	if err := validateArbiterSpec(mdb); err != nil {
 		return err
 	}
 
+	if err := validateLdap(mdb); err != nil {
+		return err
+	}
+
+	if err := validateOidc(mdb); err != nil {
+		return err
+	}
+
+	if err := validateSecretProviders(mdb); err != nil {
+		return err
+	}
+
+	if err := validateTLS(mdb); err != nil {
+		return err
+	}
+
+	if err := validateEncryptionAtRest(mdb); err != nil {
+		return err
+	}
+
+	if err := validateAuditLog(mdb); err != nil {
+		return err
+	}
+
+	if err := validateProbes(mdb); err != nil {
+		return err
+	}
+
 	if err := validateAuthModeSpec(mdb, log); err != nil {
 		return err
 	}
@@ -67,7 +109,7 @@ func validateUsers(mdb mdbv1.MongoDBCommunity) error {
 		expectedAuthMethods[mdbv1.ConvertAuthModeToAuthMechanism(auth)] = struct{}{}
 	}
 
-	for _, user := range mdb.GetAuthUsers() {
+	for i, user := range mdb.GetAuthUsers() {
 
 		// Ensure no collisions in the connection string secret names
 		connectionStringSecretName := user.ConnectionStringSecretName
@@ -96,17 +138,23 @@ func validateUsers(mdb mdbv1.MongoDBCommunity) error {
 		}
 
 		if user.Database == constants.ExternalDB {
-			if _, ok := expectedAuthMethods[constants.X509]; !ok {
-				return fmt.Errorf("X.509 user %s present but X.509 is not enabled", user.Username)
+			_, x509 := expectedAuthMethods[constants.X509]
+			_, ldap := expectedAuthMethods[constants.Ldap]
+			_, oidc := expectedAuthMethods[constants.Oidc]
+			if !x509 && !ldap && !oidc {
+				return fmt.Errorf("$external user %s present but none of X.509, LDAP and OIDC is enabled", user.Username)
 			}
-			if user.PasswordSecretKey != "" {
-				return fmt.Errorf("X509 user %s should not have a password secret key", user.Username)
+			// The password and SCRAM credentials of $external users are not part of their auth user, so they
+			// are checked on the spec.
+			specUser := mdb.Spec.Users[i]
+			if specUser.PasswordSecretRef.Key != "" {
+				return fmt.Errorf("$external user %s should not have a password secret key", user.Username)
 			}
-			if user.PasswordSecretName != "" {
-				return fmt.Errorf("X509 user %s should not have a password secret name", user.Username)
+			if specUser.PasswordSecretRef.Name != "" {
+				return fmt.Errorf("$external user %s should not have a password secret name", user.Username)
 			}
-			if user.ScramCredentialsSecretName != "" {
-				return fmt.Errorf("X509 user %s should not have scram credentials secret name", user.Username)
+			if specUser.ScramCredentialsSecretName != "" {
+				return fmt.Errorf("$external user %s should not have scram credentials secret name", user.Username)
 			}
 		} else {
 			_, sha1 := expectedAuthMethods[constants.Sha1]
@@ -138,6 +186,40 @@ func validateUsers(mdb mdbv1.MongoDBCommunity) error {
 	return nil
 }
 
+// validateCustomRoles checks that the custom roles are valid and unique. Their inheritance and the roles granted
+// to the users are validated during the reconciliation, as they can also reference MongoDBCommunityRole resources.
+// Only the roles which are not part of the given previous roles are validated, so that roles which were accepted by
+// a previous version of the operator are not rejected by an upgrade.
+func validateCustomRoles(mdb mdbv1.MongoDBCommunity, previousRoles []mdbv1.CustomRole, log *zap.SugaredLogger) error {
+	seen := map[mdbv1.Role]bool{}
+	for _, role := range mdb.Spec.Security.Roles {
+		key := mdbv1.Role{Name: role.Role, DB: role.DB}
+		unchanged := containsCustomRole(previousRoles, role)
+		if !unchanged {
+			if err := ValidateCustomRole(role); err != nil {
+				return err
+			}
+			if unknown := UnknownActions(role); len(unknown) > 0 {
+				log.Warnf("Custom role %s@%s has actions unknown to the operator, MongoDB may reject them: %s", role.Role, role.DB, strings.Join(unknown, ", "))
+			}
+			if seen[key] {
+				return fmt.Errorf("custom role %s@%s is defined more than once", role.Role, role.DB)
+			}
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func containsCustomRole(roles []mdbv1.CustomRole, role mdbv1.CustomRole) bool {
+	for i := range roles {
+		if reflect.DeepEqual(roles[i], role) {
+			return true
+		}
+	}
+	return false
+}
+
 // validateArbiterSpec checks if the initial Member spec is valid.
 func validateArbiterSpec(mdb mdbv1.MongoDBCommunity) error {
 	if mdb.Spec.Arbiters < 0 {
@@ -187,11 +269,10 @@ func validateAuthModeSpec(mdb mdbv1.MongoDBCommunity, log *zap.SugaredLogger) er
 }
 
 func validateAgentCertSecret(mdb mdbv1.MongoDBCommunity, log *zap.SugaredLogger) error {
-	agentMode := mdb.Spec.GetAgentAuthMode()
-	if agentMode != "X509" &&
+	if !mdb.Spec.IsAgentCertificateRequired() &&
 		mdb.Spec.Security.Authentication.AgentCertificateSecret != nil &&
 		mdb.Spec.Security.Authentication.AgentCertificateSecret.Name != "" {
-		log.Warnf("Agent authentication is not X.509, but the agent certificate secret is configured, it will be ignored")
+		log.Warnf("Agent authentication is not X.509 and client certificates are not required, but the agent certificate secret is configured, it will be ignored")
 	}
 	return nil
 }
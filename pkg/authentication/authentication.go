@@ -8,6 +8,8 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/ldap"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/oidc"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/scram"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/x509"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
@@ -30,6 +32,14 @@ func Enable(ctx context.Context, auth *automationconfig.Auth, secretGetUpdateCre
 			if err := x509.Enable(ctx, auth, secretGetUpdateCreateDeleter, mdb, agentCertSecret); err != nil {
 				return fmt.Errorf("could not configure x509 authentication: %s", err)
 			}
+		case constants.Ldap:
+			if err := ldap.Enable(auth, mdb); err != nil {
+				return fmt.Errorf("could not configure ldap authentication: %s", err)
+			}
+		case constants.Oidc:
+			if err := oidc.Enable(auth, mdb); err != nil {
+				return fmt.Errorf("could not configure oidc authentication: %s", err)
+			}
 		}
 	}
 	return nil
@@ -41,6 +51,35 @@ func AddRemovedUsers(auth *automationconfig.Auth, mdb mdbv1.MongoDBCommunity, la
 	auth.UsersDeleted = append(auth.UsersDeleted, deletedUsers...)
 }
 
+// AddRemovedUserResources deletes the users of the MongoDBCommunityUser resources which no longer reference the
+// resource, unless a user with the same name is still defined.
+func AddRemovedUserResources(auth *automationconfig.Auth, mdb mdbv1.MongoDBCommunity, userResources []mdbv1.UserResourceReference) {
+	defined := map[string]bool{}
+	for _, user := range mdb.GetAuthUsers() {
+		defined[user.Database+"/"+user.Username] = true
+	}
+	for _, userResource := range userResources {
+		defined[userResource.DB+"/"+userResource.Username] = true
+	}
+
+	for _, userResource := range mdb.Status.UserResources {
+		if userResource.DB == constants.ExternalDB || defined[userResource.DB+"/"+userResource.Username] {
+			continue
+		}
+		auth.UsersDeleted = append(auth.UsersDeleted, automationconfig.DeletedUser{User: userResource.Username, Dbs: []string{userResource.DB}})
+	}
+}
+
+// AddRetiredShadowUsers deletes the shadow users of the completed password rotations. Completed rotations are only kept
+// in the status until the deployment deleting their shadow users is ready, so each deletion is only sent once.
+func AddRetiredShadowUsers(auth *automationconfig.Auth, mdb mdbv1.MongoDBCommunity) {
+	for _, rotation := range mdb.Status.UserPasswordRotations {
+		if rotation.Phase == mdbv1.PasswordRotationCompleted {
+			auth.UsersDeleted = append(auth.UsersDeleted, automationconfig.DeletedUser{User: rotation.ShadowUsername, Dbs: []string{rotation.DB}})
+		}
+	}
+}
+
 func getRemovedUsersFromSpec(currentMDB mdbv1.MongoDBCommunitySpec, lastAppliedMDBSpec *mdbv1.MongoDBCommunitySpec) []automationconfig.DeletedUser {
 	type user struct {
 		db   string
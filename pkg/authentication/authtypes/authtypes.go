@@ -60,6 +60,10 @@ type User struct {
 	// PasswordSecretName is the name of the secret which stores this user's password.
 	PasswordSecretName string
 
+	// PasswordSecretNamespace is the namespace of the secret which stores this user's password.
+	// Defaults to the namespace of the resource being configured.
+	PasswordSecretNamespace string
+
 	// ScramCredentialsSecretName returns the name of the secret which stores the generated credentials
 	// for this user. These credentials will be generated if they do not exist, or used if they do.
 	// Note: there will be one secret with credentials per user created.
@@ -73,16 +77,49 @@ type User struct {
 	// ConnectionStringSecretNamespace is the namespace of the secret object created by the operator which exposes the connection strings for the user.
 	ConnectionStringSecretNamespace string `json:"connectionStringSecretNamespace,omitempty"`
 
+	// PasswordRotation is set while the password of this user is being rotated.
+	PasswordRotation *PasswordRotation
+
 	// ConnectionStringOptions contains connection string options for this user
 	// These options will be appended at the end of the connection string and
 	// will override any existing options from the resources.
 	ConnectionStringOptions map[string]interface{}
 }
 
+// PasswordRotation describes an ongoing password rotation, during which the new password is granted to a shadow user.
+type PasswordRotation struct {
+	// RetainCredentials is true while the user must keep the credentials of its previous password.
+	RetainCredentials bool
+
+	// ShadowUsername is the name of the user which is granted the new password.
+	ShadowUsername string
+
+	// ShadowScramCredentialsSecretName is the name of the secret which stores the credentials of the shadow user.
+	ShadowScramCredentialsSecretName string
+}
+
+// GetLoginUsername returns the username clients should authenticate with. While the user retains its previous
+// credentials, the new password is only valid for the shadow user.
+func (u User) GetLoginUsername() string {
+	if u.PasswordRotation != nil && u.PasswordRotation.RetainCredentials {
+		return u.PasswordRotation.ShadowUsername
+	}
+	return u.Username
+}
+
+// GetPasswordSecretNamespacedName returns the NamespacedName of the secret which stores this user's password,
+// the secret is in the given namespace unless the user sets its own.
+func (u User) GetPasswordSecretNamespacedName(namespace string) types.NamespacedName {
+	if u.PasswordSecretNamespace != "" {
+		namespace = u.PasswordSecretNamespace
+	}
+	return types.NamespacedName{Name: u.PasswordSecretName, Namespace: namespace}
+}
+
 func (u User) GetLoginString(password string) string {
 	if u.Database != constants.ExternalDB {
 		return fmt.Sprintf("%s:%s@",
-			url.QueryEscape(u.Username),
+			url.QueryEscape(u.GetLoginUsername()),
 			url.QueryEscape(password))
 	}
 	return ""
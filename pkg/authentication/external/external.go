@@ -0,0 +1,61 @@
+package external
+
+import (
+	"fmt"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/contains"
+)
+
+// Enable adds the given mechanism to the deployment and the users of the $external database, whose credentials
+// are held by an external service such as the LDAP servers or the OIDC identity providers. The MongoDB Agent
+// can't authenticate with these mechanisms, so they must be enabled together with SCRAM or X.509, which
+// configure the agent. name is the name of the mechanism in the returned errors.
+func Enable(auth *automationconfig.Auth, mdb authtypes.Configurable, mechanism, name string) error {
+	opts := mdb.GetAuthOptions()
+	if opts.AutoAuthMechanism == mechanism {
+		return fmt.Errorf("the agent can't authenticate with %s", name)
+	}
+
+	if !contains.String(auth.DeploymentAuthMechanisms, mechanism) {
+		auth.DeploymentAuthMechanisms = append(auth.DeploymentAuthMechanisms, mechanism)
+	}
+
+	for _, user := range mdb.GetAuthUsers() {
+		if user.Database != constants.ExternalDB || containsUser(auth.Users, user) {
+			continue
+		}
+		auth.Users = append(auth.Users, convertMongoDBUserToAutomationConfigUser(user))
+	}
+	return nil
+}
+
+// containsUser returns true if the user has already been added, by another mechanism using the $external database.
+func containsUser(users []automationconfig.MongoDBUser, user authtypes.User) bool {
+	for _, u := range users {
+		if u.Username == user.Username && u.Database == user.Database {
+			return true
+		}
+	}
+	return false
+}
+
+// convertMongoDBUserToAutomationConfigUser converts a user of the $external database to a user that can be added
+// directly to the AutomationConfig.
+func convertMongoDBUserToAutomationConfigUser(user authtypes.User) automationconfig.MongoDBUser {
+	acUser := automationconfig.MongoDBUser{
+		Username:                   user.Username,
+		Database:                   user.Database,
+		AuthenticationRestrictions: []string{},
+		Mechanisms:                 []string{},
+	}
+	for _, role := range user.Roles {
+		acUser.Roles = append(acUser.Roles, automationconfig.Role{
+			Role:     role.Name,
+			Database: role.Database,
+		})
+	}
+	return acUser
+}
@@ -0,0 +1,78 @@
+package external
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/mocks"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+func TestEnable(t *testing.T) {
+	externalUser := authtypes.User{
+		Username: "alice",
+		Database: constants.ExternalDB,
+		Roles:    []authtypes.Role{{Name: "readWrite", Database: "app"}},
+	}
+
+	t.Run("External users are added", func(t *testing.T) {
+		auth := automationconfig.Auth{DeploymentAuthMechanisms: []string{constants.Sha256}}
+		mdb := buildConfigurable(constants.Sha256, externalUser, mocks.BuildScramMongoDBUser("my-scram-user"))
+
+		require.NoError(t, Enable(&auth, mdb, constants.Ldap, "LDAP"))
+		assert.Equal(t, []string{constants.Sha256, constants.Ldap}, auth.DeploymentAuthMechanisms)
+		assert.Equal(t, []automationconfig.MongoDBUser{{
+			Username:                   "alice",
+			Database:                   constants.ExternalDB,
+			Roles:                      []automationconfig.Role{{Role: "readWrite", Database: "app"}},
+			AuthenticationRestrictions: []string{},
+			Mechanisms:                 []string{},
+		}}, auth.Users)
+
+		t.Run("Subsequent configuration doesn't add users or mechanisms twice", func(t *testing.T) {
+			require.NoError(t, Enable(&auth, mdb, constants.Ldap, "LDAP"))
+			assert.Equal(t, []string{constants.Sha256, constants.Ldap}, auth.DeploymentAuthMechanisms)
+			assert.Len(t, auth.Users, 1)
+		})
+
+		t.Run("Users are shared with the other external mechanisms", func(t *testing.T) {
+			require.NoError(t, Enable(&auth, mdb, constants.Oidc, "OIDC"))
+			assert.Equal(t, []string{constants.Sha256, constants.Ldap, constants.Oidc}, auth.DeploymentAuthMechanisms)
+			assert.Len(t, auth.Users, 1)
+		})
+	})
+
+	t.Run("The agent can't authenticate with an external mechanism", func(t *testing.T) {
+		auth := automationconfig.Auth{}
+		assert.EqualError(t, Enable(&auth, buildConfigurable(constants.Ldap, externalUser), constants.Ldap, "LDAP"), "the agent can't authenticate with LDAP")
+		assert.EqualError(t, Enable(&auth, buildConfigurable(constants.Oidc, externalUser), constants.Oidc, "OIDC"), "the agent can't authenticate with OIDC")
+	})
+}
+
+func buildConfigurable(autoAuthMechanism string, users ...authtypes.User) mocks.MockConfigurable {
+	return mocks.NewMockConfigurable(
+		authtypes.Options{
+			AuthoritativeSet:  false,
+			KeyFile:           "/path/to/keyfile",
+			AuthMechanisms:    []string{constants.Sha256, constants.Ldap, constants.Oidc},
+			AgentName:         constants.AgentName,
+			AutoAuthMechanism: autoAuthMechanism,
+		},
+		users,
+		types.NamespacedName{
+			Name:      "mdb",
+			Namespace: "default",
+		},
+		[]metav1.OwnerReference{{
+			APIVersion: "v1",
+			Kind:       "mdbc",
+			Name:       "my-ref",
+		}},
+	)
+}
@@ -0,0 +1,14 @@
+package oidc
+
+import (
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/external"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+// Enable configures the clients to authenticate with OpenID Connect and adds the users of the $external database,
+// which are authenticated by the identity providers.
+func Enable(auth *automationconfig.Auth, mdb authtypes.Configurable) error {
+	return external.Enable(auth, mdb, constants.Oidc, "OIDC")
+}
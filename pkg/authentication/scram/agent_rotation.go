@@ -0,0 +1,88 @@
+package scram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/authtypes"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/generate"
+)
+
+// keyfileKeyPrefix is the prefix of each key of a keyfile containing multiple keys, which
+// are stored as a YAML array.
+const keyfileKeyPrefix = "- "
+
+// AddAgentKeyfileKey generates a new agent password and appends a newly generated key to the agent keyfile.
+// While the keyfile contains several keys, the processes authenticate to each other with the first key
+// and accept all of them, which allows them to be restarted with the new keyfile one at a time.
+func AddAgentKeyfileKey(ctx context.Context, secretGetUpdateCreateDeleter secret.GetUpdateCreateDeleter, mdb authtypes.Configurable) error {
+	keyfile, err := secret.ReadKey(ctx, secretGetUpdateCreateDeleter, constants.AgentKeyfileKey, mdb.GetAgentKeyfileSecretNamespacedName())
+	if err != nil {
+		return fmt.Errorf("could not read agent keyfile: %s", err)
+	}
+
+	newKey, err := generate.KeyFileContents()
+	if err != nil {
+		return fmt.Errorf("could not generate keyfile contents: %s", err)
+	}
+
+	newPassword, err := generate.RandomFixedLengthStringOfSize(20)
+	if err != nil {
+		return fmt.Errorf("could not generate password: %s", err)
+	}
+
+	// only the key currently used for authentication is kept, in case a previous rotation was interrupted.
+	keys := keyfileKeys(keyfile)
+	if err := updateSecretKey(ctx, secretGetUpdateCreateDeleter, mdb, mdb.GetAgentKeyfileSecretNamespacedName().Name, constants.AgentKeyfileKey, multiKeyKeyfile(keys[0], newKey)); err != nil {
+		return err
+	}
+	return updateSecretKey(ctx, secretGetUpdateCreateDeleter, mdb, mdb.GetAgentPasswordSecretNamespacedName().Name, constants.AgentPasswordKey, newPassword)
+}
+
+// RemovePreviousAgentKeyfileKeys removes all the keys of the agent keyfile but the most recent one.
+func RemovePreviousAgentKeyfileKeys(ctx context.Context, secretGetUpdateCreateDeleter secret.GetUpdateCreateDeleter, mdb authtypes.Configurable) error {
+	keyfile, err := secret.ReadKey(ctx, secretGetUpdateCreateDeleter, constants.AgentKeyfileKey, mdb.GetAgentKeyfileSecretNamespacedName())
+	if err != nil {
+		return fmt.Errorf("could not read agent keyfile: %s", err)
+	}
+
+	keys := keyfileKeys(keyfile)
+	return updateSecretKey(ctx, secretGetUpdateCreateDeleter, mdb, mdb.GetAgentKeyfileSecretNamespacedName().Name, constants.AgentKeyfileKey, keys[len(keys)-1])
+}
+
+func updateSecretKey(ctx context.Context, secretGetUpdateCreateDeleter secret.GetUpdateCreateDeleter, mdb authtypes.Configurable, name, key, value string) error {
+	s := secret.Builder().
+		SetName(name).
+		SetNamespace(mdb.NamespacedName().Namespace).
+		SetField(key, value).
+		SetOwnerReferences(mdb.GetOwnerReferences()).
+		Build()
+	return secret.CreateOrUpdate(ctx, secretGetUpdateCreateDeleter, s)
+}
+
+// keyfileKeys returns the keys of the given keyfile contents.
+func keyfileKeys(keyfile string) []string {
+	if !strings.HasPrefix(keyfile, keyfileKeyPrefix) {
+		return []string{keyfile}
+	}
+
+	var keys []string
+	for _, line := range strings.Split(keyfile, "\n") {
+		if key := strings.TrimSpace(strings.TrimPrefix(line, keyfileKeyPrefix)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// multiKeyKeyfile returns the contents of a keyfile containing all the given keys.
+func multiKeyKeyfile(keys ...string) string {
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = keyfileKeyPrefix + key
+	}
+	return strings.Join(lines, "\n")
+}
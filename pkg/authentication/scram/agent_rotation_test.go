@@ -0,0 +1,98 @@
+package scram
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/mocks"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/kube/secret"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+)
+
+func TestAgentKeyfileRotation(t *testing.T) {
+	ctx := context.Background()
+	mdb := buildConfigurable("mdb-0")
+	s := mocks.NewMockedSecretGetUpdateCreateDeleter()
+
+	auth := automationconfig.Auth{}
+	require.NoError(t, Enable(ctx, &auth, s, mdb))
+	previousKey, previousPassword := auth.Key, auth.AutoPwd
+
+	t.Run("The new key is added after the key in use", func(t *testing.T) {
+		require.NoError(t, AddAgentKeyfileKey(ctx, s, mdb))
+
+		auth := automationconfig.Auth{}
+		require.NoError(t, Enable(ctx, &auth, s, mdb))
+		keys := keyfileKeys(auth.Key)
+		require.Len(t, keys, 2)
+		assert.Equal(t, previousKey, keys[0])
+		assert.NotEqual(t, previousKey, keys[1])
+		assert.NotEqual(t, previousPassword, auth.AutoPwd)
+	})
+
+	t.Run("Adding a key again replaces the key being added", func(t *testing.T) {
+		require.NoError(t, AddAgentKeyfileKey(ctx, s, mdb))
+
+		keyfile, err := secret.ReadKey(ctx, s, constants.AgentKeyfileKey, mdb.GetAgentKeyfileSecretNamespacedName())
+		require.NoError(t, err)
+		keys := keyfileKeys(keyfile)
+		require.Len(t, keys, 2)
+		assert.Equal(t, previousKey, keys[0])
+	})
+
+	t.Run("The previous key is removed", func(t *testing.T) {
+		keyfile, err := secret.ReadKey(ctx, s, constants.AgentKeyfileKey, mdb.GetAgentKeyfileSecretNamespacedName())
+		require.NoError(t, err)
+		newKey := keyfileKeys(keyfile)[1]
+
+		require.NoError(t, RemovePreviousAgentKeyfileKeys(ctx, s, mdb))
+
+		auth := automationconfig.Auth{}
+		require.NoError(t, Enable(ctx, &auth, s, mdb))
+		assert.Equal(t, newKey, auth.Key)
+	})
+}
+
+// TestAgentKeyfileRotation_KeyfileContents pins the keyfile the agent writes for the processes while a rotation is in
+// progress: auth.key is written verbatim, and mongod reads a keyfile starting with "- " as a YAML array of keys.
+func TestAgentKeyfileRotation_KeyfileContents(t *testing.T) {
+	ctx := context.Background()
+	mdb := buildConfigurable("mdb-0")
+	s := mocks.NewMockedSecretGetUpdateCreateDeleter()
+	keyfileSecret := secret.Builder().
+		SetName(mdb.GetAgentKeyfileSecretNamespacedName().Name).
+		SetNamespace(mdb.GetAgentKeyfileSecretNamespacedName().Namespace).
+		SetField(constants.AgentKeyfileKey, "previousKey").
+		Build()
+	require.NoError(t, s.CreateSecret(ctx, keyfileSecret))
+
+	require.NoError(t, AddAgentKeyfileKey(ctx, s, mdb))
+	auth := automationconfig.Auth{}
+	require.NoError(t, Enable(ctx, &auth, s, mdb))
+
+	require.True(t, strings.HasPrefix(auth.Key, "- previousKey\n- "))
+	newKey := strings.TrimPrefix(auth.Key, "- previousKey\n- ")
+	assert.NotContains(t, newKey, "\n")
+	assert.Equal(t, "- previousKey\n- "+newKey, auth.Key)
+
+	var keys []string
+	require.NoError(t, yaml.Unmarshal([]byte(auth.Key), &keys))
+	assert.Equal(t, []string{"previousKey", newKey}, keys)
+
+	require.NoError(t, RemovePreviousAgentKeyfileKeys(ctx, s, mdb))
+	auth = automationconfig.Auth{}
+	require.NoError(t, Enable(ctx, &auth, s, mdb))
+	assert.Equal(t, newKey, auth.Key)
+}
+
+func TestKeyfileKeys(t *testing.T) {
+	assert.Equal(t, []string{"abc"}, keyfileKeys("abc"))
+	assert.Equal(t, []string{"abc", "def"}, keyfileKeys(multiKeyKeyfile("abc", "def")))
+	assert.Equal(t, "- abc\n- def", multiKeyKeyfile("abc", "def"))
+}
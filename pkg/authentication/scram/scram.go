@@ -79,7 +79,7 @@ func ensureAgent(ctx context.Context, auth *automationconfig.Auth, secretGetUpda
 // secret corresponding to user of the given MongoDB deployment.
 func ensureScramCredentials(ctx context.Context, getUpdateCreator secret.GetUpdateCreator, user authtypes.User, mdbNamespacedName types.NamespacedName, ownerRef []metav1.OwnerReference) (scramcredentials.ScramCreds, scramcredentials.ScramCreds, error) {
 
-	password, err := secret.ReadKey(ctx, getUpdateCreator, user.PasswordSecretKey, types.NamespacedName{Name: user.PasswordSecretName, Namespace: mdbNamespacedName.Namespace})
+	password, err := secret.ReadKey(ctx, getUpdateCreator, user.PasswordSecretKey, user.GetPasswordSecretNamespacedName(mdbNamespacedName.Namespace))
 	if err != nil {
 		// if the password is deleted, that's fine we can read from the stored credentials that were previously generated
 		if secret.SecretNotExist(err) {
@@ -249,11 +249,47 @@ func convertMongoDBResourceUsersToAutomationConfigUsers(ctx context.Context, sec
 				return nil, fmt.Errorf("failed to convert scram user %s to Automation Config user: %s", u.Username, err)
 			}
 			usersWanted = append(usersWanted, acUser)
+
+			if u.PasswordRotation != nil {
+				shadowUser, err := convertMongoDBUserToAutomationConfigUser(ctx, secretGetUpdateCreateDeleter, mdb.NamespacedName(), mdb.GetOwnerReferences(), shadowUserOf(u))
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert shadow user of scram user %s to Automation Config user: %s", u.Username, err)
+				}
+				usersWanted = append(usersWanted, shadowUser)
+			}
 		}
 	}
 	return usersWanted, nil
 }
 
+// shadowUserOf returns the user which is granted the new password of the given user while its password is rotated.
+func shadowUserOf(user authtypes.User) authtypes.User {
+	shadowUser := user
+	shadowUser.Username = user.PasswordRotation.ShadowUsername
+	shadowUser.ScramCredentialsSecretName = user.PasswordRotation.ShadowScramCredentialsSecretName
+	shadowUser.PasswordRotation = nil
+	return shadowUser
+}
+
+// PasswordChanged returns true if the password of the given user differs from the password its existing
+// credentials were generated from. It returns false if the password or the credentials do not exist yet.
+func PasswordChanged(ctx context.Context, secretGetter secret.Getter, user authtypes.User, mdbNamespacedName types.NamespacedName) (bool, error) {
+	password, err := secret.ReadKey(ctx, secretGetter, user.PasswordSecretKey, user.GetPasswordSecretNamespacedName(mdbNamespacedName.Namespace))
+	if err != nil {
+		if secret.SecretNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	exists, err := secret.Exists(ctx, secretGetter, types.NamespacedName{Name: user.ScramCredentialsSecretName, Namespace: mdbNamespacedName.Namespace})
+	if err != nil || !exists {
+		return false, err
+	}
+
+	return needToGenerateNewCredentials(ctx, secretGetter, user.Username, user.ScramCredentialsSecretName, mdbNamespacedName, password)
+}
+
 // convertMongoDBUserToAutomationConfigUser converts a single user configured in the MongoDB resource and converts it to a user
 // that can be added directly to the AutomationConfig.
 func convertMongoDBUserToAutomationConfigUser(ctx context.Context, secretGetUpdateCreateDeleter secret.GetUpdateCreateDeleter, mdbNsName types.NamespacedName, ownerRef []metav1.OwnerReference, user authtypes.User) (automationconfig.MongoDBUser, error) {
@@ -267,7 +303,14 @@ func convertMongoDBUserToAutomationConfigUser(ctx context.Context, secretGetUpda
 			Database: role.Database,
 		})
 	}
-	sha1Creds, sha256Creds, err := ensureScramCredentials(ctx, secretGetUpdateCreateDeleter, user, mdbNsName, ownerRef)
+	var sha1Creds, sha256Creds scramcredentials.ScramCreds
+	var err error
+	if user.PasswordRotation != nil && user.PasswordRotation.RetainCredentials {
+		// the previous password stays valid until the rotation moves on, the new one is granted to the shadow user.
+		sha1Creds, sha256Creds, err = readExistingCredentials(ctx, secretGetUpdateCreateDeleter, mdbNsName, user.ScramCredentialsSecretName)
+	} else {
+		sha1Creds, sha256Creds, err = ensureScramCredentials(ctx, secretGetUpdateCreateDeleter, user, mdbNsName, ownerRef)
+	}
 	if err != nil {
 		return automationconfig.MongoDBUser{}, fmt.Errorf("could not ensure scram credentials: %s", err)
 	}
@@ -155,6 +155,75 @@ func TestConvertMongoDBUserToAutomationConfigUser(t *testing.T) {
 	})
 }
 
+func TestConvertMongoDBResourceUsersToAutomationConfigUsers_WithPasswordRotation(t *testing.T) {
+	ctx := context.Background()
+	user := mocks.BuildScramMongoDBUser("mdb-0")
+	user.PasswordRotation = &authtypes.PasswordRotation{
+		RetainCredentials:                true,
+		ShadowUsername:                   "mdb-0-rotating",
+		ShadowScramCredentialsSecretName: "mdb-0-scram-rotating",
+	}
+	mdb := buildConfigurable("mdb-0", user)
+
+	passwordSecret := secret.Builder().
+		SetName(user.PasswordSecretName).
+		SetNamespace(mdb.NamespacedName().Namespace).
+		SetField(user.PasswordSecretKey, "my-new-password").
+		Build()
+	scramCredentialsSecret := validScramCredentialsSecret(mdb.NamespacedName(), user.ScramCredentialsSecretName)
+	s := mocks.NewMockedSecretGetUpdateCreateDeleter(passwordSecret, scramCredentialsSecret)
+
+	t.Run("The user keeps its credentials while the shadow user is granted the new password", func(t *testing.T) {
+		acUsers, err := convertMongoDBResourceUsersToAutomationConfigUsers(ctx, s, mdb)
+		assert.NoError(t, err)
+		assert.Len(t, acUsers, 2)
+
+		assert.Equal(t, "mdb-0", acUsers[0].Username)
+		assert.Equal(t, testSha256StoredKey, acUsers[0].ScramSha256Creds.StoredKey)
+
+		assert.Equal(t, "mdb-0-rotating", acUsers[1].Username)
+		assert.Equal(t, acUsers[0].Roles, acUsers[1].Roles)
+		assert.NotEqual(t, testSha256StoredKey, acUsers[1].ScramSha256Creds.StoredKey)
+
+		_, err = s.GetSecret(ctx, types.NamespacedName{Name: "mdb-0-scram-rotating", Namespace: mdb.NamespacedName().Namespace})
+		assert.NoError(t, err)
+	})
+
+	t.Run("The password change is detected", func(t *testing.T) {
+		changed, err := PasswordChanged(ctx, s, user, mdb.NamespacedName())
+		assert.NoError(t, err)
+		assert.True(t, changed)
+	})
+
+	t.Run("The user is granted the new password once it does not retain its credentials", func(t *testing.T) {
+		user.PasswordRotation.RetainCredentials = false
+		mdb := buildConfigurable("mdb-0", user)
+
+		acUsers, err := convertMongoDBResourceUsersToAutomationConfigUsers(ctx, s, mdb)
+		assert.NoError(t, err)
+		assert.Len(t, acUsers, 2)
+		assert.NotEqual(t, testSha256StoredKey, acUsers[0].ScramSha256Creds.StoredKey)
+
+		changed, err := PasswordChanged(ctx, s, user, mdb.NamespacedName())
+		assert.NoError(t, err)
+		assert.False(t, changed)
+	})
+}
+
+func TestPasswordChanged_IsFalseWithoutExistingCredentials(t *testing.T) {
+	ctx := context.Background()
+	mdb, user := buildConfigurableAndUser("mdb-0")
+	passwordSecret := secret.Builder().
+		SetName(user.PasswordSecretName).
+		SetNamespace(mdb.NamespacedName().Namespace).
+		SetField(user.PasswordSecretKey, "my-password").
+		Build()
+
+	changed, err := PasswordChanged(ctx, mocks.NewMockedSecretGetUpdateCreateDeleter(passwordSecret), user, mdb.NamespacedName())
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
 func TestConfigureScram(t *testing.T) {
 	ctx := context.Background()
 	t.Run("Should fail if there is no password present for the user", func(t *testing.T) {
@@ -0,0 +1,297 @@
+package certificates
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// KeyPair is a PEM encoded certificate and its private key.
+type KeyPair struct {
+	Certificate string
+	Key         string
+}
+
+// Request describes a certificate to issue.
+type Request struct {
+	// Subject is the distinguished name of the certificate, in the RFC 2253 format MongoDB uses for X.509 usernames,
+	// such as "CN=app,OU=billing,O=example".
+	Subject string
+	// DNSNames are the subject alternative names of the certificate.
+	DNSNames []string
+	// ExtKeyUsage are the uses of the certificate, such as server or client authentication.
+	ExtKeyUsage []x509.ExtKeyUsage
+	// Validity is how long the certificate is valid for.
+	Validity time.Duration
+}
+
+// NewCA generates a self-signed certificate authority with the given subject.
+func NewCA(subject string, validity time.Duration) (KeyPair, error) {
+	rawSubject, err := marshalSubject(subject)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	template, err := newTemplate(rawSubject, validity)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	template.IsCA = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	template.BasicConstraintsValid = true
+
+	return createKeyPair(template, template, key, key)
+}
+
+// Issue issues a certificate signed by the given certificate authority.
+func Issue(ca KeyPair, request Request) (KeyPair, error) {
+	caCert, caKey, err := parseKeyPair(ca)
+	if err != nil {
+		return KeyPair{}, fmt.Errorf("could not parse the CA: %s", err)
+	}
+	rawSubject, err := marshalSubject(request.Subject)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	template, err := newTemplate(rawSubject, request.Validity)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	template.DNSNames = request.DNSNames
+	template.ExtKeyUsage = request.ExtKeyUsage
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.BasicConstraintsValid = true
+
+	return createKeyPair(template, caCert, key, caKey)
+}
+
+// NeedsRenewal returns true if the certificate can't be used as issued for the request: it is invalid, it was not
+// signed by the given CA, it doesn't match the subject of the request or cover all its DNS names, or less than a third
+// of its validity remains. A certificate covering more DNS names than requested isn't renewed, so that removing a
+// host doesn't restart the processes.
+func NeedsRenewal(certificate KeyPair, ca KeyPair, request Request, now time.Time) bool {
+	cert, _, err := parseKeyPair(certificate)
+	if err != nil {
+		return true
+	}
+	caCert, err := ParseCertificate(ca.Certificate)
+	if err != nil || cert.CheckSignatureFrom(caCert) != nil {
+		return true
+	}
+	if rawSubject, err := marshalSubject(request.Subject); err != nil || !bytes.Equal(rawSubject, cert.RawSubject) {
+		return true
+	}
+	if !coversNames(cert.DNSNames, request.DNSNames) {
+		return true
+	}
+	return !now.Before(RenewalTime(cert))
+}
+
+// RenewalTime returns the time after which a certificate is renewed, once two thirds of its validity have elapsed.
+func RenewalTime(cert *x509.Certificate) time.Time {
+	validity := cert.NotAfter.Sub(cert.NotBefore)
+	return cert.NotBefore.Add(validity * 2 / 3)
+}
+
+// ParseCertificate parses the first certificate of the given PEM.
+func ParseCertificate(certificate string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// ParseCertificates parses all the certificates of the given PEM, in their order, skipping the other blocks such as
+// private keys.
+func ParseCertificates(certificates string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(certificates)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return certs, nil
+}
+
+func parseKeyPair(keyPair KeyPair) (*x509.Certificate, crypto.Signer, error) {
+	cert, err := ParseCertificate(keyPair.Certificate)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode([]byte(keyPair.Key))
+	if block == nil {
+		return nil, nil, errors.New("no PEM encoded private key found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, errors.New("the private key can't sign certificates")
+	}
+	return cert, signer, nil
+}
+
+func newTemplate(rawSubject []byte, validity time.Duration) (*x509.Certificate, error) {
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return nil, err
+	}
+	// the certificate is valid a bit before it is issued, to tolerate clock skew between the operator and the members.
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		RawSubject:   rawSubject,
+		NotBefore:    now.Add(-5 * time.Minute),
+		NotAfter:     now.Add(validity),
+	}, nil
+}
+
+func createKeyPair(template, parent *x509.Certificate, key *ecdsa.PrivateKey, signer crypto.Signer) (KeyPair, error) {
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	return KeyPair{
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes})),
+		Key:         string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})),
+	}, nil
+}
+
+// attributeTypes are the attributes which can be used in the subjects of the certificates.
+var attributeTypes = map[string]asn1.ObjectIdentifier{
+	"CN":           {2, 5, 4, 3},
+	"SERIALNUMBER": {2, 5, 4, 5},
+	"C":            {2, 5, 4, 6},
+	"L":            {2, 5, 4, 7},
+	"ST":           {2, 5, 4, 8},
+	"STREET":       {2, 5, 4, 9},
+	"O":            {2, 5, 4, 10},
+	"OU":           {2, 5, 4, 11},
+	"DC":           {0, 9, 2342, 19200300, 100, 1, 25},
+	"UID":          {0, 9, 2342, 19200300, 100, 1, 1},
+}
+
+// ParseSubject parses a distinguished name in the RFC 2253 format, where the most specific attribute comes first.
+func ParseSubject(subject string) (pkix.RDNSequence, error) {
+	parts, err := splitUnescaped(subject, ',')
+	if err != nil {
+		return nil, err
+	}
+	rdns := make(pkix.RDNSequence, 0, len(parts))
+	// the attributes are encoded in the reverse order of the string representation.
+	for i := len(parts) - 1; i >= 0; i-- {
+		attribute := strings.TrimSpace(parts[i])
+		name, value, found := strings.Cut(attribute, "=")
+		if !found || value == "" {
+			return nil, fmt.Errorf("invalid attribute %q in subject %q", attribute, subject)
+		}
+		oid, ok := attributeTypes[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unsupported attribute %s in subject %q", name, subject)
+		}
+		rdns = append(rdns, pkix.RelativeDistinguishedNameSET{{Type: oid, Value: unescape(strings.TrimSpace(value))}})
+	}
+	return rdns, nil
+}
+
+func marshalSubject(subject string) ([]byte, error) {
+	rdns, err := ParseSubject(subject)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(rdns)
+}
+
+// splitUnescaped splits the string on the separators which are not escaped with a backslash.
+func splitUnescaped(s string, separator rune) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, errors.New("the subject is empty")
+	}
+	var parts []string
+	var current strings.Builder
+	escaped := false
+	for _, c := range s {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == separator:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(c)
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("subject %q ends with an escape character", s)
+	}
+	return append(parts, current.String()), nil
+}
+
+func unescape(value string) string {
+	var unescaped strings.Builder
+	escaped := false
+	for _, c := range value {
+		if c == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		unescaped.WriteRune(c)
+		escaped = false
+	}
+	return unescaped.String()
+}
+
+// coversNames returns true if all the requested names are part of the names of the certificate.
+func coversNames(names, requested []string) bool {
+	for _, name := range requested {
+		if !slices.Contains(names, name) {
+			return false
+		}
+	}
+	return true
+}
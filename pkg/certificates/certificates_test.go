@@ -0,0 +1,113 @@
+package certificates
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssue(t *testing.T) {
+	ca, err := NewCA("CN=my-rs-ca,O=MongoDB", 24*time.Hour)
+	require.NoError(t, err)
+	request := Request{
+		Subject:     "CN=my-rs,O=MongoDB",
+		DNSNames:    []string{"my-rs-0.my-rs-svc.my-ns.svc.cluster.local", "my-rs-svc.my-ns.svc.cluster.local"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		Validity:    time.Hour,
+	}
+	certificate, err := Issue(ca, request)
+	require.NoError(t, err)
+
+	t.Run("The certificate is signed by the CA", func(t *testing.T) {
+		caCert, err := ParseCertificate(ca.Certificate)
+		require.NoError(t, err)
+		assert.True(t, caCert.IsCA)
+		cert, err := ParseCertificate(certificate.Certificate)
+		require.NoError(t, err)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(caCert)
+		_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: "my-rs-svc.my-ns.svc.cluster.local", KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("The certificate has the requested subject", func(t *testing.T) {
+		cert, err := ParseCertificate(certificate.Certificate)
+		require.NoError(t, err)
+		var rdns pkix.RDNSequence
+		_, err = asn1.Unmarshal(cert.RawSubject, &rdns)
+		require.NoError(t, err)
+		assert.Equal(t, "CN=my-rs,O=MongoDB", rdns.String())
+	})
+
+	t.Run("The certificate is renewed when it doesn't match the request anymore", func(t *testing.T) {
+		now := time.Now()
+		assert.False(t, NeedsRenewal(certificate, ca, request, now))
+		assert.True(t, NeedsRenewal(certificate, ca, request, now.Add(45*time.Minute)), "less than a third of the validity remains")
+
+		moreHosts := request
+		moreHosts.DNSNames = append(moreHosts.DNSNames, "my-rs-1.my-rs-svc.my-ns.svc.cluster.local")
+		assert.True(t, NeedsRenewal(certificate, ca, moreHosts, now))
+
+		lessHosts := request
+		lessHosts.DNSNames = request.DNSNames[:1]
+		assert.False(t, NeedsRenewal(certificate, ca, lessHosts, now), "removing a host doesn't renew the certificate")
+
+		otherSubject := request
+		otherSubject.Subject = "CN=other,O=MongoDB"
+		assert.True(t, NeedsRenewal(certificate, ca, otherSubject, now))
+
+		otherCA, err := NewCA("CN=my-rs-ca,O=MongoDB", 24*time.Hour)
+		require.NoError(t, err)
+		assert.True(t, NeedsRenewal(certificate, otherCA, request, now))
+
+		assert.True(t, NeedsRenewal(KeyPair{Certificate: "invalid"}, ca, request, now))
+	})
+}
+
+func TestParseCertificates(t *testing.T) {
+	ca, err := NewCA("CN=my-rs-ca,O=MongoDB", 24*time.Hour)
+	require.NoError(t, err)
+	otherCA, err := NewCA("CN=my-other-ca,O=MongoDB", 24*time.Hour)
+	require.NoError(t, err)
+
+	certs, err := ParseCertificates(ca.Key + ca.Certificate + otherCA.Certificate)
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+	assert.Equal(t, "CN=my-rs-ca,O=MongoDB", certs[0].Subject.String())
+	assert.Equal(t, "CN=my-other-ca,O=MongoDB", certs[1].Subject.String())
+
+	_, err = ParseCertificates(ca.Key)
+	assert.EqualError(t, err, "no PEM encoded certificate found")
+}
+
+func TestParseSubject(t *testing.T) {
+	tests := []struct {
+		subject     string
+		expected    string
+		expectedErr string
+	}{
+		{subject: "CN=app,OU=billing,O=example,C=US", expected: "CN=app,OU=billing,O=example,C=US"},
+		{subject: "cn=app, ou=billing", expected: "CN=app,OU=billing"},
+		{subject: `CN=Doe\, John,O=example`, expected: `CN=Doe\, John,O=example`},
+		{subject: "", expectedErr: "the subject is empty"},
+		{subject: "CN=app,billing", expectedErr: "invalid attribute"},
+		{subject: "CN=app,X=billing", expectedErr: "unsupported attribute X"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			rdns, err := ParseSubject(tt.subject)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rdns.String())
+		})
+	}
+}
@@ -8,11 +8,13 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"reflect"
 	k8sClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
 	"strings"
 )
 
@@ -172,7 +174,37 @@ func makeStatefulSetReady(set *appsv1.StatefulSet) {
 	set.Status.ReadyReplicas = *set.Spec.Replicas
 }
 
-func (m mockedClient) List(_ context.Context, _ k8sClient.ObjectList, _ ...k8sClient.ListOption) error {
+// List fills the items of the given list with the stored objects of the same type, ordered by key.
+// Only the namespace and label selector list options are supported.
+func (m mockedClient) List(_ context.Context, list k8sClient.ObjectList, opts ...k8sClient.ListOption) error {
+	listOptions := k8sClient.ListOptions{}
+	listOptions.ApplyOptions(opts)
+
+	items := reflect.ValueOf(list).Elem().FieldByName("Items")
+	if !items.IsValid() {
+		return fmt.Errorf("listing objects of type %T is not implemented", list)
+	}
+
+	relevantMap := m.backingMap[reflect.PointerTo(items.Type().Elem())]
+	keys := make([]k8sClient.ObjectKey, 0, len(relevantMap))
+	for key, obj := range relevantMap {
+		if listOptions.Namespace != "" && key.Namespace != listOptions.Namespace {
+			continue
+		}
+		if listOptions.LabelSelector != nil && !listOptions.LabelSelector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	listed := reflect.MakeSlice(items.Type(), 0, len(keys))
+	for _, key := range keys {
+		listed = reflect.Append(listed, reflect.ValueOf(relevantMap[key]).Elem())
+	}
+	items.Set(listed)
 	return nil
 }
 
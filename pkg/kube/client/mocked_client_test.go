@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	k8sClient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func TestMockedClient(t *testing.T) {
@@ -47,3 +48,34 @@ func TestMockedClient(t *testing.T) {
 	assert.Equal(t, "svc-namespace", newSvc.Namespace)
 	assert.Equal(t, "svc-name", newSvc.Name)
 }
+
+func TestMockedClient_List(t *testing.T) {
+	ctx := context.Background()
+	mockedClient := NewMockedClient()
+
+	for _, nsName := range []types.NamespacedName{{Name: "cm-b", Namespace: "ns-1"}, {Name: "cm-a", Namespace: "ns-1"}, {Name: "cm-c", Namespace: "ns-2"}} {
+		cm := configmap.Builder().SetName(nsName.Name).SetNamespace(nsName.Namespace).SetLabels(map[string]string{"ns": nsName.Namespace}).Build()
+		assert.NoError(t, mockedClient.Create(ctx, &cm))
+	}
+
+	t.Run("All namespaces", func(t *testing.T) {
+		cmList := corev1.ConfigMapList{}
+		assert.NoError(t, mockedClient.List(ctx, &cmList))
+		assert.Len(t, cmList.Items, 3)
+		assert.Equal(t, "cm-a", cmList.Items[0].Name)
+	})
+
+	t.Run("Single namespace", func(t *testing.T) {
+		cmList := corev1.ConfigMapList{}
+		assert.NoError(t, mockedClient.List(ctx, &cmList, k8sClient.InNamespace("ns-2")))
+		assert.Len(t, cmList.Items, 1)
+		assert.Equal(t, "cm-c", cmList.Items[0].Name)
+	})
+
+	t.Run("Matching labels", func(t *testing.T) {
+		cmList := corev1.ConfigMapList{}
+		assert.NoError(t, mockedClient.List(ctx, &cmList, k8sClient.MatchingLabels{"ns": "ns-1"}))
+		assert.Len(t, cmList.Items, 2)
+		assert.Equal(t, "cm-b", cmList.Items[1].Name)
+	})
+}
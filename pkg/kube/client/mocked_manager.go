@@ -86,8 +86,9 @@ func (m *MockedManager) GetClient() k8sClient.Client {
 	return m.Client
 }
 
+// GetEventRecorderFor returns an EventRecorder which discards the events
 func (m *MockedManager) GetEventRecorderFor(_ string) record.EventRecorder {
-	return nil
+	return &record.FakeRecorder{}
 }
 
 // GetFieldIndexer returns a client.FieldIndexer configured with the client
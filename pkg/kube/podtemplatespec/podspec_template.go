@@ -79,6 +79,16 @@ func WithInitContainer(name string, containerfunc func(*corev1.Container)) Modif
 	}
 }
 
+// RemoveInitContainer removes the init container with the provided name, if it exists.
+func RemoveInitContainer(name string) Modification {
+	return func(podTemplateSpec *corev1.PodTemplateSpec) {
+		idx := findIndexByName(name, podTemplateSpec.Spec.InitContainers)
+		if idx != notFound {
+			podTemplateSpec.Spec.InitContainers = append(podTemplateSpec.Spec.InitContainers[:idx], podTemplateSpec.Spec.InitContainers[idx+1:]...)
+		}
+	}
+}
+
 // WithInitContainerByIndex applies the modifications to the container with the provided index
 // if the index is out of range, a new container is added to accept these changes.
 func WithInitContainerByIndex(index int, funcs ...func(container *corev1.Container)) func(podTemplateSpec *corev1.PodTemplateSpec) {
@@ -514,6 +514,17 @@ func TestAddVolumes(t *testing.T) {
 	assert.Equal(t, "new-host-path", p.Spec.Volumes[0].VolumeSource.HostPath.Path)
 }
 
+func TestRemoveInitContainer(t *testing.T) {
+	p := New(
+		WithInitContainer("init-0", container.WithImage("image-0")),
+		WithInitContainer("init-1", container.WithImage("image-1")),
+		RemoveInitContainer("init-0"),
+		RemoveInitContainer("missing"),
+	)
+	assert.Len(t, p.Spec.InitContainers, 1)
+	assert.Equal(t, "init-1", p.Spec.InitContainers[0].Name)
+}
+
 func int64Ref(i int64) *int64 {
 	return &i
 }
@@ -1,6 +1,9 @@
 package probes
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
 
 type Modification func(*corev1.Probe)
 
@@ -29,6 +32,15 @@ func WithExecCommand(cmd []string) Modification {
 	}
 }
 
+func WithHTTPGet(path string, port int) Modification {
+	return func(probe *corev1.Probe) {
+		probe.ProbeHandler.HTTPGet = &corev1.HTTPGetAction{
+			Path: path,
+			Port: intstr.FromInt32(int32(port)),
+		}
+	}
+}
+
 func WithFailureThreshold(failureThreshold int) Modification {
 	return func(probe *corev1.Probe) {
 		probe.FailureThreshold = int32(failureThreshold)
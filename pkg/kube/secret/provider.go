@@ -0,0 +1,220 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// A secret provider is a Getter reading secrets from a store other than Kubernetes, such as files mounted by the
+// Secrets Store CSI Driver or HashiCorp Vault. The secrets of a provider are read only.
+
+// FileProvider reads the secrets mounted as files, with the layout <root>/<namespace>/<secret name>/<key>.
+type FileProvider struct {
+	root string
+}
+
+// NewFileProvider returns a provider reading the secrets mounted in the given directory.
+func NewFileProvider(root string) FileProvider {
+	return FileProvider{root: root}
+}
+
+// GetSecret reads every file of the directory of the secret as a key. Hidden files, such as the ones kubelet and
+// the Secrets Store CSI Driver use to update the files atomically, are ignored.
+func (p FileProvider) GetSecret(_ context.Context, objectKey client.ObjectKey) (corev1.Secret, error) {
+	if err := validateProviderSecretName(objectKey); err != nil {
+		return corev1.Secret{}, err
+	}
+
+	dir := filepath.Join(p.root, objectKey.Namespace, objectKey.Name)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return corev1.Secret{}, providerNotFoundError(objectKey)
+		}
+		return corev1.Secret{}, err
+	}
+
+	data := map[string][]byte{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(file)
+		if err != nil {
+			return corev1.Secret{}, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		value, err := os.ReadFile(file)
+		if err != nil {
+			return corev1.Secret{}, err
+		}
+		data[entry.Name()] = value
+	}
+	return providerSecret(objectKey, data), nil
+}
+
+// VaultProvider reads the secrets of a HashiCorp Vault KV version 2 secrets engine, at
+// <mount path>/<path prefix>/<secret name>.
+type VaultProvider struct {
+	address    string
+	mountPath  string
+	pathPrefix string
+	token      string
+	httpClient *http.Client
+}
+
+// NewVaultProvider returns a provider reading the secrets of the KV version 2 secrets engine mounted at mountPath,
+// authenticating with the given token.
+func NewVaultProvider(address, mountPath, pathPrefix, token string, httpClient *http.Client) VaultProvider {
+	return VaultProvider{
+		address:    strings.TrimRight(address, "/"),
+		mountPath:  strings.Trim(mountPath, "/"),
+		pathPrefix: strings.Trim(pathPrefix, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// vaultKVResponse is the response of the Vault KV version 2 read secret API.
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+// GetSecret reads the latest version of the secret. Values which are not strings are returned as JSON.
+func (p VaultProvider) GetSecret(ctx context.Context, objectKey client.ObjectKey) (corev1.Secret, error) {
+	if err := validateProviderSecretName(objectKey); err != nil {
+		return corev1.Secret{}, err
+	}
+
+	secretURL := fmt.Sprintf("%s/v1/%s/data/%s", p.address, p.mountPath, path.Join(p.pathPrefix, url.PathEscape(objectKey.Name)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL, nil)
+	if err != nil {
+		return corev1.Secret{}, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return corev1.Secret{}, fmt.Errorf("could not read secret %s from Vault: %s", objectKey.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return corev1.Secret{}, providerNotFoundError(objectKey)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return corev1.Secret{}, fmt.Errorf("could not read secret %s from Vault: %s", objectKey.Name, resp.Status)
+	}
+
+	kv := vaultKVResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&kv); err != nil {
+		return corev1.Secret{}, fmt.Errorf("could not decode secret %s read from Vault: %s", objectKey.Name, err)
+	}
+
+	data := map[string][]byte{}
+	for key, value := range kv.Data.Data {
+		if s, ok := value.(string); ok {
+			data[key] = []byte(s)
+			continue
+		}
+		bytes, err := json.Marshal(value)
+		if err != nil {
+			return corev1.Secret{}, err
+		}
+		data[key] = bytes
+	}
+	return providerSecret(objectKey, data), nil
+}
+
+// WithProviders returns a GetUpdateCreateDeleter which reads the given secrets from their providers, and every other
+// secret with the given client. Secrets which are missing from their provider are not reported as not found, so that
+// they are never created in Kubernetes instead.
+func WithProviders(client GetUpdateCreateDeleter, providers map[types.NamespacedName]Getter) GetUpdateCreateDeleter {
+	return providerRouter{GetUpdateCreateDeleter: client, providers: providers}
+}
+
+type providerRouter struct {
+	GetUpdateCreateDeleter
+	providers map[types.NamespacedName]Getter
+}
+
+func (r providerRouter) GetSecret(ctx context.Context, objectKey client.ObjectKey) (corev1.Secret, error) {
+	provider, ok := r.providers[objectKey]
+	if !ok {
+		return r.GetUpdateCreateDeleter.GetSecret(ctx, objectKey)
+	}
+
+	s, err := provider.GetSecret(ctx, objectKey)
+	if apiErrors.IsNotFound(err) {
+		return corev1.Secret{}, fmt.Errorf("secret %s not found in its secret provider", objectKey)
+	}
+	return s, err
+}
+
+// WithKey returns a Getter exposing the key sourceKey of the secret source, read with the given getter, as the key
+// targetKey of the secret target.
+func WithKey(getter Getter, source types.NamespacedName, sourceKey string, target types.NamespacedName, targetKey string) Getter {
+	return keyGetter{getter: getter, source: source, sourceKey: sourceKey, target: target, targetKey: targetKey}
+}
+
+type keyGetter struct {
+	getter    Getter
+	source    types.NamespacedName
+	sourceKey string
+	target    types.NamespacedName
+	targetKey string
+}
+
+func (g keyGetter) GetSecret(ctx context.Context, objectKey client.ObjectKey) (corev1.Secret, error) {
+	if objectKey != g.target {
+		return corev1.Secret{}, providerNotFoundError(objectKey)
+	}
+	value, err := ReadKey(ctx, g.getter, g.sourceKey, g.source)
+	if err != nil {
+		return corev1.Secret{}, err
+	}
+	return providerSecret(g.target, map[string][]byte{g.targetKey: []byte(value)}), nil
+}
+
+// validateProviderSecretName makes sure the name of the secret can't be used to read files or Vault paths outside
+// of the ones of the provider.
+func validateProviderSecretName(objectKey client.ObjectKey) error {
+	if errs := validation.IsDNS1123Subdomain(objectKey.Name); len(errs) > 0 {
+		return fmt.Errorf("invalid secret name %q: %s", objectKey.Name, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1123Label(objectKey.Namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid secret namespace %q: %s", objectKey.Namespace, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func providerNotFoundError(objectKey client.ObjectKey) error {
+	return apiErrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, objectKey.Name)
+}
+
+func providerSecret(objectKey client.ObjectKey, data map[string][]byte) corev1.Secret {
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: objectKey.Name, Namespace: objectKey.Namespace},
+		Data:       data,
+	}
+}
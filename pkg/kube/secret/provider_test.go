@@ -0,0 +1,150 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestFileProvider(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	dir := filepath.Join(root, "my-ns", "my-user-password")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "..2024_01_01"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "..2024_01_01", "password"), []byte("secret"), 0o600))
+	require.NoError(t, os.Symlink("..2024_01_01", filepath.Join(dir, "..data")))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "password"), filepath.Join(dir, "password")))
+
+	provider := NewFileProvider(root)
+
+	t.Run("Files are read as keys", func(t *testing.T) {
+		s, err := provider.GetSecret(ctx, nsName("my-ns", "my-user-password"))
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]byte{"password": []byte("secret")}, s.Data)
+	})
+
+	t.Run("Missing secrets are not found", func(t *testing.T) {
+		_, err := provider.GetSecret(ctx, nsName("other-ns", "my-user-password"))
+		assert.True(t, apiErrors.IsNotFound(err))
+	})
+
+	t.Run("Names can't escape the directory of the provider", func(t *testing.T) {
+		_, err := provider.GetSecret(ctx, nsName("my-ns", "../../etc"))
+		assert.ErrorContains(t, err, "invalid secret name")
+	})
+}
+
+// newVaultDevServer returns a stand-in for a Vault dev server with a KV version 2 secrets engine mounted at secret/.
+func newVaultDevServer(t *testing.T, token string, secrets map[string]map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		data, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": data, "metadata": map[string]interface{}{"version": 1}}})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultProvider(t *testing.T) {
+	ctx := context.Background()
+	server := newVaultDevServer(t, "root", map[string]map[string]interface{}{
+		"/v1/secret/data/mongodb/my-user-password": {"password": "secret", "version": 2},
+	})
+
+	t.Run("Secrets are read from the KV secrets engine", func(t *testing.T) {
+		provider := NewVaultProvider(server.URL, "secret", "/mongodb/", "root", server.Client())
+		s, err := provider.GetSecret(ctx, nsName("my-ns", "my-user-password"))
+		require.NoError(t, err)
+		assert.Equal(t, "secret", string(s.Data["password"]))
+		assert.Equal(t, "2", string(s.Data["version"]))
+	})
+
+	t.Run("Missing secrets are not found", func(t *testing.T) {
+		provider := NewVaultProvider(server.URL, "secret", "mongodb", "root", server.Client())
+		_, err := provider.GetSecret(ctx, nsName("my-ns", "other-password"))
+		assert.True(t, apiErrors.IsNotFound(err))
+	})
+
+	t.Run("Authorization errors are reported", func(t *testing.T) {
+		provider := NewVaultProvider(server.URL, "secret", "mongodb", "invalid", server.Client())
+		_, err := provider.GetSecret(ctx, nsName("my-ns", "my-user-password"))
+		assert.ErrorContains(t, err, "403 Forbidden")
+	})
+}
+
+type secretGetUpdateCreateDeleter struct {
+	secrets map[types.NamespacedName]corev1.Secret
+}
+
+func (c secretGetUpdateCreateDeleter) GetSecret(_ context.Context, objectKey client.ObjectKey) (corev1.Secret, error) {
+	if s, ok := c.secrets[objectKey]; ok {
+		return s, nil
+	}
+	return corev1.Secret{}, notFoundError()
+}
+
+func (c secretGetUpdateCreateDeleter) UpdateSecret(_ context.Context, s corev1.Secret) error {
+	c.secrets[types.NamespacedName{Name: s.Name, Namespace: s.Namespace}] = s
+	return nil
+}
+
+func (c secretGetUpdateCreateDeleter) CreateSecret(ctx context.Context, s corev1.Secret) error {
+	return c.UpdateSecret(ctx, s)
+}
+
+func (c secretGetUpdateCreateDeleter) DeleteSecret(_ context.Context, key client.ObjectKey) error {
+	delete(c.secrets, key)
+	return nil
+}
+
+func TestWithProviders(t *testing.T) {
+	ctx := context.Background()
+	kubernetes := secretGetUpdateCreateDeleter{secrets: map[types.NamespacedName]corev1.Secret{
+		nsName("my-ns", "kubernetes-password"): Builder().SetName("kubernetes-password").SetNamespace("my-ns").SetField("password", "from-kubernetes").Build(),
+	}}
+	server := newVaultDevServer(t, "root", map[string]map[string]interface{}{
+		"/v1/secret/data/vault-password": {"password": "from-vault"},
+		"/v1/secret/data/keyfile":        {"value": "from-vault-keyfile"},
+	})
+	vault := NewVaultProvider(server.URL, "secret", "", "root", server.Client())
+	secrets := WithProviders(kubernetes, map[types.NamespacedName]Getter{
+		nsName("my-ns", "vault-password"):   vault,
+		nsName("my-ns", "missing-password"): vault,
+		nsName("my-ns", "my-rs-keyfile"):    WithKey(vault, nsName("my-ns", "keyfile"), "value", nsName("my-ns", "my-rs-keyfile"), "keyfile"),
+	})
+
+	password, err := ReadKey(ctx, secrets, "password", nsName("my-ns", "kubernetes-password"))
+	require.NoError(t, err)
+	assert.Equal(t, "from-kubernetes", password)
+
+	password, err = ReadKey(ctx, secrets, "password", nsName("my-ns", "vault-password"))
+	require.NoError(t, err)
+	assert.Equal(t, "from-vault", password)
+
+	keyfile, err := ReadKey(ctx, secrets, "keyfile", nsName("my-ns", "my-rs-keyfile"))
+	require.NoError(t, err)
+	assert.Equal(t, "from-vault-keyfile", keyfile)
+
+	_, err = EnsureSecretWithKey(ctx, secrets, nsName("my-ns", "missing-password"), nil, "password", "generated")
+	assert.ErrorContains(t, err, "not found in its secret provider")
+	_, err = kubernetes.GetSecret(ctx, nsName("my-ns", "missing-password"))
+	assert.True(t, apiErrors.IsNotFound(err), "secrets missing from their provider must not be created in Kubernetes")
+}
@@ -0,0 +1,99 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+var _ Client = &MockedClient{}
+
+// MockedClient is an in-memory Client which records the changes it is asked to make.
+type MockedClient struct {
+	// Databases holds the collections of each database by name.
+	Databases map[string]map[string]Collection
+	// CollectionIndexes holds the indexes of each collection, by "<database>.<collection>".
+	CollectionIndexes map[string][]mdbv1.Index
+	// Operations are the changes made to the deployment, in order.
+	Operations []string
+}
+
+// NewMockedClient returns a MockedClient of an empty deployment.
+func NewMockedClient() *MockedClient {
+	return &MockedClient{
+		Databases:         map[string]map[string]Collection{},
+		CollectionIndexes: map[string][]mdbv1.Index{},
+	}
+}
+
+func (m *MockedClient) Collections(_ context.Context, database string) (map[string]Collection, error) {
+	collections := map[string]Collection{}
+	for name, collection := range m.Databases[database] {
+		collections[name] = collection
+	}
+	return collections, nil
+}
+
+func (m *MockedClient) Indexes(_ context.Context, database, collection string) ([]mdbv1.Index, error) {
+	return append([]mdbv1.Index{}, m.CollectionIndexes[database+"."+collection]...), nil
+}
+
+func (m *MockedClient) CreateCollection(_ context.Context, database string, collection mdbv1.Collection) error {
+	if _, ok := m.Databases[database][collection.Name]; ok {
+		return fmt.Errorf("collection %s.%s already exists", database, collection.Name)
+	}
+	if m.Databases[database] == nil {
+		m.Databases[database] = map[string]Collection{}
+	}
+	collection.Indexes = nil
+	m.Databases[database][collection.Name] = Collection{Collection: collection}
+	m.Operations = append(m.Operations, fmt.Sprintf("create collection %s.%s", database, collection.Name))
+	return nil
+}
+
+func (m *MockedClient) ModifyCollection(_ context.Context, database string, collection mdbv1.Collection) error {
+	if _, ok := m.Databases[database][collection.Name]; !ok {
+		return fmt.Errorf("collection %s.%s does not exist", database, collection.Name)
+	}
+	collection.Indexes = nil
+	m.Databases[database][collection.Name] = Collection{Collection: collection}
+	m.Operations = append(m.Operations, fmt.Sprintf("modify collection %s.%s", database, collection.Name))
+	return nil
+}
+
+func (m *MockedClient) DropCollection(_ context.Context, database, collection string) error {
+	delete(m.Databases[database], collection)
+	delete(m.CollectionIndexes, database+"."+collection)
+	m.Operations = append(m.Operations, fmt.Sprintf("drop collection %s.%s", database, collection))
+	return nil
+}
+
+func (m *MockedClient) CreateIndex(_ context.Context, database, collection string, index mdbv1.Index) error {
+	key := database + "." + collection
+	for _, existing := range m.CollectionIndexes[key] {
+		if existing.Name == index.Name {
+			return fmt.Errorf("index %s of collection %s already exists", index.Name, key)
+		}
+	}
+	m.CollectionIndexes[key] = append(m.CollectionIndexes[key], index)
+	m.Operations = append(m.Operations, fmt.Sprintf("create index %s.%s", key, index.Name))
+	return nil
+}
+
+func (m *MockedClient) DropIndex(_ context.Context, database, collection, index string) error {
+	key := database + "." + collection
+	var indexes []mdbv1.Index
+	for _, existing := range m.CollectionIndexes[key] {
+		if existing.Name != index {
+			indexes = append(indexes, existing)
+		}
+	}
+	m.CollectionIndexes[key] = indexes
+	m.Operations = append(m.Operations, fmt.Sprintf("drop index %s.%s", key, index))
+	return nil
+}
+
+func (m *MockedClient) Disconnect(context.Context) error {
+	return nil
+}
@@ -0,0 +1,264 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+type collectionSpecification struct {
+	Name    string            `bson:"name"`
+	Type    string            `bson:"type"`
+	Options collectionOptions `bson:"options"`
+}
+
+type collectionOptions struct {
+	Capped             bool               `bson:"capped"`
+	Size               int64              `bson:"size"`
+	Max                int64              `bson:"max"`
+	TimeSeries         *timeSeriesOptions `bson:"timeseries"`
+	ExpireAfterSeconds *int64             `bson:"expireAfterSeconds"`
+	Validator          bson.Raw           `bson:"validator"`
+	ValidationLevel    string             `bson:"validationLevel"`
+	ValidationAction   string             `bson:"validationAction"`
+}
+
+type timeSeriesOptions struct {
+	TimeField   string `bson:"timeField"`
+	MetaField   string `bson:"metaField"`
+	Granularity string `bson:"granularity"`
+}
+
+type indexSpecification struct {
+	Name                    string   `bson:"name"`
+	Key                     bson.Raw `bson:"key"`
+	Unique                  bool     `bson:"unique"`
+	Sparse                  bool     `bson:"sparse"`
+	ExpireAfterSeconds      *int32   `bson:"expireAfterSeconds"`
+	PartialFilterExpression bson.Raw `bson:"partialFilterExpression"`
+}
+
+type mongoClient struct {
+	client *mongo.Client
+}
+
+// NewClient returns a Client which runs commands against the deployment described by the given client options.
+func NewClient(ctx context.Context, clientOptions *options.ClientOptions) (Client, error) {
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the deployment: %s", err)
+	}
+	return &mongoClient{client: client}, nil
+}
+
+func (c *mongoClient) Collections(ctx context.Context, database string) (map[string]Collection, error) {
+	cursor, err := c.client.Database(database).ListCollections(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	var specifications []collectionSpecification
+	if err := cursor.All(ctx, &specifications); err != nil {
+		return nil, err
+	}
+
+	collections := map[string]Collection{}
+	for _, specification := range specifications {
+		validator, err := fromDocument(specification.Options.Validator)
+		if err != nil {
+			return nil, err
+		}
+		collection := Collection{
+			Collection: mdbv1.Collection{
+				Name:             specification.Name,
+				Validator:        validator,
+				ValidationLevel:  specification.Options.ValidationLevel,
+				ValidationAction: specification.Options.ValidationAction,
+			},
+			View: specification.Type == "view",
+		}
+		if specification.Options.Capped {
+			collection.Capped = &mdbv1.CappedCollectionOptions{Size: specification.Options.Size, Max: specification.Options.Max}
+		}
+		if timeSeries := specification.Options.TimeSeries; timeSeries != nil {
+			collection.TimeSeries = &mdbv1.TimeSeriesCollectionOptions{
+				TimeField:          timeSeries.TimeField,
+				MetaField:          timeSeries.MetaField,
+				Granularity:        timeSeries.Granularity,
+				ExpireAfterSeconds: specification.Options.ExpireAfterSeconds,
+			}
+		}
+		collections[specification.Name] = collection
+	}
+	return collections, nil
+}
+
+func (c *mongoClient) Indexes(ctx context.Context, database, collection string) ([]mdbv1.Index, error) {
+	cursor, err := c.client.Database(database).Collection(collection).Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var specifications []indexSpecification
+	if err := cursor.All(ctx, &specifications); err != nil {
+		return nil, err
+	}
+
+	indexes := make([]mdbv1.Index, len(specifications))
+	for i, specification := range specifications {
+		keys, err := indexKeys(specification.Key)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the keys of index %s: %s", specification.Name, err)
+		}
+		partialFilterExpression, err := fromDocument(specification.PartialFilterExpression)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i] = mdbv1.Index{
+			Name:                    specification.Name,
+			Keys:                    keys,
+			Unique:                  specification.Unique,
+			Sparse:                  specification.Sparse,
+			ExpireAfterSeconds:      specification.ExpireAfterSeconds,
+			PartialFilterExpression: partialFilterExpression,
+		}
+	}
+	return indexes, nil
+}
+
+func (c *mongoClient) CreateCollection(ctx context.Context, database string, collection mdbv1.Collection) error {
+	command := bson.D{{Key: "create", Value: collection.Name}}
+	if collection.Capped != nil {
+		command = append(command, bson.E{Key: "capped", Value: true}, bson.E{Key: "size", Value: collection.Capped.Size})
+		if collection.Capped.Max > 0 {
+			command = append(command, bson.E{Key: "max", Value: collection.Capped.Max})
+		}
+	}
+	if timeSeries := collection.TimeSeries; timeSeries != nil {
+		timeSeriesCommand := bson.D{{Key: "timeField", Value: timeSeries.TimeField}}
+		if timeSeries.MetaField != "" {
+			timeSeriesCommand = append(timeSeriesCommand, bson.E{Key: "metaField", Value: timeSeries.MetaField})
+		}
+		if timeSeries.Granularity != "" {
+			timeSeriesCommand = append(timeSeriesCommand, bson.E{Key: "granularity", Value: timeSeries.Granularity})
+		}
+		command = append(command, bson.E{Key: "timeseries", Value: timeSeriesCommand})
+		if timeSeries.ExpireAfterSeconds != nil {
+			command = append(command, bson.E{Key: "expireAfterSeconds", Value: *timeSeries.ExpireAfterSeconds})
+		}
+	}
+
+	validator, err := toDocument(collection.Validator)
+	if err != nil {
+		return fmt.Errorf("invalid validator: %s", err)
+	}
+	if validator != nil {
+		command = append(command, bson.E{Key: "validator", Value: validator})
+	}
+	if collection.ValidationLevel != "" {
+		command = append(command, bson.E{Key: "validationLevel", Value: collection.ValidationLevel})
+	}
+	if collection.ValidationAction != "" {
+		command = append(command, bson.E{Key: "validationAction", Value: collection.ValidationAction})
+	}
+	return c.client.Database(database).RunCommand(ctx, command).Err()
+}
+
+func (c *mongoClient) ModifyCollection(ctx context.Context, database string, collection mdbv1.Collection) error {
+	command := bson.D{{Key: "collMod", Value: collection.Name}}
+	// time series collections don't support validators, only their expiry can change.
+	if collection.TimeSeries != nil {
+		if collection.TimeSeries.ExpireAfterSeconds != nil {
+			command = append(command, bson.E{Key: "expireAfterSeconds", Value: *collection.TimeSeries.ExpireAfterSeconds})
+		} else {
+			command = append(command, bson.E{Key: "expireAfterSeconds", Value: "off"})
+		}
+		return c.client.Database(database).RunCommand(ctx, command).Err()
+	}
+
+	validator, err := toDocument(collection.Validator)
+	if err != nil {
+		return fmt.Errorf("invalid validator: %s", err)
+	}
+	if validator == nil {
+		validator = bson.D{}
+	}
+	command = append(command,
+		bson.E{Key: "validator", Value: validator},
+		bson.E{Key: "validationLevel", Value: validationLevel(collection)},
+		bson.E{Key: "validationAction", Value: validationAction(collection)},
+	)
+	return c.client.Database(database).RunCommand(ctx, command).Err()
+}
+
+func (c *mongoClient) DropCollection(ctx context.Context, database, collection string) error {
+	return c.client.Database(database).Collection(collection).Drop(ctx)
+}
+
+func (c *mongoClient) CreateIndex(ctx context.Context, database, collection string, index mdbv1.Index) error {
+	keys := bson.D{}
+	for _, key := range index.Keys {
+		if key.Type.Type == intstr.Int {
+			keys = append(keys, bson.E{Key: key.Field, Value: key.Type.IntVal})
+		} else {
+			keys = append(keys, bson.E{Key: key.Field, Value: key.Type.StrVal})
+		}
+	}
+
+	indexCommand := bson.D{{Key: "key", Value: keys}, {Key: "name", Value: index.Name}}
+	if index.Unique {
+		indexCommand = append(indexCommand, bson.E{Key: "unique", Value: true})
+	}
+	if index.Sparse {
+		indexCommand = append(indexCommand, bson.E{Key: "sparse", Value: true})
+	}
+	if index.ExpireAfterSeconds != nil {
+		indexCommand = append(indexCommand, bson.E{Key: "expireAfterSeconds", Value: *index.ExpireAfterSeconds})
+	}
+	partialFilterExpression, err := toDocument(index.PartialFilterExpression)
+	if err != nil {
+		return fmt.Errorf("invalid partial filter expression of index %s: %s", index.Name, err)
+	}
+	if partialFilterExpression != nil {
+		indexCommand = append(indexCommand, bson.E{Key: "partialFilterExpression", Value: partialFilterExpression})
+	}
+
+	command := bson.D{
+		{Key: "createIndexes", Value: collection},
+		{Key: "indexes", Value: bson.A{indexCommand}},
+	}
+	return c.client.Database(database).RunCommand(ctx, command).Err()
+}
+
+func (c *mongoClient) DropIndex(ctx context.Context, database, collection, index string) error {
+	_, err := c.client.Database(database).Collection(collection).Indexes().DropOne(ctx, index)
+	return err
+}
+
+func (c *mongoClient) Disconnect(ctx context.Context) error {
+	return c.client.Disconnect(ctx)
+}
+
+// indexKeys returns the fields of the given index key document, in order.
+func indexKeys(key bson.Raw) ([]mdbv1.IndexKey, error) {
+	elements, err := key.Elements()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]mdbv1.IndexKey, len(elements))
+	for i, element := range elements {
+		keys[i] = mdbv1.IndexKey{Field: element.Key()}
+		if number, ok := element.Value().AsInt64OK(); ok {
+			keys[i].Type = intstr.FromInt32(int32(number))
+		} else if name, ok := element.Value().StringValueOK(); ok {
+			keys[i].Type = intstr.FromString(name)
+		} else {
+			return nil, fmt.Errorf("unsupported type of field %s", element.Key())
+		}
+	}
+	return keys, nil
+}
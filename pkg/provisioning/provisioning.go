@@ -0,0 +1,320 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+const (
+	defaultValidationLevel  = "strict"
+	defaultValidationAction = "error"
+	defaultGranularity      = "seconds"
+
+	// cappedSizeIncrement is the multiple MongoDB rounds the size of capped collections up to.
+	cappedSizeIncrement = 256
+)
+
+// Client reads and changes the collections and indexes of a deployment.
+type Client interface {
+	// Collections returns the collections of the given database by name, without their indexes.
+	Collections(ctx context.Context, database string) (map[string]Collection, error)
+	// Indexes returns the indexes of the given collection.
+	Indexes(ctx context.Context, database, collection string) ([]mdbv1.Index, error)
+	// CreateCollection creates the given collection without its indexes.
+	CreateCollection(ctx context.Context, database string, collection mdbv1.Collection) error
+	// ModifyCollection changes the validator and the expiry of an existing collection.
+	ModifyCollection(ctx context.Context, database string, collection mdbv1.Collection) error
+	DropCollection(ctx context.Context, database, collection string) error
+	CreateIndex(ctx context.Context, database, collection string, index mdbv1.Index) error
+	DropIndex(ctx context.Context, database, collection, index string) error
+	Disconnect(ctx context.Context) error
+}
+
+// Collection is an existing collection of the deployment.
+type Collection struct {
+	mdbv1.Collection
+
+	// View is true if the collection is a view.
+	View bool
+}
+
+// Converge creates the collections and indexes of the given databases which do not exist, and changes the validators
+// and expiries which differ. Collections and indexes whose other options differ have to be dropped and created again,
+// which is only done if their database allows destructive changes. Otherwise, the differences are returned.
+func Converge(ctx context.Context, client Client, databases []mdbv1.Database) ([]mdbv1.DatabaseDrift, error) {
+	var drift []mdbv1.DatabaseDrift
+	for _, database := range databases {
+		existing, err := client.Collections(ctx, database.Name)
+		if err != nil {
+			return nil, fmt.Errorf("could not list the collections of database %s: %s", database.Name, err)
+		}
+		for _, collection := range database.Collections {
+			collectionDrift, err := convergeCollection(ctx, client, database, collection, existing)
+			if err != nil {
+				return nil, fmt.Errorf("could not provision collection %s.%s: %s", database.Name, collection.Name, err)
+			}
+			drift = append(drift, collectionDrift...)
+		}
+	}
+	return drift, nil
+}
+
+func convergeCollection(ctx context.Context, client Client, database mdbv1.Database, collection mdbv1.Collection, existing map[string]Collection) ([]mdbv1.DatabaseDrift, error) {
+	current, exists := existing[collection.Name]
+	if exists {
+		if difference := collectionDifference(collection, current); difference != "" {
+			if !database.AllowDestructiveChanges {
+				return []mdbv1.DatabaseDrift{{
+					Database:   database.Name,
+					Collection: collection.Name,
+					Message:    fmt.Sprintf("%s, the collection must be dropped and created again", difference),
+				}}, nil
+			}
+			if err := client.DropCollection(ctx, database.Name, collection.Name); err != nil {
+				return nil, err
+			}
+			exists = false
+		}
+	}
+
+	if !exists {
+		if err := client.CreateCollection(ctx, database.Name, collection); err != nil {
+			return nil, err
+		}
+	} else {
+		changed, err := collectionOptionsChanged(collection, current.Collection)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			if err := client.ModifyCollection(ctx, database.Name, collection); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(collection.Indexes) == 0 {
+		return nil, nil
+	}
+	indexes, err := client.Indexes(ctx, database.Name, collection.Name)
+	if err != nil {
+		return nil, err
+	}
+	existingIndexes := map[string]mdbv1.Index{}
+	for _, index := range indexes {
+		existingIndexes[index.Name] = index
+	}
+
+	var drift []mdbv1.DatabaseDrift
+	for _, index := range collection.Indexes {
+		current, exists := existingIndexes[index.Name]
+		if exists {
+			difference, err := indexDifference(index, current)
+			if err != nil {
+				return nil, err
+			}
+			if difference != "" {
+				if !database.AllowDestructiveChanges {
+					drift = append(drift, mdbv1.DatabaseDrift{
+						Database:   database.Name,
+						Collection: collection.Name,
+						Index:      index.Name,
+						Message:    fmt.Sprintf("%s, the index must be dropped and created again", difference),
+					})
+					continue
+				}
+				if err := client.DropIndex(ctx, database.Name, collection.Name, index.Name); err != nil {
+					return nil, err
+				}
+				exists = false
+			}
+		}
+		if !exists {
+			if err := client.CreateIndex(ctx, database.Name, collection.Name, index); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return drift, nil
+}
+
+// collectionDifference returns the difference between the options of the collections which can't be changed
+// in place, or an empty string if there is none.
+func collectionDifference(desired mdbv1.Collection, current Collection) string {
+	if current.View {
+		return "the collection is a view"
+	}
+
+	switch {
+	case desired.Capped == nil && current.Capped != nil:
+		return "the collection is capped"
+	case desired.Capped != nil && current.Capped == nil:
+		return "the collection is not capped"
+	case desired.Capped != nil:
+		if size := cappedSize(desired.Capped.Size); size != current.Capped.Size {
+			return fmt.Sprintf("the capped size is %d bytes instead of %d", current.Capped.Size, size)
+		}
+		if desired.Capped.Max != current.Capped.Max {
+			return fmt.Sprintf("the maximum number of documents is %d instead of %d", current.Capped.Max, desired.Capped.Max)
+		}
+	}
+
+	switch {
+	case desired.TimeSeries == nil && current.TimeSeries != nil:
+		return "the collection is a time series collection"
+	case desired.TimeSeries != nil && current.TimeSeries == nil:
+		return "the collection is not a time series collection"
+	case desired.TimeSeries != nil:
+		if desired.TimeSeries.TimeField != current.TimeSeries.TimeField {
+			return fmt.Sprintf("the time field is %q instead of %q", current.TimeSeries.TimeField, desired.TimeSeries.TimeField)
+		}
+		if desired.TimeSeries.MetaField != current.TimeSeries.MetaField {
+			return fmt.Sprintf("the meta field is %q instead of %q", current.TimeSeries.MetaField, desired.TimeSeries.MetaField)
+		}
+		if granularity(*desired.TimeSeries) != granularity(*current.TimeSeries) {
+			return fmt.Sprintf("the granularity is %q instead of %q", granularity(*current.TimeSeries), granularity(*desired.TimeSeries))
+		}
+	}
+	return ""
+}
+
+// collectionOptionsChanged returns true if the validator or the expiry of the collection differ.
+func collectionOptionsChanged(desired, current mdbv1.Collection) (bool, error) {
+	equal, err := documentsEqual(desired.Validator, current.Validator)
+	if err != nil {
+		return false, fmt.Errorf("invalid validator: %s", err)
+	}
+	if !equal || validationLevel(desired) != validationLevel(current) || validationAction(desired) != validationAction(current) {
+		return true, nil
+	}
+	if desired.TimeSeries != nil && current.TimeSeries != nil {
+		return !reflect.DeepEqual(desired.TimeSeries.ExpireAfterSeconds, current.TimeSeries.ExpireAfterSeconds), nil
+	}
+	return false, nil
+}
+
+// indexDifference returns the difference between the indexes, or an empty string if there is none.
+func indexDifference(desired, current mdbv1.Index) (string, error) {
+	if !reflect.DeepEqual(desired.Keys, current.Keys) {
+		return fmt.Sprintf("the keys are {%s} instead of {%s}", formatKeys(current.Keys), formatKeys(desired.Keys)), nil
+	}
+	if desired.Unique != current.Unique {
+		return fmt.Sprintf("unique is %t instead of %t", current.Unique, desired.Unique), nil
+	}
+	if desired.Sparse != current.Sparse {
+		return fmt.Sprintf("sparse is %t instead of %t", current.Sparse, desired.Sparse), nil
+	}
+	if !reflect.DeepEqual(desired.ExpireAfterSeconds, current.ExpireAfterSeconds) {
+		return "the expiry differs", nil
+	}
+	equal, err := documentsEqual(desired.PartialFilterExpression, current.PartialFilterExpression)
+	if err != nil {
+		return "", fmt.Errorf("invalid partial filter expression of index %s: %s", desired.Name, err)
+	}
+	if !equal {
+		return "the partial filter expression differs", nil
+	}
+	return "", nil
+}
+
+func formatKeys(keys []mdbv1.IndexKey) string {
+	formatted := make([]string, len(keys))
+	for i, key := range keys {
+		formatted[i] = fmt.Sprintf("%s: %s", key.Field, key.Type.String())
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func cappedSize(size int64) int64 {
+	if remainder := size % cappedSizeIncrement; remainder != 0 {
+		return size + cappedSizeIncrement - remainder
+	}
+	return size
+}
+
+func granularity(timeSeries mdbv1.TimeSeriesCollectionOptions) string {
+	if timeSeries.Granularity == "" {
+		return defaultGranularity
+	}
+	return timeSeries.Granularity
+}
+
+func validationLevel(collection mdbv1.Collection) string {
+	if collection.ValidationLevel == "" {
+		return defaultValidationLevel
+	}
+	return collection.ValidationLevel
+}
+
+func validationAction(collection mdbv1.Collection) string {
+	if collection.ValidationAction == "" {
+		return defaultValidationAction
+	}
+	return collection.ValidationAction
+}
+
+// documentsEqual returns true if the given Extended JSON documents have the same fields and values, regardless of
+// the order of the fields and of the types of the numbers. A missing document is equal to an empty one.
+func documentsEqual(a, b *runtime.RawExtension) (bool, error) {
+	normalizedA, err := normalizeDocument(a)
+	if err != nil {
+		return false, err
+	}
+	normalizedB, err := normalizeDocument(b)
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(normalizedA, normalizedB), nil
+}
+
+func normalizeDocument(raw *runtime.RawExtension) (map[string]interface{}, error) {
+	doc, err := toDocument(raw)
+	if err != nil || len(doc) == 0 {
+		return nil, err
+	}
+	bytes, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	relaxed, err := bson.MarshalExtJSON(bson.Raw(bytes), false, false)
+	if err != nil {
+		return nil, err
+	}
+	normalized := map[string]interface{}{}
+	if err := json.Unmarshal(relaxed, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// toDocument parses the given Extended JSON document, keeping the order of its fields.
+func toDocument(raw *runtime.RawExtension) (bson.D, error) {
+	if raw == nil || len(raw.Raw) == 0 || string(raw.Raw) == "null" {
+		return nil, nil
+	}
+	doc := bson.D{}
+	if err := bson.UnmarshalExtJSON(raw.Raw, false, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// fromDocument returns the given document in relaxed Extended JSON.
+func fromDocument(doc bson.Raw) (*runtime.RawExtension, error) {
+	if len(doc) == 0 {
+		return nil, nil
+	}
+	relaxed, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return nil, err
+	}
+	return &runtime.RawExtension{Raw: relaxed}, nil
+}
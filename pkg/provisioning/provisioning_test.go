@@ -0,0 +1,148 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	mdbv1 "github.com/mongodb/mongodb-kubernetes-operator/api/v1"
+)
+
+func TestConverge_CreatesCollectionsAndIndexes(t *testing.T) {
+	ctx := context.Background()
+	client := NewMockedClient()
+
+	drift, err := Converge(ctx, client, []mdbv1.Database{{
+		Name: "app",
+		Collections: []mdbv1.Collection{
+			{Name: "orders", Indexes: []mdbv1.Index{customerIndex()}},
+			{Name: "events", Capped: &mdbv1.CappedCollectionOptions{Size: 1000}},
+		},
+	}})
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+	assert.Equal(t, []string{
+		"create collection app.orders",
+		"create index app.orders.customer",
+		"create collection app.events",
+	}, client.Operations)
+
+	client.Operations = nil
+	client.Databases["app"]["events"] = Collection{Collection: mdbv1.Collection{Name: "events", Capped: &mdbv1.CappedCollectionOptions{Size: 1024}}}
+	drift, err = Converge(ctx, client, []mdbv1.Database{{
+		Name: "app",
+		Collections: []mdbv1.Collection{
+			{Name: "orders", Indexes: []mdbv1.Index{customerIndex()}},
+			{Name: "events", Capped: &mdbv1.CappedCollectionOptions{Size: 1000}},
+		},
+	}})
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+	assert.Empty(t, client.Operations, "the size of capped collections is rounded up to a multiple of 256")
+}
+
+func TestConverge_ModifiesValidators(t *testing.T) {
+	ctx := context.Background()
+	client := NewMockedClient()
+	client.Databases["app"] = map[string]Collection{
+		"orders": {Collection: mdbv1.Collection{
+			Name:      "orders",
+			Validator: &runtime.RawExtension{Raw: []byte(`{"total": {"$gte": 0.0}, "customer": {"$exists": true}}`)},
+		}},
+	}
+
+	database := mdbv1.Database{
+		Name: "app",
+		Collections: []mdbv1.Collection{{
+			Name:      "orders",
+			Validator: &runtime.RawExtension{Raw: []byte(`{"customer": {"$exists": true}, "total": {"$gte": 0}}`)},
+		}},
+	}
+	_, err := Converge(ctx, client, []mdbv1.Database{database})
+	require.NoError(t, err)
+	assert.Empty(t, client.Operations, "the order of the fields and the types of the numbers are ignored")
+
+	database.Collections[0].ValidationAction = "warn"
+	_, err = Converge(ctx, client, []mdbv1.Database{database})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"modify collection app.orders"}, client.Operations)
+}
+
+func TestConverge_DestructiveChangesNeedOptIn(t *testing.T) {
+	ctx := context.Background()
+	client := NewMockedClient()
+	client.Databases["app"] = map[string]Collection{
+		"events": {Collection: mdbv1.Collection{Name: "events"}},
+		"orders": {Collection: mdbv1.Collection{Name: "orders"}},
+	}
+	client.CollectionIndexes["app.orders"] = []mdbv1.Index{customerIndex()}
+
+	uniqueIndex := customerIndex()
+	uniqueIndex.Unique = true
+	database := mdbv1.Database{
+		Name: "app",
+		Collections: []mdbv1.Collection{
+			{Name: "events", TimeSeries: &mdbv1.TimeSeriesCollectionOptions{TimeField: "timestamp"}},
+			{Name: "orders", Indexes: []mdbv1.Index{uniqueIndex}},
+		},
+	}
+	drift, err := Converge(ctx, client, []mdbv1.Database{database})
+	require.NoError(t, err)
+	assert.Empty(t, client.Operations)
+	require.Len(t, drift, 2)
+	assert.Equal(t, mdbv1.DatabaseDrift{
+		Database:   "app",
+		Collection: "events",
+		Message:    "the collection is not a time series collection, the collection must be dropped and created again",
+	}, drift[0])
+	assert.Equal(t, mdbv1.DatabaseDrift{
+		Database:   "app",
+		Collection: "orders",
+		Index:      "customer",
+		Message:    "unique is false instead of true, the index must be dropped and created again",
+	}, drift[1])
+
+	database.AllowDestructiveChanges = true
+	drift, err = Converge(ctx, client, []mdbv1.Database{database})
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+	assert.Equal(t, []string{
+		"drop collection app.events",
+		"create collection app.events",
+		"drop index app.orders.customer",
+		"create index app.orders.customer",
+	}, client.Operations)
+	assert.Equal(t, []mdbv1.Index{uniqueIndex}, client.CollectionIndexes["app.orders"])
+}
+
+func TestIndexDifference(t *testing.T) {
+	current := customerIndex()
+
+	desired := customerIndex()
+	desired.Keys = append(desired.Keys, mdbv1.IndexKey{Field: "date", Type: intstr.FromInt32(-1)})
+	difference, err := indexDifference(desired, current)
+	require.NoError(t, err)
+	assert.Equal(t, "the keys are {customer: 1} instead of {customer: 1, date: -1}", difference)
+
+	desired = customerIndex()
+	desired.PartialFilterExpression = &runtime.RawExtension{Raw: []byte(`{"total": {"$gt": 100}}`)}
+	difference, err = indexDifference(desired, current)
+	require.NoError(t, err)
+	assert.Equal(t, "the partial filter expression differs", difference)
+
+	current.PartialFilterExpression = &runtime.RawExtension{Raw: []byte(`{"total": {"$gt": {"$numberLong": "100"}}}`)}
+	difference, err = indexDifference(desired, current)
+	require.NoError(t, err)
+	assert.Empty(t, difference)
+}
+
+func customerIndex() mdbv1.Index {
+	return mdbv1.Index{
+		Name: "customer",
+		Keys: []mdbv1.IndexKey{{Field: "customer", Type: intstr.FromInt32(1)}},
+	}
+}
@@ -6,6 +6,10 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/pod"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/replication"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 
@@ -15,7 +19,19 @@ import (
 const (
 	DefaultAgentHealthStatusFilePath = "/var/log/mongodb-mms-automation/agent-health-status.json"
 	AgentHealthStatusFilePathEnv     = "AGENT_STATUS_FILEPATH"
-	WithAgentFileLogging             = "MDB_WITH_AGENT_FILE_LOGGING"
+	// DefaultAutomationConfigBackupFilePath is where the agent keeps a copy of the automation config it received
+	// from Ops Manager, the readiness probe reads it when the agent is not in headless mode.
+	DefaultAutomationConfigBackupFilePath = "/var/lib/mongodb-mms-automation/mms-cluster-config-backup.json"
+	AutomationConfigBackupFilePathEnv     = "AUTOMATION_CONFIG_BACKUP_FILEPATH"
+	WithAgentFileLogging                  = "MDB_WITH_AGENT_FILE_LOGGING"
+	// DefaultServerPort is the port the readiness probe listens on when it runs as a server.
+	DefaultServerPort = 9091
+
+	// The checks which can be performed by the probe binary with the -check flag.
+	ReadinessCheck      = "readiness"
+	AgentLivenessCheck  = "agent-liveness"
+	MongodLivenessCheck = "mongod-liveness"
+	MongodStartupCheck  = "mongod-startup"
 
 	defaultLogPath               = "/var/log/mongodb-mms-automation/readiness.log"
 	podNamespaceEnv              = "POD_NAMESPACE"
@@ -26,6 +42,35 @@ const (
 	ReadinessProbeLoggerMaxSize  = "READINESS_PROBE_LOGGER_MAX_SIZE"
 	ReadinessProbeLoggerMaxAge   = "READINESS_PROBE_LOGGER_MAX_AGE"
 	ReadinessProbeLoggerCompress = "READINESS_PROBE_LOGGER_COMPRESS"
+	// ReadinessProbeMaxReplicationLagSeconds is the replication lag above which a secondary is reported
+	// as not ready. The replication lag is not checked if it is not set or set to 0.
+	ReadinessProbeMaxReplicationLagSeconds = "READINESS_PROBE_MAX_REPLICATION_LAG_SECONDS"
+	// LivenessProbeHealthStatusStaleSeconds is the number of seconds after which the agent is considered hung
+	// if it has not updated the health status file.
+	LivenessProbeHealthStatusStaleSeconds = "LIVENESS_PROBE_HEALTH_STATUS_STALE_SECONDS"
+	// LivenessProbeMongodUnresponsiveSeconds is the number of seconds after which mongod is considered deadlocked
+	// if the agent could not reach it.
+	LivenessProbeMongodUnresponsiveSeconds = "LIVENESS_PROBE_MONGOD_UNRESPONSIVE_SECONDS"
+
+	// ReadinessProbeWaitStepGracePeriodSeconds is the number of seconds the agent must have spent on a wait step
+	// before the Pod is considered ready.
+	ReadinessProbeWaitStepGracePeriodSeconds = "READINESS_PROBE_WAIT_STEP_GRACE_PERIOD_SECONDS"
+	// ReadinessProbeWaitStepsReady configures whether a Pod whose agent is on a wait step is considered ready.
+	ReadinessProbeWaitStepsReady = "READINESS_PROBE_WAIT_STEPS_READY"
+	// ReadinessProbeWaitStepAllowList is a comma separated list of steps which are considered wait steps,
+	// even if the agent does not report them as such.
+	ReadinessProbeWaitStepAllowList = "READINESS_PROBE_WAIT_STEP_ALLOW_LIST"
+	// ReadinessProbeWaitStepDenyList is a comma separated list of steps which are never considered wait steps,
+	// even if the agent reports them as such.
+	ReadinessProbeWaitStepDenyList = "READINESS_PROBE_WAIT_STEP_DENY_LIST"
+	// ReadinessProbeMongodNotReadySeconds is the number of seconds after which mongod is considered not ready
+	// if the agent could not reach it.
+	ReadinessProbeMongodNotReadySeconds = "READINESS_PROBE_MONGOD_NOT_READY_SECONDS"
+
+	defaultHealthStatusStaleSeconds  = 300
+	defaultMongodUnresponsiveSeconds = 300
+	defaultWaitStepGracePeriod       = 15 * time.Second
+	defaultMongodNotReadyInterval    = time.Minute
 )
 
 type Config struct {
@@ -33,8 +78,20 @@ type Config struct {
 	Namespace                  string
 	Hostname                   string
 	AutomationConfigSecretName string
-	HealthStatusReader         io.Reader
-	LogFilePath                string
+	// AutomationConfigBackupFilePath is the automation config copy of the agent, used when it is not in headless mode.
+	AutomationConfigBackupFilePath string
+	HealthStatusReader             io.Reader
+	LogFilePath                    string
+	// MaxReplicationLag is the replication lag above which the Pod is not ready, 0 disables the check.
+	MaxReplicationLag time.Duration
+	// ReplicationLagReader reads the replication lag of the local mongod, it is only set when
+	// MaxReplicationLag is greater than 0.
+	ReplicationLagReader replication.LagReader
+	// Heuristics are the policies deciding whether the Pod is ready.
+	Heuristics Heuristics
+	// AnnotationThrottle limits how often the Pod annotation with the agent version is patched. It is only set when the
+	// readiness probe runs as a server, a single check always patches the Pod.
+	AnnotationThrottle *pod.AnnotationThrottle
 }
 
 func BuildFromEnvVariables(clientSet kubernetes.Interface, isHeadless bool, file *os.File) (Config, error) {
@@ -55,20 +112,78 @@ func BuildFromEnvVariables(clientSet kubernetes.Interface, isHeadless bool, file
 		if !ok {
 			return Config{}, fmt.Errorf("the '%s' environment variable must be set", hostNameEnv)
 		}
+	} else {
+		hostname = os.Getenv(hostNameEnv) // nolint:forbidigo
 	}
 
 	// Note, that we shouldn't close the file here - it will be closed very soon by the 'ioutil.ReadAll'
 	// in main.go
 	return Config{
-		ClientSet:                  clientSet,
-		Namespace:                  namespace,
-		AutomationConfigSecretName: automationConfigName,
-		Hostname:                   hostname,
-		HealthStatusReader:         file,
-		LogFilePath:                logFilePath,
+		ClientSet:                      clientSet,
+		Namespace:                      namespace,
+		AutomationConfigSecretName:     automationConfigName,
+		AutomationConfigBackupFilePath: GetEnvOrDefault(AutomationConfigBackupFilePathEnv, DefaultAutomationConfigBackupFilePath),
+		Hostname:                       hostname,
+		HealthStatusReader:             file,
+		LogFilePath:                    logFilePath,
+		MaxReplicationLag:              time.Duration(readInt(ReadinessProbeMaxReplicationLagSeconds)) * time.Second,
+		Heuristics:                     heuristicsFromEnvVariables(),
 	}, nil
 }
 
+// Heuristics are the policies used by the readiness probe to trade rollout speed against safety.
+type Heuristics struct {
+	// WaitStepGracePeriod is the time the agent must have spent on a wait step before the Pod is considered ready.
+	WaitStepGracePeriod time.Duration
+	// WaitStepsReady configures whether a Pod whose agent is on a wait step is considered ready.
+	WaitStepsReady bool
+	// WaitStepAllowList contains the steps which are considered wait steps even if the agent does not report them as such.
+	WaitStepAllowList []string
+	// WaitStepDenyList contains the steps which are never considered wait steps.
+	WaitStepDenyList []string
+	// MongodNotReadyInterval is the time after which mongod is considered not ready if the agent could not reach it.
+	MongodNotReadyInterval time.Duration
+}
+
+// DefaultHeuristics returns the heuristics used when none are configured.
+func DefaultHeuristics() Heuristics {
+	return Heuristics{
+		WaitStepGracePeriod:    defaultWaitStepGracePeriod,
+		WaitStepsReady:         true,
+		MongodNotReadyInterval: defaultMongodNotReadyInterval,
+	}
+}
+
+func heuristicsFromEnvVariables() Heuristics {
+	defaults := DefaultHeuristics()
+	return Heuristics{
+		WaitStepGracePeriod:    time.Duration(readIntOrDefault(ReadinessProbeWaitStepGracePeriodSeconds, int(defaults.WaitStepGracePeriod.Seconds()))) * time.Second,
+		WaitStepsReady:         ReadBoolWitDefault(ReadinessProbeWaitStepsReady, strconv.FormatBool(defaults.WaitStepsReady)),
+		WaitStepAllowList:      readList(ReadinessProbeWaitStepAllowList),
+		WaitStepDenyList:       readList(ReadinessProbeWaitStepDenyList),
+		MongodNotReadyInterval: time.Duration(readIntOrDefault(ReadinessProbeMongodNotReadySeconds, int(defaults.MongodNotReadyInterval.Seconds()))) * time.Second,
+	}
+}
+
+// LivenessConfig configures the liveness and startup checks.
+type LivenessConfig struct {
+	HealthStatusFilePath        string
+	HealthStatusStaleThreshold  time.Duration
+	MongodUnresponsiveThreshold time.Duration
+	// MongodNotReadyInterval is the time after which mongod is considered not started if the agent could not reach
+	// it. It is the same setting as the one of the readiness heuristics.
+	MongodNotReadyInterval time.Duration
+}
+
+func BuildLivenessFromEnvVariables() LivenessConfig {
+	return LivenessConfig{
+		HealthStatusFilePath:        GetEnvOrDefault(AgentHealthStatusFilePathEnv, DefaultAgentHealthStatusFilePath),
+		HealthStatusStaleThreshold:  time.Duration(readIntOrDefault(LivenessProbeHealthStatusStaleSeconds, defaultHealthStatusStaleSeconds)) * time.Second,
+		MongodUnresponsiveThreshold: time.Duration(readIntOrDefault(LivenessProbeMongodUnresponsiveSeconds, defaultMongodUnresponsiveSeconds)) * time.Second,
+		MongodNotReadyInterval:      heuristicsFromEnvVariables().MongodNotReadyInterval,
+	}
+}
+
 func GetLogger() *lumberjack.Logger {
 	logger := &lumberjack.Logger{
 		Filename:   readinessProbeLogFilePath(),
@@ -109,6 +224,17 @@ func readIntOrDefault(envVarName string, defaultValue int) int {
 	return intValue
 }
 
+// readList returns the comma separated values of an envvar of the given name.
+func readList(envVarName string) []string {
+	var values []string
+	for _, value := range strings.Split(GetEnvOrDefault(envVarName, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // ReadBoolWitDefault returns the boolean value of an envvar of the given name.
 func ReadBoolWitDefault(envVarName string, defaultValue string) bool {
 	envVar := GetEnvOrDefault(envVarName, defaultValue)
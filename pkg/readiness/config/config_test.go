@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeuristicsFromEnvVariables(t *testing.T) {
+	assert.Equal(t, DefaultHeuristics(), heuristicsFromEnvVariables())
+
+	t.Setenv(ReadinessProbeWaitStepGracePeriodSeconds, "30")
+	t.Setenv(ReadinessProbeWaitStepsReady, "false")
+	t.Setenv(ReadinessProbeWaitStepAllowList, "Stop, Download")
+	t.Setenv(ReadinessProbeWaitStepDenyList, "WaitAllRsMembersUp")
+	t.Setenv(ReadinessProbeMongodNotReadySeconds, "120")
+
+	assert.Equal(t, Heuristics{
+		WaitStepGracePeriod:    30 * time.Second,
+		WaitStepsReady:         false,
+		WaitStepAllowList:      []string{"Stop", "Download"},
+		WaitStepDenyList:       []string{"WaitAllRsMembersUp"},
+		MongodNotReadyInterval: 2 * time.Minute,
+	}, heuristicsFromEnvVariables())
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/config"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/health"
@@ -55,8 +56,11 @@ func PerformCheckHeadlessMode(ctx context.Context, health health.Status, conf co
 
 	currentAgentVersion := readCurrentAgentInfo(health, targetVersion)
 
-	if err = pod.PatchPodAnnotation(ctx, conf.Namespace, currentAgentVersion, conf.Hostname, conf.ClientSet); err != nil {
-		return false, err
+	if now := time.Now(); conf.AnnotationThrottle.ShouldPatch(currentAgentVersion, now) {
+		if err = pod.PatchPodAnnotation(ctx, conf.Namespace, currentAgentVersion, conf.Hostname, conf.ClientSet); err != nil {
+			return false, err
+		}
+		conf.AnnotationThrottle.Patched(currentAgentVersion, now)
 	}
 
 	return targetVersion == currentAgentVersion, nil
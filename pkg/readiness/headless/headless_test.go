@@ -3,10 +3,12 @@ package headless
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/mongodb/mongodb-kubernetes-operator/cmd/readiness/testdata"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/config"
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/health"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/readiness/pod"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -34,6 +36,32 @@ func TestPerformCheckHeadlessMode(t *testing.T) {
 	assert.Equal(t, map[string]string{"agent.mongodb.com/version": "10"}, thePod.Annotations)
 }
 
+func TestPerformCheckHeadlessModeThrottlesPodPatches(t *testing.T) {
+	ctx := context.Background()
+	c := testConfig()
+	c.AnnotationThrottle = pod.NewAnnotationThrottle(time.Hour)
+
+	c.ClientSet = fake.NewSimpleClientset(testdata.TestPod(c.Namespace, c.Hostname), testdata.TestSecret(c.Namespace, c.AutomationConfigSecretName, 10))
+	status := health.Status{
+		MmsStatus: map[string]health.MmsDirectorStatus{c.Hostname: {
+			LastGoalStateClusterConfigVersion: 10,
+		}},
+	}
+
+	_, err := PerformCheckHeadlessMode(ctx, status, c)
+	require.NoError(t, err)
+	_, err = PerformCheckHeadlessMode(ctx, status, c)
+	require.NoError(t, err)
+
+	patches := 0
+	for _, action := range c.ClientSet.(*fake.Clientset).Actions() {
+		if action.GetVerb() == "patch" {
+			patches++
+		}
+	}
+	assert.Equal(t, 1, patches)
+}
+
 func testConfig() config.Config {
 	return config.Config{
 		Namespace:                  "test-ns",
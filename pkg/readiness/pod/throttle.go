@@ -0,0 +1,43 @@
+package pod
+
+import (
+	"sync"
+	"time"
+)
+
+// AnnotationThrottle keeps track of the agent version last written to the Pod annotation, so that a long-running
+// readiness probe does not patch the Pod with the same version on every check.
+type AnnotationThrottle struct {
+	mu          sync.Mutex
+	interval    time.Duration
+	lastVersion int64
+	lastPatch   time.Time
+}
+
+// NewAnnotationThrottle returns an AnnotationThrottle which allows the same version to be patched again
+// only once the given interval has elapsed.
+func NewAnnotationThrottle(interval time.Duration) *AnnotationThrottle {
+	return &AnnotationThrottle{interval: interval, lastVersion: -1}
+}
+
+// ShouldPatch returns true if the version differs from the last patched one or if the last patch is older than the interval.
+// A nil AnnotationThrottle always allows patching.
+func (t *AnnotationThrottle) ShouldPatch(version int64, now time.Time) bool {
+	if t == nil {
+		return true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return version != t.lastVersion || now.Sub(t.lastPatch) >= t.interval
+}
+
+// Patched records that the given version has been written to the Pod annotation.
+func (t *AnnotationThrottle) Patched(version int64, now time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastVersion = version
+	t.lastPatch = now
+}
@@ -0,0 +1,26 @@
+package pod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotationThrottle(t *testing.T) {
+	now := time.Now()
+	throttle := NewAnnotationThrottle(time.Minute)
+
+	assert.True(t, throttle.ShouldPatch(1, now))
+	throttle.Patched(1, now)
+
+	assert.False(t, throttle.ShouldPatch(1, now.Add(time.Second)), "the same version is not patched again within the interval")
+	assert.True(t, throttle.ShouldPatch(2, now.Add(time.Second)), "a new version is always patched")
+	assert.True(t, throttle.ShouldPatch(1, now.Add(time.Minute)), "the same version is patched again after the interval")
+}
+
+func TestNilAnnotationThrottleAlwaysPatches(t *testing.T) {
+	var throttle *AnnotationThrottle
+	throttle.Patched(1, time.Now())
+	assert.True(t, throttle.ShouldPatch(1, time.Now()))
+}
@@ -0,0 +1,182 @@
+package replication
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	memberStatePrimary   = "PRIMARY"
+	memberStateSecondary = "SECONDARY"
+
+	connectTimeout = 2 * time.Second
+)
+
+// LagReader returns how far behind the primary the local replica set member is.
+type LagReader interface {
+	ReplicationLag(ctx context.Context) (time.Duration, error)
+}
+
+type replSetStatus struct {
+	Members []replSetMember `bson:"members"`
+}
+
+type replSetMember struct {
+	Name       string    `bson:"name"`
+	StateStr   string    `bson:"stateStr"`
+	OptimeDate time.Time `bson:"optimeDate"`
+	Self       bool      `bson:"self"`
+}
+
+type mongoLagReader struct {
+	clientOptions *options.ClientOptions
+}
+
+// NewLagReader returns a LagReader which runs replSetGetStatus against the mongod process described by
+// the given client options.
+func NewLagReader(clientOptions *options.ClientOptions) LagReader {
+	return &mongoLagReader{clientOptions: clientOptions}
+}
+
+func (r *mongoLagReader) ReplicationLag(ctx context.Context) (time.Duration, error) {
+	client, err := mongo.Connect(ctx, r.clientOptions)
+	if err != nil {
+		return 0, fmt.Errorf("could not connect to mongod: %s", err)
+	}
+	defer func() {
+		_ = client.Disconnect(ctx)
+	}()
+
+	status := replSetStatus{}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&status); err != nil {
+		return 0, fmt.Errorf("could not run replSetGetStatus: %s", err)
+	}
+	return lagFromStatus(status)
+}
+
+// lagFromStatus returns the difference between the last applied operation of the primary and the one of this member.
+// Only secondaries can lag behind, for every other state the lag is 0.
+func lagFromStatus(status replSetStatus) (time.Duration, error) {
+	var self, primary *replSetMember
+	for i := range status.Members {
+		member := &status.Members[i]
+		if member.Self {
+			self = member
+		}
+		if member.StateStr == memberStatePrimary {
+			primary = member
+		}
+	}
+
+	if self == nil {
+		return 0, fmt.Errorf("the member itself is not part of the replica set status")
+	}
+	if self.StateStr != memberStateSecondary {
+		return 0, nil
+	}
+	if primary == nil {
+		return 0, fmt.Errorf("there is no primary in the replica set, the replication lag of %s cannot be determined", self.Name)
+	}
+
+	lag := primary.OptimeDate.Sub(self.OptimeDate)
+	if lag < 0 {
+		return 0, nil
+	}
+	return lag, nil
+}
+
+// ClientOptionsFromAutomationConfig builds the options to connect to the local mongod process as the automation agent,
+// using the port, TLS and authentication settings of the given automation config.
+func ClientOptionsFromAutomationConfig(ac automationconfig.AutomationConfig, hostname string) (*options.ClientOptions, error) {
+	process := findProcess(ac, hostname)
+	if process == nil {
+		return nil, fmt.Errorf("process %s is not present in the automation config", hostname)
+	}
+
+	port := process.GetPort()
+	if port == 0 {
+		port = automationconfig.DefaultDBPort
+	}
+
+	clientOptions := options.Client().
+		SetHosts([]string{fmt.Sprintf("localhost:%d", port)}).
+		SetDirect(true).
+		SetConnectTimeout(connectTimeout).
+		SetServerSelectionTimeout(connectTimeout)
+
+	x509 := !ac.Auth.Disabled && ac.Auth.AutoAuthMechanism == constants.X509
+	if isTLSEnabled(*process) || x509 {
+		tlsConfig, err := buildTLSConfig(ac, *process, x509)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
+
+	if !ac.Auth.Disabled {
+		clientOptions.SetAuth(agentCredential(ac.Auth))
+	}
+
+	return clientOptions, nil
+}
+
+// isTLSEnabled returns true if the process accepts TLS connections.
+func isTLSEnabled(process automationconfig.Process) bool {
+	mode := process.Args26.Get("net.tls.mode").Str()
+	return mode != "" && mode != string(automationconfig.TLSModeDisabled)
+}
+
+// buildTLSConfig returns the TLS configuration of the agent: the server certificate is verified with the CA file
+// of the agent and, when the agent authenticates with X.509, its client certificate is presented.
+func buildTLSConfig(ac automationconfig.AutomationConfig, process automationconfig.Process, x509 bool) (*tls.Config, error) {
+	if ac.TLSConfig == nil || ac.TLSConfig.CAFilePath == "" {
+		return nil, fmt.Errorf("TLS is enabled but the automation config has no CA file")
+	}
+	tlsOptions := map[string]interface{}{
+		"tlsCAFile": ac.TLSConfig.CAFilePath,
+	}
+	if ac.TLSConfig.AutoPEMKeyFilePath != "" {
+		tlsOptions["tlsCertificateKeyFile"] = ac.TLSConfig.AutoPEMKeyFilePath
+	} else if x509 {
+		return nil, fmt.Errorf("the agent authenticates with X.509 but the automation config has no agent certificate")
+	}
+	tlsConfig, err := options.BuildTLSConfig(tlsOptions)
+	if err != nil {
+		return nil, fmt.Errorf("could not build the TLS configuration: %s", err)
+	}
+	// mongod is reached through localhost, the certificate is verified against the hostname of the process instead
+	tlsConfig.ServerName = process.HostName
+	return tlsConfig, nil
+}
+
+// agentCredential returns the credential the automation agent uses to authenticate to mongod.
+func agentCredential(auth automationconfig.Auth) options.Credential {
+	switch auth.AutoAuthMechanism {
+	case constants.X509:
+		return options.Credential{AuthMechanism: constants.X509, AuthSource: constants.ExternalDB}
+	case constants.Sha1:
+		return options.Credential{AuthMechanism: "SCRAM-SHA-1", AuthSource: "admin", Username: auth.AutoUser, Password: auth.AutoPwd}
+	default:
+		return options.Credential{AuthMechanism: constants.Sha256, AuthSource: "admin", Username: auth.AutoUser, Password: auth.AutoPwd}
+	}
+}
+
+// findProcess returns the process running in the Pod with the given hostname. The hostname is the Pod name, while
+// processes are registered with their FQDN.
+func findProcess(ac automationconfig.AutomationConfig, hostname string) *automationconfig.Process {
+	for i := range ac.Processes {
+		if ac.Processes[i].Name == hostname || strings.HasPrefix(ac.Processes[i].HostName, hostname+".") {
+			return &ac.Processes[i]
+		}
+	}
+	return nil
+}
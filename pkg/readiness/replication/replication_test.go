@@ -0,0 +1,160 @@
+package replication
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/x509"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/util/constants"
+	"github.com/stretchr/objx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLagFromStatus(t *testing.T) {
+	now := time.Now()
+	tests := map[string]struct {
+		status          replSetStatus
+		expectedLag     time.Duration
+		isErrorExpected bool
+	}{
+		"Secondary behind the primary": {
+			status: replSetStatus{Members: []replSetMember{
+				{Name: "mdb-0", StateStr: memberStatePrimary, OptimeDate: now},
+				{Name: "mdb-1", StateStr: memberStateSecondary, OptimeDate: now.Add(-time.Minute), Self: true},
+			}},
+			expectedLag: time.Minute,
+		},
+		"Secondary ahead of the primary": {
+			status: replSetStatus{Members: []replSetMember{
+				{Name: "mdb-0", StateStr: memberStatePrimary, OptimeDate: now.Add(-time.Second)},
+				{Name: "mdb-1", StateStr: memberStateSecondary, OptimeDate: now, Self: true},
+			}},
+			expectedLag: 0,
+		},
+		"Primary does not lag": {
+			status: replSetStatus{Members: []replSetMember{
+				{Name: "mdb-0", StateStr: memberStatePrimary, OptimeDate: now, Self: true},
+				{Name: "mdb-1", StateStr: memberStateSecondary, OptimeDate: now.Add(-time.Hour)},
+			}},
+			expectedLag: 0,
+		},
+		"Arbiter does not lag": {
+			status: replSetStatus{Members: []replSetMember{
+				{Name: "mdb-0", StateStr: memberStatePrimary, OptimeDate: now},
+				{Name: "mdb-1", StateStr: "ARBITER", Self: true},
+			}},
+			expectedLag: 0,
+		},
+		"No primary": {
+			status: replSetStatus{Members: []replSetMember{
+				{Name: "mdb-0", StateStr: memberStateSecondary, OptimeDate: now},
+				{Name: "mdb-1", StateStr: memberStateSecondary, OptimeDate: now, Self: true},
+			}},
+			isErrorExpected: true,
+		},
+		"Self is missing": {
+			status: replSetStatus{Members: []replSetMember{
+				{Name: "mdb-0", StateStr: memberStatePrimary, OptimeDate: now},
+			}},
+			isErrorExpected: true,
+		},
+	}
+	for testName, tc := range tests {
+		t.Run(testName, func(t *testing.T) {
+			lag, err := lagFromStatus(tc.status)
+			if tc.isErrorExpected {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedLag, lag)
+		})
+	}
+}
+
+func TestClientOptionsFromAutomationConfig(t *testing.T) {
+	ac := automationconfig.AutomationConfig{
+		Processes: []automationconfig.Process{
+			{Name: "mdb-0", HostName: "mdb-0.mdb-svc.ns.svc.cluster.local"},
+			{Name: "mdb-1", HostName: "mdb-1.mdb-svc.ns.svc.cluster.local"},
+		},
+		Auth: automationconfig.Auth{
+			AutoAuthMechanism: constants.Sha256,
+			AutoUser:          "mms-automation",
+			AutoPwd:           "password",
+		},
+	}
+	ac.Processes[1].SetPort(40333)
+
+	t.Run("Process is found by the Pod name", func(t *testing.T) {
+		clientOptions, err := ClientOptionsFromAutomationConfig(ac, "mdb-1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"localhost:40333"}, clientOptions.Hosts)
+		assert.Equal(t, "mms-automation", clientOptions.Auth.Username)
+		assert.Equal(t, constants.Sha256, clientOptions.Auth.AuthMechanism)
+		assert.Nil(t, clientOptions.TLSConfig)
+	})
+
+	t.Run("Default port is used", func(t *testing.T) {
+		clientOptions, err := ClientOptionsFromAutomationConfig(ac, "mdb-0")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"localhost:27017"}, clientOptions.Hosts)
+	})
+
+	t.Run("Unknown process", func(t *testing.T) {
+		_, err := ClientOptionsFromAutomationConfig(ac, "mdb-2")
+		assert.Error(t, err)
+	})
+
+	t.Run("No credentials when auth is disabled", func(t *testing.T) {
+		noAuthAc := ac
+		noAuthAc.Auth = automationconfig.Auth{Disabled: true}
+		clientOptions, err := ClientOptionsFromAutomationConfig(noAuthAc, "mdb-0")
+		require.NoError(t, err)
+		assert.Nil(t, clientOptions.Auth)
+	})
+	t.Run("The server certificate is verified with the agent CA", func(t *testing.T) {
+		caFile, pemFile := writeAgentCertificate(t)
+		tlsAc := ac
+		tlsAc.Processes = []automationconfig.Process{{Name: "mdb-0", HostName: "mdb-0.mdb-svc.ns.svc.cluster.local", Args26: objx.New(map[string]interface{}{})}}
+		tlsAc.Processes[0].Args26.Set("net.tls.mode", string(automationconfig.TLSModePreferred))
+		tlsAc.TLSConfig = &automationconfig.TLS{CAFilePath: caFile}
+		clientOptions, err := ClientOptionsFromAutomationConfig(tlsAc, "mdb-0")
+		require.NoError(t, err)
+		require.NotNil(t, clientOptions.TLSConfig)
+		assert.False(t, clientOptions.TLSConfig.InsecureSkipVerify)
+		assert.NotNil(t, clientOptions.TLSConfig.RootCAs)
+		assert.Equal(t, "mdb-0.mdb-svc.ns.svc.cluster.local", clientOptions.TLSConfig.ServerName)
+		assert.Empty(t, clientOptions.TLSConfig.Certificates)
+
+		t.Run("The agent certificate is presented with X.509", func(t *testing.T) {
+			x509Ac := tlsAc
+			x509Ac.Auth = automationconfig.Auth{AutoAuthMechanism: constants.X509}
+			_, err := ClientOptionsFromAutomationConfig(x509Ac, "mdb-0")
+			assert.ErrorContains(t, err, "no agent certificate")
+
+			x509Ac.TLSConfig = &automationconfig.TLS{CAFilePath: caFile, AutoPEMKeyFilePath: pemFile}
+			clientOptions, err := ClientOptionsFromAutomationConfig(x509Ac, "mdb-0")
+			require.NoError(t, err)
+			assert.Len(t, clientOptions.TLSConfig.Certificates, 1)
+			assert.Equal(t, constants.X509, clientOptions.Auth.AuthMechanism)
+			assert.Equal(t, constants.ExternalDB, clientOptions.Auth.AuthSource)
+		})
+	})
+}
+
+// writeAgentCertificate writes a self-signed certificate, used as its own CA, and the PEM file with its key.
+func writeAgentCertificate(t *testing.T) (string, string) {
+	cert, key, err := x509.CreateAgentCertificate()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	pemFile := filepath.Join(dir, "agent.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte(cert), 0600))
+	require.NoError(t, os.WriteFile(pemFile, []byte(cert+key), 0600))
+	return caFile, pemFile
+}
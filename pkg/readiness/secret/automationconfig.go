@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/mongodb/mongodb-kubernetes-operator/pkg/automationconfig"
 	"github.com/spf13/cast"
 	"k8s.io/client-go/kubernetes"
 )
@@ -29,3 +30,17 @@ func ReadAutomationConfigVersionFromSecret(ctx context.Context, namespace string
 	}
 	return cast.ToInt64(version), nil
 }
+
+// ReadAutomationConfigFromSecret reads the whole automation config from the given secret.
+func ReadAutomationConfigFromSecret(ctx context.Context, namespace string, clientSet kubernetes.Interface, automationConfigMap string) (automationconfig.AutomationConfig, error) {
+	secretReader := newKubernetesSecretReader(clientSet)
+	theSecret, err := secretReader.ReadSecret(ctx, namespace, automationConfigMap)
+	if err != nil {
+		return automationconfig.AutomationConfig{}, err
+	}
+	var ac automationconfig.AutomationConfig
+	if err := json.Unmarshal(theSecret.Data[automationConfigKey], &ac); err != nil {
+		return automationconfig.AutomationConfig{}, err
+	}
+	return ac, nil
+}
@@ -5,6 +5,8 @@ const (
 	Sha256                                = "SCRAM-SHA-256"
 	Sha1                                  = "MONGODB-CR"
 	X509                                  = "MONGODB-X509"
+	Ldap                                  = "PLAIN"
+	Oidc                                  = "MONGODB-OIDC"
 	AutomationAgentKeyFilePathInContainer = "/var/lib/mongodb-mms-automation/authentication/keyfile"
 	AgentName                             = "mms-automation"
 	AgentPasswordKey                      = "password"
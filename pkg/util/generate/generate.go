@@ -5,7 +5,10 @@ import (
 	"crypto/sha1" // nolint
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"hash"
+	"math/big"
 	"unicode"
 
 	"github.com/mongodb/mongodb-kubernetes-operator/pkg/authentication/scramcredentials"
@@ -42,6 +45,58 @@ func RandomFixedLengthStringOfSize(n int) (string, error) {
 	return base64.URLEncoding.EncodeToString(b)[:n], err
 }
 
+// Character sets which can be passed to RandomStringFromCharacterSets.
+const (
+	LowercaseCharacters = "abcdefghijklmnopqrstuvwxyz"
+	UppercaseCharacters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	DigitCharacters     = "0123456789"
+	SymbolCharacters    = "!#%*+-.=?@^_~"
+)
+
+// RandomStringFromCharacterSets generates a random fixed-length string which contains
+// at least one character of each of the given character sets.
+func RandomStringFromCharacterSets(n int, characterSets ...string) (string, error) {
+	if len(characterSets) == 0 {
+		return "", errors.New("at least one character set is required")
+	}
+	if n < len(characterSets) {
+		return "", fmt.Errorf("a string of size %d cannot contain a character of each of the %d character sets", n, len(characterSets))
+	}
+
+	alphabet := ""
+	for _, characterSet := range characterSets {
+		if characterSet == "" {
+			return "", errors.New("character sets must not be empty")
+		}
+		alphabet += characterSet
+	}
+
+	chars := make([]byte, n)
+	for i := range chars {
+		// the first characters are picked from each set so that every set is represented
+		characterSet := alphabet
+		if i < len(characterSets) {
+			characterSet = characterSets[i]
+		}
+		idx, err := randomInt(len(characterSet))
+		if err != nil {
+			return "", err
+		}
+		chars[i] = characterSet[idx]
+	}
+
+	// shuffle the characters so that the position of the guaranteed characters cannot be predicted
+	for i := len(chars) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		chars[i], chars[j] = chars[j], chars[i]
+	}
+
+	return string(chars), nil
+}
+
 // Salts generates 2 different salts. The first is for the sha1 algorithm
 // the second is for sha256
 func Salts() ([]byte, []byte, error) {
@@ -82,6 +137,15 @@ func generateRandomBytes(size int) ([]byte, error) {
 	return b, nil
 }
 
+// randomInt returns a uniformly distributed random int in [0, upperBound).
+func randomInt(upperBound int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(upperBound)))
+	if err != nil {
+		return 0, err
+	}
+	return int(n.Int64()), nil
+}
+
 func generateRandomString(numBytes int) (string, error) {
 	b, err := generateRandomBytes(numBytes)
 	return base64.StdEncoding.EncodeToString(b), err
@@ -0,0 +1,32 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomStringFromCharacterSets(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		str, err := RandomStringFromCharacterSets(8, LowercaseCharacters, DigitCharacters, SymbolCharacters)
+		require.NoError(t, err)
+		assert.Len(t, str, 8)
+		assert.True(t, strings.ContainsAny(str, LowercaseCharacters))
+		assert.True(t, strings.ContainsAny(str, DigitCharacters))
+		assert.True(t, strings.ContainsAny(str, SymbolCharacters))
+		assert.False(t, strings.ContainsAny(str, UppercaseCharacters))
+	}
+}
+
+func TestRandomStringFromCharacterSets_InvalidArguments(t *testing.T) {
+	_, err := RandomStringFromCharacterSets(8)
+	assert.Error(t, err)
+
+	_, err = RandomStringFromCharacterSets(1, LowercaseCharacters, DigitCharacters)
+	assert.Error(t, err)
+
+	_, err = RandomStringFromCharacterSets(8, LowercaseCharacters, "")
+	assert.Error(t, err)
+}